@@ -0,0 +1,200 @@
+package main
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+const agendaDayForm = "Monday 2 January" // Headings of the agenda's days.
+
+// dayParts are the parts of the day timed agenda items are grouped under,
+// by the hour they start at.
+var dayParts = []struct {
+	name string
+	from int
+}{
+	{"Morning", 0},
+	{"Afternoon", 12},
+	{"Evening", 17},
+}
+
+// todayCommand returns the today command.
+func todayCommand() *command {
+	return &command{
+		name: "today", summary: "Show today's agenda: overdue, due, scheduled and waking tasks", group: groupPlanning,
+		setup: run(func([]string) error {
+			return printAgenda(clock(), 1)
+		}),
+	}
+}
+
+// agendaCommand returns the agenda command.
+func agendaCommand() *command {
+	return &command{
+		name: "agenda", summary: "Show the agenda of the coming days, day by day", group: groupPlanning,
+		setup: func(fs *flag.FlagSet) runFunc {
+			week := fs.Bool("week", false, "show the coming 7 days")
+			days := fs.Int("days", 1, "number of `days` to show, from today")
+			return func([]string) error {
+				if *week {
+					*days = 7
+				}
+				if *days < 1 {
+					return usagef("invalid number of days %d", *days)
+				}
+				return printAgenda(clock(), *days)
+			}
+		},
+	}
+}
+
+// agendaItem is a task on one day of the agenda, with why it is there and
+// when, if at a time of day.
+type agendaItem struct {
+	task  Task
+	kind  string // "overdue", "due", "scheduled", "wakes" or "repeats".
+	at    time.Time
+	timed bool
+}
+
+// agendaDay returns the open tasks of the current project on the day
+// starting at day: those due, scheduled, waking from a snooze, or coming
+// round again as the next occurrence of a recurring task on it, and on the
+// first day of the agenda the overdue ones carried over. A task is listed
+// once a day, for the first of those reasons.
+func agendaDay(tasks []Task, day time.Time, first bool) []agendaItem {
+	end := day.AddDate(0, 0, 1)
+	on := func(t *time.Time) bool { return t != nil && !t.Before(day) && t.Before(end) }
+	var items []agendaItem
+	for _, t := range tasks {
+		if config.Workflow.IsDone(t.Status) || t.Wake != nil && !t.Wake.Before(end) {
+			continue
+		}
+		var item agendaItem
+		switch {
+		case first && t.Due != nil && !tracker.Deadline(*t.Due).After(day):
+			item = agendaItem{kind: "overdue", at: *t.Due}
+		case on(t.Due):
+			item = agendaItem{kind: "due", at: *t.Due}
+		case on(t.Scheduled):
+			item = agendaItem{kind: "scheduled", at: *t.Scheduled}
+		case on(t.Wake):
+			item = agendaItem{kind: "wakes", at: *t.Wake}
+		case t.Recur != "" && t.Due != nil:
+			next := *t.Due
+			for next.Before(day) {
+				next = tracker.NextOccurrence(next, t.Recur)
+			}
+			if !next.Before(end) {
+				continue
+			}
+			item = agendaItem{kind: "repeats", at: next}
+		default:
+			continue
+		}
+		item.task = t
+		item.timed = item.kind != "overdue" && !item.at.Equal(startOfDay(item.at))
+		items = append(items, item)
+	}
+	return items
+}
+
+// printAgenda prints the agenda of the given number of days from now's,
+// each grouped by the part of the day its timed tasks start in, then the
+// tasks of the day without a time. Overdue tasks come first, then by
+// time, then by urgency.
+func printAgenda(now time.Time, days int) error {
+	tasks, err := tr().Tasks()
+	if err != nil {
+		return err
+	}
+	scores := urgencies(tasks, now)
+	today := startOfDay(now)
+
+	for d := range days {
+		day := today.AddDate(0, 0, d)
+		heading := formatLocalTime(day, agendaDayForm)
+		switch d {
+		case 0:
+			heading = fmt.Sprintf(msg("Today, %s"), heading)
+		case 1:
+			heading = fmt.Sprintf(msg("Tomorrow, %s"), heading)
+		}
+		fmt.Fprintf(stdout, "--- %s ---\n", heading)
+
+		items := agendaDay(tasks, day, d == 0)
+		if len(items) == 0 {
+			fmt.Fprintln(stdout, msg("Nothing planned."))
+			continue
+		}
+		slices.SortStableFunc(items, func(a, b agendaItem) int {
+			c := cmp.Compare(agendaGroup(a), agendaGroup(b))
+			if c == 0 && a.timed {
+				c = a.at.Compare(b.at)
+			}
+			return cmp.Or(c, cmp.Compare(scores[b.task.ID].score, scores[a.task.ID].score), cmp.Compare(a.task.ID, b.task.ID))
+		})
+
+		part := ""
+		for _, item := range items {
+			if p := agendaPart(item); p != part {
+				part = p
+				fmt.Fprintln(stdout, colorize(part, "gray"))
+			}
+			at := "     "
+			if item.timed {
+				at = item.at.Format("15:04")
+			}
+			var note string
+			switch item.kind {
+			case "overdue":
+				note = relativeDue(*item.task.Due, now)
+			case "repeats":
+				note = fmt.Sprintf(msg("repeats %s"), item.task.Recur)
+			default:
+				note = msg(item.kind)
+			}
+			fmt.Fprintf(stdout, "  %s [ID: %d] [%s] %s %s", at, item.task.ID, colorStatus(item.task.Status), item.task.Description, colorize("("+note+")", "gray"))
+			if progress := checklistProgress(item.task); progress != "" {
+				fmt.Fprint(stdout, " "+progress)
+			}
+			fmt.Fprintln(stdout)
+		}
+	}
+	fmt.Fprintln(stdout, "-----------------")
+	return nil
+}
+
+// agendaGroup orders overdue items first, then timed ones, then the rest.
+func agendaGroup(item agendaItem) int {
+	switch {
+	case item.kind == "overdue":
+		return 0
+	case item.timed:
+		return 1
+	}
+	return 2
+}
+
+// agendaPart returns the heading an item is listed under: "Overdue", the
+// part of the day it starts in, or "Any time".
+func agendaPart(item agendaItem) string {
+	switch {
+	case item.kind == "overdue":
+		return msg("Overdue")
+	case !item.timed:
+		return msg("Any time")
+	}
+	part := dayParts[0].name
+	for _, p := range dayParts {
+		if item.at.Hour() >= p.from {
+			part = p.name
+		}
+	}
+	return msg(part)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+const anomalyLookback = 90 // Days 'expense report anomalies' looks back by default.
+
+// expenseReportCommand returns the expense report command group.
+func expenseReportCommand() *command {
+	return &command{
+		name: "report", summary: "Show reports on expenses",
+		subcommands: []*command{
+			{
+				name: "anomalies", summary: "Flag unusually large expenses, duplicate charges and category spikes",
+				setup: func(fs *flag.FlagSet) runFunc {
+					since := fs.String("since", "", fmt.Sprintf("only flag expenses on or after this `date` (default %d days ago)", anomalyLookback))
+					days := fs.Int("days", 0, "flag the same charge this many `days` apart as a duplicate (default 3)")
+					factor := fs.Float64("factor", 0, "flag expenses this many `times` their category's median as large (default 3)")
+					return func([]string) error {
+						now := clock()
+						opts := tracker.AnomalyOptions{
+							Since:         startOfDay(now).AddDate(0, 0, -anomalyLookback),
+							DuplicateDays: *days,
+							LargeFactor:   *factor,
+						}
+						if *since != "" {
+							window, err := parseDateRange(*since, "", now)
+							if err != nil {
+								return usagef("%v", err)
+							}
+							opts.Since = *window.Since
+						}
+						if *days < 0 || *factor < 0 {
+							return usagef("--days and --factor must not be negative")
+						}
+						return reportAnomalies(opts)
+					}
+				},
+			},
+		},
+	}
+}
+
+// reportAnomalies prints the anomalies in the current project's expenses,
+// oldest first. Recurring charges that have come due are recorded first.
+func reportAnomalies(opts tracker.AnomalyOptions) error {
+	if _, err := chargeRecurringExpenses(clock()); err != nil {
+		return err
+	}
+	anomalies, err := tr().Anomalies(opts)
+	if err != nil {
+		return err
+	}
+	if len(anomalies) == 0 {
+		fmt.Fprintf(stdout, "No anomalies found since %s.\n", opts.Since.Format(dateLayout))
+		return nil
+	}
+
+	fmt.Fprintln(stdout, "--- Expense Anomalies ---")
+	tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	for _, a := range anomalies {
+		amount := func(v float64) string { return formatAmount(v, cmp.Or(a.Currency, config.Currency.Base)) }
+		category := cmp.Or(a.Category, "uncategorized")
+		switch a.Kind {
+		case tracker.AnomalyLarge:
+			e := a.Expenses[0]
+			fmt.Fprintf(tw, "%s\t%s\t[ID: %d] %s %s\t%s usually costs %s\n",
+				e.Date.Format(dateLayout), a.Kind, e.ID, amount(e.Amount), expenseLabel(e), category, amount(a.Baseline))
+		case tracker.AnomalyDuplicate:
+			first, second := a.Expenses[0], a.Expenses[1]
+			fmt.Fprintf(tw, "%s\t%s\t[ID: %d] %s %s\tsame as ID %d, %s\n",
+				second.Date.Format(dateLayout), a.Kind, second.ID, amount(second.Amount), expenseLabel(second), first.ID, daysApart(first.Date, second.Date))
+		case tracker.AnomalySpike:
+			fmt.Fprintf(tw, "%s\t%s\t%s %s in %s\t%s a month over the %d months before\n",
+				a.Month.Format(monthLayout), a.Kind, category, amount(a.Amount), a.Month.Format("January"), amount(a.Baseline), cmp.Or(opts.TrailingMonths, 3))
+		}
+	}
+	tw.Flush()
+	fmt.Fprintln(stdout, "-----------------")
+	return nil
+}
+
+// expenseLabel names what an expense was for: its description, and its
+// payee if it has one.
+func expenseLabel(e Expense) string {
+	label := e.Description
+	if e.Payee != "" {
+		label = strings.TrimSpace(label + " @ " + e.Payee)
+	}
+	return label
+}
+
+// daysApart describes how far apart two dates are in days.
+func daysApart(a, b time.Time) string {
+	days := int(startOfDay(b).Sub(startOfDay(a)).Round(24*time.Hour).Hours() / 24)
+	if days == 0 {
+		return "the same day"
+	}
+	return plural(days, "day") + " apart"
+}
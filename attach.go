@@ -0,0 +1,289 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+const (
+	attachmentsFile = "attachments.json" // Metadata of the files attached to tasks and expenses.
+	attachmentsDir  = "attachments"      // Holds the attached files, a directory per record.
+)
+
+// Attachment is a file, such as a receipt, copied into the data directory
+// and attached to a task or an expense.
+type Attachment struct {
+	ID      int       `json:"id"`
+	Record  string    `json:"record"` // "task:<uuid>" or "expense:<id>".
+	Name    string    `json:"name"`   // The file's name when attached.
+	Path    string    `json:"path"`   // Of the copy, relative to the project's directory.
+	Size    int64     `json:"size"`
+	SHA256  string    `json:"sha256"`
+	AddedAt time.Time `json:"addedAt"`
+}
+
+// taskRecord and expenseRecord key the attachments of a task, by UUID so
+// they survive its ID being reused, and of an expense.
+func taskRecord(task Task) string { return "task:" + task.UUID }
+func expenseRecord(id int) string { return fmt.Sprintf("expense:%d", id) }
+
+// attachCommand returns the attach command.
+func attachCommand() *command {
+	return &command{
+		name: "attach", args: "<id> <file> | open <n> | delete <n>", summary: "Attach a file to a task, or open or delete an attachment", group: groupTasks, minArgs: 2,
+		complete: positional(taskIDs),
+		setup: run(func(args []string) error {
+			if len(args) != 2 {
+				return usagef("expected a task ID and a file, open <n> or delete <n>")
+			}
+			if args[0] == "open" || args[0] == "delete" {
+				n, err := parseID(args[1], "attachment")
+				if err != nil {
+					return err
+				}
+				if args[0] == "open" {
+					return openAttachment(n)
+				}
+				return deleteAttachment(n)
+			}
+			id, err := parseID(args[0], "task")
+			if err != nil {
+				return err
+			}
+			task, err := tr().Task(id)
+			if err != nil {
+				return err
+			}
+			return attachFile(taskRecord(task), args[1])
+		}),
+	}
+}
+
+// loadAttachments reads the current project's attachments.
+func loadAttachments() ([]Attachment, error) {
+	var attachments []Attachment
+	raw, err := loadDocument(attachmentsFile)
+	if err != nil || raw == nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &attachments); err != nil {
+		return nil, fmt.Errorf("error unmarshalling JSON: %w", err)
+	}
+	return attachments, nil
+}
+
+// recordAttachments returns the attachments of a record.
+func recordAttachments(record string) ([]Attachment, error) {
+	attachments, err := loadAttachments()
+	return slices.DeleteFunc(attachments, func(a Attachment) bool { return a.Record != record }), err
+}
+
+// attachFile copies a file into the record's attachments directory, under
+// a name not yet taken there, and records its size and checksum. A dry run
+// only reads the file.
+func attachFile(record, path string) error {
+	attachments, err := loadAttachments()
+	if err != nil {
+		return err
+	}
+	src, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("error reading file: %w", err)
+	}
+	defer src.Close()
+	if info, err := src.Stat(); err != nil || info.IsDir() {
+		return fmt.Errorf("'%s' is not a file", path)
+	}
+
+	name := filepath.Base(path)
+	rel := filepath.Join(attachmentsDir, strings.ReplaceAll(record, ":", "-"))
+	dir := filepath.Join(projectDir(currentProject), rel)
+	if dryRun {
+		// Only read the file, for its size and hash
+		hash := sha256.New()
+		size, err := io.Copy(hash, src)
+		if err != nil {
+			return fmt.Errorf("error reading file: %w", err)
+		}
+		dryRunActions = append(dryRunActions, "copy "+path+" to "+dir)
+		return saveAttachment(attachments, Attachment{
+			Record: record, Name: name, Path: filepath.Join(rel, name),
+			Size: size, SHA256: hex.EncodeToString(hash.Sum(nil)),
+		}, "")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creating directory: %w", err)
+	}
+	stem, ext := strings.TrimSuffix(name, filepath.Ext(name)), filepath.Ext(name)
+	dst, err := os.OpenFile(filepath.Join(dir, name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	for n := 2; os.IsExist(err); n++ {
+		name = fmt.Sprintf("%s-%d%s", stem, n, ext)
+		dst, err = os.OpenFile(filepath.Join(dir, name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	}
+	if err != nil {
+		return fmt.Errorf("error writing file: %w", err)
+	}
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(dst, hash), src)
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(dst.Name())
+		return fmt.Errorf("error writing file: %w", err)
+	}
+	return saveAttachment(attachments, Attachment{
+		Record: record, Name: filepath.Base(path), Path: filepath.Join(rel, name),
+		Size: size, SHA256: hex.EncodeToString(hash.Sum(nil)),
+	}, dst.Name())
+}
+
+// saveAttachment records a, copied to the file copied, with the next ID
+// after those of attachments. The copy is removed if a cannot be saved; a
+// dry run made none.
+func saveAttachment(attachments []Attachment, a Attachment, copied string) error {
+	a.AddedAt = clock()
+	for _, existing := range attachments {
+		a.ID = max(a.ID, existing.ID)
+	}
+	a.ID++
+	if err := saveDocument(attachmentsFile, append(attachments, a)); err != nil {
+		if copied != "" {
+			os.Remove(copied)
+		}
+		return err
+	}
+	printDone("Attached %s (%s) as attachment %d.\n", a.Name, formatSize(a.Size), a.ID)
+	return nil
+}
+
+// findAttachment returns the attachment with an ID among attachments.
+func findAttachment(attachments []Attachment, id int) (int, error) {
+	i := slices.IndexFunc(attachments, func(a Attachment) bool { return a.ID == id })
+	if i < 0 {
+		return -1, fmt.Errorf("attachment with ID %d %w", id, tracker.ErrNotFound)
+	}
+	return i, nil
+}
+
+// openAttachment opens an attachment with the platform's default
+// application, after checking that it has not changed since it was
+// attached.
+func openAttachment(id int) error {
+	attachments, err := loadAttachments()
+	if err != nil {
+		return err
+	}
+	i, err := findAttachment(attachments, id)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(projectDir(currentProject), attachments[i].Path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading file: %w", err)
+	}
+	if sum := sha256.Sum256(data); hex.EncodeToString(sum[:]) != attachments[i].SHA256 {
+		fmt.Fprintf(stdout, "Warning: %s has changed since it was attached\n", attachments[i].Path)
+	}
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", path)
+	case "windows":
+		cmd = exec.Command("cmd", "/c", "start", "", path)
+	case "android":
+		cmd = exec.Command("termux-open", path)
+	default:
+		cmd = exec.Command("xdg-open", path)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("could not open %s: %w", path, err)
+	}
+	return nil
+}
+
+// deleteAttachment removes an attachment and its copy of the file.
+func deleteAttachment(id int) error {
+	attachments, err := loadAttachments()
+	if err != nil {
+		return err
+	}
+	i, err := findAttachment(attachments, id)
+	if err != nil {
+		return err
+	}
+	a := attachments[i]
+	if err := saveDocument(attachmentsFile, slices.Delete(attachments, i, i+1)); err != nil {
+		return err
+	}
+	if dryRun {
+		fmt.Fprintf(stdout, "Attachment %d (%s) would be deleted.\n", id, a.Name)
+		return nil
+	}
+	path := filepath.Join(projectDir(currentProject), a.Path)
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error deleting file: %w", err)
+	}
+	os.Remove(filepath.Dir(path)) // Only succeeds once the record has no attachments left.
+	fmt.Fprintf(stdout, "Attachment %d (%s) deleted.\n", id, a.Name)
+	return nil
+}
+
+// deleteRecordAttachments removes the attachments of a record that is
+// being deleted, so that a record given its ID later does not inherit them.
+func deleteRecordAttachments(record string) error {
+	attachments, err := loadAttachments()
+	if err != nil {
+		return err
+	}
+	kept := slices.DeleteFunc(slices.Clone(attachments), func(a Attachment) bool { return a.Record == record })
+	if len(kept) == len(attachments) {
+		return nil
+	}
+	if err := saveDocument(attachmentsFile, kept); err != nil || dryRun {
+		return err
+	}
+	dir := filepath.Join(projectDir(currentProject), attachmentsDir, strings.ReplaceAll(record, ":", "-"))
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("error deleting attachments: %w", err)
+	}
+	return nil
+}
+
+// printAttachments lists the attachments of a record, if any.
+func printAttachments(record string) error {
+	attachments, err := recordAttachments(record)
+	if err != nil || len(attachments) == 0 {
+		return err
+	}
+	fmt.Fprintln(stdout, "--- Attachments ---")
+	for _, a := range attachments {
+		fmt.Fprintf(stdout, "[%d] %s  %s  sha256:%s…\n", a.ID, a.Name, formatSize(a.Size), a.SHA256[:12])
+	}
+	return nil
+}
+
+// formatSize renders a file size in bytes, KB or MB.
+func formatSize(n int64) string {
+	switch {
+	case n < 1000:
+		return fmt.Sprintf("%d B", n)
+	case n < 1000*1000:
+		return fmt.Sprintf("%.1f KB", float64(n)/1000)
+	}
+	return fmt.Sprintf("%.1f MB", float64(n)/1000/1000)
+}
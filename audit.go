@@ -0,0 +1,279 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+)
+
+const (
+	auditFile  = "audit.json" // Changes made to tasks and expenses, per project.
+	auditLimit = 10000        // Entries kept; the oldest are dropped.
+	auditShown = 50           // Entries 'task log' shows without --limit.
+)
+
+// Changes recorded in the audit log.
+const (
+	auditAdded    = "added"
+	auditChanged  = "changed"
+	auditDeleted  = "deleted"
+	auditArchived = "archived"
+	auditRestored = "restored"
+)
+
+// AuditEntry records a change to a task or expense: one added, deleted,
+// archived or restored, or a field of one changed.
+type AuditEntry struct {
+	At     time.Time `json:"at"`
+	Actor  string    `json:"actor,omitempty"` // The user in config.json who made it.
+	Kind   string    `json:"kind"`            // "task" or "expense".
+	ID     int       `json:"id"`
+	UUID   string    `json:"uuid,omitempty"` // Of a task, whose ID can change.
+	Action string    `json:"action"`
+	Field  string    `json:"field,omitempty"` // The field changed, as named in the data files.
+	From   string    `json:"from,omitempty"`
+	To     string    `json:"to,omitempty"` // For a record added, its description.
+}
+
+// loadAudit reads the audit log of the current project.
+func loadAudit() ([]AuditEntry, error) {
+	raw, err := loadDocument(auditFile)
+	if err != nil || raw == nil {
+		return nil, err
+	}
+	var entries []AuditEntry
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, fmt.Errorf("error unmarshalling JSON: %w", err)
+	}
+	return entries, nil
+}
+
+// appendAudit adds entries to the audit log of the current project.
+func appendAudit(entries []AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	saved, err := loadAudit()
+	if err != nil {
+		return err
+	}
+	saved = append(saved, entries...)
+	return saveDocument(auditFile, saved[max(0, len(saved)-auditLimit):])
+}
+
+// auditTasks records the changes between saved and tasks.
+func auditTasks(saved, tasks []Task) error {
+	previous := map[string]Task{}
+	for _, task := range saved {
+		previous[task.UUID] = task
+	}
+	inArchive := archiveChecker()
+	now := clock()
+	entry := func(task Task, action string) AuditEntry {
+		return AuditEntry{At: now, Actor: config.User, Kind: "task", ID: task.ID, UUID: task.UUID, Action: action}
+	}
+
+	var entries []AuditEntry
+	for _, task := range tasks {
+		prev, existed := previous[task.UUID]
+		delete(previous, task.UUID)
+		switch {
+		case !existed:
+			e := entry(task, auditAdded)
+			if inArchive(task.UUID) {
+				e.Action = auditRestored
+			}
+			e.To = task.Description
+			entries = append(entries, e)
+		default:
+			for _, c := range fieldChanges(prev, task, "updatedAt") {
+				e := entry(task, auditChanged)
+				e.Field, e.From, e.To = c[0], c[1], c[2]
+				entries = append(entries, e)
+			}
+		}
+	}
+	for _, task := range saved {
+		if _, removed := previous[task.UUID]; removed {
+			e := entry(task, auditDeleted)
+			if inArchive(task.UUID) {
+				e.Action = auditArchived
+			}
+			e.From = task.Description
+			entries = append(entries, e)
+		}
+	}
+	return appendAudit(entries)
+}
+
+// auditExpenses records the changes between saved and expenses.
+func auditExpenses(saved, expenses []Expense) error {
+	previous := map[int]Expense{}
+	for _, e := range saved {
+		previous[e.ID] = e
+	}
+	now := clock()
+	entry := func(e Expense, action string) AuditEntry {
+		return AuditEntry{At: now, Actor: config.User, Kind: "expense", ID: e.ID, Action: action}
+	}
+
+	var entries []AuditEntry
+	for _, e := range expenses {
+		prev, existed := previous[e.ID]
+		delete(previous, e.ID)
+		if !existed {
+			a := entry(e, auditAdded)
+			a.To = e.Description
+			entries = append(entries, a)
+			continue
+		}
+		for _, c := range fieldChanges(prev, e) {
+			a := entry(e, auditChanged)
+			a.Field, a.From, a.To = c[0], c[1], c[2]
+			entries = append(entries, a)
+		}
+	}
+	for _, e := range saved {
+		if _, removed := previous[e.ID]; removed {
+			a := entry(e, auditDeleted)
+			a.From = e.Description
+			entries = append(entries, a)
+		}
+	}
+	return appendAudit(entries)
+}
+
+// fieldChanges compares two versions of a record field by field, as they
+// are written to the data files, and returns the name, old and new value of
+// each field that changed, other than those ignored.
+func fieldChanges(before, after any, ignore ...string) [][3]string {
+	was, is := recordFields(before), recordFields(after)
+	for _, name := range ignore {
+		delete(was, name)
+		delete(is, name)
+	}
+	names := maps.Clone(was)
+	maps.Copy(names, is)
+	var changes [][3]string
+	for _, name := range sortedKeys(names) {
+		if was[name] != is[name] {
+			changes = append(changes, [3]string{name, was[name], is[name]})
+		}
+	}
+	return changes
+}
+
+// recordFields returns the fields of a record as written to the data files,
+// strings as they are and other values as JSON.
+func recordFields(record any) map[string]string {
+	data, _ := json.Marshal(record)
+	var raw map[string]json.RawMessage
+	json.Unmarshal(data, &raw)
+	fields := make(map[string]string, len(raw))
+	for name, value := range raw {
+		var s string
+		if json.Unmarshal(value, &s) != nil {
+			s = string(value)
+		}
+		fields[name] = s
+	}
+	return fields
+}
+
+// logCommand returns the log command.
+func logCommand() *command {
+	return &command{
+		name: "log", args: "[id]", summary: "Show the changes made to a task, or to every task and expense", group: groupData,
+		complete: positional(taskIDs),
+		setup: func(fs *flag.FlagSet) runFunc {
+			expense := fs.Bool("expense", false, "the id is an expense's")
+			limit := fs.Int("limit", auditShown, "show at most this many `changes`, the latest")
+			return func(args []string) error {
+				if len(args) > 1 {
+					return usagef("too many arguments")
+				}
+				kind := "task"
+				if *expense {
+					kind = "expense"
+				}
+				id := 0
+				if len(args) == 1 {
+					var err error
+					if id, err = parseID(args[0], kind); err != nil {
+						return err
+					}
+				} else if *expense {
+					return usagef("--expense needs an expense id")
+				}
+				if *limit < 1 {
+					return usagef("--limit must be at least 1")
+				}
+				return printAudit(kind, id, *limit)
+			}
+		},
+	}
+}
+
+// printAudit prints the latest changes, at most limit of them, made to the
+// record of kind with id, or to every record if id is 0. A task is followed
+// by its UUID, so that its changes under an earlier ID are included.
+func printAudit(kind string, id, limit int) error {
+	entries, err := loadAudit()
+	if err != nil {
+		return err
+	}
+	title := "--- Log ---"
+	if id != 0 {
+		title = fmt.Sprintf("--- Log of %s %d ---", kind, id)
+		uuid := ""
+		if kind == "task" {
+			if task, err := tr().Task(id); err == nil {
+				uuid = task.UUID
+			}
+		}
+		var matched []AuditEntry
+		for _, e := range entries {
+			if e.Kind == kind && (uuid != "" && e.UUID == uuid || uuid == "" && e.ID == id) {
+				matched = append(matched, e)
+			}
+		}
+		entries = matched
+	}
+	if len(entries) == 0 {
+		fmt.Fprintln(stdout, "No changes recorded.")
+		return nil
+	}
+
+	fmt.Fprintln(stdout, title)
+	for _, e := range entries[max(0, len(entries)-limit):] {
+		what := e.Action
+		switch {
+		case e.Field != "":
+			what = fmt.Sprintf("%s: %s → %s", e.Field, auditValue(e.From), auditValue(e.To))
+		case e.To != "" || e.From != "":
+			what += ": " + auditValue(cmp.Or(e.To, e.From))
+		}
+		line := fmt.Sprintf("%s  %s %d %s", e.At.Format("2006-01-02 15:04"), e.Kind, e.ID, what)
+		if e.Actor != "" {
+			line += "  (" + e.Actor + ")"
+		}
+		fmt.Fprintln(stdout, line)
+	}
+	fmt.Fprintln(stdout, "---------------")
+	return nil
+}
+
+// auditValue renders a logged value, "-" for none, cut to fit a line.
+func auditValue(v string) string {
+	if v == "" {
+		return "-"
+	}
+	if r := []rune(strings.ReplaceAll(v, "\n", " ")); len(r) > 60 {
+		return string(r[:59]) + "…"
+	}
+	return v
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+const balanceMonths = 12 // Months the balance report covers without --month.
+
+// monthBalance totals a month's income and expenses by currency, "" being
+// the base currency.
+type monthBalance struct {
+	income   map[string]float64
+	expenses map[string]float64
+}
+
+// reportBalance prints income, expenses, net savings and the savings rate
+// of each month across projects, in the base currency: the given month, or
+// the last twelve up to now.
+func reportBalance(month *time.Time, now time.Time) error {
+	end := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.Local)
+	start := end.AddDate(0, -balanceMonths, 0)
+	if month != nil {
+		start = time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.Local)
+		end = start.AddDate(0, 1, 0)
+	}
+	inWindow := func(t time.Time) bool { return !t.Before(start) && t.Before(end) }
+
+	months := map[string]*monthBalance{}
+	balance := func(t time.Time) *monthBalance {
+		key := t.Format(monthLayout)
+		if months[key] == nil {
+			months[key] = &monthBalance{income: map[string]float64{}, expenses: map[string]float64{}}
+		}
+		return months[key]
+	}
+	mixed := false
+	err := forEachProject(func(string) error {
+		if _, err := chargeRecurringExpenses(now); err != nil {
+			return err
+		}
+		income, err := tr().Income()
+		if err != nil {
+			return err
+		}
+		expenses, err := loadExpenses()
+		if err != nil {
+			return err
+		}
+		for _, in := range income {
+			if inWindow(in.Date) {
+				balance(in.Date).income[in.Currency] += in.Amount
+				mixed = mixed || in.Currency != "" && in.Currency != config.Currency.Base
+			}
+		}
+		for _, e := range expenses {
+			if inWindow(e.Date) {
+				balance(e.Date).expenses[e.Currency] += e.Amount
+				mixed = mixed || e.Currency != "" && e.Currency != config.Currency.Base
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(months) == 0 {
+		fmt.Fprintln(stdout, "No income or expenses found.")
+		return nil
+	}
+
+	base := config.Currency.Base
+	rates := tracker.Rates{Base: base}
+	if mixed {
+		if base == "" {
+			return fmt.Errorf("set currency.base in %s to total money in different currencies", configFile)
+		}
+		if rates, err = config.Currency.rateProvider().Rates(); err != nil {
+			return err
+		}
+	}
+
+	title := "Balance"
+	if base != "" {
+		title += " in " + base
+	}
+	fmt.Fprintf(stdout, "--- %s ---\n", title)
+	fmt.Fprintf(stdout, "  %-8s %12s %12s %12s %8s\n", "Month", "Income", "Expenses", "Net", "Saved")
+	var totalIncome, totalExpenses float64
+	for _, key := range sortedKeys(months) {
+		income, err := rates.ConvertTotals(months[key].income, base, base)
+		if err != nil {
+			return err
+		}
+		expenses, err := rates.ConvertTotals(months[key].expenses, base, base)
+		if err != nil {
+			return err
+		}
+		printBalanceRow(key, income, expenses)
+		totalIncome += income
+		totalExpenses += expenses
+	}
+	if len(months) > 1 {
+		printBalanceRow("Total", totalIncome, totalExpenses)
+	}
+	fmt.Fprintln(stdout, "----------------")
+	if !rates.AsOf.IsZero() {
+		fmt.Fprintf(stdout, "Rates as of %s.\n", rates.AsOf.Format(dateLayout))
+	}
+	return nil
+}
+
+// printBalanceRow prints a line of the balance report. The savings rate is
+// the share of income not spent, and is left out without income.
+func printBalanceRow(label string, income, expenses float64) {
+	net := income - expenses
+	saved := "-"
+	if income > 0 {
+		saved = fmt.Sprintf("%.1f%%", net/income*100)
+	}
+	fmt.Fprintf(stdout, "  %-8s %12.2f %12.2f %s %8s\n", label, income, expenses, signedColumn(net), saved)
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+const (
+	boardGap       = 2  // Spaces between columns.
+	minColumnWidth = 12 // Narrower columns leave no room for descriptions.
+	defaultWidth   = 80 // Used when the terminal width is unknown.
+)
+
+// boardCommand returns the board command.
+func boardCommand() *command {
+	return &command{
+		name: "board", summary: "Show tasks side by side in a column per status", group: groupTasks,
+		completeFlags: map[string]func() []candidate{
+			"tag": tagCandidates,
+			"priority": func() []candidate {
+				return fixed(tracker.PriorityLow, tracker.PriorityMedium, tracker.PriorityHigh)(nil)
+			},
+		},
+		setup: func(fs *flag.FlagSet) runFunc {
+			tag := fs.String("tag", "", "only show tasks with this tag")
+			priority := fs.String("priority", "", "only show tasks with this priority")
+			width := fs.Int("width", 0, "width of the board in characters (default: the terminal's)")
+			return func(args []string) error {
+				if len(args) > 0 {
+					return usagef("too many arguments")
+				}
+				if *priority != "" && !tracker.IsValidPriority(*priority) {
+					return usagef("invalid priority '%s'; use low, medium or high", *priority)
+				}
+				if *width == 0 {
+					*width = outputWidth()
+				}
+				return showBoard(tracker.TaskFilter{
+					Tag:      normalizeTag(strings.TrimPrefix(*tag, "#")),
+					Priority: *priority,
+				}, *width)
+			}
+		},
+	}
+}
+
+// outputWidth returns the width of the terminal, taken from $COLUMNS if set.
+func outputWidth() int {
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		return n
+	}
+	if n := terminalWidth(os.Stdout); n > 0 {
+		return n
+	}
+	return defaultWidth
+}
+
+// showBoard prints the tasks matching filter in a column per workflow
+// status, high priority first. Descriptions are cut to fit width.
+func showBoard(filter tracker.TaskFilter, width int) error {
+	tasks, err := tr().ListTasks(filter)
+	if err != nil {
+		return err
+	}
+
+	defs := config.Workflow.Statuses
+	colWidth := (width - boardGap*(len(defs)-1)) / len(defs)
+	if colWidth < minColumnWidth {
+		return usagef("a width of %d is too narrow for %d columns", width, len(defs))
+	}
+
+	columns := make([][]Task, len(defs))
+	for _, task := range tasks {
+		i := slices.IndexFunc(defs, func(def StatusDef) bool { return def.Name == task.Status })
+		if i >= 0 {
+			columns[i] = append(columns[i], task)
+		}
+	}
+	for _, column := range columns {
+		slices.SortStableFunc(column, func(a, b Task) int {
+			return priorityRank(b.Priority) - priorityRank(a.Priority)
+		})
+	}
+
+	cells := make([]string, len(defs))
+	for i, def := range defs {
+		heading := fmt.Sprintf("%s (%d)", strings.ToUpper(def.Name), len(columns[i]))
+		cells[i] = colorize(padRight(truncate(heading, colWidth), colWidth), def.Color)
+	}
+	printBoardRow(cells)
+	for i := range defs {
+		cells[i] = strings.Repeat("─", colWidth)
+	}
+	printBoardRow(cells)
+
+	now := clock()
+	rows := 0
+	for _, column := range columns {
+		rows = max(rows, len(column))
+	}
+	for row := range rows {
+		for i, column := range columns {
+			cells[i] = strings.Repeat(" ", colWidth)
+			if row < len(column) {
+				cells[i] = boardCard(column[row], colWidth, now)
+			}
+		}
+		printBoardRow(cells)
+	}
+	if len(tasks) == 0 {
+		fmt.Fprintln(stdout, "No tasks found.")
+	}
+	return nil
+}
+
+// boardCard renders a task as a board cell of width characters: its ID and
+// description, marked '!' if high priority and red if overdue.
+func boardCard(task Task, width int, now time.Time) string {
+	card := fmt.Sprintf("#%d %s", task.ID, task.Description)
+	if task.Priority == tracker.PriorityHigh {
+		card = "!" + card
+	}
+	card = padRight(truncate(card, width), width)
+	if def, _ := config.Workflow.Find(task.Status); !def.Done && task.Due != nil && now.After(tracker.Deadline(*task.Due)) {
+		return colorize(card, "red")
+	}
+	return card
+}
+
+// printBoardRow prints the cells of a board row side by side.
+func printBoardRow(cells []string) {
+	line := strings.Join(cells, strings.Repeat(" ", boardGap))
+	fmt.Fprintln(stdout, strings.TrimRight(line, " "))
+}
+
+// truncate shortens s to at most width characters, ending it with '…' if
+// anything was cut.
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+// padRight pads s with spaces to width characters.
+func padRight(s string, width int) string {
+	return s + strings.Repeat(" ", max(0, width-len([]rune(s))))
+}
@@ -0,0 +1,556 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+const caldavSyncFile = "caldav.json" // The to-dos as of the last CalDAV sync, per project.
+
+// caldavQuery asks a calendar collection for the ETag and data of each of
+// its to-dos.
+const caldavQuery = `<?xml version="1.0" encoding="utf-8"?>
+<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:prop><d:getetag/><c:calendar-data/></d:prop>
+  <c:filter><c:comp-filter name="VCALENDAR"><c:comp-filter name="VTODO"/></c:comp-filter></c:filter>
+</c:calendar-query>`
+
+// CalDAVSync configures 'task sync caldav'.
+type CalDAVSync struct {
+	URL      string `json:"url,omitempty"` // The task list's collection, unless --list is given.
+	User     string `json:"user,omitempty"`
+	Password string `json:"password,omitempty"` // May name a secret; an app password where the server has them.
+}
+
+// caldavTodo is what is synced of a to-do, in iCalendar terms, so that the
+// two sides compare alike: due dates are a date or a UTC date-time, and
+// priorities one of 1, 5 and 9.
+type caldavTodo struct {
+	Summary  string `json:"summary"`
+	Status   string `json:"status"`
+	Due      string `json:"due,omitempty"`
+	Priority int    `json:"priority,omitempty"`
+}
+
+// caldavLink ties a to-do on the server to a task, with the to-do as both
+// had it at the last sync, to tell which side changed it since.
+type caldavLink struct {
+	Href   string     `json:"href"`
+	Task   string     `json:"task"` // UUID of the task.
+	Synced caldavTodo `json:"synced"`
+}
+
+// caldavSyncState is what a project remembers of its last CalDAV sync, by
+// to-do UID.
+type caldavSyncState struct {
+	URL   string                `json:"url"` // As configured, so a secret stays one.
+	Todos map[string]caldavLink `json:"todos"`
+}
+
+// remoteTodo is a to-do as the server has it now.
+type remoteTodo struct {
+	href     string
+	etag     string
+	data     []byte
+	todo     caldavTodo
+	modified time.Time
+}
+
+// caldavSource identifies the task imported from a to-do created elsewhere.
+func caldavSource(uid string) string {
+	return "caldav:" + uid
+}
+
+// caldavClient talks to a CalDAV task list.
+type caldavClient struct {
+	list     *url.URL
+	user     string
+	password string
+}
+
+// do sends a request for target, returning the response's status and body.
+func (c caldavClient) do(method, target string, header http.Header, body []byte) (int, []byte, error) {
+	req, err := http.NewRequest(method, target, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if c.user != "" || c.password != "" {
+		req.SetBasicAuth(c.user, c.password)
+	}
+	client := &http.Client{Timeout: syncTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("error contacting %s: %w", c.list.Host, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSyncDocument))
+	if err != nil {
+		return 0, nil, fmt.Errorf("error reading the response to %s %s: %w", method, target, err)
+	}
+	return resp.StatusCode, data, nil
+}
+
+// todos returns the to-dos in the task list by UID.
+func (c caldavClient) todos() (map[string]remoteTodo, error) {
+	header := http.Header{"Depth": {"1"}, "Content-Type": {"application/xml; charset=utf-8"}}
+	status, data, err := c.do("REPORT", c.list.String(), header, []byte(caldavQuery))
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusMultiStatus {
+		return nil, fmt.Errorf("%s answered %d %s to the to-do query", c.list.Host, status, http.StatusText(status))
+	}
+
+	var ms struct {
+		Responses []struct {
+			Href     string `xml:"DAV: href"`
+			Propstat []struct {
+				Status string `xml:"DAV: status"`
+				Prop   struct {
+					ETag string `xml:"DAV: getetag"`
+					Data string `xml:"urn:ietf:params:xml:ns:caldav calendar-data"`
+				} `xml:"DAV: prop"`
+			} `xml:"DAV: propstat"`
+		} `xml:"DAV: response"`
+	}
+	if err := xml.Unmarshal(data, &ms); err != nil {
+		return nil, fmt.Errorf("error reading the to-do list: %w", err)
+	}
+	todos := map[string]remoteTodo{}
+	for _, r := range ms.Responses {
+		href, err := c.list.Parse(r.Href)
+		if err != nil {
+			continue
+		}
+		for _, ps := range r.Propstat {
+			if !strings.Contains(ps.Status, " 200 ") || ps.Prop.Data == "" {
+				continue
+			}
+			uid, todo, modified, ok := parseVTODO([]byte(ps.Prop.Data))
+			if ok {
+				todos[uid] = remoteTodo{href: href.String(), etag: ps.Prop.ETag, data: []byte(ps.Prop.Data), todo: todo, modified: modified}
+			}
+		}
+	}
+	return todos, nil
+}
+
+// put stores a to-do at href: only if it is still at etag, or only if
+// there is none yet when etag is empty.
+func (c caldavClient) put(href, etag string, data []byte) error {
+	header := http.Header{"Content-Type": {"text/calendar; charset=utf-8"}}
+	if etag != "" {
+		header.Set("If-Match", etag)
+	} else {
+		header.Set("If-None-Match", "*")
+	}
+	status, _, err := c.do(http.MethodPut, href, header, data)
+	if err != nil {
+		return err
+	}
+	if status == http.StatusPreconditionFailed {
+		return errSyncStale
+	}
+	if status >= 300 {
+		return fmt.Errorf("%s answered %d %s to storing %s", c.list.Host, status, http.StatusText(status), href)
+	}
+	return nil
+}
+
+// remove deletes the to-do at href if it is still at etag.
+func (c caldavClient) remove(href, etag string) error {
+	header := http.Header{}
+	if etag != "" {
+		header.Set("If-Match", etag)
+	}
+	status, _, err := c.do(http.MethodDelete, href, header, nil)
+	if err != nil {
+		return err
+	}
+	if status == http.StatusPreconditionFailed {
+		return errSyncStale
+	}
+	if status >= 300 && status != http.StatusNotFound {
+		return fmt.Errorf("%s answered %d %s to deleting %s", c.list.Host, status, http.StatusText(status), href)
+	}
+	return nil
+}
+
+// syncCalDAV syncs the current project's tasks two ways with a CalDAV task
+// list, such as one of Nextcloud Tasks or Fastmail, mapping descriptions to
+// summaries and status, due date and priority to their own. Open tasks not
+// on the list yet are added to it, and to-dos added to it are added here.
+// A task or to-do changed on one side since the last sync is changed on
+// the other; one changed on both keeps the latest change. Deleting either
+// deletes the other.
+func syncCalDAV(list string) error {
+	list = cmp.Or(list, config.Sync.CalDAV.URL)
+	if list == "" {
+		return usagef("no task list; pass --list URL or set sync.caldav.url in %s", configFile)
+	}
+	target, err := resolveSecret(list)
+	if err != nil {
+		return err
+	}
+	u, err := url.Parse(target)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return usagef("'%s' is not an http or https URL", list)
+	}
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	password, err := resolveSecret(config.Sync.CalDAV.Password)
+	if err != nil {
+		return err
+	}
+	client := caldavClient{list: u, user: config.Sync.CalDAV.User, password: password}
+
+	remote, err := client.todos()
+	if err != nil {
+		return err
+	}
+	var state caldavSyncState
+	if err := loadStateDocument(caldavSyncFile, &state); err != nil {
+		return err
+	}
+	if state.URL != list || state.Todos == nil {
+		// Links to another list mean nothing for this one
+		state = caldavSyncState{URL: list, Todos: map[string]caldavLink{}}
+	}
+	tasks, err := loadTasks()
+	if err != nil {
+		return err
+	}
+
+	now := clock()
+	statuses := map[string]string{} // Status changes to make, by task UUID.
+	var added, updated, deleted, pushed, removed int
+	changed := false
+	warn := func(uid string, err error) {
+		if errors.Is(err, errSyncStale) {
+			err = fmt.Errorf("it changed on the server meanwhile; sync again")
+		}
+		fmt.Fprintf(stdout, "Warning: could not sync to-do %s: %v\n", uid, err)
+	}
+	inArchive := archiveChecker()
+	indexOf := func(uuid string) int {
+		return slices.IndexFunc(tasks, func(t Task) bool { return t.UUID == uuid })
+	}
+
+	for _, uid := range sortedKeys(remote) {
+		r := remote[uid]
+		link, linked := state.Todos[uid]
+		if !linked {
+			i := slices.IndexFunc(tasks, func(t Task) bool { return t.UUID == uid || t.Source == caldavSource(uid) })
+			if i < 0 {
+				// Added on the server
+				task := Task{
+					ID:        getNextID(tasks),
+					UUID:      tracker.NewUUID(),
+					Status:    config.Workflow.Initial(),
+					Source:    caldavSource(uid),
+					CreatedAt: now,
+				}
+				statuses[task.UUID] = applyTodo(&task, r.todo, now)
+				tasks = append(tasks, task)
+				state.Todos[uid] = caldavLink{Href: r.href, Task: task.UUID, Synced: r.todo}
+				added++
+				changed = true
+				continue
+			}
+			// Synced before state was kept, or exported and imported: the latest change wins
+			link = caldavLink{Href: r.href, Task: tasks[i].UUID}
+		}
+		i := indexOf(link.Task)
+		if i < 0 && inArchive(link.Task) {
+			// Archived tasks leave their to-dos be
+			state.Todos[uid] = link
+			continue
+		}
+		if i < 0 {
+			// Deleted here
+			if err := client.remove(r.href, r.etag); err != nil {
+				warn(uid, err)
+				continue
+			}
+			delete(state.Todos, uid)
+			removed++
+			continue
+		}
+
+		local := todoOf(tasks[i])
+		localChanged, remoteChanged := local != link.Synced, r.todo != link.Synced
+		link.Href = r.href
+		switch {
+		case remoteChanged && (!localChanged || r.modified.After(tasks[i].UpdatedAt)):
+			if r.todo != local {
+				statuses[tasks[i].UUID] = applyTodo(&tasks[i], r.todo, now)
+				updated++
+				changed = true
+			}
+			link.Synced = r.todo
+		case localChanged:
+			data, err := patchVTODO(r.data, local, now)
+			if err == nil {
+				err = client.put(r.href, r.etag, data)
+			}
+			if err != nil {
+				warn(uid, err)
+				continue
+			}
+			link.Synced = local
+			pushed++
+		}
+		state.Todos[uid] = link
+	}
+
+	// To-dos deleted on the server
+	for _, uid := range sortedKeys(state.Todos) {
+		if _, ok := remote[uid]; ok {
+			continue
+		}
+		if i := indexOf(state.Todos[uid].Task); i >= 0 {
+			tasks = slices.Delete(tasks, i, i+1)
+			deleted++
+			changed = true
+		}
+		delete(state.Todos, uid)
+	}
+
+	// Open tasks not on the list yet
+	linked := map[string]bool{}
+	for _, link := range state.Todos {
+		linked[link.Task] = true
+	}
+	for _, task := range tasks {
+		if linked[task.UUID] || config.Workflow.IsDone(task.Status) {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := exportICS(&buf, []Task{task}); err != nil {
+			return err
+		}
+		href := u.JoinPath(task.UUID + ".ics").String()
+		if err := client.put(href, "", buf.Bytes()); err != nil {
+			warn(task.UUID, err)
+			continue
+		}
+		state.Todos[task.UUID] = caldavLink{Href: href, Task: task.UUID, Synced: todoOf(task)}
+		pushed++
+	}
+
+	if changed {
+		if err := saveTasks(tasks); err != nil {
+			return err
+		}
+	}
+	for _, task := range tasks {
+		if status := statuses[task.UUID]; status != "" && status != task.Status {
+			if _, err := tr().SetStatus(task.ID, status); err != nil {
+				fmt.Fprintf(stdout, "Warning: could not move task ID %d to %s: %v\n", task.ID, status, err)
+			}
+		}
+	}
+	if err := saveDocument(caldavSyncFile, state); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "Synced %s: %s added, %d updated and %d deleted here; %s added or updated and %d deleted there.\n",
+		u.Host, plural(added, "task"), updated, deleted, plural(pushed, "to-do"), removed)
+	return nil
+}
+
+// todoOf returns what is synced of a task.
+func todoOf(task Task) caldavTodo {
+	todo := caldavTodo{Summary: task.Description, Status: icsStatus(task.Status), Priority: icsPriority(task.Priority)}
+	if task.Due != nil {
+		if task.Due.Equal(startOfDay(*task.Due)) {
+			todo.Due = task.Due.Format(icsDateLayout)
+		} else {
+			todo.Due = formatICSTime(*task.Due)
+		}
+	}
+	return todo
+}
+
+// applyTodo changes a task to match a to-do, returning the status it
+// should move to; statuses are left to the caller, as moving between them
+// is up to the workflow.
+func applyTodo(task *Task, todo caldavTodo, now time.Time) string {
+	task.Description = todo.Summary
+	task.Due = nil
+	if len(todo.Due) == len(icsDateLayout) {
+		if due, err := time.ParseInLocation(icsDateLayout, todo.Due, time.Local); err == nil {
+			task.Due = &due
+		}
+	} else if due, err := time.Parse(icsDateTimeLayout, todo.Due); err == nil {
+		due = due.Local()
+		task.Due = &due
+	}
+	task.Priority = taskPriority(todo.Priority)
+	task.UpdatedAt = now
+
+	w := config.Workflow
+	switch todo.Status {
+	case "COMPLETED":
+		if !w.IsDone(task.Status) {
+			return w.DoneStatus()
+		}
+	case "IN-PROCESS":
+		if task.Status != w.Initial() && !w.IsDone(task.Status) {
+			return task.Status
+		}
+		for _, s := range w.Statuses {
+			if s.Name != w.Initial() && !s.Done {
+				return s.Name
+			}
+		}
+	default:
+		return w.Initial()
+	}
+	return task.Status
+}
+
+// taskPriority maps a VTODO priority to a task priority: 1-4 is high, 5
+// medium and 6-9 low.
+func taskPriority(p int) string {
+	switch {
+	case p < 1 || p > 9:
+		return ""
+	case p < 5:
+		return tracker.PriorityHigh
+	case p == 5:
+		return tracker.PriorityMedium
+	default:
+		return tracker.PriorityLow
+	}
+}
+
+// parseVTODO reads the first to-do of a calendar object, returning its UID,
+// what is synced of it and when it was last modified.
+func parseVTODO(data []byte) (uid string, todo caldavTodo, modified time.Time, ok bool) {
+	var stack []string
+	done := false
+	for _, line := range unfoldICSLines(data) {
+		name, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		name, params, _ := strings.Cut(name, ";")
+		name = strings.ToUpper(name)
+		switch name {
+		case "BEGIN":
+			stack = append(stack, strings.ToUpper(value))
+			continue
+		case "END":
+			if len(stack) > 0 && stack[len(stack)-1] == "VTODO" {
+				done = true
+			}
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			continue
+		}
+		if done || len(stack) == 0 || stack[len(stack)-1] != "VTODO" {
+			continue
+		}
+		switch name {
+		case "UID":
+			uid = value
+		case "SUMMARY":
+			todo.Summary = unescapeICSText(value)
+		case "STATUS":
+			todo.Status = strings.ToUpper(value)
+		case "DUE":
+			if strings.Contains(strings.ToUpper(params), "VALUE=DATE") && len(value) >= len(icsDateLayout) {
+				todo.Due = value[:len(icsDateLayout)]
+			} else if t, timed := parseICSTime(value, params); timed {
+				todo.Due = formatICSTime(t)
+			}
+		case "PRIORITY":
+			p, _ := strconv.Atoi(value)
+			todo.Priority = icsPriority(taskPriority(p))
+		case "LAST-MODIFIED":
+			modified, _ = parseICSTime(value, params)
+		}
+	}
+	switch todo.Status {
+	case "COMPLETED", "IN-PROCESS":
+	case "CANCELLED":
+		todo.Status = "COMPLETED"
+	default:
+		todo.Status = "NEEDS-ACTION"
+	}
+	return uid, todo, modified, uid != "" && done
+}
+
+// patchVTODO replaces the synced properties of the to-do in a calendar
+// object with todo's, keeping all others, such as alarms and those only
+// other apps know, as they are.
+func patchVTODO(data []byte, todo caldavTodo, now time.Time) ([]byte, error) {
+	replaced := []string{"DTSTAMP", "LAST-MODIFIED", "SUMMARY", "STATUS", "DUE", "PRIORITY", "COMPLETED", "PERCENT-COMPLETE"}
+	var buf bytes.Buffer
+	bw := bufio.NewWriter(&buf)
+	var stack []string
+	for _, line := range unfoldICSLines(data) {
+		name, value, _ := strings.Cut(line, ":")
+		name, _, _ = strings.Cut(name, ";")
+		name = strings.ToUpper(name)
+		inTodo := len(stack) > 0 && stack[len(stack)-1] == "VTODO"
+		if inTodo && slices.Contains(replaced, name) {
+			continue
+		}
+		writeICSLine(bw, line)
+		switch name {
+		case "BEGIN":
+			stack = append(stack, strings.ToUpper(value))
+			if stack[len(stack)-1] == "VTODO" {
+				writeTodoProps(bw, todo, now)
+			}
+		case "END":
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeTodoProps writes the synced properties of a to-do.
+func writeTodoProps(bw *bufio.Writer, todo caldavTodo, now time.Time) {
+	writeICSLine(bw, "DTSTAMP:"+formatICSTime(now))
+	writeICSLine(bw, "LAST-MODIFIED:"+formatICSTime(now))
+	writeICSLine(bw, "SUMMARY:"+escapeICSText(todo.Summary))
+	writeICSLine(bw, "STATUS:"+todo.Status)
+	if len(todo.Due) == len(icsDateLayout) {
+		writeICSLine(bw, "DUE;VALUE=DATE:"+todo.Due)
+	} else if todo.Due != "" {
+		writeICSLine(bw, "DUE:"+todo.Due)
+	}
+	if todo.Priority != 0 {
+		writeICSLine(bw, fmt.Sprintf("PRIORITY:%d", todo.Priority))
+	}
+	if todo.Status == "COMPLETED" {
+		writeICSLine(bw, "COMPLETED:"+formatICSTime(now))
+		writeICSLine(bw, "PERCENT-COMPLETE:100")
+	}
+}
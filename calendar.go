@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// icsDurationPattern matches the iCalendar durations events use, such as
+// "PT1H30M" or "P1D".
+var icsDurationPattern = regexp.MustCompile(`^P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// Calendar configures the calendar due times are checked against.
+type Calendar struct {
+	// Feed is the URL or path of an iCalendar (.ics) feed of meetings.
+	// Setting a due time during one of them prints a warning. As private
+	// feed URLs carry a key, it may name a secret ("secret:<name>").
+	Feed string `json:"feed,omitempty"`
+}
+
+// meeting is a busy block of a calendar feed.
+type meeting struct {
+	Summary    string
+	Start, End time.Time
+}
+
+// warnMeetingConflicts prints a warning for each meeting of the configured
+// feed that due falls in. Due dates without a time of day, and all-day
+// events, are not checked. Failing to read the feed is only a warning too.
+func warnMeetingConflicts(due time.Time) {
+	if config.Calendar.Feed == "" || due.Hour() == 0 && due.Minute() == 0 {
+		return
+	}
+	var meetings []meeting
+	feed, err := resolveSecret(config.Calendar.Feed)
+	if err == nil {
+		meetings, err = loadMeetings(feed)
+	}
+	if err != nil {
+		fmt.Fprintf(stdout, "Warning: could not check your calendar: %v\n", err)
+		return
+	}
+	for _, m := range meetings {
+		if due.Before(m.Start) || !due.Before(m.End) {
+			continue
+		}
+		start, end := m.Start.In(due.Location()), m.End.In(due.Location())
+		layout := "15:04"
+		if start.YearDay() != end.YearDay() || start.Year() != end.Year() {
+			layout = dateLayout + " 15:04"
+		}
+		fmt.Fprintf(stdout, "Warning: you have a meeting %s–%s", start.Format("15:04"), end.Format(layout))
+		if m.Summary != "" {
+			fmt.Fprintf(stdout, " (%s)", m.Summary)
+		}
+		fmt.Fprintln(stdout)
+	}
+}
+
+// loadMeetings reads the timed events of an iCalendar feed from a URL or a
+// file.
+func loadMeetings(feed string) ([]meeting, error) {
+	var data []byte
+	var err error
+	if url, ok := strings.CutPrefix(feed, "webcal://"); ok {
+		feed = "https://" + url
+	}
+	if strings.HasPrefix(feed, "http://") || strings.HasPrefix(feed, "https://") {
+		data, err = httpGet(feed, "text/calendar")
+	} else {
+		data, err = os.ReadFile(feed)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseMeetings(data), nil
+}
+
+// parseMeetings returns the timed events of an iCalendar document. Events
+// that are cancelled or marked free are left out, as are all-day events.
+// Recurrence rules are not expanded: only an event's first occurrence
+// counts.
+func parseMeetings(data []byte) []meeting {
+	var meetings []meeting
+	var m *meeting
+	var duration time.Duration
+	skip := false
+	for _, line := range unfoldICSLines(data) {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		name, params, _ := strings.Cut(name, ";")
+		switch strings.ToUpper(name) {
+		case "BEGIN":
+			if value == "VEVENT" {
+				m, duration, skip = &meeting{}, 0, false
+			}
+		case "END":
+			if value != "VEVENT" || m == nil {
+				continue
+			}
+			if m.End.IsZero() {
+				m.End = m.Start.Add(duration)
+			}
+			if !skip && !m.Start.IsZero() && m.End.After(m.Start) {
+				meetings = append(meetings, *m)
+			}
+			m = nil
+		case "SUMMARY":
+			if m != nil {
+				m.Summary = unescapeICSText(value)
+			}
+		case "DTSTART", "DTEND":
+			if m == nil {
+				continue
+			}
+			t, timed := parseICSTime(value, params)
+			skip = skip || !timed
+			if strings.EqualFold(name, "DTSTART") {
+				m.Start = t
+			} else {
+				m.End = t
+			}
+		case "DURATION":
+			duration = parseICSDuration(value)
+		case "STATUS":
+			skip = skip || value == "CANCELLED"
+		case "TRANSP":
+			skip = skip || value == "TRANSPARENT"
+		}
+	}
+	return meetings
+}
+
+// unfoldICSLines splits an iCalendar document into content lines, joining
+// the continuation lines of folded ones.
+func unfoldICSLines(data []byte) []string {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if n := len(lines); n > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[n-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// parseICSTime parses a DTSTART or DTEND value: UTC, in the zone named by
+// a TZID parameter, or floating in local time. It reports false for dates
+// without a time of day and for values it cannot parse.
+func parseICSTime(value, params string) (time.Time, bool) {
+	if strings.Contains(strings.ToUpper(params), "VALUE=DATE") && !strings.Contains(strings.ToUpper(params), "VALUE=DATE-TIME") {
+		return time.Time{}, false
+	}
+	if t, err := time.Parse(icsDateTimeLayout, value); err == nil {
+		return t, true
+	}
+	loc := time.Local
+	for _, param := range strings.Split(params, ";") {
+		if tzid, ok := strings.CutPrefix(param, "TZID="); ok {
+			if l, err := time.LoadLocation(strings.Trim(tzid, `"`)); err == nil {
+				loc = l
+			}
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t, err == nil
+}
+
+// parseICSDuration parses an iCalendar duration, returning 0 if it is
+// invalid.
+func parseICSDuration(value string) time.Duration {
+	m := icsDurationPattern.FindStringSubmatch(strings.TrimPrefix(value, "+"))
+	if m == nil {
+		return 0
+	}
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var d time.Duration
+	for i, unit := range units {
+		n, _ := strconv.Atoi(m[i+1])
+		d += time.Duration(n) * unit
+	}
+	return d
+}
+
+// unescapeICSText reverses escapeICSText.
+func unescapeICSText(s string) string {
+	r := strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+	return r.Replace(s)
+}
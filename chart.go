@@ -0,0 +1,279 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	chartRows     = 10     // Height of a column chart, in lines.
+	chartBarWidth = 40     // Width of the longest bar of a bar chart, in characters.
+	chartWeeks    = 8      // Weeks 'task chart completed' shows without --weeks.
+	defaultSprint = "2w"   // Length of a sprint without --sprint.
+	maxSprintDays = 12 * 7 // Days a burndown may cover.
+	maxChartWeeks = 52     // Weeks 'task chart completed' may cover.
+	idealMark     = '·'    // Where the ideal burndown line runs.
+	blockEighths  = " ▁▂▃▄▅▆▇█"
+	barEighths    = " ▏▎▍▌▋▊▉█"
+)
+
+// chartCommand returns the chart command group.
+func chartCommand() *command {
+	return &command{
+		name: "chart", summary: "Chart open and completed tasks over time in the terminal", group: groupPlanning,
+		subcommands: []*command{
+			{
+				name: "burndown", summary: "Chart the tasks left open on each day of a sprint against the ideal",
+				completeFlags: map[string]func() []candidate{"tag": tagCandidates},
+				setup: func(fs *flag.FlagSet) runFunc {
+					sprint := fs.String("sprint", defaultSprint, "`length` of the sprint, such as 2w or 10d")
+					startStr := fs.String("start", "", "first `date` of the sprint (default so that it ends this week)")
+					tag := fs.String("tag", "", "only chart tasks with this tag")
+					return func([]string) error {
+						length, err := parseAge(*sprint)
+						days := int(length.Hours() / 24)
+						if err != nil || days < 1 || days > maxSprintDays {
+							return usagef("invalid sprint '%s'; use 1d to %dw", *sprint, maxSprintDays/7)
+						}
+						now := clock()
+						start := startOfWeek(now).AddDate(0, 0, 7-days)
+						if *startStr != "" {
+							if start, err = parseDate(*startStr, now); err != nil {
+								return usagef("%v", err)
+							}
+						}
+						return chartBurndown(startOfDay(start), days, normalizeTag(strings.TrimPrefix(*tag, "#")), now)
+					}
+				},
+			},
+			{
+				name: "completed", summary: "Chart the tasks completed each week, with those added and left open",
+				completeFlags: map[string]func() []candidate{"tag": tagCandidates},
+				setup: func(fs *flag.FlagSet) runFunc {
+					weeks := fs.Int("weeks", chartWeeks, "how many `weeks` to chart, up to this one")
+					tag := fs.String("tag", "", "only chart tasks with this tag")
+					return func([]string) error {
+						if *weeks < 1 || *weeks > maxChartWeeks {
+							return usagef("--weeks must be between 1 and %d", maxChartWeeks)
+						}
+						return chartCompleted(*weeks, normalizeTag(strings.TrimPrefix(*tag, "#")), clock())
+					}
+				},
+			},
+		},
+	}
+}
+
+// chartTasks returns the tasks of the current project, archived ones
+// included, with tag if it is set.
+func chartTasks(tag string) ([]Task, error) {
+	tasks, err := loadTasks()
+	if err != nil {
+		return nil, err
+	}
+	archived, err := tr().ArchivedTasks()
+	if err != nil {
+		return nil, err
+	}
+	tasks = append(tasks, archived...)
+	if tag != "" {
+		tasks = slices.DeleteFunc(tasks, func(t Task) bool { return !slices.Contains(t.Tags, tag) })
+	}
+	return tasks, nil
+}
+
+// openAt counts the tasks that had been created and were not completed at
+// t, going by when they were created and completed.
+func openAt(tasks []Task, t time.Time) int {
+	n := 0
+	for _, task := range tasks {
+		if task.CreatedAt.After(t) {
+			continue
+		}
+		if task.CompletedAt == nil || task.CompletedAt.After(t) {
+			n++
+		}
+	}
+	return n
+}
+
+// chartBurndown draws the tasks open at the end of each day of the sprint
+// of days starting at start, up to today, with the ideal line from those
+// open when it began down to none at its end. Tasks added during the
+// sprint are counted in from the day they were added.
+func chartBurndown(start time.Time, days int, tag string, now time.Time) error {
+	tasks, err := chartTasks(tag)
+	if err != nil {
+		return err
+	}
+	end := start.AddDate(0, 0, days)
+
+	scope := openAt(tasks, start)
+	added := 0
+	for _, task := range tasks {
+		if !task.CreatedAt.Before(start) && task.CreatedAt.Before(end) {
+			added++
+		}
+	}
+	open := make([]int, 0, days) // Up to today only.
+	ideal := make([]float64, days)
+	peak := float64(scope)
+	for i := range days {
+		ideal[i] = float64(scope) * float64(days-1-i) / float64(max(1, days-1))
+		day := start.AddDate(0, 0, i)
+		if day.After(now) {
+			continue
+		}
+		n := openAt(tasks, minTime(day.AddDate(0, 0, 1).Add(-time.Nanosecond), now))
+		open = append(open, n)
+		peak = max(peak, float64(n))
+	}
+
+	fmt.Fprintf(stdout, "--- Burndown: %s to %s ---\n", start.Format(dateLayout), end.AddDate(0, 0, -1).Format(dateLayout))
+	if peak == 0 {
+		fmt.Fprintln(stdout, "No tasks were open during the sprint.")
+		return nil
+	}
+	label := len(fmt.Sprint(int(peak)))
+	for row := chartRows; row >= 1; row-- {
+		axis := strings.Repeat(" ", label)
+		if row == chartRows {
+			axis = fmt.Sprintf("%*d", label, int(peak))
+		}
+		var b strings.Builder
+		for i := range days {
+			cell := ' '
+			if i < len(open) {
+				cell = columnCell(float64(open[i])/peak*chartRows, row)
+			}
+			// The ideal runs in the gap beside each day's column, so that
+			// columns above it do not hide it
+			mark := ' '
+			if int(math.Round(ideal[i]/peak*chartRows)) == row {
+				mark = idealMark
+			}
+			b.WriteRune(cell)
+			b.WriteRune(mark)
+		}
+		fmt.Fprintf(stdout, "%s │%s\n", axis, strings.TrimRight(b.String(), " "))
+	}
+	fmt.Fprintf(stdout, "%*d └%s\n", label, 0, strings.Repeat("─", 2*days))
+
+	// Label the first day and the Mondays after it that there is room for
+	labels := []rune(strings.Repeat(" ", 2*days+6))
+	next := 0
+	for i := range days {
+		day := start.AddDate(0, 0, i)
+		if 2*i < next || i > 0 && day.Weekday() != time.Monday {
+			continue
+		}
+		text := []rune(day.Format("2 Jan"))
+		copy(labels[2*i:], text)
+		next = 2*i + len(text) + 1
+	}
+	fmt.Fprintf(stdout, "%s  %s\n", strings.Repeat(" ", label), strings.TrimRight(string(labels), " "))
+	fmt.Fprintln(stdout, "-----------------")
+
+	fmt.Fprintf(stdout, "%d open when the sprint began, %d added since.\n", scope, added)
+	if len(open) == 0 {
+		fmt.Fprintln(stdout, "The sprint has not begun.")
+		return nil
+	}
+	last := len(open) - 1
+	left, target := open[last], int(math.Ceil(ideal[last]))
+	switch {
+	case left > target:
+		fmt.Fprintf(stdout, "%d open, %d behind the ideal of %d.\n", left, left-target, target)
+	case left < target:
+		fmt.Fprintf(stdout, "%d open, %d ahead of the ideal of %d.\n", left, target-left, target)
+	default:
+		fmt.Fprintf(stdout, "%d open, on the ideal.\n", left)
+	}
+	return nil
+}
+
+// chartCompleted draws the tasks completed in each of the last weeks, up
+// to this one, as bars, with those added each week and those left open
+// at its end, and the trend of the open ones.
+func chartCompleted(weeks int, tag string, now time.Time) error {
+	tasks, err := chartTasks(tag)
+	if err != nil {
+		return err
+	}
+	first := startOfWeek(now).AddDate(0, 0, -7*(weeks-1))
+	done, added, open := make([]int, weeks), make([]int, weeks), make([]int, weeks)
+	for _, task := range tasks {
+		if i := int(task.CreatedAt.Sub(first).Hours() / 24 / 7); !task.CreatedAt.Before(first) && i < weeks {
+			added[i]++
+		}
+		if at := task.CompletedAt; at != nil && !at.Before(first) {
+			if i := int(at.Sub(first).Hours() / 24 / 7); i < weeks {
+				done[i]++
+			}
+		}
+	}
+	peak, total := 0, 0
+	for i := range weeks {
+		open[i] = openAt(tasks, minTime(first.AddDate(0, 0, 7*(i+1)).Add(-time.Nanosecond), now))
+		peak = max(peak, done[i])
+		total += done[i]
+	}
+
+	fmt.Fprintln(stdout, "--- Completed per week ---")
+	for i := range weeks {
+		bar := ""
+		if peak > 0 {
+			bar = horizontalBar(float64(done[i]) / float64(peak) * chartBarWidth)
+		}
+		fmt.Fprintf(stdout, "  %s  %-*s %3d  (+%d added, %d open)\n",
+			first.AddDate(0, 0, 7*i).Format(dateLayout), chartBarWidth, bar, done[i], added[i], open[i])
+	}
+	fmt.Fprintln(stdout, "-----------------")
+	fmt.Fprintf(stdout, "%.1f completed a week on average.\n", float64(total)/float64(weeks))
+	fmt.Fprintf(stdout, "Open: %s %d → %d\n", sparkline(open), open[0], open[weeks-1])
+	return nil
+}
+
+// columnCell returns what a column of height (in rows, fractions included)
+// shows in row, counting from 1 at the bottom.
+func columnCell(height float64, row int) rune {
+	eighths := []rune(blockEighths)
+	switch {
+	case height >= float64(row):
+		return eighths[8]
+	case height > float64(row-1):
+		return eighths[int((height-float64(row-1))*8)]
+	}
+	return ' '
+}
+
+// horizontalBar returns a bar width characters long, fractions included.
+func horizontalBar(width float64) string {
+	eighths := []rune(barEighths)
+	full := int(width)
+	bar := strings.Repeat(string(eighths[8]), full)
+	if part := int((width - float64(full)) * 8); part > 0 {
+		bar += string(eighths[part])
+	}
+	return bar
+}
+
+// sparkline draws values as a line of blocks, from the lowest to the
+// highest.
+func sparkline(values []int) string {
+	eighths := []rune(blockEighths)[1:]
+	low, high := slices.Min(values), slices.Max(values)
+	var b strings.Builder
+	for _, v := range values {
+		level := len(eighths) - 1
+		if high > low {
+			level = (v - low) * (len(eighths) - 1) / (high - low)
+		}
+		b.WriteRune(eighths[level])
+	}
+	return b.String()
+}
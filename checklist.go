@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// checklistCommand returns the check command group.
+func checklistCommand() *command {
+	item := func(action func(id, n int) error) func(args []string) error {
+		return func(args []string) error {
+			id, err := parseID(args[0], "task")
+			if err != nil {
+				return err
+			}
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return usagef("invalid checklist item '%s'; use its number in 'task show'", args[1])
+			}
+			return action(id, n)
+		}
+	}
+	return &command{
+		name: "check", summary: "Keep a checklist of small steps within a task", group: groupTasks,
+		subcommands: []*command{
+			{
+				name: "add", args: "<id> <text>", summary: "Add an item to a task's checklist", minArgs: 2,
+				complete: positional(taskIDs),
+				setup: run(func(args []string) error {
+					id, err := parseID(args[0], "task")
+					if err != nil {
+						return err
+					}
+					task, err := tr().AddChecklistItem(id, strings.Join(args[1:], " "))
+					if err != nil {
+						return err
+					}
+					fmt.Fprintf(stdout, "Checklist item %d added to task ID %d %s\n", len(task.Checklist), id, checklistProgress(task))
+					return nil
+				}),
+			},
+			{
+				name: "done", args: "<id> <item>", summary: "Tick off an item of a task's checklist", minArgs: 2,
+				complete: positional(taskIDs, checklistItems(false)),
+				setup:    run(item(func(id, n int) error { return checkItem(id, n, true) })),
+			},
+			{
+				name: "undo", args: "<id> <item>", summary: "Mark an item of a task's checklist as not done", minArgs: 2,
+				complete: positional(taskIDs, checklistItems(true)),
+				setup:    run(item(func(id, n int) error { return checkItem(id, n, false) })),
+			},
+			{
+				name: "remove", args: "<id> <item>", summary: "Remove an item from a task's checklist", minArgs: 2,
+				complete: positional(taskIDs, checklistItems(true)),
+				setup: run(item(func(id, n int) error {
+					task, err := tr().RemoveChecklistItem(id, n)
+					if err != nil {
+						return err
+					}
+					fmt.Fprintf(stdout, "Checklist item %d removed from task ID %d %s\n", n, id, checklistProgress(task))
+					return nil
+				})),
+			},
+		},
+	}
+}
+
+// checkItem marks the nth item of a task's checklist done or not done.
+func checkItem(id, n int, done bool) error {
+	task, err := tr().CheckItem(id, n, done)
+	if err != nil {
+		return err
+	}
+	mark := "done"
+	if !done {
+		mark = "not done"
+	}
+	fmt.Fprintf(stdout, "Checklist item %d of task ID %d marked %s %s\n", n, id, mark, checklistProgress(task))
+	return nil
+}
+
+// checklistProgress returns how much of a task's checklist is done, such as
+// "[2/5]", or "" if it has none.
+func checklistProgress(task Task) string {
+	if len(task.Checklist) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("[%d/%d]", task.ChecklistDone(), len(task.Checklist))
+}
+
+// printChecklist prints a task's checklist, numbered.
+func printChecklist(task Task) {
+	for i, item := range task.Checklist {
+		check := " "
+		if item.Done {
+			check = "x"
+		}
+		fmt.Fprintf(stdout, "  %2d. [%s] %s\n", i+1, check, item.Text)
+	}
+}
+
+// checklistItems returns a completer offering the numbers of the checklist
+// items of the task named by the first argument: those not yet done, or
+// all of them if all is set.
+func checklistItems(all bool) completer {
+	return func(args []string) []candidate {
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			return nil
+		}
+		var cs []candidate
+		for _, task := range completionTasks() {
+			if task.ID != id {
+				continue
+			}
+			for i, item := range task.Checklist {
+				if all || !item.Done {
+					cs = append(cs, candidate{strconv.Itoa(i + 1), item.Text})
+				}
+			}
+		}
+		return cs
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+// listChores prints every rotating chore with whose turn it is now, marking
+// chores due this week, and who is up next.
+func listChores() error {
+	tasks, err := loadTasks()
+	if err != nil {
+		return err
+	}
+
+	now := clock()
+	weekStart := startOfWeek(now)
+	weekEnd := weekStart.AddDate(0, 0, 7)
+
+	found := false
+	for _, task := range tasks {
+		if len(task.Rotation) == 0 || task.Due == nil {
+			continue
+		}
+		if !found {
+			fmt.Fprintf(stdout, "--- Chores (week of %s) ---\n", weekStart.Format(dateLayout))
+			found = true
+		}
+
+		when := "due " + formatDue(*task.Due)
+		switch {
+		case task.Due.Before(weekStart):
+			when = "overdue since " + formatDue(*task.Due)
+		case task.Due.Before(weekEnd):
+			when = "this week, " + when
+		}
+		fmt.Fprintf(stdout, "[ID: %d] %s: %s (%s)\n", task.ID, task.Description, task.Assignee, when)
+		fmt.Fprintf(stdout, "  Next: %s | Rotation: %s\n", tracker.NextInRotation(task.Rotation, task.Assignee), strings.Join(task.Rotation, " → "))
+	}
+
+	if !found {
+		fmt.Fprintln(stdout, "No rotating chores. Use 'task recur <id> weekly --rotate a,b,c' to set one up.")
+		return nil
+	}
+	fmt.Fprintln(stdout, "-------------------------------")
+	return nil
+}
+
+// startOfWeek returns midnight on the Monday of t's week.
+func startOfWeek(t time.Time) time.Time {
+	day := startOfDay(t)
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
@@ -0,0 +1,390 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+// Headings commands are grouped under in the help.
+const (
+	groupTasks     = "Tasks"
+	groupPlanning  = "Planning"
+	groupExpenses  = "Expenses"
+	groupHousehold = "Household"
+	groupData      = "Projects and data"
+)
+
+// runFunc carries out a command with its positional arguments.
+type runFunc func(args []string) error
+
+// command is a CLI command, or a group of subcommands when subcommands is
+// set. Every command gets --help, flags that may appear anywhere among its
+// arguments, and the same handling of usage errors.
+type command struct {
+	name    string
+	args    string // Synopsis of the positional arguments, e.g. "<id> <date>".
+	summary string
+	group   string // Heading the command is listed under in its parent's help.
+	minArgs int    // Fewer positional arguments is a usage error.
+	hidden  bool   // Left out of help and completion.
+
+	// setup registers the command's flags on fs and returns its action. A
+	// command without flags gets its arguments unparsed, so "-tag" or "-5"
+	// reach the action as they are.
+	setup func(fs *flag.FlagSet) runFunc
+
+	subcommands []*command
+
+	// complete, if set, returns the candidates for the next positional
+	// argument after args, and completeFlags those for a flag's value.
+	complete      completer
+	completeFlags map[string]func() []candidate
+}
+
+// run is the setup of a command without flags.
+func run(action runFunc) func(*flag.FlagSet) runFunc {
+	return func(*flag.FlagSet) runFunc { return action }
+}
+
+// usageError reports invalid command-line input. It is printed with the
+// usage of the command it occurred in.
+type usageError struct {
+	msg     string
+	command string // The full command, e.g. "task expense add".
+	usage   string
+}
+
+func (e *usageError) Error() string { return e.msg }
+
+// usagef returns a usageError for the command being run.
+func usagef(format string, a ...any) error {
+	return &usageError{msg: fmt.Sprintf(format, a...)}
+}
+
+// parseID parses a numeric ID argument, naming kind in the error.
+func parseID(s, kind string) (int, error) {
+	id, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, usagef("invalid %s ID '%s'", kind, s)
+	}
+	return id, nil
+}
+
+// execute finds the command named by args and runs it. "help" or --help in
+// place of a command, and "help <command>", print help instead.
+func execute(root *command, args []string) error {
+	if len(args) > 1 && args[0] == "help" {
+		args = append(args[1:], "--help")
+	}
+
+	cmd, path := root, []string{root.name}
+	fail := func(err error) error {
+		var uerr *usageError
+		if errors.As(err, &uerr) && uerr.usage == "" {
+			uerr.command = strings.Join(path, " ")
+			uerr.usage = synopsis(cmd, path)
+		}
+		return err
+	}
+
+	for cmd.subcommands != nil {
+		if len(args) == 0 {
+			return fail(usagef("missing command"))
+		}
+		if isHelp(args[0]) {
+			printCommandHelp(stdout, cmd, path)
+			return nil
+		}
+		sub := cmd.find(args[0])
+		if sub == nil {
+			return fail(usagef("unknown command '%s'", strings.Join(append(path[1:], args[0]), " ")))
+		}
+		cmd, path, args = sub, append(path, sub.name), args[1:]
+	}
+
+	fs := newFlagSet(cmd)
+	action := cmd.setup(fs)
+
+	positional := args
+	if hasFlags(fs) {
+		var err error
+		positional, err = parseFlags(fs, args)
+		if errors.Is(err, flag.ErrHelp) {
+			printCommandHelp(stdout, cmd, path)
+			return nil
+		}
+		if err != nil {
+			return fail(usagef("%v", err))
+		}
+	} else if slices.ContainsFunc(args, func(arg string) bool { return isHelp(arg) && arg != "help" }) {
+		printCommandHelp(stdout, cmd, path)
+		return nil
+	}
+
+	if len(positional) < cmd.minArgs {
+		return fail(usagef("missing arguments"))
+	}
+	return fail(action(positional))
+}
+
+// globalFlags are the flags that apply to any command, given before it
+// (or after --project).
+var globalFlags = map[string]*bool{"dry-run": &dryRun, "json-errors": &jsonErrors, "no-pager": &noPager}
+
+// extractGlobalFlags removes the leading global flags from args, setting
+// them.
+func extractGlobalFlags(args []string) []string {
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		flag, ok := globalFlags[strings.TrimLeft(args[0], "-")]
+		if !ok {
+			break
+		}
+		*flag = true
+		args = args[1:]
+	}
+	return args
+}
+
+// Exit statuses, by the kind of error a command failed with.
+const (
+	exitFailed     = 1 // Any other failure.
+	exitNotFound   = 2 // A task, expense or other record does not exist.
+	exitValidation = 3 // Invalid arguments or values.
+	exitStorage    = 4 // A data file could not be read or written.
+	exitConflict   = 5 // The data changed elsewhere meanwhile.
+)
+
+// jsonErrors is set by the global --json-errors flag.
+var jsonErrors bool
+
+// classifyError returns the code naming the kind of err, as --json-errors
+// reports it, and the exit status for it.
+func classifyError(err error) (string, int) {
+	var uerr *usageError
+	var pathErr *fs.PathError
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &uerr), errors.Is(err, tracker.ErrInvalid):
+		return "validation", exitValidation
+	case errors.Is(err, tracker.ErrNotFound):
+		return "not_found", exitNotFound
+	case errors.Is(err, errSyncStale):
+		return "conflict", exitConflict
+	case errors.As(err, &pathErr), errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+		return "storage", exitStorage
+	}
+	return "error", exitFailed
+}
+
+// reportError prints err, with the usage of the command for a usage error,
+// and returns the exit status for it. With --json-errors it writes the
+// error to stderr as JSON instead.
+func reportError(err error) int {
+	code, status := classifyError(err)
+	var uerr *usageError
+	isUsage := errors.As(err, &uerr)
+	if jsonErrors {
+		writeJSONError(code, err, uerr)
+		return status
+	}
+	if isUsage {
+		fmt.Fprintf(stdout, "Error: %v.\n", uerr)
+		fmt.Fprintf(stdout, "Usage: %s\n", uerr.usage)
+		fmt.Fprintf(stdout, "Run '%s --help' for details.\n", uerr.command)
+		return status
+	}
+	fmt.Fprintf(stdout, "Operation Failed: %v\n", err)
+	return status
+}
+
+// exitWith reports an error met before a command could run, such as a
+// broken config file, and exits.
+func exitWith(err error) {
+	code, status := classifyError(err)
+	if jsonErrors {
+		writeJSONError(code, err, nil)
+	} else {
+		fmt.Fprintf(stdout, "Error: %v\n", err)
+	}
+	os.Exit(status)
+}
+
+// writeJSONError writes {"error": {"code": ..., "message": ...}} to stderr,
+// with the command's usage for a usage error.
+func writeJSONError(code string, err error, uerr *usageError) {
+	type jsonError struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		Usage   string `json:"usage,omitempty"`
+	}
+	e := jsonError{Code: code, Message: err.Error()}
+	if uerr != nil && uerr.usage != "" {
+		e.Usage = uerr.usage
+	}
+	enc := json.NewEncoder(os.Stderr)
+	enc.SetEscapeHTML(false)
+	enc.Encode(map[string]jsonError{"error": e})
+}
+
+// find returns the subcommand called name.
+func (c *command) find(name string) *command {
+	for _, sub := range c.subcommands {
+		if sub.name == name {
+			return sub
+		}
+	}
+	return nil
+}
+
+// isHelp reports whether arg asks for help.
+func isHelp(arg string) bool {
+	return arg == "-h" || arg == "-help" || arg == "--help" || arg == "help"
+}
+
+// newFlagSet returns an empty flag set for cmd that reports errors instead
+// of printing them.
+func newFlagSet(cmd *command) *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+// hasFlags reports whether any flag is defined in fs.
+func hasFlags(fs *flag.FlagSet) bool {
+	found := false
+	fs.VisitAll(func(*flag.Flag) { found = true })
+	return found
+}
+
+// parseFlags parses args with fs, allowing flags to appear before, between,
+// or after positional arguments, and returns the positional arguments.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// synopsis returns the one-line usage of a command.
+func synopsis(cmd *command, path []string) string {
+	line := strings.Join(path, " ")
+	if cmd.subcommands != nil {
+		return line + " <command> [arguments]"
+	}
+	if cmd.args != "" {
+		line += " " + cmd.args
+	}
+	fs := newFlagSet(cmd)
+	cmd.setup(fs)
+	if hasFlags(fs) {
+		line += " [flags]"
+	}
+	return line
+}
+
+// printCommandHelp prints the usage of a command and its flags or, for a
+// group, its subcommands by heading.
+func printCommandHelp(w io.Writer, cmd *command, path []string) {
+	if len(path) == 1 {
+		fmt.Fprintf(w, "Usage: %s [--project <name>] <command> [arguments]\n", path[0])
+	} else {
+		fmt.Fprintf(w, "Usage: %s\n", synopsis(cmd, path))
+	}
+	if cmd.summary != "" {
+		fmt.Fprintf(w, "\n%s\n", cmd.summary)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	if cmd.subcommands != nil {
+		var groups []string
+		for _, sub := range cmd.subcommands {
+			if !sub.hidden && !slices.Contains(groups, sub.group) {
+				groups = append(groups, sub.group)
+			}
+		}
+		for _, group := range groups {
+			if group == "" {
+				group = "Commands"
+			}
+			fmt.Fprintf(tw, "\n%s:\n", group)
+			for _, sub := range cmd.subcommands {
+				if !sub.hidden && (sub.group == group || (group == "Commands" && sub.group == "")) {
+					fmt.Fprintf(tw, "  %s\t%s\n", helpName(sub), sub.summary)
+				}
+			}
+		}
+		tw.Flush()
+		fmt.Fprintf(w, "\nRun '%s <command> --help' for details.\n", strings.Join(path, " "))
+		return
+	}
+
+	fs := newFlagSet(cmd)
+	cmd.setup(fs)
+	if hasFlags(fs) {
+		fmt.Fprintln(tw, "\nFlags:")
+		fs.VisitAll(func(f *flag.Flag) {
+			name, usage := flag.UnquoteUsage(f)
+			spec := "--" + f.Name
+			if name != "" {
+				spec += " " + name
+			}
+			if f.DefValue != "" && f.DefValue != "false" && f.DefValue != "0" && f.DefValue != "-1" {
+				usage += fmt.Sprintf(" (default %s)", f.DefValue)
+			}
+			fmt.Fprintf(tw, "  %s\t%s\n", spec, usage)
+		})
+		tw.Flush()
+	}
+}
+
+// helpName returns how a subcommand is listed in its parent's help.
+func helpName(cmd *command) string {
+	if cmd.subcommands != nil {
+		names := make([]string, len(cmd.subcommands))
+		for i, sub := range cmd.subcommands {
+			names[i] = sub.name
+		}
+		return cmd.name + " <" + strings.Join(names, "|") + ">"
+	}
+	if cmd.args != "" {
+		return cmd.name + " " + cmd.args
+	}
+	return cmd.name
+}
+
+// writeOutput calls write with a new file at path, or with standard output
+// when path is empty.
+func writeOutput(path string, write func(io.Writer) error) error {
+	if path == "" {
+		return write(stdout)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating file: %w", err)
+	}
+	err = write(f)
+	if closeErr := f.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("error writing file: %w", closeErr)
+	}
+	return err
+}
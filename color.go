@@ -0,0 +1,46 @@
+package main
+
+import "os"
+
+// ansiColors maps color names usable in the config to ANSI escape codes.
+var ansiColors = map[string]string{
+	"red":     "\033[31m",
+	"green":   "\033[32m",
+	"yellow":  "\033[33m",
+	"blue":    "\033[34m",
+	"magenta": "\033[35m",
+	"cyan":    "\033[36m",
+	"gray":    "\033[90m",
+}
+
+const ansiReset = "\033[0m"
+
+// useColor reports whether output should be colored: only when writing to a
+// terminal that supports it, and neither NO_COLOR is set nor TERM is dumb.
+var useColor = func() bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return isTerminal(os.Stdout) && enableANSI(os.Stdout)
+}()
+
+// isTerminal reports whether f is a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// colorize wraps s in the named color when color output is enabled.
+func colorize(s, color string) string {
+	code, ok := ansiColors[color]
+	if !useColor || !ok {
+		return s
+	}
+	return code + s + ansiReset
+}
+
+// colorStatus returns status colored as configured in the workflow.
+func colorStatus(status string) string {
+	def, _ := config.Workflow.Find(status)
+	return colorize(status, def.Color)
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	columnGap      = 2 // Spaces between table columns.
+	minColumnShare = 8 // Narrowest a column is cut to when the table is too wide.
+)
+
+// taskColumn is a column 'task list --columns' can show.
+type taskColumn struct {
+	name    string
+	aliases []string
+	header  string
+	flex    bool // Cut to make the table fit the terminal.
+	value   func(task Task, now time.Time, relativeTimes bool) string
+}
+
+// taskColumns are the columns tasks can be listed with, in the order the
+// help lists them.
+var taskColumns = []taskColumn{
+	{name: "id", header: "ID", value: func(task Task, _ time.Time, _ bool) string {
+		return fmt.Sprint(task.ID)
+	}},
+	{name: "status", header: "STATUS", value: func(task Task, _ time.Time, _ bool) string {
+		return task.Status
+	}},
+	{name: "desc", aliases: []string{"description"}, header: "DESCRIPTION", flex: true, value: func(task Task, _ time.Time, _ bool) string {
+		return task.Description
+	}},
+	{name: "due", header: "DUE", value: func(task Task, now time.Time, relativeTimes bool) string {
+		if task.Due == nil {
+			return "-"
+		}
+		if relativeTimes && !config.Workflow.IsDone(task.Status) {
+			return relativeDue(*task.Due, now)
+		}
+		return formatDue(*task.Due)
+	}},
+	{name: "checklist", aliases: []string{"check"}, header: "CHECKLIST", value: func(task Task, _ time.Time, _ bool) string {
+		return cmp.Or(strings.Trim(checklistProgress(task), "[]"), "-")
+	}},
+	{name: "priority", header: "PRIORITY", value: func(task Task, _ time.Time, _ bool) string {
+		return cmp.Or(task.Priority, "-")
+	}},
+	{name: "tags", header: "TAGS", flex: true, value: func(task Task, _ time.Time, _ bool) string {
+		if len(task.Tags) == 0 {
+			return "-"
+		}
+		return formatTags(task.Tags)
+	}},
+	{name: "project", header: "PROJECT", value: func(Task, time.Time, bool) string {
+		return cmp.Or(currentProject, defaultProject)
+	}},
+	{name: "assignee", header: "ASSIGNEE", flex: true, value: func(task Task, _ time.Time, _ bool) string {
+		return cmp.Or(task.Assignee, "-")
+	}},
+	{name: "estimate", header: "ESTIMATE", value: func(task Task, _ time.Time, _ bool) string {
+		if task.Estimate == 0 {
+			return "-"
+		}
+		return formatHours(task.Estimate)
+	}},
+	{name: "scheduled", header: "SCHEDULED", value: func(task Task, _ time.Time, _ bool) string {
+		if task.Scheduled == nil {
+			return "-"
+		}
+		return formatDue(*task.Scheduled)
+	}},
+	{name: "wake", header: "WAKE", value: func(task Task, now time.Time, _ bool) string {
+		switch {
+		case task.Wake == nil:
+			return "-"
+		case task.Woken(now):
+			return "woken"
+		}
+		return formatDue(*task.Wake)
+	}},
+	{name: "recur", header: "REPEATS", value: func(task Task, _ time.Time, _ bool) string {
+		return cmp.Or(task.Recur, "-")
+	}},
+	{name: "created", header: "CREATED", value: func(task Task, now time.Time, relativeTimes bool) string {
+		return formatListTime(task.CreatedAt, now, relativeTimes)
+	}},
+	{name: "updated", header: "UPDATED", value: func(task Task, now time.Time, relativeTimes bool) string {
+		return formatListTime(task.UpdatedAt, now, relativeTimes)
+	}},
+	{name: "completed", aliases: []string{"done"}, header: "COMPLETED", value: func(task Task, now time.Time, relativeTimes bool) string {
+		if task.CompletedAt == nil {
+			return "-"
+		}
+		return formatListTime(*task.CompletedAt, now, relativeTimes)
+	}},
+}
+
+// formatListTime formats a timestamp as lists show it.
+func formatListTime(t, now time.Time, relativeTimes bool) string {
+	if relativeTimes {
+		return relativeTime(t, now)
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
+
+// columnNames returns the names of the columns tasks can be listed with.
+func columnNames() []string {
+	names := make([]string, len(taskColumns))
+	for i, c := range taskColumns {
+		names[i] = c.name
+	}
+	return names
+}
+
+// parseColumns returns the columns named in a comma-separated list.
+func parseColumns(names []string) ([]taskColumn, error) {
+	var columns []taskColumn
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		i := slices.IndexFunc(taskColumns, func(c taskColumn) bool {
+			return c.name == name || slices.Contains(c.aliases, name)
+		})
+		if i < 0 {
+			return nil, usagef("unknown column '%s'; use: %s", name, strings.Join(columnNames(), ", "))
+		}
+		columns = append(columns, taskColumns[i])
+	}
+	if len(columns) == 0 {
+		return nil, usagef("no columns given; use: %s", strings.Join(columnNames(), ", "))
+	}
+	return columns, nil
+}
+
+// tableWidth returns the width a table must fit in: the terminal's, or 0
+// when writing to a file or pipe, which gets every value in full.
+func tableWidth() int {
+	if !isTerminal(os.Stdout) {
+		return 0
+	}
+	return outputWidth()
+}
+
+// printTaskTable prints tasks as a table of columns. If the table is wider
+// than width, the widest of the flexible columns, such as descriptions and
+// tags, are cut in turn until it fits or none can be cut further.
+func printTaskTable(tasks []Task, columns []taskColumn, now time.Time, relativeTimes bool, width int) {
+	cells := make([][]string, len(tasks))
+	widths := make([]int, len(columns))
+	for i, c := range columns {
+		widths[i] = len(c.header)
+	}
+	for row, task := range tasks {
+		cells[row] = make([]string, len(columns))
+		for i, c := range columns {
+			cells[row][i] = c.value(task, now, relativeTimes)
+			widths[i] = max(widths[i], len([]rune(cells[row][i])))
+		}
+	}
+
+	if width > 0 {
+		total := columnGap * (len(columns) - 1)
+		for _, w := range widths {
+			total += w
+		}
+		for total > width {
+			widest := -1
+			for i, c := range columns {
+				if c.flex && widths[i] > max(minColumnShare, len(c.header)) && (widest < 0 || widths[i] > widths[widest]) {
+					widest = i
+				}
+			}
+			if widest < 0 {
+				break
+			}
+			widths[widest]--
+			total--
+		}
+	}
+
+	printRow := func(values []string, status string) {
+		var line strings.Builder
+		for i, value := range values {
+			if i > 0 {
+				line.WriteString(strings.Repeat(" ", columnGap))
+			}
+			value = padRight(truncate(value, widths[i]), widths[i])
+			if columns[i].name == "status" && status != "" {
+				def, _ := config.Workflow.Find(status)
+				value = colorize(value, def.Color)
+			}
+			line.WriteString(value)
+		}
+		fmt.Fprintln(stdout, strings.TrimRight(line.String(), " "))
+	}
+	headers := make([]string, len(columns))
+	for i, c := range columns {
+		headers[i] = c.header
+	}
+	printRow(headers, "")
+	for row, task := range tasks {
+		printRow(cells[row], task.Status)
+	}
+}
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// candidate is a value offered by shell completion, with an optional
+// description the shell may show next to it.
+type candidate struct {
+	value string
+	desc  string
+}
+
+// completer returns the candidates for the positional argument after args.
+type completer func(args []string) []candidate
+
+// completionShells lists the shells completion scripts are generated for.
+var completionShells = []string{"bash", "zsh", "fish", "powershell"}
+
+// completionCommand returns the completion command.
+func completionCommand() *command {
+	return &command{
+		name: "completion", args: "<bash|zsh|fish|powershell>", summary: "Print a shell completion script", group: groupData, minArgs: 1,
+		complete: positional(fixed(completionShells...)),
+		setup: run(func(args []string) error {
+			script, ok := completionScripts[args[0]]
+			if !ok {
+				return usagef("unknown shell '%s'; use one of: %s", args[0], strings.Join(completionShells, ", "))
+			}
+			fmt.Fprint(stdout, script)
+			return nil
+		}),
+	}
+}
+
+// completeCommand returns the hidden command the completion scripts call.
+// Its arguments are the words after the program name up to the cursor, the
+// last being the word being completed. It prints one candidate per line,
+// followed by a tab and its description when it has one.
+func completeCommand(root *command) *command {
+	return &command{
+		name: "__complete", hidden: true,
+		setup: run(func(words []string) error {
+			if len(words) == 0 {
+				words = []string{""}
+			}
+			// PowerShell cannot pass an empty argument to a native command,
+			// so its script passes "" for an empty word instead.
+			if words[len(words)-1] == `""` {
+				words[len(words)-1] = ""
+			}
+			for _, c := range completeWords(root, words) {
+				if c.desc != "" {
+					fmt.Fprintf(stdout, "%s\t%s\n", c.value, c.desc)
+				} else {
+					fmt.Fprintln(stdout, c.value)
+				}
+			}
+			return nil
+		}),
+	}
+}
+
+// completeWords returns the candidates for the last of words, given the
+// words before it.
+func completeWords(root *command, words []string) []candidate {
+	current, words := words[len(words)-1], words[:len(words)-1]
+	matching := func(cs []candidate) []candidate {
+		return slices.DeleteFunc(cs, func(c candidate) bool { return !strings.HasPrefix(c.value, current) })
+	}
+
+	if len(words) > 0 && (words[0] == "--project" || words[0] == "-project") {
+		if len(words) == 1 {
+			return matching(projectCandidates())
+		}
+		if err := selectProject(words[1]); err != nil {
+			return nil
+		}
+		words = words[2:]
+	}
+	if len(words) > 0 && words[0] == "help" {
+		words = words[1:]
+	}
+
+	cmd := root
+	for cmd.subcommands != nil && len(words) > 0 {
+		if cmd = cmd.find(words[0]); cmd == nil {
+			return nil
+		}
+		words = words[1:]
+	}
+	if cmd.subcommands != nil {
+		var cs []candidate
+		for _, sub := range cmd.subcommands {
+			if !sub.hidden {
+				cs = append(cs, candidate{sub.name, sub.summary})
+			}
+		}
+		if cmd == root {
+			cs = append(cs, candidate{"--project", "use a project for this command"})
+		}
+		return matching(cs)
+	}
+
+	fs := newFlagSet(cmd)
+	cmd.setup(fs)
+	var args []string
+	for i := 0; i < len(words); i++ {
+		name, isFlag := flagName(words[i])
+		f := fs.Lookup(name)
+		if !isFlag || f == nil {
+			args = append(args, words[i])
+			continue
+		}
+		if strings.Contains(words[i], "=") || isBoolFlag(f) {
+			continue
+		}
+		if i == len(words)-1 {
+			// current is the flag's value.
+			if values, ok := cmd.completeFlags[name]; ok {
+				return matching(values())
+			}
+			return nil
+		}
+		i++
+	}
+
+	if strings.HasPrefix(current, "-") && hasFlags(fs) {
+		var cs []candidate
+		fs.VisitAll(func(f *flag.Flag) {
+			_, usage := flag.UnquoteUsage(f)
+			cs = append(cs, candidate{"--" + f.Name, usage})
+		})
+		return matching(cs)
+	}
+	if cmd.complete == nil {
+		return nil
+	}
+	return matching(cmd.complete(args))
+}
+
+// flagName returns the name of the flag in word, if it is one.
+func flagName(word string) (string, bool) {
+	if len(word) < 2 || word[0] != '-' {
+		return "", false
+	}
+	name := strings.TrimLeft(word, "-")
+	name, _, _ = strings.Cut(name, "=")
+	return name, name != ""
+}
+
+// isBoolFlag reports whether f takes no value.
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// positional returns a completer offering each argument position's
+// candidates in turn; the last completer repeats for further arguments.
+func positional(fns ...completer) completer {
+	return func(args []string) []candidate {
+		return fns[min(len(args), len(fns)-1)](args)
+	}
+}
+
+// fixed returns a completer offering values.
+func fixed(values ...string) completer {
+	return func([]string) []candidate {
+		cs := make([]candidate, len(values))
+		for i, v := range values {
+			cs[i] = candidate{value: v}
+		}
+		return cs
+	}
+}
+
+// completionTasks returns the tasks of the current project, or nothing when
+// reading them would prompt for a passphrase.
+func completionTasks() []Task {
+	if tr().Encrypted() && os.Getenv(passphraseEnvVar) == "" {
+		return nil
+	}
+	tasks, err := loadTasks()
+	if err != nil {
+		return nil
+	}
+	return tasks
+}
+
+// taskIDs offers the IDs of the current project's tasks, described by
+// their status and description.
+func taskIDs([]string) []candidate {
+	var cs []candidate
+	for _, task := range completionTasks() {
+		cs = append(cs, candidate{strconv.Itoa(task.ID), "[" + task.Status + "] " + task.Description})
+	}
+	return cs
+}
+
+// archivedTaskIDs offers the IDs of the current project's archived tasks.
+func archivedTaskIDs([]string) []candidate {
+	if tr().Encrypted() && os.Getenv(passphraseEnvVar) == "" {
+		return nil
+	}
+	tasks, _ := tr().ArchivedTasks()
+	var cs []candidate
+	for _, task := range tasks {
+		cs = append(cs, candidate{strconv.Itoa(task.ID), "[" + task.Status + "] " + task.Description})
+	}
+	return cs
+}
+
+// taskIDsToMark offers the IDs of tasks not already in the status given as
+// the first argument.
+func taskIDsToMark(args []string) []candidate {
+	var cs []candidate
+	for _, task := range completionTasks() {
+		if task.Status != strings.ToLower(args[0]) {
+			cs = append(cs, candidate{strconv.Itoa(task.ID), "[" + task.Status + "] " + task.Description})
+		}
+	}
+	return cs
+}
+
+// statuses offers the statuses of the workflow.
+func statuses([]string) []candidate {
+	var cs []candidate
+	for _, def := range config.Workflow.Statuses {
+		desc := ""
+		if def.Done {
+			desc = "counts as done"
+		}
+		cs = append(cs, candidate{def.Name, desc})
+	}
+	return cs
+}
+
+// tagCandidates returns the tags used by the current project's tasks.
+func tagCandidates() []candidate {
+	counts := map[string]int{}
+	for _, task := range completionTasks() {
+		for _, tag := range task.Tags {
+			counts[tag]++
+		}
+	}
+	var cs []candidate
+	for _, tag := range sortedKeys(counts) {
+		cs = append(cs, candidate{tag, plural(counts[tag], "task")})
+	}
+	return cs
+}
+
+// tagChanges offers "+tag" for the tags in use and "-tag" for the tags on
+// the task given as the first argument.
+func tagChanges(args []string) []candidate {
+	var cs []candidate
+	for _, c := range tagCandidates() {
+		cs = append(cs, candidate{"+" + c.value, c.desc})
+	}
+	id, _ := strconv.Atoi(args[0])
+	for _, task := range completionTasks() {
+		if task.ID == id {
+			for _, tag := range task.Tags {
+				cs = append(cs, candidate{"-" + tag, "remove"})
+			}
+		}
+	}
+	return cs
+}
+
+// projectCandidates returns the project names.
+func projectCandidates() []candidate {
+	names, _ := projectNames()
+	var cs []candidate
+	for _, name := range names {
+		cs = append(cs, candidate{value: name})
+	}
+	return cs
+}
+
+// completionScripts holds the completion script for each shell. Each calls
+// back into the program to complete the current word.
+var completionScripts = map[string]string{
+	"bash": `# bash completion for task
+# Add to ~/.bashrc: source <(task completion bash)
+_task_complete() {
+    local IFS=$'\n' line
+    COMPREPLY=()
+    for line in $("${COMP_WORDS[0]}" __complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null); do
+        COMPREPLY+=("${line%%$'\t'*}")
+    done
+}
+complete -o default -F _task_complete task
+`,
+
+	"zsh": `#compdef task
+# Add to ~/.zshrc after compinit: source <(task completion zsh)
+_task() {
+    local -a completions
+    local line
+    for line in "${(@f)$("${words[1]}" __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}"; do
+        [[ -z $line ]] && continue
+        if [[ $line == *$'\t'* ]]; then
+            completions+=("${${line%%$'\t'*}//:/\\:}:${line#*$'\t'}")
+        else
+            completions+=("${line//:/\\:}")
+        fi
+    done
+    _describe 'task' completions
+}
+compdef _task task
+`,
+
+	"fish": `# fish completion for task
+# Save as ~/.config/fish/completions/task.fish: task completion fish > ~/.config/fish/completions/task.fish
+function __task_complete
+    set -l tokens (commandline -opc)
+    set -l current (commandline -ct)
+    $tokens[1] __complete $tokens[2..-1] "$current" 2>/dev/null
+end
+complete -c task -f -a '(__task_complete)'
+`,
+
+	"powershell": `# PowerShell completion for task
+# Add to $PROFILE: task completion powershell | Out-String | Invoke-Expression
+Register-ArgumentCompleter -Native -CommandName task -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+    $words = @($commandAst.CommandElements |
+        Where-Object { $_.Extent.StartOffset -lt $cursorPosition } |
+        Select-Object -Skip 1 |
+        ForEach-Object { $_.ToString() })
+    if ($wordToComplete -eq '') { $words += '""' }
+    & $commandAst.CommandElements[0].ToString() __complete @words 2>$null | ForEach-Object {
+        $value, $desc = $_ -split "` + "`t" + `", 2
+        if (-not $desc) { $desc = $value }
+        [System.Management.Automation.CompletionResult]::new($value, $value, 'ParameterValue', $desc)
+    }
+}
+`,
+}
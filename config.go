@@ -0,0 +1,230 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	_ "time/tzdata" // For display.timezone on systems without a zone database.
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+const configFile = "config.json" // User settings shared by all projects.
+
+// Config holds the user's settings.
+type Config struct {
+	Workflow      Workflow          `json:"workflow"`
+	Gamification  Gamification      `json:"gamification"`
+	Urgency       Urgency           `json:"urgency,omitempty"`
+	Display       Display           `json:"display"`
+	History       History           `json:"history"`
+	Hooks         Hooks             `json:"hooks"`
+	Webhooks      []Webhook         `json:"webhooks,omitempty"`
+	Sync          Sync              `json:"sync"`
+	Currency      Currency          `json:"currency"`
+	Dash          Dash              `json:"dash"`
+	Timer         Timer             `json:"timer"`
+	Calendar      Calendar          `json:"calendar"`
+	Statement     Statement         `json:"statement"`
+	Schedule      Schedule          `json:"schedule"`
+	Inbox         Inbox             `json:"inbox"`
+	Storage       Storage           `json:"storage"`
+	Validation    Validation        `json:"validation"`
+	SMTP          SMTP              `json:"smtp"`
+	Reports       []ScheduledReport `json:"reports,omitempty"`       // Run by 'task daemon'.
+	Daemon        Daemon            `json:"daemon"`                  // Jobs 'task daemon' runs besides the reports.
+	Filters       map[string]string `json:"filters,omitempty"`       // Queries 'task list <name>' lists tasks by.
+	CategoryRules []CategoryRule    `json:"categoryRules,omitempty"` // Tried in order on uncategorized expenses.
+	Locale        string            `json:"locale,omitempty"`        // Language of output and of dates written; from the environment if unset.
+	User          string            `json:"user,omitempty"`          // Who runs the commands: "me" to 'task assign', --mine and the history.
+}
+
+// Storage configures how the data files are written.
+type Storage struct {
+	// Journal appends changes to tasks to tasks.json.journal, compacting
+	// it into tasks.json now and then, instead of rewriting tasks.json on
+	// every change. It speeds up changes to large task lists.
+	Journal bool `json:"journal,omitempty"`
+}
+
+// Validation limits what tasks, expenses and income may hold.
+type Validation struct {
+	MaxDescription int     `json:"maxDescription,omitempty"` // Characters in a description; 500 if unset.
+	MaxAmount      float64 `json:"maxAmount,omitempty"`      // Of an expense or income; 1000000000 if unset.
+}
+
+// validate checks the limits are positive when set.
+func (v Validation) validate() error {
+	if v.MaxDescription < 0 {
+		return fmt.Errorf("maxDescription %d is negative", v.MaxDescription)
+	}
+	if v.MaxAmount < 0 {
+		return fmt.Errorf("maxAmount %g is negative", v.MaxAmount)
+	}
+	return nil
+}
+
+// Display configures how lists are rendered.
+type Display struct {
+	Times    string   `json:"times,omitempty"`    // "relative" (the default) or "absolute".
+	Columns  []string `json:"columns,omitempty"`  // Columns 'task list' shows as a table; the full listing if empty.
+	Timezone string   `json:"timezone,omitempty"` // IANA zone times are shown and dates read in, such as "Europe/Berlin"; the system's if empty.
+}
+
+const (
+	timesRelative = "relative"
+	timesAbsolute = "absolute"
+)
+
+// identity returns the user's name from config.json.
+func identity() (string, error) {
+	if config.User == "" {
+		return "", fmt.Errorf(`no user set; add "user": "<your name>" to %s`, configFile)
+	}
+	return config.User, nil
+}
+
+// relativeTimes reports whether lists show times relative to now by default.
+func (d Display) relativeTimes() bool {
+	return d.Times != timesAbsolute
+}
+
+// config is the loaded configuration, or the defaults if there is no config file.
+var config = defaultConfig()
+
+// defaultConfig returns the settings used when the config file does not set them.
+func defaultConfig() Config {
+	return Config{
+		Workflow:     tracker.DefaultWorkflow(),
+		Gamification: defaultGamification(),
+	}
+}
+
+// loadConfig reads the config file, keeping the defaults for anything it
+// does not set.
+func loadConfig() error {
+	data, err := os.ReadFile(configFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", configFile, err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return fmt.Errorf("error unmarshalling %s: %w", configFile, err)
+	}
+	if len(cfg.Workflow.Statuses) == 0 {
+		cfg.Workflow = defaultConfig().Workflow
+	}
+	if err := cfg.Workflow.Validate(); err != nil {
+		return fmt.Errorf("invalid workflow in %s: %w", configFile, err)
+	}
+	for _, s := range cfg.Workflow.Statuses {
+		if _, ok := ansiColors[s.Color]; s.Color != "" && !ok {
+			return fmt.Errorf("invalid workflow in %s: unknown color '%s' for status '%s'", configFile, s.Color, s.Name)
+		}
+	}
+	if t := cfg.Display.Times; t != "" && t != timesRelative && t != timesAbsolute {
+		return fmt.Errorf("invalid display.times '%s' in %s; use '%s' or '%s'", t, configFile, timesRelative, timesAbsolute)
+	}
+	loc := time.Local
+	if tz := cfg.Display.Timezone; tz != "" {
+		if loc, err = time.LoadLocation(tz); err != nil {
+			return fmt.Errorf("invalid display.timezone in %s: %w", configFile, err)
+		}
+	}
+
+	if err := cfg.Urgency.validate(); err != nil {
+		return fmt.Errorf("invalid urgency in %s: %w", configFile, err)
+	}
+
+	if cfg.History.Size < 0 {
+		return fmt.Errorf("invalid history.size %d in %s", cfg.History.Size, configFile)
+	}
+
+	if _, ok := dateLocales[localeLanguage(cfg.Locale)]; cfg.Locale != "" && !ok {
+		return fmt.Errorf("unknown locale '%s' in %s; use one of: %s", cfg.Locale, configFile, strings.Join(sortedKeys(dateLocales), ", "))
+	}
+
+	if err := cfg.Currency.validate(); err != nil {
+		return fmt.Errorf("invalid currency in %s: %w", configFile, err)
+	}
+
+	if _, err := cfg.Schedule.workHours(); err != nil {
+		return fmt.Errorf("invalid schedule in %s: %w", configFile, err)
+	}
+
+	if err := cfg.Inbox.validate(); err != nil {
+		return fmt.Errorf("invalid inbox in %s: %w", configFile, err)
+	}
+
+	if err := validateCategoryRules(cfg.CategoryRules); err != nil {
+		return fmt.Errorf("invalid categoryRules in %s: %w", configFile, err)
+	}
+
+	if err := cfg.Statement.validate(); err != nil {
+		return fmt.Errorf("invalid statement in %s: %w", configFile, err)
+	}
+
+	if err := cfg.Timer.validate(); err != nil {
+		return fmt.Errorf("invalid timer in %s: %w", configFile, err)
+	}
+
+	if err := cfg.Dash.validate(); err != nil {
+		return fmt.Errorf("invalid dash in %s: %w", configFile, err)
+	}
+
+	if err := validateWebhooks(cfg.Webhooks); err != nil {
+		return fmt.Errorf("invalid webhooks in %s: %w", configFile, err)
+	}
+
+	if err := cfg.Hooks.validate(); err != nil {
+		return fmt.Errorf("invalid hooks in %s: %w", configFile, err)
+	}
+
+	if err := validateFilters(cfg.Filters, cfg.Workflow); err != nil {
+		return fmt.Errorf("invalid filters in %s: %w", configFile, err)
+	}
+
+	if err := validateReports(cfg.Reports); err != nil {
+		return fmt.Errorf("invalid reports in %s: %w", configFile, err)
+	}
+
+	if err := cfg.Daemon.validate(); err != nil {
+		return fmt.Errorf("invalid daemon in %s: %w", configFile, err)
+	}
+
+	if err := cfg.Validation.validate(); err != nil {
+		return fmt.Errorf("invalid validation in %s: %w", configFile, err)
+	}
+
+	config = cfg
+	time.Local = loc
+	return nil
+}
+
+// printWorkflow prints the configured statuses and their allowed transitions.
+func printWorkflow() error {
+	w := config.Workflow
+	fmt.Fprintln(stdout, "--- Workflow ---")
+	for _, s := range w.Statuses {
+		next := "any"
+		if allowed, ok := w.Transitions[s.Name]; ok {
+			next = strings.Join(allowed, ", ")
+			if next == "" {
+				next = "none"
+			}
+		}
+		suffix := ""
+		if s.Done {
+			suffix = " (done)"
+		}
+		fmt.Fprintf(stdout, "%s%s → %s\n", colorStatus(s.Name), suffix, next)
+	}
+	fmt.Fprintln(stdout, "----------------")
+	return nil
+}
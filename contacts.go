@@ -0,0 +1,317 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+const (
+	occasionBirthday    = "birthday"
+	occasionAnniversary = "anniversary"
+)
+
+// occasion is a yearly date read from a contacts file.
+type occasion struct {
+	Name  string
+	Kind  string // occasionBirthday or occasionAnniversary.
+	Month time.Month
+	Day   int
+}
+
+// source identifies the task created for an occasion so re-imports can find it.
+func (o occasion) source() string {
+	return "contacts:" + o.Kind + ":" + strings.ToLower(o.Name)
+}
+
+// description is the task description for an occasion.
+func (o occasion) description() string {
+	return fmt.Sprintf("%s's %s", o.Name, o.Kind)
+}
+
+// importCommand returns the import command group.
+func importCommand() *command {
+	return &command{
+		name: "import", summary: "Import data from other tools", group: groupData,
+		subcommands: []*command{
+			{
+				name: "contacts", args: "<file.csv|file.vcf>", summary: "Import birthdays and anniversaries from CSV or vCard", minArgs: 1,
+				setup: func(fs *flag.FlagSet) runFunc {
+					remind := fs.Int("remind", 7, "`days` before the date to start reminding")
+					yes := fs.Bool("yes", false, "update tasks imported before without asking for confirmation")
+					return func(args []string) error { return importContacts(args[0], *remind, *yes) }
+				},
+			},
+			{
+				name: "pack", args: "<file.json>", summary: "Preview and install a shared pack of templates, recipes, filters and rules", minArgs: 1,
+				setup: func(fs *flag.FlagSet) runFunc {
+					yes := fs.Bool("yes", false, "install without asking for confirmation")
+					key := fs.String("key", "", "only install the pack if its .minisig signature matches this public `key` or key file")
+					return func(args []string) error {
+						if err := checkSignature(args[0], *key); err != nil {
+							return err
+						}
+						return importSharedPack(args[0], *yes)
+					}
+				},
+			},
+			{
+				name: "tasks", args: "<file.csv|file.json>", summary: "Import tasks from CSV or JSON, reporting each row", minArgs: 1,
+				setup: func(fs *flag.FlagSet) runFunc {
+					format := fs.String("format", "", "`format` of the file, csv or json (default from the file extension)")
+					key := fs.String("key", "", "only import the file if its .minisig signature matches this public `key` or key file")
+					return func(args []string) error {
+						if *format != "" && *format != importCSV && *format != importJSON {
+							return usagef("invalid import format '%s'; use csv or json", *format)
+						}
+						if err := checkSignature(args[0], *key); err != nil {
+							return err
+						}
+						return importTasksFile(args[0], *format)
+					}
+				},
+			},
+		},
+	}
+}
+
+// importContacts reads birthdays and anniversaries from a CSV or vCard file
+// and creates a yearly recurring task for each, reminding remindDays ahead.
+// Occasions imported before are updated in place rather than duplicated,
+// once the user has confirmed overwriting them unless yes is set.
+func importContacts(path string, remindDays int, yes bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("error opening file: %w", err)
+	}
+	defer f.Close()
+
+	var occasions []occasion
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".vcf", ".vcard":
+		occasions, err = parseVCards(f)
+	default:
+		occasions, err = parseContactsCSV(f)
+	}
+	if err != nil {
+		return err
+	}
+
+	tasks, err := loadTasks()
+	if err != nil {
+		return err
+	}
+
+	now := clock()
+	added, updated, unchanged := 0, 0, 0
+	var overwritten []string
+	for _, o := range occasions {
+		due := nextAnnual(o.Month, o.Day, now)
+
+		existing := -1
+		for i, task := range tasks {
+			if task.Source == o.source() {
+				existing = i
+				break
+			}
+		}
+
+		if existing >= 0 {
+			task := &tasks[existing]
+			if task.Due != nil && task.Due.Month() == due.Month() && task.Due.Day() == due.Day() && task.RemindDays == remindDays {
+				unchanged++
+				continue
+			}
+			from := "none"
+			if task.Due != nil {
+				from = formatDue(*task.Due)
+			}
+			overwritten = append(overwritten, fmt.Sprintf("  [ID: %d] %s: due %s → %s, reminding %d → %d days ahead", task.ID, task.Description, from, formatDue(due), task.RemindDays, remindDays))
+			task.Due = &due
+			task.RemindDays = remindDays
+			task.UpdatedAt = now
+			updated++
+			continue
+		}
+
+		tasks = append(tasks, Task{
+			ID:          getNextID(tasks),
+			Description: o.description(),
+			Status:      config.Workflow.Initial(),
+			Due:         &due,
+			Recur:       tracker.RecurYearly,
+			RemindDays:  remindDays,
+			Source:      o.source(),
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		})
+		added++
+	}
+
+	if updated > 0 && !yes {
+		fmt.Fprintf(stdout, "Importing would update %s imported before:\n", plural(updated, "task"))
+		for _, line := range overwritten {
+			fmt.Fprintln(stdout, line)
+		}
+		ok, err := confirm("Overwrite them?")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Fprintln(stdout, "Nothing imported.")
+			return nil
+		}
+	}
+	if added+updated > 0 {
+		if err := saveTasks(tasks); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(stdout, "Imported contacts: %d added, %d updated, %d unchanged.\n", added, updated, unchanged)
+	return nil
+}
+
+// nextAnnual returns the next date on or after now's day with the given month
+// and day. February 29 falls back to February 28 in non-leap years.
+func nextAnnual(month time.Month, day int, now time.Time) time.Time {
+	today := startOfDay(now)
+	for year := today.Year(); ; year++ {
+		d := day
+		if month == time.February && day == 29 && !isLeapYear(year) {
+			d = 28
+		}
+		date := time.Date(year, month, d, 0, 0, 0, 0, now.Location())
+		if !date.Before(today) {
+			return date
+		}
+	}
+}
+
+// isLeapYear reports whether year is a leap year.
+func isLeapYear(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+// parseContactsCSV reads occasions from a CSV file with a header row naming
+// at least "name" and "date" columns, plus an optional "type" column.
+func parseContactsCSV(r io.Reader) ([]occasion, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading CSV: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("CSV file is empty")
+	}
+
+	columns := map[string]int{}
+	for i, name := range rows[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	nameCol, hasName := columns["name"]
+	dateCol, hasDate := columns["date"]
+	if !hasName || !hasDate {
+		return nil, errors.New("CSV header must contain 'name' and 'date' columns")
+	}
+	typeCol, hasType := columns["type"]
+
+	var occasions []occasion
+	for line, row := range rows[1:] {
+		if nameCol >= len(row) || dateCol >= len(row) {
+			return nil, fmt.Errorf("line %d: missing columns", line+2)
+		}
+		kind := occasionBirthday
+		if hasType && typeCol < len(row) && strings.TrimSpace(row[typeCol]) != "" {
+			kind = strings.ToLower(strings.TrimSpace(row[typeCol]))
+			if kind != occasionBirthday && kind != occasionAnniversary {
+				return nil, fmt.Errorf("line %d: unknown type '%s'", line+2, row[typeCol])
+			}
+		}
+		month, day, err := parseOccasionDate(row[dateCol])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line+2, err)
+		}
+		occasions = append(occasions, occasion{Name: strings.TrimSpace(row[nameCol]), Kind: kind, Month: month, Day: day})
+	}
+	return occasions, nil
+}
+
+// parseVCards reads birthdays (BDAY) and anniversaries (ANNIVERSARY or
+// X-ANNIVERSARY) from a vCard file.
+func parseVCards(r io.Reader) ([]occasion, error) {
+	var occasions []occasion
+	var name string
+	var dates []occasion
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		// Drop parameters such as BDAY;VALUE=date.
+		key, _, _ = strings.Cut(strings.ToUpper(key), ";")
+
+		switch key {
+		case "BEGIN":
+			name, dates = "", nil
+		case "FN":
+			name = strings.TrimSpace(value)
+		case "BDAY", "ANNIVERSARY", "X-ANNIVERSARY":
+			month, day, err := parseOccasionDate(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			kind := occasionAnniversary
+			if key == "BDAY" {
+				kind = occasionBirthday
+			}
+			dates = append(dates, occasion{Kind: kind, Month: month, Day: day})
+		case "END":
+			if name == "" {
+				continue
+			}
+			for _, o := range dates {
+				o.Name = name
+				occasions = append(occasions, o)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading vCard: %w", err)
+	}
+	return occasions, nil
+}
+
+var occasionDatePattern = regexp.MustCompile(`^(?:(\d{4})|--)?-?(\d{2})-?(\d{2})(?:T.*)?$`)
+
+// parseOccasionDate extracts the month and day from dates such as
+// 1990-03-15, 19900315, --0315, --03-15 or 03-15. The year is ignored.
+func parseOccasionDate(s string) (time.Month, int, error) {
+	m := occasionDatePattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, 0, fmt.Errorf("invalid date '%s'", s)
+	}
+
+	month, _ := strconv.Atoi(m[2])
+	day, _ := strconv.Atoi(m[3])
+
+	// Validate against a leap year so February 29 is accepted.
+	date := time.Date(2000, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if month < 1 || month > 12 || date.Day() != day {
+		return 0, 0, fmt.Errorf("invalid date '%s'", s)
+	}
+	return time.Month(month), day, nil
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+const (
+	passphraseEnvVar = "TASK_PASSPHRASE" // Supplies the passphrase non-interactively.
+	passphraseSecret = "passphrase"      // The keyring entry the passphrase can be kept in.
+)
+
+// extraDataFiles lists the per-project data files kept by the CLI itself,
+// which encryption applies to along with tasks and expenses.
+var extraDataFiles = []string{
+	shoppingFile, medsFile, scoreFile, okrFile, recurringFile, debtsFile, reconciliationsFile, attachmentsFile, auditFile,
+	syncFile, caldavSyncFile, githubSyncFile,
+}
+
+// sharedDataFiles lists the data files shared by all projects, which are
+// kept, and encrypted, with the default project's data.
+var sharedDataFiles = []string{inboxFile, historyFile}
+
+// encryptCommand returns the encrypt command group.
+func encryptCommand() *command {
+	return &command{
+		name: "encrypt", summary: "Encrypt or decrypt the data files with a passphrase", group: groupData,
+		subcommands: []*command{
+			{
+				name: "enable", summary: "Encrypt the data files",
+				setup: func(fs *flag.FlagSet) runFunc {
+					keyring := fs.Bool("keyring", false, "keep the passphrase in the OS keyring so that it is not asked for")
+					return func([]string) error { return enableEncryption(*keyring) }
+				},
+			},
+			{name: "disable", summary: "Decrypt the data files", setup: run(func([]string) error { return disableEncryption() })},
+		},
+	}
+}
+
+// enableEncryption encrypts the plaintext data files of every project with
+// a new passphrase, kept in the keyring if asked. Projects encrypted before
+// this applied to all of them keep theirs, which the others are then
+// encrypted with.
+func enableEncryption(keyring bool) error {
+	var plain []*tracker.Tracker
+	var encrypted *tracker.Tracker
+	err := forEachProject(func(string) error {
+		if t := tr(); !t.Encrypted() {
+			plain = append(plain, t)
+		} else if encrypted == nil {
+			encrypted = t
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(plain) == 0 {
+		return errors.New("encryption is already enabled")
+	}
+	tool := ""
+	if keyring {
+		if tool = keyringTool(); tool == "" {
+			return fmt.Errorf("no keyring to keep the passphrase in; set %s instead", passphraseEnvVar)
+		}
+	}
+
+	var passphrase string
+	if encrypted != nil {
+		passphrase, err = existingPassphrase(encrypted)
+	} else {
+		passphrase, err = promptNewPassphrase()
+	}
+	if err != nil {
+		return err
+	}
+	for _, t := range plain {
+		if err := t.EnableEncryption(passphrase, encryptedDataFiles(t)...); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintln(stdout, "Encryption enabled. Keep your passphrase safe; data cannot be recovered without it.")
+	if tool != "" {
+		if err := keyringSet(tool, passphraseSecret, passphrase); err != nil {
+			return fmt.Errorf("could not keep the passphrase in the keyring: %w", err)
+		}
+		fmt.Fprintln(stdout, "Passphrase saved to the keyring.")
+	}
+	return nil
+}
+
+// disableEncryption decrypts the encrypted data files of every project back
+// to plain JSON.
+func disableEncryption() error {
+	disabled := false
+	err := forEachProject(func(string) error {
+		t := tr()
+		if !t.Encrypted() {
+			return nil
+		}
+		disabled = true
+		return t.DisableEncryption(encryptedDataFiles(t)...)
+	})
+	if err != nil {
+		return err
+	}
+	if !disabled {
+		return errors.New("encryption is not enabled")
+	}
+
+	fmt.Fprintln(stdout, "Encryption disabled.")
+	if tool := keyringTool(); tool != "" && keyringDelete(tool, passphraseSecret) == nil {
+		fmt.Fprintln(stdout, "Passphrase removed from the keyring.")
+	}
+	return nil
+}
+
+// encryptedDataFiles returns the CLI's own data files that encryption
+// applies to in t's directory.
+func encryptedDataFiles(t *tracker.Tracker) []string {
+	if t.Dir() == projectDir(defaultProject) {
+		return append(slices.Clone(extraDataFiles), sharedDataFiles...)
+	}
+	return extraDataFiles
+}
+
+// existingPassphrase asks for the passphrase the data files of t are
+// encrypted with, for more files to be encrypted with it.
+func existingPassphrase(t *tracker.Tracker) (string, error) {
+	passphrase, err := promptPassphrase()
+	if err != nil {
+		return "", err
+	}
+	if err := t.CheckPassphrase(passphrase); err != nil {
+		return "", err
+	}
+	return passphrase, nil
+}
+
+// promptPassphrase reads the passphrase from the environment, the keyring
+// or, failing both, standard input.
+func promptPassphrase() (string, error) {
+	if env := os.Getenv(passphraseEnvVar); env != "" {
+		return env, nil
+	}
+	if tool := keyringTool(); tool != "" {
+		if p, err := keyringGet(tool, passphraseSecret); err == nil && p != "" {
+			return p, nil
+		}
+	}
+
+	p, err := readPassphrase("Passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if p == "" {
+		return "", errors.New("passphrase must not be empty")
+	}
+	return p, nil
+}
+
+// promptNewPassphrase asks for a new passphrase twice and checks both match.
+func promptNewPassphrase() (string, error) {
+	if env := os.Getenv(passphraseEnvVar); env != "" {
+		return env, nil
+	}
+
+	p, err := readPassphrase("New passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if p == "" {
+		return "", errors.New("passphrase must not be empty")
+	}
+	confirm, err := readPassphrase("Confirm passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if p != confirm {
+		return "", errors.New("passphrases do not match")
+	}
+	return p, nil
+}
+
+// readPassphrase reads a line as readLine does, without echoing it when
+// standard input is a terminal.
+func readPassphrase(prompt string) (string, error) {
+	restore := disableEcho(os.Stdin)
+	if restore == nil {
+		return readLine(prompt)
+	}
+	defer restore()
+	p, err := readLine(prompt)
+	if pageBuffer != nil {
+		fmt.Fprintln(os.Stdout) // The Enter typed was not echoed either.
+	} else {
+		fmt.Fprintln(stdout)
+	}
+	return p, err
+}
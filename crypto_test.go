@@ -0,0 +1,106 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestPassphraseFromKeyring(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("fakes secret-tool, the Linux keyring tool")
+	}
+	setupCLI(t)
+	bin := t.TempDir()
+	script := "#!/bin/sh\n[ \"$1 $5\" = \"lookup " + passphraseSecret + "\" ] && printf 'correct horse\\n'\n"
+	if err := os.WriteFile(filepath.Join(bin, "secret-tool"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+	t.Setenv(passphraseEnvVar, "")
+
+	p, err := promptPassphrase()
+	if err != nil || p != "correct horse" {
+		t.Errorf("promptPassphrase = %q, %v; want the keyring's", p, err)
+	}
+}
+
+func TestEncryptHistory(t *testing.T) {
+	out := setupCLI(t)
+	t.Setenv("PATH", "")
+	t.Setenv(passphraseEnvVar, "correct horse")
+	mustRunCLI(t, out, "add", "Call the lawyer")
+	if _, err := recordHistory("", []string{"add", "Call the lawyer"}, testNow); err != nil {
+		t.Fatal(err)
+	}
+
+	mustRunCLI(t, out, "encrypt", "enable")
+	if !historyTracker().DocumentEncrypted(historyFile) {
+		t.Errorf("%s was not encrypted", historyFile)
+	}
+	entries, err := loadHistory()
+	if err != nil || len(entries) != 1 || entries[0].Args[1] != "Call the lawyer" {
+		t.Errorf("loadHistory = %+v, %v; want the entry recorded", entries, err)
+	}
+
+	// Commands run once the data is encrypted are still recorded.
+	if id, err := recordHistory("", []string{"add", "Pay the lawyer"}, testNow); err != nil || id != 2 {
+		t.Errorf("recordHistory = %d, %v; want entry 2", id, err)
+	}
+	if !historyTracker().DocumentEncrypted(historyFile) {
+		t.Errorf("%s was saved unencrypted", historyFile)
+	}
+}
+
+func TestEncryptSyncState(t *testing.T) {
+	out := setupCLI(t)
+	t.Setenv("PATH", "")
+	t.Setenv(passphraseEnvVar, "correct horse")
+	mustRunCLI(t, out, "add", "Call the lawyer")
+	tasks, _ := loadTasks()
+	if err := saveDocument(syncFile, syncState{Remote: "https://sync.example.com", Base: tasks}); err != nil {
+		t.Fatal(err)
+	}
+
+	mustRunCLI(t, out, "encrypt", "enable")
+	for _, name := range []string{syncFile, caldavSyncFile, githubSyncFile} {
+		if !tr().DocumentEncrypted(name) {
+			t.Errorf("%s was not encrypted", name)
+		}
+	}
+	if state, err := loadSyncState(); err != nil || len(state.Base) != 1 {
+		t.Errorf("loadSyncState = %+v, %v; want the task synced", state, err)
+	}
+
+	// The empty state files encryption creates read as no state.
+	var github githubSyncState
+	if err := loadStateDocument(githubSyncFile, &github); err != nil || github.Closed != nil {
+		t.Errorf("loadStateDocument(%s) = %+v, %v; want no state", githubSyncFile, github, err)
+	}
+}
+
+func TestEncryptEveryProject(t *testing.T) {
+	out := setupCLI(t)
+	t.Setenv("PATH", "")
+	t.Setenv(passphraseEnvVar, "correct horse")
+	mustRunCLI(t, out, "project", "create", "work")
+	mustRunCLI(t, out, "encrypt", "enable")
+	mustRunCLI(t, out, "project", "create", "home")
+
+	for _, project := range []string{defaultProject, "work", "home"} {
+		if !tr().At(projectDir(project)).Encrypted() {
+			t.Errorf("project %s was not encrypted", project)
+		}
+	}
+	if _, err := runCLI(t, out, "encrypt", "enable"); err == nil {
+		t.Error("enabling encryption twice succeeded")
+	}
+
+	mustRunCLI(t, out, "encrypt", "disable")
+	for _, project := range []string{defaultProject, "work", "home"} {
+		if tr().At(projectDir(project)).Encrypted() {
+			t.Errorf("project %s is still encrypted", project)
+		}
+	}
+}
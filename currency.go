@@ -0,0 +1,231 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+const (
+	ratesFile   = "rates.json" // Cached rates fetched from the ECB, shared by all projects.
+	ratesMaxAge = 24 * time.Hour
+	ecbRatesURL = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml"
+
+	ratesStatic = "static"
+	ratesECB    = "ecb"
+)
+
+// Currency configures the currency of expenses without one and where
+// exchange rates come from.
+type Currency struct {
+	Base string `json:"base,omitempty"` // Of expenses recorded without a currency.
+
+	// Source is "static" (the default) for the Rates table, or "ecb" for the
+	// European Central Bank's daily reference rates.
+	Source string `json:"source,omitempty"`
+
+	// Rates lists how many units of each currency buy one unit of Base.
+	Rates map[string]float64 `json:"rates,omitempty"`
+}
+
+// validate checks the currency codes and the rate source.
+func (c Currency) validate() error {
+	if c.Base != "" && !tracker.ValidCurrency(c.Base) {
+		return fmt.Errorf("invalid base currency '%s'; use a code such as EUR", c.Base)
+	}
+	switch c.Source {
+	case "", ratesStatic:
+		if len(c.Rates) > 0 && c.Base == "" {
+			return errors.New("rates need a base currency")
+		}
+	case ratesECB:
+	default:
+		return fmt.Errorf("unknown rate source '%s'; use %s or %s", c.Source, ratesStatic, ratesECB)
+	}
+	for code, rate := range c.Rates {
+		if !tracker.ValidCurrency(code) || rate <= 0 {
+			return fmt.Errorf("invalid rate %g for '%s'", rate, code)
+		}
+	}
+	return nil
+}
+
+// rateProvider returns the configured source of exchange rates.
+func (c Currency) rateProvider() tracker.RateProvider {
+	if c.Source == ratesECB {
+		return ecbRates{}
+	}
+	return tracker.StaticRates{Base: c.Base, PerBase: c.Rates}
+}
+
+// codes returns the currencies of the user's own rates and the base
+// currency.
+func (c Currency) codes() []string {
+	codes := sortedKeys(c.Rates)
+	if c.Base != "" {
+		codes = append(codes, c.Base)
+	}
+	return codes
+}
+
+// parseCurrency checks and upper-cases a currency code given on the
+// command line.
+func parseCurrency(code string) (string, error) {
+	code = strings.ToUpper(code)
+	if !tracker.KnownCurrency(code) && !slices.Contains(config.Currency.codes(), code) {
+		return "", usagef("unknown currency '%s'; use a code such as EUR or USD, or add a rate for it", code)
+	}
+	return code, nil
+}
+
+// ecbRates fetches the European Central Bank's daily reference rates,
+// which are against the euro, caching them for a day.
+type ecbRates struct{}
+
+// Rates returns the cached rates if fresh, otherwise fetches them. The
+// stale cache is used, with a warning, when they cannot be fetched.
+func (ecbRates) Rates() (tracker.Rates, error) {
+	var cached tracker.Rates
+	data, err := os.ReadFile(ratesFile)
+	if err == nil {
+		err = json.Unmarshal(data, &cached)
+	}
+	if err == nil && time.Since(cached.AsOf) < ratesMaxAge {
+		return cached, nil
+	}
+
+	rates, err := fetchECBRates()
+	if err != nil {
+		if cached.Base == "" {
+			return rates, err
+		}
+		fmt.Fprintf(stdout, "Warning: using exchange rates from %s: %v\n", cached.AsOf.Format(dateLayout), err)
+		return cached, nil
+	}
+	if data, err := json.MarshalIndent(rates, "", "  "); err == nil {
+		os.WriteFile(ratesFile, data, 0644)
+	}
+	return rates, nil
+}
+
+// fetchECBRates downloads and parses the ECB's daily rates.
+func fetchECBRates() (tracker.Rates, error) {
+	rates := tracker.Rates{Base: "EUR", PerBase: map[string]float64{}, AsOf: clock()}
+	data, err := httpGet(ecbRatesURL, "")
+	if err != nil {
+		return rates, fmt.Errorf("error fetching exchange rates: %w", err)
+	}
+
+	var doc struct {
+		Cubes []struct {
+			Currency string `xml:"currency,attr"`
+			Rate     string `xml:"rate,attr"`
+		} `xml:"Cube>Cube>Cube"`
+	}
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return rates, fmt.Errorf("invalid exchange rates: %w", err)
+	}
+	for _, c := range doc.Cubes {
+		if rate, err := strconv.ParseFloat(c.Rate, 64); err == nil && tracker.ValidCurrency(c.Currency) {
+			rates.PerBase[c.Currency] = rate
+		}
+	}
+	if len(rates.PerBase) == 0 {
+		return rates, errors.New("invalid exchange rates: none found")
+	}
+	return rates, nil
+}
+
+// formatAmount renders an amount followed by its currency, if known.
+func formatAmount(amount float64, currency string) string {
+	if currency == "" {
+		return formatNumber(amount)
+	}
+	return formatNumber(amount) + " " + currency
+}
+
+// formatTotals renders amounts by currency, "" being the base currency,
+// such as "10.00 EUR + 5.00 GBP".
+func formatTotals(totals map[string]float64) string {
+	var amounts []string
+	for _, code := range sortedKeys(totals) {
+		currency := code
+		if currency == "" {
+			currency = config.Currency.Base
+		}
+		amounts = append(amounts, formatAmount(totals[code], currency))
+	}
+	return strings.Join(amounts, " + ")
+}
+
+// expenseSummary prints the expenses in a window by category, converted to
+// one currency: to, or the base currency. Recurring charges that have come
+// due are recorded first.
+func expenseSummary(to string, window DateRange) error {
+	if _, err := chargeRecurringExpenses(clock()); err != nil {
+		return err
+	}
+	base := config.Currency.Base
+	if to == "" {
+		to = base
+	}
+	list, err := tr().ListExpenses(tracker.ExpenseFilter{Since: window.Since, Until: window.Until})
+	if err != nil {
+		return err
+	}
+	if len(list.Expenses) == 0 {
+		fmt.Fprintln(stdout, "No expenses found.")
+		return nil
+	}
+
+	byCategory := map[string]map[string]float64{}
+	mixed := false
+	for _, e := range list.Expenses {
+		category := e.Category
+		if category == "" {
+			category = "uncategorized"
+		}
+		if byCategory[category] == nil {
+			byCategory[category] = map[string]float64{}
+		}
+		byCategory[category][e.Currency] += e.Amount
+		mixed = mixed || e.Currency != "" && e.Currency != to
+	}
+	if (mixed || to != base) && base == "" {
+		return fmt.Errorf("set currency.base in %s to total expenses in different currencies", configFile)
+	}
+
+	rates := tracker.Rates{Base: to}
+	if mixed || to != base {
+		if rates, err = config.Currency.rateProvider().Rates(); err != nil {
+			return err
+		}
+	}
+
+	total, err := rates.ConvertTotals(list.Totals, base, to)
+	if err != nil {
+		return err
+	}
+	title := msg("Expense Summary")
+	if to != "" {
+		title = fmt.Sprintf(msg("Expense Summary in %s"), to)
+	}
+	fmt.Fprintf(stdout, "--- %s ---\n", title)
+	for _, category := range sortedKeys(byCategory) {
+		subtotal, _ := rates.ConvertTotals(byCategory[category], base, to)
+		fmt.Fprintf(stdout, "%-20s %10s\n", category, formatNumber(subtotal))
+	}
+	fmt.Fprintf(stdout, msg("--- Total: %s ---\n"), formatAmount(total, to))
+	if !rates.AsOf.IsZero() {
+		fmt.Fprintf(stdout, "Rates as of %s.\n", rates.AsOf.Format(dateLayout))
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	reportsFile       = "reports.json" // When each scheduled report last ran, shared by all projects.
+	daemonInterval    = time.Minute    // How often the daemon looks for reports to run.
+	defaultReportTime = "08:00"
+)
+
+// Reports the daemon can run.
+const (
+	reportDigest  = "digest"  // Today's due, overdue and yesterday's completed tasks, to Slack or Discord.
+	reportSummary = "summary" // The task and expense summary of 'task report email', by email.
+)
+
+// ScheduledReport is a report the daemon runs and delivers on a schedule:
+// a digest to a Slack or Discord channel, or a summary by email.
+type ScheduledReport struct {
+	Name    string `json:"name"`              // Identifies the report in the daemon's output and reports.json.
+	Report  string `json:"report"`            // digest or summary.
+	Period  string `json:"period,omitempty"`  // What a summary covers: week (the default) or month.
+	Every   string `json:"every"`             // day, week or month.
+	On      string `json:"on,omitempty"`      // Weekday of a weekly report (monday by default), or day of the month (1-28, 1 by default) of a monthly one.
+	At      string `json:"at,omitempty"`      // Time of day, as HH:MM; 08:00 by default.
+	Channel string `json:"channel,omitempty"` // Slack or Discord webhook URL of a digest; may name a secret.
+	To      string `json:"to,omitempty"`      // Addresses of a summary; smtp.to by default.
+}
+
+// validateReports checks the schedule and delivery of each report.
+func validateReports(reports []ScheduledReport) error {
+	seen := map[string]bool{}
+	for _, r := range reports {
+		if r.Name == "" || seen[r.Name] {
+			return fmt.Errorf("each report needs a name of its own")
+		}
+		seen[r.Name] = true
+		switch r.Report {
+		case reportDigest:
+			if r.Channel == "" {
+				return fmt.Errorf("digest '%s' needs a channel", r.Name)
+			}
+		case reportSummary:
+			if r.Period != "" && r.Period != reportWeek && r.Period != reportMonth {
+				return fmt.Errorf("invalid period '%s' of report '%s'; use week or month", r.Period, r.Name)
+			}
+		default:
+			return fmt.Errorf("unknown report '%s'; use digest or summary", r.Report)
+		}
+		if _, err := r.lastDue(clock()); err != nil {
+			return fmt.Errorf("report '%s': %w", r.Name, err)
+		}
+	}
+	return nil
+}
+
+// lastDue returns the latest time at or before now the report was due.
+func (r ScheduledReport) lastDue(now time.Time) (time.Time, error) {
+	at, err := time.Parse("15:04", cmp.Or(r.At, defaultReportTime))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time '%s'; use HH:MM", r.At)
+	}
+	day := startOfDay(now)
+	due := func(d time.Time) time.Time {
+		return d.Add(time.Duration(at.Hour())*time.Hour + time.Duration(at.Minute())*time.Minute)
+	}
+
+	switch r.Every {
+	case "day":
+		if t := due(day); !t.After(now) {
+			return t, nil
+		}
+		return due(day.AddDate(0, 0, -1)), nil
+	case "week":
+		weekday, ok := parseWeekday(cmp.Or(r.On, "monday"))
+		if !ok {
+			return time.Time{}, fmt.Errorf("invalid weekday '%s'", r.On)
+		}
+		d := day.AddDate(0, 0, -((int(day.Weekday())-int(weekday))+7)%7)
+		if t := due(d); !t.After(now) {
+			return t, nil
+		}
+		return due(d.AddDate(0, 0, -7)), nil
+	case "month":
+		n, err := strconv.Atoi(cmp.Or(r.On, "1"))
+		if err != nil || n < 1 || n > 28 {
+			return time.Time{}, fmt.Errorf("invalid day of the month '%s'; use 1 to 28", r.On)
+		}
+		d := time.Date(now.Year(), now.Month(), n, 0, 0, 0, 0, now.Location())
+		if t := due(d); !t.After(now) {
+			return t, nil
+		}
+		return due(d.AddDate(0, -1, 0)), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid interval '%s'; use day, week or month", r.Every)
+}
+
+// parseWeekday parses the English name of a weekday, or its first three
+// letters.
+func parseWeekday(name string) (time.Weekday, bool) {
+	name = strings.ToLower(name)
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		full := strings.ToLower(d.String())
+		if name == full || name == full[:3] {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// daemonCommand returns the daemon command.
+func daemonCommand() *command {
+	return &command{
+		name: "daemon", args: "[start | status | stop]", summary: "Run the reports and jobs in config.json on their schedules, in the foreground or background", group: groupData,
+		complete: positional(fixed("start", "status", "stop")),
+		setup: func(fs *flag.FlagSet) runFunc {
+			once := fs.Bool("once", false, "run the reports and jobs that are due and exit, as from cron")
+			return func(args []string) error {
+				if len(args) == 0 {
+					return runDaemon(*once)
+				}
+				switch {
+				case len(args) > 1 || *once:
+				case args[0] == "start":
+					return startDaemon()
+				case args[0] == "status":
+					return daemonStatus()
+				case args[0] == "stop":
+					return stopDaemon()
+				}
+				return usagef("expected start, status or stop, or --once alone")
+			}
+		},
+	}
+}
+
+// runDaemon runs the scheduled reports and jobs as they come due, checking
+// every minute until interrupted or stopped. Reports missed while it was
+// not running are run once when it starts. It locks the data files only
+// while running them, and daemon.pid while it runs, so only one daemon
+// runs at a time.
+func runDaemon(once bool) error {
+	jobs := config.Daemon.jobs()
+	if len(config.Reports) == 0 && len(jobs) == 0 {
+		return fmt.Errorf("nothing to run; add reports, or jobs under daemon, to %s", configFile)
+	}
+	release, err := claimDaemon()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if !once {
+		var running []string
+		if len(config.Reports) > 0 {
+			running = append(running, plural(len(config.Reports), "scheduled report"))
+		}
+		if len(jobs) > 0 {
+			running = append(running, fmt.Sprintf("%s (%s)", plural(len(jobs), "job"), strings.Join(jobs, ", ")))
+		}
+		fmt.Fprintf(stdout, "Running %s; stop with Ctrl-C or 'task daemon stop'.\n", strings.Join(running, " and "))
+	}
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+	ticker := time.NewTicker(daemonInterval)
+	defer ticker.Stop()
+
+	for {
+		unlock, err := lockData()
+		if err != nil {
+			return err
+		}
+		now := clock()
+		err = runDueReports(now)
+		if err == nil {
+			err = runDueJobs(now)
+		}
+		unlock()
+		if err != nil || once {
+			return err
+		}
+		select {
+		case <-ticker.C:
+		case <-interrupt:
+			fmt.Fprintf(stdout, "%s Daemon stopped.\n", clock().Format(dateLayout+" 15:04"))
+			return nil
+		}
+	}
+}
+
+// runDueReports runs each report due since it last ran. A report that
+// fails is a warning, and is not tried again until it is next due.
+func runDueReports(now time.Time) error {
+	lastRun := map[string]time.Time{}
+	data, err := os.ReadFile(reportsFile)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error reading %s: %w", reportsFile, err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &lastRun); err != nil {
+			return fmt.Errorf("error unmarshalling %s: %w", reportsFile, err)
+		}
+	}
+
+	ran := false
+	for _, r := range config.Reports {
+		due, err := r.lastDue(now)
+		if err != nil || !due.After(lastRun[r.Name]) {
+			continue
+		}
+		fmt.Fprintf(stdout, "%s Running report %s\n", now.Format(dateLayout+" 15:04"), r.Name)
+		switch r.Report {
+		case reportDigest:
+			err = postDigest(r.Channel, now)
+		case reportSummary:
+			err = emailReportTo(r.To, cmp.Or(r.Period, reportWeek), now)
+		}
+		if err != nil {
+			fmt.Fprintf(stdout, "Warning: report %s failed: %v\n", r.Name, err)
+		}
+		lastRun[r.Name] = now
+		ran = true
+	}
+	if !ran {
+		return nil
+	}
+
+	// Forget reports no longer configured
+	for name := range lastRun {
+		if !slices.ContainsFunc(config.Reports, func(r ScheduledReport) bool { return r.Name == name }) {
+			delete(lastRun, name)
+		}
+	}
+	data, err = json.MarshalIndent(lastRun, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling JSON: %w", err)
+	}
+	if err := os.WriteFile(reportsFile, data, 0644); err != nil {
+		return fmt.Errorf("error writing file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"archive/zip"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	daemonStateFile    = "daemon.json" // When the daemon's jobs last ran, shared by all projects.
+	daemonLockFile     = "daemon.lock" // Locked while the daemon runs.
+	daemonPIDFile      = "daemon.pid"  // The process ID of the running daemon.
+	daemonLogFile      = "daemon.log"  // Output of the daemon started in the background.
+	backupsDir         = "backups"
+	defaultKeepBackups = 7
+	minSyncInterval    = time.Minute
+)
+
+// Daemon configures the jobs 'task daemon' runs besides the reports. Every
+// job is off unless set.
+type Daemon struct {
+	Recurring   bool   `json:"recurring,omitempty"`   // Record recurring expenses as they come due, in every project.
+	Reminders   bool   `json:"reminders,omitempty"`   // Notify of each task whose reminder lead time has started, once a day, and of missed doses.
+	Backup      string `json:"backup,omitempty"`      // How often to back up the data directory: day or week.
+	KeepBackups int    `json:"keepBackups,omitempty"` // Backups kept; 7 if unset.
+	Sync        string `json:"sync,omitempty"`        // How often to sync the current project with sync.remote, as a duration such as "15m".
+}
+
+// validate checks the backup and sync intervals.
+func (d Daemon) validate() error {
+	if d.Backup != "" && d.Backup != "day" && d.Backup != "week" {
+		return fmt.Errorf("invalid backup interval '%s'; use day or week", d.Backup)
+	}
+	if d.KeepBackups < 0 {
+		return fmt.Errorf("invalid keepBackups %d", d.KeepBackups)
+	}
+	if d.Sync != "" {
+		interval, err := time.ParseDuration(d.Sync)
+		if err != nil || interval < minSyncInterval {
+			return fmt.Errorf("invalid sync interval '%s'; use a duration of a minute or more, such as 15m", d.Sync)
+		}
+	}
+	return nil
+}
+
+// jobs returns the names of the jobs that are on.
+func (d Daemon) jobs() []string {
+	var jobs []string
+	if d.Recurring {
+		jobs = append(jobs, "recurring")
+	}
+	if d.Reminders {
+		jobs = append(jobs, "reminders")
+	}
+	if d.Backup != "" {
+		jobs = append(jobs, "backup")
+	}
+	if d.Sync != "" {
+		jobs = append(jobs, "sync")
+	}
+	return jobs
+}
+
+// daemonState is what the daemon remembers between runs.
+type daemonState struct {
+	Backup   time.Time         `json:"backup,omitzero"`    // When the data was last backed up.
+	Sync     time.Time         `json:"sync,omitzero"`      // When the project was last synced.
+	Reminded map[string]string `json:"reminded,omitempty"` // Day each task was last reminded of, by UUID.
+}
+
+// runDueJobs runs each job that is on and due. A job that fails is a
+// warning, and is tried again when next due.
+func runDueJobs(now time.Time) error {
+	d := config.Daemon
+	if len(d.jobs()) == 0 {
+		return nil
+	}
+	var state daemonState
+	data, err := os.ReadFile(daemonStateFile)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error reading %s: %w", daemonStateFile, err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &state); err != nil {
+			return fmt.Errorf("error unmarshalling %s: %w", daemonStateFile, err)
+		}
+	}
+	stamp := now.Format(dateLayout + " 15:04")
+	warn := func(job string, err error) {
+		if err != nil {
+			fmt.Fprintf(stdout, "%s Warning: job %s failed: %v\n", stamp, job, err)
+		}
+	}
+
+	if d.Recurring {
+		warn("recurring", forEachProject(func(string) error {
+			_, err := chargeRecurringExpenses(now)
+			return err
+		}))
+	}
+	if d.Reminders {
+		warn("reminders", sendReminders(&state, now))
+	}
+	if every := map[string]int{"day": 1, "week": 7}[d.Backup]; every > 0 && !now.Before(state.Backup.AddDate(0, 0, every)) {
+		path, err := backupData(now, cmp.Or(d.KeepBackups, defaultKeepBackups))
+		if err == nil {
+			fmt.Fprintf(stdout, "%s Backed up the data to %s\n", stamp, path)
+			state.Backup = now
+		}
+		warn("backup", err)
+	}
+	if interval, _ := time.ParseDuration(d.Sync); interval > 0 && now.Sub(state.Sync) >= interval {
+		err := syncTasks("")
+		if err == nil {
+			state.Sync = now
+		}
+		warn("sync", err)
+	}
+
+	data, err = json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling JSON: %w", err)
+	}
+	if err := os.WriteFile(daemonStateFile, data, 0644); err != nil {
+		return fmt.Errorf("error writing file: %w", err)
+	}
+	return nil
+}
+
+// sendReminders notifies of each task, in any project, whose reminder lead
+// time has started and that has not been reminded of today, and of each
+// dose of medication missed by more than the default grace.
+func sendReminders(state *daemonState, now time.Time) error {
+	today := now.Format(dateLayout)
+	reminded := map[string]string{}
+	err := forEachProject(func(string) error {
+		tasks, err := loadTasks()
+		if err != nil {
+			return err
+		}
+		for _, task := range tasks {
+			if !inReminderWindow(task, now) {
+				continue
+			}
+			if state.Reminded[task.UUID] != today {
+				notify("Reminder", fmt.Sprintf("%s (due %s)", task.Description, reminderWhen(*task.Due, now)))
+			}
+			reminded[task.UUID] = today
+		}
+		_, err = notifyMissedDoses(defaultMedGrace, now)
+		return err
+	})
+	// Only today's reminders are kept, so the state does not grow.
+	state.Reminded = reminded
+	return err
+}
+
+// backupData writes the data directory to a zip file in backups, leaving
+// out the backups themselves, the lock, the daemon's own files and the git
+// history, and deletes all but the latest keep backups. It returns the
+// path of the backup.
+func backupData(now time.Time, keep int) (string, error) {
+	if err := os.MkdirAll(backupsDir, 0755); err != nil {
+		return "", fmt.Errorf("error creating %s: %w", backupsDir, err)
+	}
+	path := filepath.Join(backupsDir, "task-"+now.Format("2006-01-02-150405")+".zip")
+	f, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("error creating backup: %w", err)
+	}
+	zw := zip.NewWriter(f)
+	skipped := []string{backupsDir, lockFile, daemonLockFile, daemonPIDFile, daemonLogFile, ".git"}
+	err = filepath.WalkDir(".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || name == "." {
+			return err
+		}
+		if slices.Contains(skipped, name) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		src, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		dst, err := zw.Create(filepath.ToSlash(name))
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(dst, src)
+		return err
+	})
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", fmt.Errorf("error writing backup: %w", err)
+	}
+
+	// The names sort by time.
+	backups, _ := filepath.Glob(filepath.Join(backupsDir, "task-*.zip"))
+	slices.Sort(backups)
+	for _, old := range backups[:max(0, len(backups)-keep)] {
+		os.Remove(old)
+	}
+	return path, nil
+}
+
+// claimDaemon locks daemon.lock and writes the process ID to daemon.pid,
+// failing if another daemon holds the lock. It returns the function that
+// releases it.
+func claimDaemon() (func(), error) {
+	f, err := os.OpenFile(daemonLockFile, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening %s: %w", daemonLockFile, err)
+	}
+	if err := lockFileExclusive(f, false); err != nil {
+		f.Close()
+		if errors.Is(err, errLocked) {
+			pid, _ := runningDaemon()
+			return nil, fmt.Errorf("the daemon is already running (PID %d)", pid)
+		}
+		return nil, fmt.Errorf("error locking %s: %w", daemonLockFile, err)
+	}
+	if err := os.WriteFile(daemonPIDFile, fmt.Appendf(nil, "%d\n", os.Getpid()), 0644); err != nil {
+		unlockFile(f)
+		f.Close()
+		return nil, fmt.Errorf("error writing file: %w", err)
+	}
+	return func() {
+		os.Remove(daemonPIDFile)
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
+
+// runningDaemon returns the process ID of the running daemon, and whether
+// one is running: whether daemon.lock is locked.
+func runningDaemon() (int, bool) {
+	f, err := os.OpenFile(daemonLockFile, os.O_RDWR, 0)
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+	if err := lockFileExclusive(f, false); err == nil {
+		unlockFile(f)
+		return 0, false
+	}
+	data, _ := os.ReadFile(daemonPIDFile)
+	pid, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+	return pid, true
+}
+
+// startDaemon starts the daemon in the background, detached from the
+// terminal, with its output going to daemon.log, and waits until it runs.
+func startDaemon() error {
+	if pid, running := runningDaemon(); running {
+		return fmt.Errorf("the daemon is already running (PID %d)", pid)
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	log, err := os.OpenFile(daemonLogFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("error opening %s: %w", daemonLogFile, err)
+	}
+	defer log.Close()
+	cmd := exec.Command(executable, "--project", cmp.Or(currentProject, defaultProject), "daemon")
+	cmd.Stdout, cmd.Stderr = log, log
+	detach(cmd)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("error starting the daemon: %w", err)
+	}
+	exited := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(exited)
+	}()
+
+	for range 50 {
+		if pid, running := runningDaemon(); running {
+			fmt.Fprintf(stdout, "Daemon started (PID %d); its output goes to %s.\n", pid, daemonLogFile)
+			return nil
+		}
+		select {
+		case <-exited:
+			return fmt.Errorf("the daemon stopped; see %s", daemonLogFile)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	return fmt.Errorf("the daemon did not start; see %s", daemonLogFile)
+}
+
+// daemonStatus prints whether the daemon is running and when its jobs last
+// ran.
+func daemonStatus() error {
+	pid, running := runningDaemon()
+	if running {
+		fmt.Fprintf(stdout, "The daemon is running (PID %d).\n", pid)
+	} else {
+		fmt.Fprintln(stdout, "The daemon is not running.")
+	}
+	var state daemonState
+	if data, err := os.ReadFile(daemonStateFile); err == nil {
+		json.Unmarshal(data, &state)
+	}
+	for _, job := range config.Daemon.jobs() {
+		last := map[string]time.Time{"backup": state.Backup, "sync": state.Sync}[job]
+		switch {
+		case job != "backup" && job != "sync":
+			fmt.Fprintf(stdout, "  %-10s every minute\n", job)
+		case last.IsZero():
+			fmt.Fprintf(stdout, "  %-10s not run yet\n", job)
+		default:
+			fmt.Fprintf(stdout, "  %-10s last run %s\n", job, last.Format(dateLayout+" 15:04"))
+		}
+	}
+	return nil
+}
+
+// stopDaemon interrupts the running daemon and waits for it to stop.
+func stopDaemon() error {
+	pid, running := runningDaemon()
+	if !running {
+		fmt.Fprintln(stdout, "The daemon is not running.")
+		return nil
+	}
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return fmt.Errorf("error finding the daemon (PID %d): %w", pid, err)
+	}
+	// Windows cannot interrupt another process.
+	if err := p.Signal(os.Interrupt); err != nil {
+		if err := p.Kill(); err != nil {
+			return fmt.Errorf("error stopping the daemon (PID %d): %w", pid, err)
+		}
+	}
+	for range 100 {
+		if _, running := runningDaemon(); !running {
+			fmt.Fprintf(stdout, "Daemon stopped (PID %d).\n", pid)
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return fmt.Errorf("the daemon (PID %d) did not stop", pid)
+}
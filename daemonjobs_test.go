@@ -0,0 +1,21 @@
+package main
+
+import "testing"
+
+func TestRemindersNotifyMissedDoses(t *testing.T) {
+	out := setupCLI(t)
+	t.Setenv("PATH", "") // No notification tool, so notifying rings the bell.
+	mustRunCLI(t, out, "med", "add", "--times", "07:00", "Vitamin D")
+
+	state := &daemonState{}
+	if err := sendReminders(state, testNow); err != nil {
+		t.Fatal(err)
+	}
+	meds, err := loadMedications()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry := meds[0].logFor(meds[0].slotsOn(testNow)[0]); entry == nil || !entry.Notified {
+		t.Errorf("the missed 07:00 dose was not notified of: %+v", meds[0].Log)
+	}
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultDashSession = "task"
+
+	splitHorizontal = "horizontal" // Side by side.
+	splitVertical   = "vertical"   // One above the other.
+)
+
+// Dash configures the panes 'task dash' opens in tmux or screen.
+type Dash struct {
+	Session string     `json:"session,omitempty"` // Name of the tmux session or screen; "task" if unset.
+	Layout  string     `json:"layout,omitempty"`  // tmux layout applied once the panes are open, such as "main-vertical".
+	Panes   []DashPane `json:"panes,omitempty"`
+}
+
+// DashPane is a pane of the dashboard and the command it runs.
+type DashPane struct {
+	Command string `json:"command,omitempty"` // A shell if unset.
+	Split   string `json:"split,omitempty"`   // How the pane is split off the one before: "horizontal" (default) or "vertical".
+	Size    int    `json:"size,omitempty"`    // Percentage of the split pane it takes; half if unset.
+}
+
+// defaultDash watches the task list and what is due today beside a shell
+// to start pomodoro timers from. It is not 'task shell', which would keep
+// the data locked from the other panes.
+var defaultDash = Dash{
+	Session: defaultDashSession,
+	Panes: []DashPane{
+		{Command: "task watch list"},
+		{Command: "watch -t -n 60 task list --until today", Split: splitHorizontal},
+		{Split: splitVertical, Size: 30},
+	},
+}
+
+// validate checks the panes of the dashboard.
+func (d Dash) validate() error {
+	for i, p := range d.Panes {
+		if p.Split != "" && p.Split != splitHorizontal && p.Split != splitVertical {
+			return fmt.Errorf("invalid split '%s' for pane %d; use %s or %s", p.Split, i+1, splitHorizontal, splitVertical)
+		}
+		if p.Size < 0 || p.Size >= 100 {
+			return fmt.Errorf("invalid size %d for pane %d; use a percentage", p.Size, i+1)
+		}
+	}
+	return nil
+}
+
+// withDefaults fills in the session name and panes left unset.
+func (d Dash) withDefaults() Dash {
+	if d.Session == "" {
+		d.Session = defaultDashSession
+	}
+	if len(d.Panes) == 0 {
+		d.Panes = defaultDash.Panes
+	}
+	return d
+}
+
+// dashCommand returns the dash command.
+func dashCommand() *command {
+	return &command{
+		name: "dash", summary: "Open the task list, today's tasks and a shell for timers in tmux or screen", group: groupTasks,
+		setup: func(fs *flag.FlagSet) runFunc {
+			tmux := fs.Bool("tmux", false, "open the dashboard in tmux")
+			screen := fs.Bool("screen", false, "open the dashboard in GNU screen")
+			return func([]string) error {
+				dash := config.Dash.withDefaults()
+				switch {
+				case *tmux && *screen:
+					return usagef("use only one of --tmux and --screen")
+				case *tmux:
+					return openTmuxDash(dash)
+				case *screen:
+					return openScreenDash(dash)
+				}
+				return usagef("use --tmux or --screen")
+			}
+		},
+	}
+}
+
+// openTmuxDash opens the dashboard in a new tmux session and attaches to
+// it, or attaches to the session if it is already open. Inside tmux the
+// dashboard opens in a new window instead.
+func openTmuxDash(d Dash) error {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		return errors.New("tmux not found; install it or use --screen")
+	}
+	inside := os.Getenv("TMUX") != ""
+	if !inside && exec.Command("tmux", "has-session", "-t", "="+d.Session).Run() == nil {
+		return attach("tmux", "attach-session", "-t", "="+d.Session)
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	// The panes use this data directory even if the tmux server was
+	// started from another.
+	env := []string{"-c", dir, "-e", dataDirEnvVar + "=" + dir}
+	open := append([]string{"new-session", "-d", "-s", d.Session, "-P", "-F", "#{window_id}"}, env...)
+	if inside {
+		open = append([]string{"new-window", "-n", d.Session, "-P", "-F", "#{window_id}"}, env...)
+	}
+	out, err := exec.Command("tmux", append(open, paneCommand(d.Panes[0])...)...).Output()
+	if err != nil {
+		return fmt.Errorf("error opening tmux %s: %w", d.Session, err)
+	}
+	window := strings.TrimSpace(string(out))
+
+	for i, p := range d.Panes[1:] {
+		split := []string{"split-window", "-t", window, "-h"}
+		if p.Split == splitVertical {
+			split[3] = "-v"
+		}
+		if p.Size > 0 {
+			split = append(split, "-l", strconv.Itoa(p.Size)+"%")
+		}
+		split = append(append(split, env...), paneCommand(p)...)
+		if err := exec.Command("tmux", split...).Run(); err != nil {
+			return fmt.Errorf("error opening pane %d: %w", i+2, err)
+		}
+	}
+	if d.Layout != "" {
+		if err := exec.Command("tmux", "select-layout", "-t", window, d.Layout).Run(); err != nil {
+			fmt.Fprintf(stdout, "Warning: could not apply tmux layout '%s': %v\n", d.Layout, err)
+		}
+	}
+	exec.Command("tmux", "select-pane", "-t", window+".0").Run()
+
+	if inside {
+		return exec.Command("tmux", "select-window", "-t", window).Run()
+	}
+	return attach("tmux", "attach-session", "-t", "="+d.Session)
+}
+
+// openScreenDash opens the dashboard in a new GNU screen session, from a
+// screenrc written for it. screen has no named layouts, so Layout is not
+// used.
+func openScreenDash(d Dash) error {
+	if _, err := exec.LookPath("screen"); err != nil {
+		return errors.New("screen not found; install it or use --tmux")
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	var rc strings.Builder
+	for i, p := range d.Panes {
+		if i > 0 {
+			if p.Split == splitVertical {
+				rc.WriteString("split\n")
+			} else {
+				rc.WriteString("split -v\n")
+			}
+			rc.WriteString("focus\n")
+			if p.Size > 0 {
+				fmt.Fprintf(&rc, "resize %d%%\n", p.Size)
+			}
+		}
+		if p.Command == "" {
+			fmt.Fprintf(&rc, "screen -t %d\n", i+1)
+		} else {
+			fmt.Fprintf(&rc, "screen -t %d sh -c %s\n", i+1, screenQuote(p.Command))
+		}
+	}
+	rc.WriteString("focus top\n")
+
+	f, err := os.CreateTemp("", "task-dash-*.screenrc")
+	if err != nil {
+		return fmt.Errorf("error writing screenrc: %w", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(rc.String()); err != nil {
+		f.Close()
+		return fmt.Errorf("error writing screenrc: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("error writing screenrc: %w", err)
+	}
+
+	os.Setenv(dataDirEnvVar, dir)
+	return attach("screen", "-S", d.Session, "-c", f.Name())
+}
+
+// paneCommand returns the arguments tmux takes for the command of a pane:
+// none for a shell.
+func paneCommand(p DashPane) []string {
+	if p.Command == "" {
+		return nil
+	}
+	return []string{p.Command}
+}
+
+// screenQuote quotes s as one argument in a screenrc.
+func screenQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// attach runs a terminal multiplexer in the foreground until it detaches
+// or exits.
+func attach(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+const (
+	dataDirEnvVar = "TASK_DIR" // Overrides where the data files are kept.
+	lockFile      = ".lock"    // Locked while a command uses the data files.
+)
+
+// unlockedCommands lists the commands that do not hold the data lock while
+// they run: long-running ones lock it only while reading and writing, so
+// other commands are not kept waiting, completion only reads, and the
+// dashboard's panes and the commands watch runs are commands of their own.
+var unlockedCommands = []string{"serve", "daemon", "pomo", "dash", "watch", "__complete"}
+
+// useDataDir changes to the directory holding the data files: $TASK_DIR if
+// set, otherwise the platform's default (see defaultDataDir), created if
+// needed. Without either the data files are in the current directory.
+func useDataDir() error {
+	dir := os.Getenv(dataDirEnvVar)
+	if dir == "" {
+		dir = defaultDataDir()
+	}
+	if dir == "" {
+		return nil
+	}
+	// An absolute path lets Windows paths exceed 260 characters.
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("invalid data directory: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creating data directory: %w", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		return fmt.Errorf("error changing to data directory: %w", err)
+	}
+	return nil
+}
+
+// dataLocked is set while this process holds the data lock.
+var dataLocked bool
+
+// lockData locks the data files against other task commands, waiting for
+// any that has them, and returns the function that unlocks them. Locking
+// them again while they are locked, as pomo does in the shell, does nothing.
+func lockData() (func(), error) {
+	if dataLocked {
+		return func() {}, nil
+	}
+	f, err := os.OpenFile(lockFile, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening lock file: %w", err)
+	}
+	err = lockFileExclusive(f, false)
+	if errors.Is(err, errLocked) {
+		fmt.Fprintln(stdout, "Waiting for another task command to finish...")
+		err = lockFileExclusive(f, true)
+	}
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("error locking data files: %w", err)
+	}
+	dataLocked = true
+	return func() {
+		unlockFile(f)
+		f.Close()
+		dataLocked = false
+	}, nil
+}
+
+// holdsDataLock reports whether a command locks the data files for as long
+// as it runs.
+func holdsDataLock(name string) bool {
+	return !slices.Contains(unlockedCommands, name)
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// defaultDataDir returns ~/.local/share/task, so the data files are the
+// same whichever directory Termux starts in, unless the current directory
+// already holds data files from before.
+func defaultDataDir() string {
+	if _, err := os.Stat(tasksFile); err == nil {
+		return ""
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".local", "share", "task")
+}
@@ -0,0 +1,8 @@
+//go:build !windows && !android
+
+package main
+
+// defaultDataDir returns "": data files are in the current directory.
+func defaultDataDir() string {
+	return ""
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// defaultDataDir returns %APPDATA%\task, where Windows programs keep their
+// data, unless the current directory already holds data files from before.
+func defaultDataDir() string {
+	if _, err := os.Stat(tasksFile); err == nil {
+		return ""
+	}
+	appData := os.Getenv("APPDATA")
+	if appData == "" {
+		return ""
+	}
+	return filepath.Join(appData, "task")
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// numericDatePattern matches a day, month and year written with dots,
+// slashes or dashes, such as "24.12.2025" or "12/24/2025".
+var numericDatePattern = regexp.MustCompile(`^(\d{1,2})[./-](\d{1,2})[./-](\d{4})\b`)
+
+// dateLocale holds the words of a language that due dates can be written
+// in, mapped to the English words the parser understands.
+type dateLocale struct {
+	words      map[string]string
+	fillers    []string // Articles and prepositions English phrases do without.
+	monthFirst bool     // Numeric dates are month/day/year rather than day/month/year.
+}
+
+// dateLocales are the languages dates can be written in besides English,
+// which is always understood. Abbreviations are left out where they would
+// clash with English words or other languages.
+var dateLocales = map[string]dateLocale{
+	"en": {monthFirst: true},
+	"de": {fillers: []string{"am", "den", "der"}, words: map[string]string{
+		"heute": "today", "morgen": "tomorrow", "gestern": "yesterday", "jetzt": "now",
+		"nächste": "next", "nächsten": "next", "nächster": "next", "kommenden": "next", "kommende": "next",
+		"diese": "this", "diesen": "this", "dieser": "this", "letzte": "last", "letzten": "last", "letzter": "last",
+		"um": "at", "mittag": "noon", "mitternacht": "midnight",
+		"minuten": "minute", "stunde": "hour", "stunden": "hour",
+		"tag": "day", "tagen": "day", "tage": "day", "woche": "week", "wochen": "week",
+		"monat": "month", "monaten": "month", "monate": "month", "jahr": "year", "jahren": "year", "jahre": "year",
+		"montag": "monday", "dienstag": "tuesday", "mittwoch": "wednesday", "donnerstag": "thursday",
+		"freitag": "friday", "samstag": "saturday", "sonnabend": "saturday", "sonntag": "sunday",
+		"januar": "january", "jänner": "january", "februar": "february", "märz": "march", "maerz": "march",
+		"mai": "may", "juni": "june", "juli": "july", "oktober": "october", "dezember": "december",
+	}},
+	"fr": {fillers: []string{"le", "la", "de"}, words: map[string]string{
+		"aujourd'hui": "today", "demain": "tomorrow", "hier": "yesterday", "maintenant": "now",
+		"prochain": "next", "prochaine": "next", "ce": "this", "cette": "this", "dernier": "last", "dernière": "last",
+		"dans": "in", "à": "at", "midi": "noon", "minuit": "midnight",
+		"heure": "hour", "heures": "hour", "jour": "day", "jours": "day", "semaine": "week", "semaines": "week",
+		"mois": "month", "ans": "year", "année": "year", "années": "year",
+		"lundi": "monday", "mardi": "tuesday", "mercredi": "wednesday", "jeudi": "thursday",
+		"vendredi": "friday", "samedi": "saturday", "dimanche": "sunday",
+		"janvier": "january", "février": "february", "fevrier": "february", "mars": "march", "avril": "april",
+		"juin": "june", "juillet": "july", "août": "august", "aout": "august", "septembre": "september",
+		"octobre": "october", "novembre": "november", "décembre": "december", "decembre": "december",
+	}},
+	"es": {fillers: []string{"el", "la", "de", "del"}, words: map[string]string{
+		"hoy": "today", "mañana": "tomorrow", "manana": "tomorrow", "ayer": "yesterday", "ahora": "now",
+		"próximo": "next", "proximo": "next", "próxima": "next", "proxima": "next", "este": "this", "esta": "this",
+		"pasado": "last", "pasada": "last", "en": "in", "mediodía": "noon", "medianoche": "midnight",
+		"minuto": "minute", "minutos": "minute", "hora": "hour", "horas": "hour", "día": "day", "días": "day",
+		"dia": "day", "dias": "day", "semana": "week", "semanas": "week", "mes": "month", "meses": "month",
+		"año": "year", "años": "year",
+		"lunes": "monday", "martes": "tuesday", "miércoles": "wednesday", "miercoles": "wednesday", "jueves": "thursday",
+		"viernes": "friday", "sábado": "saturday", "sabado": "saturday", "domingo": "sunday",
+		"enero": "january", "febrero": "february", "marzo": "march", "abril": "april", "mayo": "may",
+		"junio": "june", "julio": "july", "agosto": "august", "septiembre": "september", "setiembre": "september",
+		"octubre": "october", "noviembre": "november", "diciembre": "december",
+	}},
+	"it": {fillers: []string{"il", "la", "di", "del"}, words: map[string]string{
+		"oggi": "today", "domani": "tomorrow", "ieri": "yesterday", "adesso": "now",
+		"prossimo": "next", "prossima": "next", "questo": "this", "questa": "this", "scorso": "last", "scorsa": "last",
+		"tra": "in", "fra": "in", "alle": "at", "mezzogiorno": "noon", "mezzanotte": "midnight",
+		"minuto": "minute", "minuti": "minute", "ore": "hour", "giorno": "day", "giorni": "day",
+		"settimana": "week", "settimane": "week", "mese": "month", "mesi": "month", "anno": "year", "anni": "year",
+		"lunedì": "monday", "lunedi": "monday", "martedì": "tuesday", "martedi": "tuesday", "mercoledì": "wednesday",
+		"mercoledi": "wednesday", "giovedì": "thursday", "giovedi": "thursday", "venerdì": "friday", "venerdi": "friday",
+		"sabato": "saturday", "domenica": "sunday",
+		"gennaio": "january", "febbraio": "february", "marzo": "march", "aprile": "april", "maggio": "may",
+		"giugno": "june", "luglio": "july", "settembre": "september", "ottobre": "october",
+		"novembre": "november", "dicembre": "december",
+	}},
+	"nl": {fillers: []string{"de", "het"}, words: map[string]string{
+		"vandaag": "today", "morgen": "tomorrow", "gisteren": "yesterday", "nu": "now",
+		"volgende": "next", "komende": "next", "deze": "this", "vorige": "last", "afgelopen": "last",
+		"over": "in", "om": "at", "middag": "noon", "middernacht": "midnight",
+		"minuut": "minute", "minuten": "minute", "uur": "hour", "dag": "day", "dagen": "day",
+		"weken": "week", "maand": "month", "maanden": "month", "jaar": "year", "jaren": "year",
+		"maandag": "monday", "dinsdag": "tuesday", "woensdag": "wednesday", "donderdag": "thursday",
+		"vrijdag": "friday", "zaterdag": "saturday", "zondag": "sunday",
+		"januari": "january", "februari": "february", "maart": "march", "mei": "may", "juni": "june",
+		"juli": "july", "augustus": "august", "oktober": "october",
+	}},
+	"pt": {fillers: []string{"de", "do", "da", "o"}, words: map[string]string{
+		"hoje": "today", "amanhã": "tomorrow", "amanha": "tomorrow", "ontem": "yesterday", "agora": "now",
+		"próximo": "next", "proximo": "next", "próxima": "next", "proxima": "next", "este": "this", "esta": "this",
+		"passado": "last", "passada": "last", "em": "in", "daqui": "in", "às": "at", "meio-dia": "noon", "meia-noite": "midnight",
+		"minuto": "minute", "minutos": "minute", "hora": "hour", "horas": "hour", "dia": "day", "dias": "day",
+		"semana": "week", "semanas": "week", "mês": "month", "mes": "month", "meses": "month",
+		"ano": "year", "anos": "year",
+		"segunda": "monday", "segunda-feira": "monday", "terça": "tuesday", "terça-feira": "tuesday",
+		"quarta": "wednesday", "quarta-feira": "wednesday", "quinta": "thursday", "quinta-feira": "thursday",
+		"sexta": "friday", "sexta-feira": "friday", "sábado": "saturday", "sabado": "saturday", "domingo": "sunday",
+		"janeiro": "january", "fevereiro": "february", "março": "march", "marco": "march", "abril": "april",
+		"maio": "may", "junho": "june", "julho": "july", "agosto": "august", "setembro": "september",
+		"outubro": "october", "novembro": "november", "dezembro": "december",
+	}},
+}
+
+// localeLanguage returns the language of a locale such as "de_DE.UTF-8",
+// "fr-CA" or "es".
+func localeLanguage(locale string) string {
+	lang, _, _ := strings.Cut(strings.ToLower(locale), ".")
+	lang, _, _ = strings.Cut(lang, "@")
+	if i := strings.IndexAny(lang, "_-"); i >= 0 {
+		return lang[:i]
+	}
+	return lang
+}
+
+// currentDateLocale returns the locale dates are read in: the one set in
+// config.json, otherwise the first of LC_ALL, LC_TIME and LANG that names a
+// known language, otherwise English. British and most other English
+// locales write numeric dates day first, like other languages.
+func currentDateLocale() dateLocale {
+	locale := envLocale("LC_TIME")
+	l, ok := dateLocales[localeLanguage(locale)]
+	if !ok {
+		return dateLocales["en"]
+	}
+	if region, _, _ := strings.Cut(strings.ToLower(locale), "."); localeLanguage(locale) == "en" {
+		l.monthFirst = region == "en" || region == "en_us" || region == "en-us"
+	}
+	return l
+}
+
+// localizeDate rewrites a lowercase date written in a locale into the
+// English the parser understands: numeric dates become YYYY-MM-DD and known
+// words are translated, so "freitag" reads as "friday" and "3. märz" as
+// "3 march". English words are left as they are.
+func localizeDate(text string, l dateLocale) string {
+	if m := numericDatePattern.FindStringSubmatch(text); m != nil {
+		day, month := m[1], m[2]
+		if l.monthFirst {
+			day, month = month, day
+		}
+		text = fmt.Sprintf("%s-%02s-%02s", m[3], month, day) + text[len(m[0]):]
+	}
+
+	words := strings.Fields(text)
+	for i, word := range words {
+		if _, ok := weekdays[word]; ok {
+			continue
+		}
+		if _, ok := months[word]; ok {
+			continue
+		}
+		if english, ok := l.words[strings.TrimSuffix(word, ",")]; ok {
+			words[i] = english
+		} else if n := strings.TrimSuffix(word, "."); n != word && n != "" && strings.Trim(n, "0123456789") == "" {
+			words[i] = n // "3." is how German writes the third
+		}
+	}
+	words = slices.DeleteFunc(words, func(w string) bool { return slices.Contains(l.fillers, w) })
+	// "vendredi prochain" and "semaine prochaine" put the adjective last
+	if n := len(words); n >= 2 && slices.Contains([]string{"next", "this", "last"}, words[n-1]) {
+		words = append([]string{words[n-1]}, words[:n-1]...)
+	}
+	return strings.Join(words, " ")
+}
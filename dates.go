@@ -0,0 +1,398 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// errUnknownDate is returned for phrases the parser does not recognise.
+var errUnknownDate = errors.New("unknown date")
+
+// Limits on the dates parsed: the years a date can fall in, and the
+// largest count of a relative date such as "in 3 days".
+const (
+	minDateYear   = 1
+	maxDateYear   = 9999
+	maxDateOffset = 100000
+)
+
+// dateHint is appended to date parsing errors to show what is accepted.
+const dateHint = `try e.g. "tomorrow 5pm", "next monday", "in 3 days", "end of month" or "2025-03-01"`
+
+var (
+	isoDateTimePattern = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})(?:[t ](\d{1,2}:\d{2}))?$`)
+	clockPattern       = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)?$`)
+	relativePattern    = regexp.MustCompile(`^(?:in )?(\d+|a|an) (minute|hour|day|week|month|year)s?( ago)?$`)
+	monthDayPattern    = regexp.MustCompile(`^(?:([a-z]+) (\d{1,2})|(\d{1,2}) ([a-z]+))(?: (\d{4}))?$`)
+	agePattern         = regexp.MustCompile(`^(\d+)([dw])$`)
+)
+
+// weekdays maps weekday names and abbreviations to time.Weekday.
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+// months maps month names and abbreviations to time.Month.
+var months = map[string]time.Month{
+	"january": time.January, "jan": time.January,
+	"february": time.February, "feb": time.February,
+	"march": time.March, "mar": time.March,
+	"april": time.April, "apr": time.April,
+	"may":  time.May,
+	"june": time.June, "jun": time.June,
+	"july": time.July, "jul": time.July,
+	"august": time.August, "aug": time.August,
+	"september": time.September, "sep": time.September, "sept": time.September,
+	"october": time.October, "oct": time.October,
+	"november": time.November, "nov": time.November,
+	"december": time.December, "dec": time.December,
+}
+
+// parseDate interprets a date written either as YYYY-MM-DD [HH:MM] or in
+// natural language relative to now, such as "tomorrow 5pm", "next monday",
+// "in 3 days", "2 weeks ago", "end of month" or "march 3". Dates in the
+// user's language, such as "freitag" or "3 mars", are understood too. Dates
+// without a time of day are returned at midnight.
+func parseDate(input string, now time.Time) (time.Time, error) {
+	text := localizeDate(strings.ToLower(input), currentDateLocale())
+	if text == "" {
+		return time.Time{}, fmt.Errorf("empty date; %s", dateHint)
+	}
+
+	if m := isoDateTimePattern.FindStringSubmatch(text); m != nil {
+		layout, value := dateLayout, m[1]
+		if m[2] != "" {
+			layout, value = dateLayout+" 15:04", m[1]+" "+fmt.Sprintf("%05s", m[2])
+		}
+		t, err := time.ParseInLocation(layout, value, now.Location())
+		if err != nil || t.Year() < minDateYear {
+			return time.Time{}, fmt.Errorf("invalid date '%s'; %s", input, dateHint)
+		}
+		return t, nil
+	}
+
+	// Split off a trailing time of day ("5pm", "at 17:30", "noon").
+	words := strings.Fields(text)
+	hour, minute, hasClock := 0, 0, false
+	if n := len(words); n > 0 {
+		if h, m, ok := parseClock(words[n-1]); ok {
+			hour, minute, hasClock = h, m, true
+			words = words[:n-1]
+			if n := len(words); n > 0 && words[n-1] == "at" {
+				words = words[:n-1]
+			}
+		}
+	}
+	phrase := strings.Join(words, " ")
+
+	day, exact, err := parseDayPhrase(phrase, now)
+	if errors.Is(err, errUnknownDate) {
+		return time.Time{}, fmt.Errorf("cannot understand date '%s'; %s", input, dateHint)
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date '%s': %w", input, err)
+	}
+	if day.Year() < minDateYear || day.Year() > maxDateYear {
+		return time.Time{}, fmt.Errorf("invalid date '%s': it falls outside the years %d-%d", input, minDateYear, maxDateYear)
+	}
+	if exact {
+		if hasClock {
+			return time.Time{}, fmt.Errorf("cannot combine a time of day with '%s'; %s", phrase, dateHint)
+		}
+		return day.Truncate(time.Minute), nil
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, day.Location()), nil
+}
+
+// parseClock parses a time of day such as "5pm", "5:30pm", "17:00", "noon"
+// or "midnight".
+func parseClock(word string) (int, int, bool) {
+	switch word {
+	case "noon":
+		return 12, 0, true
+	case "midnight":
+		return 0, 0, true
+	}
+
+	m := clockPattern.FindStringSubmatch(word)
+	if m == nil || (m[2] == "" && m[3] == "") {
+		// A bare number is not a time; "3" in "in 3 days" must stay a count.
+		return 0, 0, false
+	}
+	hour, _ := strconv.Atoi(m[1])
+	minute := 0
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	switch m[3] {
+	case "am", "pm":
+		if hour < 1 || hour > 12 {
+			return 0, 0, false
+		}
+		hour %= 12
+		if m[3] == "pm" {
+			hour += 12
+		}
+	}
+	if hour > 23 || minute > 59 {
+		return 0, 0, false
+	}
+	return hour, minute, true
+}
+
+// parseDayPhrase resolves the date part of a natural language expression.
+// It reports exact when the phrase already names a precise instant (such as
+// "in 2 hours") rather than a calendar day.
+func parseDayPhrase(phrase string, now time.Time) (time.Time, bool, error) {
+	today := startOfDay(now)
+
+	switch phrase {
+	case "", "today", "tonight":
+		return today, false, nil
+	case "tomorrow", "tmr", "tmrw":
+		return today.AddDate(0, 0, 1), false, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), false, nil
+	case "now":
+		return now, true, nil
+	case "next week":
+		return startOfWeek(now).AddDate(0, 0, 7), false, nil
+	case "next month":
+		return time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, now.Location()), false, nil
+	case "next year":
+		return time.Date(now.Year()+1, time.January, 1, 0, 0, 0, 0, now.Location()), false, nil
+	case "end of week", "eow":
+		return startOfWeek(now).AddDate(0, 0, 6), false, nil
+	case "end of month", "eom":
+		return time.Date(now.Year(), now.Month()+1, 0, 0, 0, 0, 0, now.Location()), false, nil
+	case "end of year", "eoy":
+		return time.Date(now.Year(), time.December, 31, 0, 0, 0, 0, now.Location()), false, nil
+	case "start of week", "beginning of week":
+		return startOfWeek(now), false, nil
+	case "start of month", "beginning of month":
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()), false, nil
+	}
+
+	// Weekdays: "friday" and "next friday" mean the coming Friday after
+	// today; "this friday" is Friday of the current week.
+	words := strings.Fields(phrase)
+	if wd, ok := weekdays[words[len(words)-1]]; ok && len(words) <= 2 {
+		switch {
+		case len(words) == 1 || words[0] == "next":
+			offset := (int(wd) - int(today.Weekday()) + 7) % 7
+			if offset == 0 {
+				offset = 7
+			}
+			return today.AddDate(0, 0, offset), false, nil
+		case words[0] == "this":
+			return startOfWeek(now).AddDate(0, 0, (int(wd)+6)%7), false, nil
+		case words[0] == "last":
+			offset := (int(today.Weekday()) - int(wd) + 7) % 7
+			if offset == 0 {
+				offset = 7
+			}
+			return today.AddDate(0, 0, -offset), false, nil
+		}
+	}
+
+	if m := relativePattern.FindStringSubmatch(phrase); m != nil {
+		n := 1
+		if m[1] != "a" && m[1] != "an" {
+			var err error
+			if n, err = strconv.Atoi(m[1]); err != nil || n > maxDateOffset {
+				return time.Time{}, false, fmt.Errorf("%s %ss is too far off; at most %d are allowed", m[1], m[2], maxDateOffset)
+			}
+		}
+		if m[3] != "" {
+			n = -n
+		}
+		switch m[2] {
+		case "minute":
+			return now.Add(time.Duration(n) * time.Minute), true, nil
+		case "hour":
+			return now.Add(time.Duration(n) * time.Hour), true, nil
+		case "day":
+			return today.AddDate(0, 0, n), false, nil
+		case "week":
+			return today.AddDate(0, 0, 7*n), false, nil
+		case "month":
+			return today.AddDate(0, n, 0), false, nil
+		case "year":
+			return today.AddDate(n, 0, 0), false, nil
+		}
+	}
+
+	if m := monthDayPattern.FindStringSubmatch(phrase); m != nil {
+		name, dayStr := m[1], m[2]
+		if name == "" {
+			name, dayStr = m[4], m[3]
+		}
+		month, ok := months[name]
+		if !ok {
+			return time.Time{}, false, fmt.Errorf("unknown month '%s'", name)
+		}
+		day, _ := strconv.Atoi(dayStr)
+		if m[5] != "" {
+			year, _ := strconv.Atoi(m[5])
+			date := time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+			if date.Day() != day {
+				return time.Time{}, false, fmt.Errorf("invalid day %d", day)
+			}
+			return date, false, nil
+		}
+		if day < 1 || day > 31 || time.Date(2000, month, day, 0, 0, 0, 0, time.UTC).Day() != day {
+			return time.Time{}, false, fmt.Errorf("invalid day %d", day)
+		}
+		// Unlike a birthday, a date asked for is not moved to February 28.
+		date := nextAnnual(month, day, now)
+		if date.Day() != day {
+			return time.Time{}, false, fmt.Errorf("%s %d does not fall in %d; give the year", month, day, date.Year())
+		}
+		return date, false, nil
+	}
+
+	return time.Time{}, false, errUnknownDate
+}
+
+// parseAge parses a length of time such as "30d", "2w" or "12h": days and
+// weeks, or any Go duration.
+func parseAge(s string) (time.Duration, error) {
+	if m := agePattern.FindStringSubmatch(strings.ToLower(s)); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		if m[2] == "w" {
+			n *= 7
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age '%s'; use e.g. 30d, 2w or 12h", s)
+	}
+	return d, nil
+}
+
+// formatDue renders a due date, including the time of day when one is set.
+func formatDue(t time.Time) string {
+	if t.Hour() == 0 && t.Minute() == 0 {
+		return formatDate(t)
+	}
+	return formatDate(t) + t.Format(" 15:04")
+}
+
+// DateRange is an optional inclusive window used by --since/--until filters.
+type DateRange struct {
+	Since *time.Time
+	Until *time.Time
+}
+
+// parseDateRange parses --since and --until values; either may be empty.
+// An until date without a time of day includes that whole day.
+func parseDateRange(since, until string, now time.Time) (DateRange, error) {
+	return parseFlagRange("since", since, "until", until, now)
+}
+
+// parseFlagRange parses a pair of flags such as --since and --until, named
+// sinceFlag and untilFlag in errors, like parseDateRange.
+func parseFlagRange(sinceFlag, since, untilFlag, until string, now time.Time) (DateRange, error) {
+	var r DateRange
+	if since != "" {
+		t, err := parseDate(since, now)
+		if err != nil {
+			return r, fmt.Errorf("--%s: %w", sinceFlag, err)
+		}
+		r.Since = &t
+	}
+	if until != "" {
+		t, err := parseDate(until, now)
+		if err != nil {
+			return r, fmt.Errorf("--%s: %w", untilFlag, err)
+		}
+		if r.Since != nil && t.Before(*r.Since) {
+			return r, fmt.Errorf("--%s '%s' is before --%s '%s'", untilFlag, until, sinceFlag, since)
+		}
+		if t.Equal(startOfDay(t)) {
+			t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		r.Until = &t
+	}
+	return r, nil
+}
+
+// relativeTime describes t relative to now, such as "2 hours ago" or
+// "in 3 days", in the language of messages.
+func relativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	future := d < 0
+	if future {
+		d = -d
+	}
+	if d < time.Minute {
+		return msg("just now")
+	}
+	amount := relativeAmount(d)
+	if future {
+		return fmt.Sprintf(msg("in %s"), amount)
+	}
+	return fmt.Sprintf(msg("%s ago"), amount)
+}
+
+// relativeAmount renders a duration of at least a minute in its largest
+// whole unit, such as "3 days".
+func relativeAmount(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return localPlural(int(d.Minutes()), "minute")
+	case d < 24*time.Hour:
+		return localPlural(int(d.Hours()), "hour")
+	case d < 30*24*time.Hour:
+		return localPlural(int(d.Hours()/24), "day")
+	case d < 365*24*time.Hour:
+		return localPlural(int(d.Hours()/24/30), "month")
+	}
+	return localPlural(int(d.Hours()/24/365), "year")
+}
+
+// relativeDue describes a due date relative to now, counting calendar days:
+// "today", "tomorrow at 17:00", "in 3 days" or "overdue by 2 days", in the
+// language of messages.
+func relativeDue(due, now time.Time) string {
+	timed := !due.Equal(startOfDay(due))
+	if timed && due.Before(now) {
+		return fmt.Sprintf(msg("overdue by %s"), relativeAmount(max(now.Sub(due), time.Minute)))
+	}
+
+	days := int(startOfDay(due).Sub(startOfDay(now)).Round(24*time.Hour).Hours() / 24)
+	label := ""
+	switch {
+	case days < 0:
+		return fmt.Sprintf(msg("overdue by %s"), localPlural(-days, "day"))
+	case days == 0:
+		label = msg("today")
+	case days == 1:
+		label = msg("tomorrow")
+	default:
+		return fmt.Sprintf(msg("in %s"), localPlural(days, "day"))
+	}
+	if timed {
+		label = fmt.Sprintf(msg("%s at %s"), label, due.Format("15:04"))
+	}
+	return label
+}
+
+// plural formats n with unit, adding an "s" unless n is one.
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
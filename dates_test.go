@@ -0,0 +1,22 @@
+package main
+
+import "testing"
+
+func TestParseDateRejectsInvalidDates(t *testing.T) {
+	setupCLI(t)
+	for _, s := range []string{"feb 29", "feb 30", "feb 29 2026", "2026-02-29", "0000-01-01", "in 99999999999 days", "in 99999 years", "99999999999 minutes ago"} {
+		if got, err := parseDate(s, testNow); err == nil {
+			t.Errorf("parseDate(%q) = %v, want an error", s, got)
+		}
+	}
+	for s, want := range map[string]string{
+		"feb 29 2028":   "2028-02-29",
+		"march 3":       "2026-03-03",
+		"in 1000 days":  "2027-12-05",
+		"in 7974 years": "9999-03-10",
+	} {
+		if got, err := parseDate(s, testNow); err != nil || got.Format(dateLayout) != want {
+			t.Errorf("parseDate(%q) = %v, %v; want %s", s, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,387 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+const (
+	debtsFile         = "debts.json" // The name of the saved debts file.
+	debtCategory      = "debt"       // Category of the recurring payments a plan creates.
+	debtMinShare      = 0.02         // Share of the balance that is the minimum payment unless --min is given.
+	maxDebtMonths     = 600          // Months a plan may take before it is taken never to pay off.
+	strategyAvalanche = "avalanche"
+	strategySnowball  = "snowball"
+	debtDateLayout    = "Jan 2006"
+)
+
+// Debt is money owed, such as a credit card balance or a loan, paid off
+// monthly. Amounts are in the base currency.
+type Debt struct {
+	ID         int       `json:"id"`
+	Name       string    `json:"name"`
+	Balance    float64   `json:"balance"`
+	APR        float64   `json:"apr"`        // Yearly interest rate in percent.
+	MinPayment float64   `json:"minPayment"` // Paid every month while any is owed.
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// debtCommand returns the expense debt command group.
+func debtCommand() *command {
+	return &command{
+		name: "debt", summary: "Track debts and plan paying them off",
+		subcommands: []*command{
+			{
+				name: "add", args: "<name> <balance>", summary: "Add a debt, or update the one with that name", minArgs: 2,
+				setup: func(fs *flag.FlagSet) runFunc {
+					apr := fs.Float64("apr", 0, "yearly interest rate in `percent`")
+					minPayment := fs.Float64("min", 0, fmt.Sprintf("minimum monthly `payment` (default %g%% of the balance)", debtMinShare*100))
+					return func(args []string) error {
+						n := len(args)
+						balance, err := strconv.ParseFloat(args[n-1], 64)
+						if err != nil || balance <= 0 {
+							return usagef("invalid balance '%s'", args[n-1])
+						}
+						if *apr < 0 || *minPayment < 0 {
+							return usagef("--apr and --min must not be negative")
+						}
+						d := Debt{Name: strings.Join(args[:n-1], " "), Balance: balance, APR: *apr, MinPayment: *minPayment}
+						if d.MinPayment == 0 {
+							d.MinPayment = math.Ceil(balance*debtMinShare*100) / 100
+						}
+						return addDebt(d)
+					}
+				},
+			},
+			{
+				name: "list", summary: "List debts with their interest and minimum payments",
+				setup: run(func([]string) error { return listDebts() }),
+			},
+			{
+				name: "delete", args: "<id>", summary: "Delete a paid off debt, stopping its planned payments", minArgs: 1,
+				setup: run(func(args []string) error {
+					id, err := parseID(args[0], "debt")
+					if err != nil {
+						return err
+					}
+					return deleteDebt(id)
+				}),
+			},
+			{
+				name: "plan", summary: "Plan paying off the debts with a monthly amount, and schedule the payments",
+				setup: func(fs *flag.FlagSet) runFunc {
+					monthly := fs.Float64("monthly", 0, "`amount` paid towards the debts each month")
+					strategy := fs.String("strategy", strategyAvalanche, "which debt extra money goes to first: avalanche (highest interest) or snowball (smallest balance)")
+					start := fs.String("start", "", "`date` of the first payment (default the first of next month)")
+					return func([]string) error {
+						if *monthly <= 0 {
+							return usagef("expected a --monthly amount")
+						}
+						if *strategy != strategyAvalanche && *strategy != strategySnowball {
+							return usagef("invalid strategy '%s'; use avalanche or snowball", *strategy)
+						}
+						now := clock()
+						first := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.Local)
+						if *start != "" {
+							date, err := parseDate(*start, now)
+							if err != nil {
+								return usagef("%v", err)
+							}
+							first = startOfDay(date)
+						}
+						return planDebts(*monthly, *strategy, first)
+					}
+				},
+			},
+		},
+	}
+}
+
+// loadDebts reads the debts.
+func loadDebts() ([]Debt, error) {
+	raw, err := loadDocument(debtsFile)
+	if err != nil || raw == nil {
+		return []Debt{}, err
+	}
+
+	var debts []Debt
+	if err := json.Unmarshal(raw, &debts); err != nil {
+		return nil, fmt.Errorf("error unmarshalling JSON: %w", err)
+	}
+	return debts, nil
+}
+
+// addDebt saves a new debt, or the new balance, rate and minimum payment
+// of the debt with the same name.
+func addDebt(d Debt) error {
+	debts, err := loadDebts()
+	if err != nil {
+		return err
+	}
+	d.UpdatedAt = clock()
+	if i := slices.IndexFunc(debts, func(e Debt) bool { return strings.EqualFold(e.Name, d.Name) }); i >= 0 {
+		d.ID = debts[i].ID
+		debts[i] = d
+		if err := saveDocument(debtsFile, debts); err != nil {
+			return err
+		}
+		printDone("Debt ID %d updated successfully\n", d.ID)
+		return nil
+	}
+	for _, existing := range debts {
+		d.ID = max(d.ID, existing.ID)
+	}
+	d.ID++
+	if err := saveDocument(debtsFile, append(debts, d)); err != nil {
+		return err
+	}
+
+	printDone("Debt added successfully (ID: %d)\n", d.ID)
+	return nil
+}
+
+// listDebts prints the debts, highest interest first, with their total.
+func listDebts() error {
+	debts, err := loadDebts()
+	if err != nil {
+		return err
+	}
+	if len(debts) == 0 {
+		fmt.Fprintln(stdout, "No debts.")
+		return nil
+	}
+
+	slices.SortStableFunc(debts, func(a, b Debt) int { return cmp.Compare(b.APR, a.APR) })
+	total, minimum := 0.0, 0.0
+	fmt.Fprintln(stdout, "--- Debts ---")
+	for _, d := range debts {
+		fmt.Fprintf(stdout, "[ID: %d] %-20s %s at %.2f%% APR, at least %s a month (as of %s)\n", d.ID, d.Name,
+			formatAmount(d.Balance, ""), d.APR, formatAmount(d.MinPayment, ""), d.UpdatedAt.Format(dateLayout))
+		total += d.Balance
+		minimum += d.MinPayment
+	}
+	fmt.Fprintf(stdout, "--- Total: %s, at least %s a month ---\n", formatAmount(total, ""), formatAmount(minimum, ""))
+	return nil
+}
+
+// deleteDebt deletes a debt and the recurring payments planned for it.
+func deleteDebt(id int) error {
+	debts, err := loadDebts()
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(debts, func(d Debt) bool { return d.ID == id })
+	if i < 0 {
+		return fmt.Errorf("debt with ID %d %w", id, tracker.ErrNotFound)
+	}
+	if err := saveDocument(debtsFile, slices.Delete(debts, i, i+1)); err != nil {
+		return err
+	}
+	if _, err := replaceDebtPayments(func(r RecurringExpense) bool { return r.Debt == id }, nil); err != nil {
+		return err
+	}
+
+	printDone("Debt ID %d deleted successfully\n", id)
+	return nil
+}
+
+// debtRun is a stretch of equal monthly payments to one debt, which becomes
+// a recurring expense.
+type debtRun struct {
+	debt        Debt
+	amount      float64
+	first, last time.Time
+}
+
+// debtPayoff is how and when a plan pays off one debt.
+type debtPayoff struct {
+	debt     Debt
+	paidOff  time.Time
+	months   int
+	interest float64
+}
+
+// simulateDebts pays monthly towards debts each month from first until
+// they are paid off: interest is added, every debt gets its minimum
+// payment, and what is left goes to the first debt in the strategy's order,
+// then the next once it is paid off. It returns the runs of equal payments
+// and when each debt is paid off.
+func simulateDebts(debts []Debt, monthly float64, strategy string, first time.Time) ([]debtRun, []debtPayoff, error) {
+	minimum := 0.0
+	for _, d := range debts {
+		minimum += d.MinPayment
+	}
+	if monthly < minimum {
+		return nil, nil, usagef("%s a month does not cover the minimum payments of %s", formatAmount(monthly, ""), formatAmount(minimum, ""))
+	}
+
+	order := slices.Clone(debts)
+	slices.SortStableFunc(order, func(a, b Debt) int {
+		if strategy == strategySnowball {
+			return cmp.Or(cmp.Compare(a.Balance, b.Balance), cmp.Compare(b.APR, a.APR))
+		}
+		return cmp.Or(cmp.Compare(b.APR, a.APR), cmp.Compare(a.Balance, b.Balance))
+	})
+	balances := make([]float64, len(order))
+	payoffs := make([]debtPayoff, len(order))
+	for i, d := range order {
+		balances[i] = d.Balance
+		payoffs[i].debt = d
+	}
+	var runs []debtRun
+	current := make([]int, len(order)) // Index in runs of each debt's latest run.
+	for i := range current {
+		current[i] = -1
+	}
+
+	month := first
+	for n := 1; ; n++ {
+		if n > maxDebtMonths {
+			return nil, nil, fmt.Errorf("%s a month does not pay off the debts within %d years", formatAmount(monthly, ""), maxDebtMonths/12)
+		}
+		payments := make([]float64, len(order))
+		left := monthly
+		for i, d := range order {
+			if balances[i] <= 0 {
+				continue
+			}
+			interest := roundCents(balances[i] * d.APR / 100 / 12)
+			balances[i] += interest
+			payoffs[i].interest += interest
+			payments[i] = min(d.MinPayment, balances[i])
+			left -= payments[i]
+		}
+		for i := range order {
+			extra := min(left, balances[i]-payments[i])
+			if extra > 0 {
+				payments[i] += extra
+				left -= extra
+			}
+		}
+
+		open := 0
+		for i, d := range order {
+			if payments[i] == 0 {
+				continue
+			}
+			payments[i] = roundCents(payments[i])
+			balances[i] = roundCents(balances[i] - payments[i])
+			if j := current[i]; j >= 0 && runs[j].amount == payments[i] {
+				runs[j].last = month
+			} else {
+				runs = append(runs, debtRun{debt: d, amount: payments[i], first: month, last: month})
+				current[i] = len(runs) - 1
+			}
+			if balances[i] <= 0 {
+				payoffs[i].paidOff, payoffs[i].months = month, n
+			} else {
+				open++
+			}
+		}
+		if open == 0 {
+			return runs, payoffs, nil
+		}
+		month = month.AddDate(0, 1, 0)
+	}
+}
+
+// planDebts prints a plan paying monthly towards the debts, and replaces
+// the recurring payments planned before with its own, one for each run of
+// equal payments, so they are recorded as expenses when they fall due.
+func planDebts(monthly float64, strategy string, first time.Time) error {
+	debts, err := loadDebts()
+	if err != nil {
+		return err
+	}
+	if len(debts) == 0 {
+		fmt.Fprintln(stdout, "No debts.")
+		return nil
+	}
+	runs, payoffs, err := simulateDebts(debts, monthly, strategy, first)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "--- Payoff Plan (%s, %s a month) ---\n", strategy, formatAmount(monthly, ""))
+	totalInterest, last := 0.0, first
+	for _, p := range payoffs {
+		fmt.Fprintf(stdout, "[ID: %d] %-20s %s at %.2f%%: paid off %s (%s), %s interest\n", p.debt.ID, p.debt.Name, formatAmount(p.debt.Balance, ""),
+			p.debt.APR, p.paidOff.Format(debtDateLayout), plural(p.months, "month"), formatAmount(p.interest, ""))
+		for _, r := range runs {
+			if r.debt.ID != p.debt.ID {
+				continue
+			}
+			if r.first.Equal(r.last) {
+				fmt.Fprintf(stdout, "  %s in %s\n", formatAmount(r.amount, ""), r.first.Format(debtDateLayout))
+			} else {
+				fmt.Fprintf(stdout, "  %s a month, %s to %s\n", formatAmount(r.amount, ""), r.first.Format(debtDateLayout), r.last.Format(debtDateLayout))
+			}
+		}
+		totalInterest += p.interest
+		if p.paidOff.After(last) {
+			last = p.paidOff
+		}
+	}
+	fmt.Fprintf(stdout, "--- Debt-free by %s, %s interest in all ---\n", last.Format(debtDateLayout), formatAmount(totalInterest, ""))
+
+	payments := make([]RecurringExpense, len(runs))
+	for i, r := range runs {
+		until := r.last
+		payments[i] = RecurringExpense{
+			Description: "Payment: " + r.debt.Name, Amount: r.amount,
+			Category: debtCategory, Payee: r.debt.Name, Recur: tracker.RecurMonthly,
+			Next: r.first, Until: &until, Debt: r.debt.ID,
+		}
+	}
+	replaced, err := replaceDebtPayments(func(r RecurringExpense) bool { return r.Debt != 0 }, payments)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Scheduled %s", plural(len(payments), "recurring payment"))
+	if replaced > 0 {
+		fmt.Fprintf(stdout, ", replacing %d planned before", replaced)
+	}
+	fmt.Fprintln(stdout, ".")
+	_, err = chargeRecurringExpenses(clock())
+	return err
+}
+
+// replaceDebtPayments deletes the recurring expenses matching old and adds
+// payments, returning how many it deleted.
+func replaceDebtPayments(old func(RecurringExpense) bool, payments []RecurringExpense) (int, error) {
+	recurring, err := loadRecurringExpenses()
+	if err != nil {
+		return 0, err
+	}
+	n := len(recurring)
+	recurring = slices.DeleteFunc(recurring, old)
+	replaced := n - len(recurring)
+	if replaced == 0 && len(payments) == 0 {
+		return 0, nil
+	}
+	id := 0
+	for _, r := range recurring {
+		id = max(id, r.ID)
+	}
+	now := clock()
+	for _, p := range payments {
+		id++
+		p.ID, p.CreatedAt = id, now
+		recurring = append(recurring, p)
+	}
+	return replaced, saveDocument(recurringFile, recurring)
+}
+
+// roundCents rounds an amount to cents.
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
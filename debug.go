@@ -0,0 +1,273 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	debugHistoryEntries = 50  // Most recent commands a debug bundle includes.
+	debugSampleRecords  = 20  // Most recent records of each kind a debug bundle includes.
+	debugLogLines       = 100 // Most recent lines of daemon.log a debug bundle includes.
+	strippedValue       = "[stripped]"
+)
+
+// secretKeyPattern matches the config keys whose values a debug bundle
+// leaves out.
+var secretKeyPattern = regexp.MustCompile(`(?i)token|password|passphrase|secret|api.?key|credential|auth`)
+
+// debugSettingKeys lists the config keys whose string values are settings,
+// such as "relative" or "15m", rather than the user's own text, and which a
+// debug bundle keeps as they are.
+var debugSettingKeys = []string{
+	"locale", "times", "columns", "timezone", "base", "source", "field", "layout", "split",
+	"backup", "sync", "report", "period", "every", "on", "at", "start", "end", "days",
+	"events", "dateFormat", "decimal", "delimiter", "fields",
+}
+
+// logTimePattern matches the time daemon.log lines start with.
+var logTimePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2} \d{2}:\d{2} `)
+
+// debugCommand returns the debug command group.
+func debugCommand() *command {
+	return &command{
+		name: "debug", summary: "Collect what a bug report needs", group: groupData,
+		subcommands: []*command{
+			{
+				name: "bundle", summary: "Write version info and a redacted config, recent commands, daemon log and data sample to a zip file",
+				setup: func(fs *flag.FlagSet) runFunc {
+					output := fs.String("output", "", "write the bundle to this `file` (default task-debug-<date>.zip)")
+					return func([]string) error {
+						path := *output
+						if path == "" {
+							path = fmt.Sprintf("task-debug-%s.zip", clock().Format("20060102-150405"))
+						}
+						return writeDebugBundle(path)
+					}
+				},
+			},
+		},
+	}
+}
+
+// writeDebugBundle writes a zip file to attach to a bug report. Nothing in
+// it identifies what the data is about: config values that look like
+// secrets are stripped, URLs are cut down to their host, and the other
+// config values but settings, commands, log lines and records are redacted
+// as 'task export --redact' does.
+func writeDebugBundle(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating file: %w", err)
+	}
+	zw := zip.NewWriter(f)
+	add := func(name string, data []byte) error {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: clock()})
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+
+	parts := []struct {
+		name    string
+		collect func() ([]byte, error)
+	}{
+		{"version.txt", debugVersion},
+		{configFile, debugConfig},
+		{"history.json", debugHistory},
+		{daemonLogFile, debugLog},
+		{"data.json", debugData},
+	}
+	for _, part := range parts {
+		data, err := part.collect()
+		if err != nil {
+			data = []byte(fmt.Sprintf("could not collect %s: %v\n", part.name, err))
+		}
+		if data == nil {
+			continue
+		}
+		if err := add(part.name, data); err != nil {
+			f.Close()
+			return fmt.Errorf("error writing file: %w", err)
+		}
+	}
+	err = zw.Close()
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("error writing file: %w", err)
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	fmt.Fprintf(stdout, "Debug bundle written to %s. Check what it holds before attaching it to an issue.\n", path)
+	return nil
+}
+
+// debugVersion describes the build, the platform and how the data is kept.
+func debugVersion() ([]byte, error) {
+	var b strings.Builder
+	if info, ok := debug.ReadBuildInfo(); ok {
+		fmt.Fprintf(&b, "module: %s %s\n", info.Main.Path, info.Main.Version)
+		for _, s := range info.Settings {
+			if strings.HasPrefix(s.Key, "vcs.") {
+				fmt.Fprintf(&b, "%s: %s\n", s.Key, s.Value)
+			}
+		}
+	}
+	fmt.Fprintf(&b, "go: %s\n", runtime.Version())
+	fmt.Fprintf(&b, "platform: %s/%s\n", runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&b, "data directory set: %t\n", os.Getenv(dataDirEnvVar) != "" || defaultDataDir() != "")
+	fmt.Fprintf(&b, "encrypted: %t\n", tr().Encrypted())
+	fmt.Fprintf(&b, "journal: %t\n", config.Storage.Journal)
+	fmt.Fprintf(&b, "generated: %s\n", clock().UTC().Format(time.RFC3339))
+	return []byte(b.String()), nil
+}
+
+// debugConfig returns config.json with its secrets stripped, or nil if
+// there is none.
+func debugConfig() ([]byte, error) {
+	data, err := os.ReadFile(configFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cfg any
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("error unmarshalling %s: %w", configFile, err)
+	}
+	data, err = json.MarshalIndent(stripSecrets("", cfg), "", "  ")
+	return append(data, '\n'), err
+}
+
+// stripSecrets replaces the values of keys that look like they hold a
+// secret, unless they only name one ("secret:<name>"), and cuts URLs down to
+// their scheme and host: webhook URLs carry their token in the path, and
+// private feed links in the query. Other strings, such as addresses, names
+// and queries, are redacted unless they are settings of debugSettingKeys.
+func stripSecrets(key string, v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, value := range v {
+			v[k] = stripSecrets(k, value)
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = stripSecrets(key, value)
+		}
+	case string:
+		if strings.HasPrefix(v, secretPrefix) || v == "" {
+			return v
+		}
+		if secretKeyPattern.MatchString(key) {
+			return strippedValue
+		}
+		if u, err := url.Parse(v); err == nil && u.Scheme != "" && u.Host != "" {
+			stripped := u.Scheme + "://" + u.Host
+			if u.User != nil || strings.Trim(u.Path, "/") != "" || u.RawQuery != "" || u.Fragment != "" {
+				stripped += "/" + strippedValue
+			}
+			return stripped
+		}
+		if !slices.Contains(debugSettingKeys, key) {
+			return redactText(v)
+		}
+	}
+	return v
+}
+
+// debugLog returns the latest lines of daemon.log, redacted but for the
+// time they start with, or nil if there is none.
+func debugLog() ([]byte, error) {
+	data, err := os.ReadFile(daemonLogFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	lines = lines[max(0, len(lines)-debugLogLines):]
+	for i, line := range lines {
+		at := logTimePattern.FindString(line)
+		lines[i] = at + redactText(line[len(at):])
+	}
+	return []byte(strings.Join(lines, "\n") + "\n"), nil
+}
+
+// debugHistory returns the latest commands run, redacted but for the
+// command names and flags.
+func debugHistory() ([]byte, error) {
+	if historyTracker().DocumentEncrypted(historyFile) {
+		return nil, errors.New("the history is encrypted; not included")
+	}
+	entries, err := loadHistory()
+	if err != nil {
+		return nil, err
+	}
+	entries = entries[max(0, len(entries)-debugHistoryEntries):]
+	for i := range entries {
+		entries[i].Args = redactArgs(entries[i].Args)
+		entries[i].Project = redactText(entries[i].Project)
+	}
+	return json.MarshalIndent(entries, "", "  ")
+}
+
+// redactArgs masks the arguments of a command, keeping the names of the
+// command and its subcommands, and of flags.
+func redactArgs(args []string) []string {
+	redacted := make([]string, len(args))
+	cmd := rootCommand()
+	for i, arg := range args {
+		if cmd != nil {
+			if sub := cmd.find(arg); sub != nil {
+				redacted[i], cmd = arg, sub
+				continue
+			}
+			cmd = nil
+		}
+		if name, value, ok := strings.Cut(arg, "="); ok && strings.HasPrefix(arg, "-") {
+			redacted[i] = name + "=" + redactText(value)
+		} else if strings.HasPrefix(arg, "-") {
+			redacted[i] = arg
+		} else {
+			redacted[i] = redactText(arg)
+		}
+	}
+	return redacted
+}
+
+// debugData returns a redacted sample of the current project's records:
+// the latest of each kind.
+func debugData() ([]byte, error) {
+	t := tr()
+	if t.Encrypted() {
+		return nil, errors.New("the data is encrypted; not included")
+	}
+	data, err := loadDataExport()
+	if err != nil {
+		return nil, err
+	}
+	data.Tasks = data.Tasks[max(0, len(data.Tasks)-debugSampleRecords):]
+	data.Archive = data.Archive[max(0, len(data.Archive)-debugSampleRecords):]
+	data.Expenses = data.Expenses[max(0, len(data.Expenses)-debugSampleRecords):]
+	data.Income = data.Income[max(0, len(data.Income)-debugSampleRecords):]
+	return json.MarshalIndent(redactData(data), "", "  ")
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !windows
+
+package main
+
+import "os/exec"
+
+// detach does nothing: cmd runs as started on this platform.
+func detach(*exec.Cmd) {}
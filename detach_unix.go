@@ -0,0 +1,14 @@
+//go:build linux || darwin
+
+package main
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach makes cmd run in a session of its own, so it outlives the
+// terminal it was started from.
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
@@ -0,0 +1,17 @@
+package main
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+const (
+	createNewProcessGroup = 0x00000200
+	detachedProcess       = 0x00000008
+)
+
+// detach makes cmd run without a console, so it outlives the one it was
+// started from.
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: createNewProcessGroup | detachedProcess}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+const (
+	digestItems   = 10   // Tasks listed per section; the rest are counted.
+	discordLimit  = 2000 // Characters Discord accepts in a message.
+	digestDayForm = "Monday 2 January"
+)
+
+// digestCommand returns the digest command.
+func digestCommand() *command {
+	return &command{
+		name: "digest", summary: "Post today's due, overdue and yesterday's completed tasks to Slack or Discord", group: groupTasks,
+		setup: func(fs *flag.FlagSet) runFunc {
+			channel := fs.String("channel", "", "Slack or Discord webhook `URL` to post to, or a secret naming one (default print the digest)")
+			return func([]string) error {
+				return postDigest(*channel, clock())
+			}
+		},
+	}
+}
+
+// digestItem is a task in the digest, with the project it is in.
+type digestItem struct {
+	task    Task
+	project string
+}
+
+// postDigest posts a summary of the tasks due today, those overdue and
+// those completed yesterday, across projects, to a Slack or Discord
+// incoming webhook; without a channel, or in a dry run, it prints it. It
+// is meant to run from cron each morning.
+func postDigest(channel string, now time.Time) error {
+	discord := false
+	target := ""
+	if channel != "" {
+		var err error
+		if target, err = resolveSecret(channel); err != nil {
+			return err
+		}
+		u, err := url.Parse(target)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return usagef("'%s' is not an http or https URL", channel)
+		}
+		discord = strings.HasSuffix(u.Hostname(), "discord.com") || strings.HasSuffix(u.Hostname(), "discordapp.com")
+	}
+
+	today := startOfDay(now)
+	yesterday := today.AddDate(0, 0, -1)
+	var due, overdue, done []digestItem
+	err := forEachProject(func(project string) error {
+		tasks, err := tr().Tasks()
+		if err != nil {
+			return err
+		}
+		for _, task := range tasks {
+			item := digestItem{task, project}
+			switch {
+			case config.Workflow.IsDone(task.Status):
+				if task.CompletedAt != nil && !task.CompletedAt.Before(yesterday) && task.CompletedAt.Before(today) {
+					done = append(done, item)
+				}
+			case task.Due == nil:
+			case now.After(tracker.Deadline(*task.Due)):
+				overdue = append(overdue, item)
+			case startOfDay(*task.Due).Equal(today):
+				due = append(due, item)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	byDue := func(a, b digestItem) int { return a.task.Due.Compare(*b.task.Due) }
+	slices.SortStableFunc(due, byDue)
+	slices.SortStableFunc(overdue, byDue)
+
+	bold := func(s string) string { return "*" + s + "*" }
+	if discord {
+		bold = func(s string) string { return "**" + s + "**" }
+	}
+	var b strings.Builder
+	b.WriteString(bold("Tasks for " + today.Format(digestDayForm)))
+	writeDigestSection(&b, bold("Due today"), due, func(t Task) string {
+		if !t.Due.Equal(startOfDay(*t.Due)) {
+			return "at " + t.Due.Format("15:04")
+		}
+		return ""
+	})
+	writeDigestSection(&b, bold("Overdue"), overdue, func(t Task) string { return relativeDue(*t.Due, now) })
+	writeDigestSection(&b, bold("Done yesterday"), done, func(Task) string { return "" })
+	if len(due)+len(overdue)+len(done) == 0 {
+		b.WriteString("\nNothing due, overdue or done yesterday.")
+	}
+	text := b.String()
+
+	if target == "" || dryRun {
+		fmt.Fprintln(stdout, text)
+		if target != "" {
+			fmt.Fprintln(stdout, "Dry run: the digest was not posted.")
+		}
+		return nil
+	}
+	// Slack reads <...> as links and mentions
+	payload := map[string]string{"text": strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)}
+	if discord {
+		if r := []rune(text); len(r) > discordLimit {
+			text = string(r[:discordLimit-1]) + "…"
+		}
+		payload = map[string]string{"content": text}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("error marshalling JSON: %w", err)
+	}
+	if err := postJSON(target, body, nil); err != nil {
+		return fmt.Errorf("error posting the digest: %w", err)
+	}
+	fmt.Fprintf(stdout, "Digest posted: %d due today, %d overdue, %d done yesterday.\n", len(due), len(overdue), len(done))
+	return nil
+}
+
+// writeDigestSection writes a heading and a line per task, with what note
+// returns for it and its project if it is not the default one. Past
+// digestItems tasks, the rest are only counted. Empty sections are left out.
+func writeDigestSection(b *strings.Builder, heading string, items []digestItem, note func(Task) string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n\n%s (%d)", heading, len(items))
+	for i, item := range items {
+		if i == digestItems {
+			fmt.Fprintf(b, "\n…and %d more", len(items)-i)
+			break
+		}
+		fmt.Fprintf(b, "\n• %s (#%d)", item.task.Description, item.task.ID)
+		var details []string
+		if n := note(item.task); n != "" {
+			details = append(details, n)
+		}
+		if item.project != defaultProject {
+			details = append(details, item.project)
+		}
+		if len(details) > 0 {
+			b.WriteString(" — " + strings.Join(details, ", "))
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+// dryRun is set by the global --dry-run flag: commands run as usual, but
+// their changes are dropped and described instead of saved.
+var dryRun bool
+
+// noDryRun lists the commands --dry-run cannot preview: those that run
+// other commands, save changes themselves or keep running. Commands that
+// post or send something outside check dryRun and print it instead, and
+// those writing files other than the data files do it with writeFile,
+// makeDir and removeFile, which a dry run lists instead.
+var noDryRun = []string{"shell", "batch", "begin", "commit", "rollback", "serve", "dash", "sync", "repeat", "!!", "daemon", "watch", "secret"}
+
+// dryRunActions lists what a dry run skipped doing to files other than the
+// data files, such as "write filters.json", for its summary.
+var dryRunActions []string
+
+// dryRunLabelLen is how much of a changed value a dry run shows.
+const dryRunLabelLen = 40
+
+// dryRunFile is a data file whose records a dry run compares one by one.
+type dryRunFile struct {
+	name, kind, key string // key is the field identifying a record.
+	load            func(*tracker.Tracker) (any, error)
+}
+
+var dryRunFiles = []dryRunFile{
+	{tracker.TasksFile, "task", "uuid", func(t *tracker.Tracker) (any, error) { return t.Tasks() }},
+	{tracker.ArchiveFile, "archived task", "uuid", func(t *tracker.Tracker) (any, error) { return t.ArchivedTasks() }},
+	{tracker.ExpensesFile, "expense", "id", func(t *tracker.Tracker) (any, error) { return t.Expenses() }},
+	{tracker.IncomeFile, "income", "id", func(t *tracker.Tracker) (any, error) { return t.Income() }},
+}
+
+// executeDryRun runs a command holding its changes in memory, then prints
+// the records it would add, delete or change, field by field, and the
+// other files it would write, and drops the changes. Nothing is recorded
+// in the history and hooks do not run.
+func executeDryRun(root *command, args []string) error {
+	if len(args) > 0 && slices.Contains(noDryRun, args[0]) {
+		return fmt.Errorf("--dry-run cannot be used with '%s'", args[0])
+	}
+	tr()
+	baseTracker.Begin()
+	dryRunActions = nil
+	defer func() {
+		baseTracker.Rollback()
+		dryRunActions = nil
+	}()
+	t := tr()
+
+	before := make([][]map[string]any, len(dryRunFiles))
+	for i, f := range dryRunFiles {
+		records, err := dryRunRecords(t, f)
+		if err != nil {
+			return err
+		}
+		before[i] = records
+	}
+	if err := execute(root, args); err != nil {
+		return err
+	}
+
+	var lines []string
+	compared := map[string]bool{}
+	for i, f := range dryRunFiles {
+		compared[t.Path(f.name)] = true
+		after, err := dryRunRecords(t, f)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, diffRecords(f, before[i], after)...)
+	}
+	for _, path := range baseTracker.Pending() {
+		if !compared[path] {
+			lines = append(lines, "write "+path)
+		}
+	}
+	lines = append(lines, dryRunActions...)
+
+	fmt.Fprintln(stdout, "--- Dry run: nothing was saved ---")
+	if len(lines) == 0 {
+		fmt.Fprintln(stdout, "No changes.")
+	}
+	for _, line := range lines {
+		fmt.Fprintln(stdout, line)
+	}
+	return nil
+}
+
+// writeFile writes a file other than the data files, such as config.json
+// or a template file. A dry run lists it instead.
+func writeFile(path string, data []byte, perm os.FileMode) error {
+	if dryRun {
+		dryRunActions = append(dryRunActions, "write "+path)
+		return nil
+	}
+	return os.WriteFile(path, data, perm)
+}
+
+// makeDir creates a directory and its parents. A dry run lists it instead.
+func makeDir(path string, perm os.FileMode) error {
+	if dryRun {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			dryRunActions = append(dryRunActions, "create directory "+path)
+		}
+		return nil
+	}
+	return os.MkdirAll(path, perm)
+}
+
+// removeFile removes a file, if it exists. A dry run lists it instead.
+func removeFile(path string) error {
+	if dryRun {
+		if _, err := os.Stat(path); err == nil {
+			dryRunActions = append(dryRunActions, "delete "+path)
+		}
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// dryRunRecords loads a data file's records as JSON objects, so that any
+// kind of record can be compared field by field.
+func dryRunRecords(t *tracker.Tracker, f dryRunFile) ([]map[string]any, error) {
+	items, err := f.load(t)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(items)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling JSON: %w", err)
+	}
+	var records []map[string]any
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("error unmarshalling JSON: %w", err)
+	}
+	return records, nil
+}
+
+// diffRecords describes the records added, deleted and changed between
+// two versions of a file, changes with each field's old and new value.
+func diffRecords(f dryRunFile, before, after []map[string]any) []string {
+	old := map[string]map[string]any{}
+	for _, r := range before {
+		old[fmt.Sprint(r[f.key])] = r
+	}
+	var lines []string
+	seen := map[string]bool{}
+	for _, r := range after {
+		key := fmt.Sprint(r[f.key])
+		seen[key] = true
+		prev, ok := old[key]
+		if !ok {
+			lines = append(lines, fmt.Sprintf("add %s", recordLabel(f, r)))
+			continue
+		}
+		var changes []string
+		for _, field := range changedFields(prev, r) {
+			changes = append(changes, fmt.Sprintf("%s: %s → %s", field, formatDryRunValue(prev[field]), formatDryRunValue(r[field])))
+		}
+		if len(changes) > 0 {
+			lines = append(lines, fmt.Sprintf("change %s", recordLabel(f, prev)))
+			for _, c := range changes {
+				lines = append(lines, "    "+c)
+			}
+		}
+	}
+	for _, r := range before {
+		if !seen[fmt.Sprint(r[f.key])] {
+			lines = append(lines, fmt.Sprintf("delete %s", recordLabel(f, r)))
+		}
+	}
+	return lines
+}
+
+// changedFields returns the fields whose values differ, sorted.
+func changedFields(a, b map[string]any) []string {
+	var fields []string
+	for field := range a {
+		if formatDryRunValue(a[field]) != formatDryRunValue(b[field]) {
+			fields = append(fields, field)
+		}
+	}
+	for field := range b {
+		if _, ok := a[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	slices.Sort(fields)
+	return fields
+}
+
+// recordLabel names a record by its kind, ID and description.
+func recordLabel(f dryRunFile, r map[string]any) string {
+	label := fmt.Sprintf("%s %v", f.kind, r["id"])
+	if desc, ok := r["description"].(string); ok && desc != "" {
+		label += fmt.Sprintf(" (%s)", truncateDryRun(desc))
+	}
+	return label
+}
+
+// formatDryRunValue renders a field's value as JSON, or "(none)" if unset.
+func formatDryRunValue(v any) string {
+	if v == nil {
+		return "(none)"
+	}
+	data, _ := json.Marshal(v)
+	return truncateDryRun(string(data))
+}
+
+// truncateDryRun shortens s to dryRunLabelLen characters.
+func truncateDryRun(s string) string {
+	if r := []rune(s); len(r) > dryRunLabelLen {
+		return strings.TrimSpace(string(r[:dryRunLabelLen-1])) + "…"
+	}
+	return s
+}
+
+// printDone confirms a change a command made. A dry run prints nothing, as
+// the change was not made; its summary describes the change instead.
+func printDone(format string, args ...any) {
+	if dryRun {
+		return
+	}
+	fmt.Fprintf(stdout, format, args...)
+}
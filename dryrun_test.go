@@ -0,0 +1,38 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDryRunWritesNoOtherFiles(t *testing.T) {
+	out := setupCLI(t)
+	mustRunCLI(t, out, "add", "Buy milk")
+	if err := os.WriteFile("receipt.txt", []byte("milk 1.20"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dryRun = true
+	for _, c := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"attach", "1", "receipt.txt"}, "copy receipt.txt to attachments"},
+		{[]string{"project", "create", "work"}, "create directory projects/work"},
+		{[]string{"filter", "save", "urgent", "priority:high"}, "write " + filtersFile},
+		{[]string{"expense", "rules", "add", "grocer", "Food"}, "write " + configFile},
+	} {
+		s := mustRunCLI(t, out, c.args...)
+		if !strings.Contains(s, c.want) || strings.Contains(s, "No changes.") {
+			t.Errorf("dry run of %s:\n%s\nwant %q", strings.Join(c.args, " "), s, c.want)
+		}
+	}
+	dryRun = false
+
+	for _, path := range []string{attachmentsDir, projectsDir, filtersFile, configFile} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("dry run wrote %s", path)
+		}
+	}
+}
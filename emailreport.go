@@ -0,0 +1,305 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/tls"
+	"fmt"
+	"html/template"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+const (
+	smtpPort        = 587 // Submission with STARTTLS, unless smtp.port is set.
+	smtpsPort       = 465 // Submission over TLS from the start.
+	mailTimeout     = 30 * time.Second
+	reportWeek      = "week"
+	reportMonth     = "month"
+	reportDayLayout = "Mon 2 Jan"
+)
+
+// SMTP configures the mail server 'task report email' sends through.
+type SMTP struct {
+	Host     string `json:"host,omitempty"`
+	Port     int    `json:"port,omitempty"` // 587 if unset; 465 is TLS from the start.
+	User     string `json:"user,omitempty"`
+	Password string `json:"password,omitempty"` // May name a secret.
+	From     string `json:"from,omitempty"`     // The user if unset.
+	To       string `json:"to,omitempty"`       // Where reports go unless --to is given.
+}
+
+// reportPeriod returns the last full week (Monday to Sunday) or month
+// before now.
+func reportPeriod(period string, now time.Time) (start, end time.Time) {
+	if period == reportMonth {
+		end = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+		return end.AddDate(0, -1, 0), end
+	}
+	end = startOfWeek(now)
+	return end.AddDate(0, 0, -7), end
+}
+
+// emailReport is what the report template is given.
+type emailReport struct {
+	Title     string
+	Completed []reportLine
+	Slipped   []reportLine
+	Upcoming  []reportLine
+	Projects  []reportRow
+	Expenses  []reportRow
+	Total     string
+}
+
+// reportLine is a task in a report, with its details.
+type reportLine struct {
+	Text, Detail string
+	Strong       bool
+}
+
+// reportRow is a labelled row of figures.
+type reportRow struct {
+	Label  string
+	Values []string
+}
+
+var emailTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.Title}}</title></head>
+<body style="font-family: -apple-system, Segoe UI, Helvetica, Arial, sans-serif; color: #222; max-width: 640px;">
+<h1 style="font-size: 20px;">{{.Title}}</h1>
+{{define "lines"}}{{if .}}<ul>{{range .}}<li>{{if .Strong}}<strong>{{.Text}}</strong>{{else}}{{.Text}}{{end}}{{if .Detail}} <span style="color: #777;">({{.Detail}})</span>{{end}}</li>{{end}}</ul>{{else}}<p style="color: #777;">None.</p>{{end}}{{end}}
+<h2 style="font-size: 16px;">Completed ({{len .Completed}})</h2>
+{{template "lines" .Completed}}
+<h2 style="font-size: 16px;">Slipped deadlines ({{len .Slipped}})</h2>
+{{template "lines" .Slipped}}
+<h2 style="font-size: 16px;">Coming up ({{len .Upcoming}})</h2>
+{{template "lines" .Upcoming}}
+<h2 style="font-size: 16px;">Projects</h2>
+<table cellpadding="4" style="border-collapse: collapse;">
+<tr><th align="left">Project</th><th align="right">Completed</th><th align="right">Focus time</th><th align="right">Spent</th></tr>
+{{range .Projects}}<tr><td>{{.Label}}</td>{{range .Values}}<td align="right">{{.}}</td>{{end}}</tr>
+{{end}}</table>
+<h2 style="font-size: 16px;">Expenses</h2>
+{{if .Expenses}}<table cellpadding="4" style="border-collapse: collapse;">
+{{range .Expenses}}<tr><td>{{.Label}}</td>{{range .Values}}<td align="right">{{.}}</td>{{end}}</tr>
+{{end}}<tr><td><strong>Total</strong></td><td align="right"><strong>{{.Total}}</strong></td></tr>
+</table>{{else}}<p style="color: #777;">No expenses were recorded.</p>{{end}}
+</body></html>
+`))
+
+// renderEmailReport renders the HTML summary of the last week or month
+// across projects: tasks completed, deadlines slipped, what is coming up
+// in the next one and what was spent. It returns the subject with it.
+func renderEmailReport(period string, now time.Time) (subject string, html []byte, err error) {
+	start, end := reportPeriod(period, now)
+	a, err := collectActivity(start, end, now)
+	if err != nil {
+		return "", nil, err
+	}
+
+	r := emailReport{Title: "Week of " + start.Format("2 January 2006")}
+	if period == reportMonth {
+		r.Title = start.Format("January 2006")
+	}
+	slices.SortStableFunc(a.completed, func(x, y projectTask) int {
+		return cmp.Or(priorityRank(y.Priority)-priorityRank(x.Priority), x.CompletedAt.Compare(*y.CompletedAt))
+	})
+	for _, t := range a.completed {
+		r.Completed = append(r.Completed, reportLine{
+			Text:   t.Description,
+			Detail: t.CompletedAt.Format(reportDayLayout) + ", " + t.Project,
+			Strong: t.Priority == tracker.PriorityHigh,
+		})
+	}
+	slices.SortStableFunc(a.slipped, func(x, y projectTask) int { return x.Due.Compare(*y.Due) })
+	for _, t := range a.slipped {
+		outcome := "still open"
+		if done := t.CompletedAt; done != nil {
+			outcome = "done " + done.Format(reportDayLayout)
+		}
+		r.Slipped = append(r.Slipped, reportLine{Text: t.Description, Detail: "due " + formatDue(*t.Due) + ", " + outcome + ", " + t.Project})
+	}
+
+	// Overdue tasks and those due by the end of the next period
+	today := startOfDay(now)
+	next := today.AddDate(0, 0, 7)
+	if period == reportMonth {
+		next = today.AddDate(0, 1, 0)
+	}
+	var upcoming []projectTask
+	for _, t := range a.open {
+		if t.Due != nil && t.Due.Before(next) {
+			upcoming = append(upcoming, t)
+		}
+	}
+	slices.SortStableFunc(upcoming, func(x, y projectTask) int { return x.Due.Compare(*y.Due) })
+	for _, t := range upcoming {
+		r.Upcoming = append(r.Upcoming, reportLine{
+			Text:   t.Description,
+			Detail: relativeDue(*t.Due, now) + ", " + t.Project,
+			Strong: t.Due.Before(today),
+		})
+	}
+
+	total := 0.0
+	for _, p := range a.projects {
+		r.Projects = append(r.Projects, reportRow{p.Name, []string{
+			strconv.Itoa(p.Completed),
+			fmt.Sprintf("%dh%02dm", p.FocusMinutes/60, p.FocusMinutes%60),
+			formatAmount(p.Spent, ""),
+		}})
+		total += p.Spent
+	}
+	for _, category := range sortedKeys(a.byCategory) {
+		r.Expenses = append(r.Expenses, reportRow{category, []string{formatAmount(a.byCategory[category], "")}})
+	}
+	r.Total = formatAmount(total, "")
+
+	var buf bytes.Buffer
+	if err := emailTemplate.Execute(&buf, r); err != nil {
+		return "", nil, err
+	}
+	return "Task report: " + r.Title, buf.Bytes(), nil
+}
+
+// emailReportTo renders the report and sends it to the comma-separated
+// addresses in to, or the configured ones, through the configured server.
+// A dry run renders it but does not send it.
+func emailReportTo(to, period string, now time.Time) error {
+	s := config.SMTP
+	to = cmp.Or(to, s.To)
+	if to == "" {
+		return usagef("no recipient; pass --to or set smtp.to in %s", configFile)
+	}
+	recipients, err := mail.ParseAddressList(to)
+	if err != nil {
+		return usagef("invalid recipient '%s': %v", to, err)
+	}
+	if s.Host == "" {
+		return fmt.Errorf("no mail server; set smtp.host in %s", configFile)
+	}
+	from, err := mail.ParseAddress(cmp.Or(s.From, s.User))
+	if err != nil {
+		return fmt.Errorf("invalid sender; set smtp.from in %s: %w", configFile, err)
+	}
+
+	subject, html, err := renderEmailReport(period, now)
+	if err != nil {
+		return err
+	}
+	var msg bytes.Buffer
+	header := func(name, value string) { fmt.Fprintf(&msg, "%s: %s\r\n", name, value) }
+	var names, addresses []string
+	for _, r := range recipients {
+		names = append(names, r.String())
+		addresses = append(addresses, r.Address)
+	}
+	header("From", from.String())
+	header("To", strings.Join(names, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/html; charset=UTF-8")
+	header("Content-Transfer-Encoding", "quoted-printable")
+	msg.WriteString("\r\n")
+	qp := quotedprintable.NewWriter(&msg)
+	qp.Write(html)
+	qp.Close()
+
+	if dryRun {
+		fmt.Fprintf(stdout, "Dry run: the report '%s' was not sent to %s.\n", subject, strings.Join(addresses, ", "))
+		return nil
+	}
+	if err := sendMail(s, from.Address, recipients, msg.Bytes()); err != nil {
+		return fmt.Errorf("error sending the report: %w", err)
+	}
+	fmt.Fprintf(stdout, "Report sent to %s.\n", strings.Join(addresses, ", "))
+	return nil
+}
+
+// sendMail delivers msg through the server in s, over TLS: from the start
+// on port 465, or by STARTTLS where the server offers it. The password is
+// only ever sent encrypted, or to a server on this machine.
+func sendMail(s SMTP, from string, to []*mail.Address, msg []byte) error {
+	password, err := resolveSecret(s.Password)
+	if err != nil {
+		return err
+	}
+	port := cmp.Or(s.Port, smtpPort)
+	addr := net.JoinHostPort(s.Host, strconv.Itoa(port))
+	tlsConfig := &tls.Config{ServerName: s.Host}
+	dialer := &net.Dialer{Timeout: mailTimeout}
+
+	var conn net.Conn
+	if port == smtpsPort {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(mailTimeout))
+	c, err := smtp.NewClient(conn, s.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok && port != smtpsPort {
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	if s.User != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.User, password, s.Host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(from); err != nil {
+		return err
+	}
+	for _, r := range to {
+		if err := c.Rcpt(r.Address); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// writeEmailReport writes the report's HTML to path instead of sending it.
+func writeEmailReport(path, period string, now time.Time) error {
+	_, html, err := renderEmailReport(period, now)
+	if err != nil {
+		return err
+	}
+	err = writeOutput(path, func(w io.Writer) error {
+		_, err := w.Write(html)
+		return err
+	})
+	if err == nil {
+		fmt.Fprintf(stdout, "Report written to %s\n", path)
+	}
+	return err
+}
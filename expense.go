@@ -0,0 +1,340 @@
+package main
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+// expenseCommand returns the expense command group.
+func expenseCommand() *command {
+	return &command{
+		name: "expense", summary: "Record and list expenses", group: groupExpenses,
+		subcommands: []*command{
+			{
+				name: "add", args: "<amount> [description]", summary: "Record an expense, optionally spent on a task", minArgs: 1,
+				completeFlags: map[string]func() []candidate{"task": func() []candidate { return taskIDs(nil) }},
+				setup: func(fs *flag.FlagSet) runFunc {
+					category := fs.String("category", "", "expense category")
+					payee := fs.String("payee", "", "who was paid")
+					currency := fs.String("currency", "", "currency `code` such as USD (default the base currency)")
+					dateStr := fs.String("date", "", "`date` of the expense (e.g. 2025-03-01 or \"yesterday\", default today)")
+					taskID := fs.String("task", "", "`id` of the task it was spent on (its description if none is given)")
+					account := fs.String("account", "", "bank `account` it was paid from, for 'task expense reconcile'")
+					fields := fieldFlag{}
+					fs.Var(fields, "field", "set one of your own fields, as `name=value`; repeat for more")
+					return func(args []string) error {
+						amount, err := strconv.ParseFloat(args[0], 64)
+						if err != nil || amount < 0 {
+							return usagef("invalid amount '%s'", args[0])
+						}
+						e := Expense{Amount: amount, Description: strings.Join(args[1:], " "), Category: *category, Payee: *payee, Account: *account}
+						if len(fields) > 0 {
+							e.Fields = fields
+						}
+						if *taskID != "" {
+							id, err := parseID(*taskID, "task")
+							if err != nil {
+								return err
+							}
+							task, err := tr().Task(id)
+							if err != nil {
+								return err
+							}
+							e.Task = task.UUID
+							if e.Description == "" {
+								e.Description = task.Description
+							}
+						}
+						if e.Description == "" {
+							return usagef("expected a description or --task")
+						}
+						if *currency != "" {
+							if e.Currency, err = parseCurrency(*currency); err != nil {
+								return err
+							}
+						}
+						e.Date = clock()
+						if *dateStr != "" {
+							e.Date, err = parseDate(*dateStr, clock())
+							if err != nil {
+								return usagef("%v", err)
+							}
+						}
+						applyCategoryRules(&e)
+						return addExpense(e)
+					}
+				},
+			},
+			{
+				name: "list", summary: "List expenses with a total",
+				setup: func(fs *flag.FlagSet) runFunc {
+					category := fs.String("category", "", "only list expenses in this category")
+					since := fs.String("since", "", "only list expenses on or after this `date`")
+					until := fs.String("until", "", "only list expenses on or before this `date`")
+					fields := fieldFlag{}
+					fs.Var(fields, "field", "only list expenses with this `name=value` of one of your fields; repeat for more")
+					return func([]string) error {
+						window, err := parseDateRange(*since, *until, clock())
+						if err != nil {
+							return usagef("%v", err)
+						}
+						return listExpenses(tracker.ExpenseFilter{Category: *category, Since: window.Since, Until: window.Until, Fields: fields})
+					}
+				},
+			},
+			{
+				name: "summary", summary: "Total expenses by category, converted to one currency",
+				setup: func(fs *flag.FlagSet) runFunc {
+					in := fs.String("in", "", "currency `code` to convert to (default the base currency)")
+					since := fs.String("since", "", "only count expenses on or after this `date`")
+					until := fs.String("until", "", "only count expenses on or before this `date`")
+					return func([]string) error {
+						window, err := parseDateRange(*since, *until, clock())
+						if err != nil {
+							return usagef("%v", err)
+						}
+						to := ""
+						if *in != "" {
+							if to, err = parseCurrency(*in); err != nil {
+								return err
+							}
+						}
+						return expenseSummary(to, window)
+					}
+				},
+			},
+			{
+				name: "show", args: "<id>", summary: "Show an expense with its attachments", minArgs: 1,
+				setup: run(func(args []string) error {
+					id, err := parseID(args[0], "expense")
+					if err != nil {
+						return err
+					}
+					return showExpense(id)
+				}),
+			},
+			{
+				name: "field", args: "<id> <name=value>...", summary: "Set your own fields of an expense; an empty value removes one", minArgs: 2,
+				setup: run(func(args []string) error {
+					id, err := parseID(args[0], "expense")
+					if err != nil {
+						return err
+					}
+					fields, err := parseFieldArgs(args[1:])
+					if err != nil {
+						return err
+					}
+					e, err := tr().SetExpenseFields(id, fields)
+					if err != nil {
+						return err
+					}
+					printFieldsSet("Expense", e.ID, e.Fields)
+					return nil
+				}),
+			},
+			{
+				name: "attach", args: "<id> <file>", summary: "Attach a file such as a receipt to an expense", minArgs: 2,
+				setup: run(func(args []string) error {
+					id, err := parseID(args[0], "expense")
+					if err != nil {
+						return err
+					}
+					if len(args) > 2 {
+						return usagef("expected one file")
+					}
+					if _, err := tr().Expense(id); err != nil {
+						return err
+					}
+					return attachFile(expenseRecord(id), args[1])
+				}),
+			},
+			{
+				name: "delete", args: "<id>", summary: "Delete an expense, after showing it and asking", minArgs: 1,
+				setup: func(fs *flag.FlagSet) runFunc {
+					yes := fs.Bool("yes", false, "delete without asking for confirmation")
+					return func(args []string) error {
+						id, err := parseID(args[0], "expense")
+						if err != nil {
+							return err
+						}
+						return deleteExpense(id, *yes)
+					}
+				},
+			},
+			{
+				name: "import", args: "<statement.csv|statement.ofx>", summary: "Import a bank statement, skipping transactions already recorded", minArgs: 1,
+				setup: func(fs *flag.FlagSet) runFunc {
+					format := fs.String("format", "", "`format` of the file, csv or ofx (default from the file extension)")
+					noPrompt := fs.Bool("no-prompt", false, "do not ask for the categories of expenses no rule categorizes")
+					account := fs.String("account", "", "bank `account` the statement is of, for 'task expense reconcile'")
+					return func(args []string) error {
+						if *format != "" && *format != statementCSV && *format != statementOFX {
+							return usagef("invalid statement format '%s'; use csv or ofx", *format)
+						}
+						return importStatement(args[0], *format, *account, !*noPrompt)
+					}
+				},
+			},
+			{
+				name: "forecast", summary: "Project income, recurring charges and spending over the coming months",
+				setup: func(fs *flag.FlagSet) runFunc {
+					months := fs.Int("months", forecastMonths, "how many `months` ahead to forecast")
+					return func([]string) error {
+						if *months < 1 || *months > maxForecast {
+							return usagef("--months must be between 1 and %d", maxForecast)
+						}
+						return forecastCashFlow(*months, clock())
+					}
+				},
+			},
+			reconcileCommand(),
+			expenseReportCommand(),
+			recurringExpenseCommand(),
+			debtCommand(),
+			categoryRulesCommand(),
+		},
+	}
+}
+
+// loadExpenses reads expenses from the saved JSON file.
+func loadExpenses() ([]Expense, error) {
+	return tr().Expenses()
+}
+
+// addExpense records a new expense.
+func addExpense(e Expense) error {
+	expense, err := tr().AddExpense(e)
+	if err != nil {
+		return err
+	}
+
+	printDone(msg("Expense added successfully (ID: %d)\n"), expense.ID)
+	return nil
+}
+
+// showExpense prints an expense with its details and attachments.
+func showExpense(id int) error {
+	e, err := tr().Expense(id)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, msg("--- Expense ---"))
+	fmt.Fprintf(stdout, "[ID: %d] %s %s  %s\n", e.ID, formatDate(e.Date), formatAmount(e.Amount, e.Currency), e.Description)
+	if e.Category != "" || e.Payee != "" {
+		fmt.Fprintf(stdout, msg("  Category: %s | Payee: %s\n"), cmp.Or(e.Category, "-"), cmp.Or(e.Payee, "-"))
+	}
+	if e.Task != "" {
+		tasks, err := loadTasks()
+		if err != nil {
+			return err
+		}
+		for _, task := range tasks {
+			if task.UUID == e.Task {
+				fmt.Fprintf(stdout, msg("  Task: %d %s\n"), task.ID, task.Description)
+			}
+		}
+	}
+	if len(e.Fields) > 0 {
+		fmt.Fprintf(stdout, msg("  Fields: %s\n"), formatFields(e.Fields))
+	}
+	if err := printAttachments(expenseRecord(id)); err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, "---------------")
+	return nil
+}
+
+// deleteExpense deletes an expense by ID, once confirmed unless yes is set.
+func deleteExpense(id int, yes bool) error {
+	if !yes {
+		e, err := tr().Expense(id)
+		if err != nil {
+			return err
+		}
+		ok, err := confirm(fmt.Sprintf("Delete expense %d (%s, %s %q)?", id, e.Date.Format(dateLayout), formatAmount(e.Amount, e.Currency), e.Description))
+		if err != nil || !ok {
+			if err == nil {
+				fmt.Fprintln(stdout, msg("Expense not deleted."))
+			}
+			return err
+		}
+	}
+	if err := tr().DeleteExpense(id); err != nil {
+		return err
+	}
+	if err := deleteRecordAttachments(expenseRecord(id)); err != nil {
+		return err
+	}
+
+	printDone(msg("Expense ID %d deleted successfully\n"), id)
+	return nil
+}
+
+// taskCosts totals the expenses spent on each task by currency, keyed by
+// task UUID.
+func taskCosts() (map[string]map[string]float64, error) {
+	expenses, err := loadExpenses()
+	if err != nil {
+		return nil, err
+	}
+	costs := map[string]map[string]float64{}
+	for _, e := range expenses {
+		if e.Task == "" {
+			continue
+		}
+		if costs[e.Task] == nil {
+			costs[e.Task] = map[string]float64{}
+		}
+		costs[e.Task][e.Currency] += e.Amount
+	}
+	return costs, nil
+}
+
+// listExpenses prints the expenses matching filter with a total. Recurring
+// charges that have come due are recorded first.
+func listExpenses(filter tracker.ExpenseFilter) error {
+	if _, err := chargeRecurringExpenses(clock()); err != nil {
+		return err
+	}
+	list, err := tr().ListExpenses(filter)
+	if err != nil {
+		return err
+	}
+
+	if len(list.Expenses) == 0 {
+		fmt.Fprintln(stdout, msg("No expenses found."))
+		return nil
+	}
+
+	tasks, err := loadTasks()
+	if err != nil {
+		return err
+	}
+	taskIDs := map[string]int{}
+	for _, task := range tasks {
+		taskIDs[task.UUID] = task.ID
+	}
+
+	fmt.Fprintln(stdout, msg("--- Expenses ---"))
+	for _, expense := range list.Expenses {
+		fmt.Fprintf(stdout, "[ID: %d] %s %10s %-3s  %s", expense.ID, formatDate(expense.Date), formatNumber(expense.Amount), expense.Currency, expense.Description)
+		if expense.Category != "" {
+			fmt.Fprintf(stdout, " [%s]", expense.Category)
+		}
+		if expense.Payee != "" {
+			fmt.Fprintf(stdout, " @ %s", expense.Payee)
+		}
+		if id, ok := taskIDs[expense.Task]; ok {
+			fmt.Fprintf(stdout, msg(" (task %d)"), id)
+		}
+		fmt.Fprintln(stdout)
+	}
+	fmt.Fprintf(stdout, msg("--- Total: %s ---\n"), formatTotals(list.Totals))
+
+	return nil
+}
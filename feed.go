@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	feedEntries   = 50                                                     // Most entries a project's feed lists.
+	feedTagPrefix = "tag:github.com,2025:arijit-gogoi/expense-tracker-go/" // Starts the tag URIs identifying entries.
+)
+
+// atomFeed is an Atom (RFC 4287) feed.
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Link    atomLink    `xml:"link"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	ID      string   `xml:"id"`
+	Title   string   `xml:"title"`
+	Updated string   `xml:"updated"`
+	Content atomText `xml:"content"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+}
+
+// handleFeed responds with an Atom feed of the tasks recently added to and
+// completed in a project, including those since archived, newest first.
+func handleFeed(w http.ResponseWriter, r *http.Request) {
+	serveMu.Lock()
+	defer serveMu.Unlock()
+
+	name := r.PathValue("project")
+	saved := currentProject
+	defer func() { currentProject = saved }()
+	if !selectRequestProject(w, name) {
+		return
+	}
+	tasks, err := loadTasks()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	archived, err := tr().ArchivedTasks()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	self := "http://" + r.Host + r.URL.Path
+	if r.TLS != nil {
+		self = "https://" + r.Host + r.URL.Path
+	}
+	feed := projectFeed(name, append(tasks, archived...), clock())
+	feed.ID, feed.Link = self, atomLink{Rel: "self", Href: self}
+
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, xml.Header)
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	enc.Encode(feed)
+}
+
+// projectFeed builds the feed of a project's tasks: an entry for each task
+// added and one for each completed, the latest feedEntries of them.
+func projectFeed(project string, tasks []Task, now time.Time) atomFeed {
+	type event struct {
+		at    time.Time
+		entry atomEntry
+	}
+	var events []event
+	for _, task := range tasks {
+		events = append(events, event{task.CreatedAt, feedEntry(task, "added", "Added", task.CreatedAt)})
+		if task.CompletedAt != nil && config.Workflow.IsDone(task.Status) {
+			events = append(events, event{*task.CompletedAt, feedEntry(task, "done", "Done", *task.CompletedAt)})
+		}
+	}
+	slices.SortFunc(events, func(a, b event) int { return b.at.Compare(a.at) })
+	events = events[:min(len(events), feedEntries)]
+
+	feed := atomFeed{
+		Title:   "Tasks: " + project,
+		Updated: now.UTC().Format(time.RFC3339),
+		Author:  atomAuthor{Name: project},
+	}
+	if len(events) > 0 {
+		feed.Updated = events[0].at.UTC().Format(time.RFC3339)
+	}
+	for _, e := range events {
+		feed.Entries = append(feed.Entries, e.entry)
+	}
+	return feed
+}
+
+// feedEntry returns the entry for something that happened to a task. Its
+// ID is a tag URI (RFC 4151) that stays the same however often the feed is
+// fetched, so readers show each event once.
+func feedEntry(task Task, kind, verb string, at time.Time) atomEntry {
+	var details []string
+	details = append(details, "Status: "+task.Status)
+	if task.Priority != "" {
+		details = append(details, "Priority: "+task.Priority)
+	}
+	if task.Due != nil {
+		details = append(details, "Due: "+task.Due.Format(dateLayout))
+	}
+	if task.Assignee != "" {
+		details = append(details, "Assignee: "+task.Assignee)
+	}
+	if len(task.Tags) > 0 {
+		details = append(details, "Tags: "+formatTags(task.Tags))
+	}
+	return atomEntry{
+		ID:      feedTagPrefix + task.UUID + "/" + kind,
+		Title:   fmt.Sprintf("%s: %s", verb, task.Description),
+		Updated: at.UTC().Format(time.RFC3339),
+		Content: atomText{Type: "text", Text: strings.Join(details, "\n")},
+	}
+}
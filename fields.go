@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var fieldNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// fieldFlag collects the name=value pairs of a repeatable --field flag.
+type fieldFlag map[string]string
+
+func (f fieldFlag) String() string {
+	return formatFields(f)
+}
+
+func (f fieldFlag) Set(s string) error {
+	name, value, err := parseField(s)
+	if err != nil {
+		return err
+	}
+	if value == "" {
+		return fmt.Errorf("field %s needs a value", name)
+	}
+	f[name] = value
+	return nil
+}
+
+// parseField parses a name=value pair naming one of the user's own
+// fields. Names are lower case, and cannot be those of the built-in
+// fields queries test.
+func parseField(s string) (name, value string, err error) {
+	name, value, ok := strings.Cut(s, "=")
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !ok {
+		return "", "", fmt.Errorf("invalid field '%s'; use name=value", s)
+	}
+	if !fieldNamePattern.MatchString(name) || slices.Contains(queryFields, name) {
+		return "", "", fmt.Errorf("invalid field name '%s'; use lower-case letters, digits, - and _, other than %s", name, strings.Join(queryFields, ", "))
+	}
+	return name, strings.TrimSpace(value), nil
+}
+
+// parseFieldArgs parses the name=value arguments of the field commands; an
+// empty value removes a field.
+func parseFieldArgs(args []string) (map[string]string, error) {
+	fields := map[string]string{}
+	for _, arg := range args {
+		name, value, err := parseField(arg)
+		if err != nil {
+			return nil, usagef("%v", err)
+		}
+		fields[name] = value
+	}
+	return fields, nil
+}
+
+// formatFields lists fields by name, such as "client=ACME, invoice=1234".
+func formatFields(fields map[string]string) string {
+	var pairs []string
+	for _, name := range sortedKeys(fields) {
+		pairs = append(pairs, name+"="+fields[name])
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// printFieldsSet confirms the fields of a task or expense after a change.
+func printFieldsSet(kind string, id int, fields map[string]string) {
+	if len(fields) == 0 {
+		fmt.Fprintf(stdout, "%s ID %d has no fields.\n", kind, id)
+		return
+	}
+	fmt.Fprintf(stdout, "%s ID %d fields: %s\n", kind, id, formatFields(fields))
+}
@@ -0,0 +1,534 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+const filtersFile = "filters.json" // Filters saved with 'task filter save', shared by all projects.
+
+// Fields a query can test, as in "status:todo" or "due<friday".
+var queryFields = []string{"status", "tag", "priority", "assignee", "due", "scheduled", "created", "completed"}
+
+var (
+	queryTermPattern  = regexp.MustCompile(`^([a-z][a-z0-9_-]*)(<=|>=|<|>|:|=)(.+)$`)
+	filterNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+)
+
+// taskQuery is a parsed query. A nil query matches every task.
+type taskQuery func(Task) bool
+
+// matches reports whether task matches q.
+func (q taskQuery) matches(task Task) bool {
+	return q == nil || q(task)
+}
+
+// and returns a query matching the tasks both q and other match.
+func (q taskQuery) and(other taskQuery) taskQuery {
+	switch {
+	case q == nil:
+		return other
+	case other == nil:
+		return q
+	}
+	return func(t Task) bool { return q(t) && other(t) }
+}
+
+// queryToken is a word or parenthesis of a query.
+type queryToken struct {
+	text   string
+	quoted bool // Some of it was quoted, so it is never an operator.
+}
+
+// op reports whether t is the operator or parenthesis op.
+func (t queryToken) op(op string) bool {
+	return !t.quoted && strings.EqualFold(t.text, op)
+}
+
+// parseQuery parses a query: terms combined with "and" (or just spaces),
+// "or", "not" (or a "-" in front) and parentheses, "and" binding tighter
+// than "or". The terms are:
+//
+//	status:todo,doing      one of these statuses; a status on its own too
+//	tag:work or #work      carries the tag
+//	priority>=medium       priority compared as none < low < medium < high
+//	assignee:sam           assigned to sam; assignee:none for nobody
+//	due<friday             also due, scheduled, created and completed, with
+//	                       <, <=, >, >= or : (that day); "none" or "any"
+//	due<7d                 before the day 7 days from now; created>-2w for
+//	                       since 2 weeks ago
+//	client:acme            one of your own fields; <, <=, > and >= compare
+//	                       numbers as numbers; "none" or "any"
+//	invoice                the description contains the word
+//
+// Quotes group words, as in due<"end of week". Statuses are those of w, and
+// dates are relative to now.
+func parseQuery(query string, w Workflow, now time.Time) (taskQuery, error) {
+	tokens, err := queryTokens(query)
+	if err != nil || len(tokens) == 0 {
+		return nil, err
+	}
+	p := &queryParser{tokens: tokens, w: w, now: now}
+	q, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(tokens) {
+		return nil, fmt.Errorf("unexpected '%s'", tokens[p.pos].text)
+	}
+	return q, nil
+}
+
+// queryTokens splits a query into words the way splitWords does, with
+// parentheses outside quotes as tokens of their own.
+func queryTokens(query string) ([]queryToken, error) {
+	var tokens []queryToken
+	var word strings.Builder
+	var quote rune
+	inWord, quoted := false, false
+	flush := func() {
+		if inWord {
+			tokens = append(tokens, queryToken{word.String(), quoted})
+			word.Reset()
+			inWord, quoted = false, false
+		}
+	}
+	for _, r := range query {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord, quoted = r, true, true
+		case r == '(' || r == ')':
+			flush()
+			tokens = append(tokens, queryToken{text: string(r)})
+		case r == ' ' || r == '\t':
+			flush()
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("unterminated quote")
+	}
+	flush()
+	return tokens, nil
+}
+
+// queryParser parses the tokens of a query by recursive descent.
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+	w      Workflow
+	now    time.Time
+}
+
+// peek returns the next token without consuming it, or an empty token at
+// the end.
+func (p *queryParser) peek() queryToken {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return queryToken{quoted: true}
+}
+
+// or parses terms joined by "or".
+func (p *queryParser) or() (taskQuery, error) {
+	q, err := p.and()
+	for err == nil && p.peek().op("or") {
+		p.pos++
+		var right taskQuery
+		if right, err = p.and(); err == nil {
+			left := q
+			q = func(t Task) bool { return left(t) || right(t) }
+		}
+	}
+	return q, err
+}
+
+// and parses terms joined by "and" or only by spaces.
+func (p *queryParser) and() (taskQuery, error) {
+	q, err := p.not()
+	for err == nil && p.pos < len(p.tokens) && !p.peek().op(")") && !p.peek().op("or") {
+		if p.peek().op("and") {
+			p.pos++
+		}
+		var right taskQuery
+		if right, err = p.not(); err == nil {
+			q = q.and(right)
+		}
+	}
+	return q, err
+}
+
+// not parses a term or parenthesised query, negated or not.
+func (p *queryParser) not() (taskQuery, error) {
+	if p.pos == len(p.tokens) {
+		return nil, errors.New("the query ends too soon")
+	}
+	tok := p.tokens[p.pos]
+	p.pos++
+	negate := func(q taskQuery, err error) (taskQuery, error) {
+		if err != nil {
+			return nil, err
+		}
+		return func(t Task) bool { return !q(t) }, nil
+	}
+	switch {
+	case tok.op("not"), tok.op("-"):
+		return negate(p.not())
+	case tok.op("("):
+		q, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.peek().op(")") {
+			return nil, errors.New("missing ')'")
+		}
+		p.pos++
+		return q, nil
+	case tok.op(")"), tok.op("and"), tok.op("or"):
+		return nil, fmt.Errorf("unexpected '%s'", tok.text)
+	}
+	if rest, ok := strings.CutPrefix(tok.text, "-"); ok && rest != "" {
+		tok.text = rest
+		return negate(parseQueryTerm(tok, p.w, p.now))
+	}
+	return parseQueryTerm(tok, p.w, p.now)
+}
+
+// parseQueryTerm parses a single term of a query.
+func parseQueryTerm(tok queryToken, w Workflow, now time.Time) (taskQuery, error) {
+	word := tok.text
+	if !tok.quoted && w.HasStatus(strings.ToLower(word)) {
+		word = "status:" + word
+	}
+	if tag, ok := strings.CutPrefix(word, "#"); ok {
+		word = "tag:" + tag
+	}
+	var q taskQuery
+	m := queryTermPattern.FindStringSubmatch(word)
+	if m == nil {
+		text := strings.ToLower(word)
+		q = func(t Task) bool { return strings.Contains(strings.ToLower(t.Description), text) }
+		return q, nil
+	}
+	field, op, value := m[1], m[2], strings.ToLower(m[3])
+	if op == "=" {
+		op = ":"
+	}
+	if !slices.Contains(queryFields, field) {
+		return userFieldTerm(field, op, value), nil
+	}
+	equalOnly := func() error {
+		if op != ":" {
+			return fmt.Errorf("%s can only be matched with ':', not '%s'", field, op)
+		}
+		return nil
+	}
+
+	switch field {
+	case "status":
+		if err := equalOnly(); err != nil {
+			return nil, err
+		}
+		statuses := strings.Split(value, ",")
+		for _, s := range statuses {
+			if !w.HasStatus(s) {
+				return nil, fmt.Errorf("invalid status '%s'; use one of: %s", s, strings.Join(w.StatusNames(), ", "))
+			}
+		}
+		q = func(t Task) bool { return slices.Contains(statuses, t.Status) }
+	case "tag":
+		if err := equalOnly(); err != nil {
+			return nil, err
+		}
+		tag := normalizeTag(value)
+		q = func(t Task) bool { return slices.Contains(t.Tags, tag) }
+	case "assignee":
+		if err := equalOnly(); err != nil {
+			return nil, err
+		}
+		if value == "none" {
+			value = ""
+		}
+		q = func(t Task) bool { return strings.EqualFold(t.Assignee, value) }
+	case "priority":
+		if value == "none" {
+			value = ""
+		} else if !tracker.IsValidPriority(value) {
+			return nil, fmt.Errorf("invalid priority '%s'; use none, low, medium or high", value)
+		}
+		rank := priorityRank(value)
+		q = func(t Task) bool { return compareWith(op, priorityRank(t.Priority)-rank) }
+	default:
+		date := func(t Task) *time.Time {
+			switch field {
+			case "due":
+				return t.Due
+			case "scheduled":
+				return t.Scheduled
+			case "created":
+				return &t.CreatedAt
+			}
+			return t.CompletedAt
+		}
+		if value == "none" || value == "any" {
+			if err := equalOnly(); err != nil {
+				return nil, err
+			}
+			want := value == "any"
+			q = func(t Task) bool { return (date(t) != nil) == want }
+			return q, nil
+		}
+		day, err := parseDate(value, now)
+		if ago, ok := strings.CutPrefix(value, "-"); err != nil {
+			// A span from now, such as 7d, or before now, such as -2w
+			span, spanErr := parseAge(ago)
+			if spanErr != nil {
+				return nil, fmt.Errorf("%s: %w", field, err)
+			}
+			if ok {
+				span = -span
+			}
+			day, err = now.Add(span), nil
+		}
+		start, end := startOfDay(day), startOfDay(day).AddDate(0, 0, 1)
+		q = func(t Task) bool {
+			d := date(t)
+			if d == nil {
+				return false
+			}
+			switch op {
+			case "<":
+				return d.Before(start)
+			case "<=":
+				return d.Before(end)
+			case ">":
+				return !d.Before(end)
+			case ">=":
+				return !d.Before(start)
+			}
+			return !d.Before(start) && d.Before(end)
+		}
+	}
+	return q, nil
+}
+
+// userFieldTerm matches tasks by one of the user's own fields: by whether
+// they have it with "none" or "any", by number if both values are numbers,
+// and by text, in any case, otherwise.
+func userFieldTerm(field, op, value string) taskQuery {
+	if op == ":" && (value == "none" || value == "any") {
+		want := value == "any"
+		return func(t Task) bool { _, ok := t.Fields[field]; return ok == want }
+	}
+	return func(t Task) bool {
+		have, ok := t.Fields[field]
+		if !ok {
+			return false
+		}
+		have = strings.ToLower(have)
+		a, errA := strconv.ParseFloat(have, 64)
+		b, errB := strconv.ParseFloat(value, 64)
+		if errA == nil && errB == nil {
+			return compareWith(op, cmp.Compare(a, b))
+		}
+		return compareWith(op, strings.Compare(have, value))
+	}
+}
+
+// compareWith reports whether a difference between two values satisfies op.
+func compareWith(op string, diff int) bool {
+	switch op {
+	case "<":
+		return diff < 0
+	case "<=":
+		return diff <= 0
+	case ">":
+		return diff > 0
+	case ">=":
+		return diff >= 0
+	}
+	return diff == 0
+}
+
+// savedFilters returns the named filters of config.json and filters.json.
+func savedFilters() (map[string]string, error) {
+	filters, err := fileFilters()
+	if err != nil {
+		return nil, err
+	}
+	for name, query := range config.Filters {
+		filters[name] = query
+	}
+	return filters, nil
+}
+
+// validateFilters checks the names and queries of the filters in config.json.
+func validateFilters(filters map[string]string, w Workflow) error {
+	for name, query := range filters {
+		if err := checkFilterName(name, w); err != nil {
+			return err
+		}
+		if _, err := parseQuery(query, w, time.Now()); err != nil {
+			return fmt.Errorf("filter '%s': %w", name, err)
+		}
+	}
+	return nil
+}
+
+// checkFilterName checks that name can name a filter: lower case, and not
+// a status, which 'task list' would take it for.
+func checkFilterName(name string, w Workflow) error {
+	if !filterNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid filter name '%s'; use lower-case letters, digits, - and _", name)
+	}
+	if w.HasStatus(name) {
+		return fmt.Errorf("filter name '%s' is a status", name)
+	}
+	return nil
+}
+
+// filterCommand returns the filter command group.
+func filterCommand() *command {
+	return &command{
+		name: "filter", summary: "Save named queries to list tasks by with 'task list <name>'", group: groupTasks,
+		subcommands: []*command{
+			{
+				name: "save", args: "<name> <query>", summary: "Save a query under a name, replacing any saved before", minArgs: 2,
+				setup: run(func(args []string) error {
+					return saveFilter(args[0], strings.Join(args[1:], " "))
+				}),
+			},
+			{
+				name: "list", summary: "List the saved filters",
+				setup: run(func([]string) error { return listFilters() }),
+			},
+			{
+				name: "delete", args: "<name>", summary: "Delete a saved filter", minArgs: 1,
+				complete: positional(filterCandidates),
+				setup: run(func(args []string) error {
+					return deleteFilter(args[0])
+				}),
+			},
+		},
+	}
+}
+
+// filterCandidates offers the names of the saved filters.
+func filterCandidates([]string) []candidate {
+	filters, err := savedFilters()
+	if err != nil {
+		return nil
+	}
+	var cs []candidate
+	for _, name := range sortedKeys(filters) {
+		cs = append(cs, candidate{name, filters[name]})
+	}
+	return cs
+}
+
+// writeFilters saves the filters of filters.json.
+func writeFilters(filters map[string]string) error {
+	data, err := json.MarshalIndent(filters, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling JSON: %w", err)
+	}
+	if err := writeFile(filtersFile, data, 0644); err != nil {
+		return fmt.Errorf("error writing file: %w", err)
+	}
+	return nil
+}
+
+// fileFilters reads the filters of filters.json alone.
+func fileFilters() (map[string]string, error) {
+	filters := map[string]string{}
+	data, err := os.ReadFile(filtersFile)
+	if os.IsNotExist(err) {
+		return filters, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", filtersFile, err)
+	}
+	if err := json.Unmarshal(data, &filters); err != nil {
+		return nil, fmt.Errorf("error unmarshalling %s: %w", filtersFile, err)
+	}
+	return filters, nil
+}
+
+// saveFilter checks query and saves it as name in filters.json.
+func saveFilter(name, query string) error {
+	if err := checkFilterName(name, config.Workflow); err != nil {
+		return usagef("%v", err)
+	}
+	if _, ok := config.Filters[name]; ok {
+		return fmt.Errorf("filter '%s' is set in %s; change it there", name, configFile)
+	}
+	if _, err := parseQuery(query, config.Workflow, clock()); err != nil {
+		return usagef("%v", err)
+	}
+	filters, err := fileFilters()
+	if err != nil {
+		return err
+	}
+	filters[name] = query
+	if err := writeFilters(filters); err != nil {
+		return err
+	}
+	printDone("Filter %s saved; list its tasks with 'task list %s'.\n", name, name)
+	return nil
+}
+
+// deleteFilter removes name from filters.json.
+func deleteFilter(name string) error {
+	if _, ok := config.Filters[name]; ok {
+		return fmt.Errorf("filter '%s' is set in %s; remove it there", name, configFile)
+	}
+	filters, err := fileFilters()
+	if err != nil {
+		return err
+	}
+	if _, ok := filters[name]; !ok {
+		return fmt.Errorf("filter '%s' %w", name, tracker.ErrNotFound)
+	}
+	delete(filters, name)
+	if err := writeFilters(filters); err != nil {
+		return err
+	}
+	printDone("Filter %s deleted.\n", name)
+	return nil
+}
+
+// listFilters prints the saved filters and their queries.
+func listFilters() error {
+	filters, err := savedFilters()
+	if err != nil {
+		return err
+	}
+	if len(filters) == 0 {
+		fmt.Fprintln(stdout, `No saved filters. Save one with: task filter save <name> "status:todo #work"`)
+		return nil
+	}
+	fmt.Fprintln(stdout, "--- Filters ---")
+	for _, name := range sortedKeys(filters) {
+		fmt.Fprintf(stdout, "%-16s %s\n", name, filters[name])
+	}
+	fmt.Fprintln(stdout, "---------------")
+	return nil
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+const (
+	forecastMonths  = 3  // Months forecast without --months.
+	forecastHistory = 3  // Past months that income and other spending are averaged over.
+	maxForecast     = 24 // Months the forecast may reach.
+)
+
+// monthForecast is a projected month: its recurring charges and the
+// income and other spending expected from the months before, by currency.
+type monthForecast struct {
+	month     time.Time
+	income    map[string]float64
+	recurring map[string]float64
+	other     map[string]float64
+}
+
+// forecastCashFlow prints the income, recurring charges and other spending
+// expected in each of the next months across projects, in the base
+// currency, with the money left after each month and the running total.
+// Recurring charges are taken from their schedules; income and other
+// spending are the averages of the last full months, leaving out the
+// charges of recurring expenses. A month projected to spend more than it
+// brings in is warned about.
+func forecastCashFlow(months int, now time.Time) error {
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	historyStart := thisMonth.AddDate(0, -forecastHistory, 0)
+	forecast := make([]monthForecast, months)
+	for i := range forecast {
+		forecast[i] = monthForecast{
+			month:     thisMonth.AddDate(0, i+1, 0),
+			income:    map[string]float64{},
+			recurring: map[string]float64{},
+			other:     map[string]float64{},
+		}
+	}
+	end := forecast[months-1].month.AddDate(0, 1, 0)
+
+	income, other := map[string]float64{}, map[string]float64{}
+	first := thisMonth // The first month with records, if in the history.
+	mixed := false
+	note := func(currency string) {
+		mixed = mixed || currency != "" && currency != config.Currency.Base
+	}
+	err := forEachProject(func(string) error {
+		if _, err := chargeRecurringExpenses(now); err != nil {
+			return err
+		}
+		recurring, err := loadRecurringExpenses()
+		if err != nil {
+			return err
+		}
+		for _, r := range recurring {
+			for next := r.Next; next.Before(end) && (r.Until == nil || !next.After(*r.Until)); next = tracker.NextOccurrence(next, r.Recur) {
+				if i := monthsBetween(thisMonth, next) - 1; i >= 0 {
+					forecast[i].recurring[r.Currency] += r.Amount
+					note(r.Currency)
+				}
+			}
+		}
+
+		received, err := tr().Income()
+		if err != nil {
+			return err
+		}
+		for _, in := range received {
+			if !in.Date.Before(historyStart) && in.Date.Before(thisMonth) {
+				income[in.Currency] += in.Amount
+				first = minTime(first, in.Date)
+				note(in.Currency)
+			}
+		}
+		expenses, err := loadExpenses()
+		if err != nil {
+			return err
+		}
+		for _, e := range expenses {
+			if e.Date.Before(historyStart) || !e.Date.Before(thisMonth) {
+				continue
+			}
+			first = minTime(first, e.Date)
+			if !isRecurringCharge(e, recurring) {
+				other[e.Currency] += e.Amount
+				note(e.Currency)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Average over the months there are records for, so that a short
+	// history is not taken for a quiet one
+	history := float64(max(1, monthsBetween(first, thisMonth)))
+	for i := range forecast {
+		for currency, amount := range income {
+			forecast[i].income[currency] = amount / history
+		}
+		for currency, amount := range other {
+			forecast[i].other[currency] = amount / history
+		}
+	}
+
+	base := config.Currency.Base
+	rates := tracker.Rates{Base: base}
+	if mixed {
+		if base == "" {
+			return fmt.Errorf("set currency.base in %s to total money in different currencies", configFile)
+		}
+		if rates, err = config.Currency.rateProvider().Rates(); err != nil {
+			return err
+		}
+	}
+
+	title := "Forecast"
+	if base != "" {
+		title += " in " + base
+	}
+	fmt.Fprintf(stdout, "--- %s ---\n", title)
+	fmt.Fprintf(stdout, "  %-8s %12s %12s %12s %12s %12s\n", "Month", "Income", "Recurring", "Other", "Net", "Running")
+	running := 0.0
+	var short []string
+	for _, f := range forecast {
+		var amounts [3]float64
+		for i, totals := range []map[string]float64{f.income, f.recurring, f.other} {
+			if amounts[i], err = rates.ConvertTotals(totals, base, base); err != nil {
+				return err
+			}
+		}
+		net := amounts[0] - amounts[1] - amounts[2]
+		running += net
+		label := f.month.Format(monthLayout)
+		if net < 0 {
+			short = append(short, label)
+		}
+		fmt.Fprintf(stdout, "  %-8s %12.2f %12.2f %12.2f %s %s\n", label, amounts[0], amounts[1], amounts[2], signedColumn(net), signedColumn(running))
+	}
+	fmt.Fprintln(stdout, "-----------------")
+	fmt.Fprintf(stdout, "Income and other spending are averaged over %s before %s.\n", plural(int(history), "month"), thisMonth.Format(monthLayout))
+	if !rates.AsOf.IsZero() {
+		fmt.Fprintf(stdout, "Rates as of %s.\n", rates.AsOf.Format(dateLayout))
+	}
+	for _, label := range short {
+		fmt.Fprintf(stdout, "Warning: %s is projected to spend more than it brings in.\n", label)
+	}
+	return nil
+}
+
+// signedColumn formats an amount for a 12 character column, in red if it
+// is negative.
+func signedColumn(amount float64) string {
+	text := fmt.Sprintf("%12.2f", amount)
+	if amount < 0 {
+		return colorize(text, "red")
+	}
+	return text
+}
+
+// isRecurringCharge reports whether e was recorded for one of the
+// recurring expenses.
+func isRecurringCharge(e Expense, recurring []RecurringExpense) bool {
+	for _, r := range recurring {
+		if e.Description == r.Description && e.Amount == r.Amount && e.Currency == r.Currency {
+			return true
+		}
+	}
+	return false
+}
+
+// monthsBetween returns the number of calendar months from the month of a
+// to the month of b.
+func monthsBetween(a, b time.Time) int {
+	return (b.Year()-a.Year())*12 + int(b.Month()) - int(a.Month())
+}
+
+// minTime returns the earlier of a and b.
+func minTime(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+// gitIgnore lists the files kept out of the data repository: the command
+// history, which changes with every command, backups, the lock and the
+// daemon's own files.
+const gitIgnore = "history.json\n*.bak\nbackups/\n.lock\ndaemon.*\n"
+
+// pendingSnapshot holds the commands whose changes the shell or a batch file
+// has not saved yet, and their history numbers.
+var pendingSnapshot struct {
+	messages []string
+	ids      []int
+}
+
+// snapshotMessage describes a command in a commit message. Only the command
+// name is given while the data is encrypted, since arguments can contain the
+// text of tasks.
+func snapshotMessage(project string, args []string) string {
+	if tr().Encrypted() {
+		return args[0]
+	}
+	words := make([]string, len(args))
+	for i, arg := range args {
+		words[i] = shellQuote(arg)
+	}
+	message := strings.Join(words, " ")
+	if project != "" {
+		message += " (project " + project + ")"
+	}
+	return message
+}
+
+// noteChange records a command whose changes the next snapshot commits.
+func noteChange(project string, args []string, id int) {
+	if !config.History.Git {
+		return
+	}
+	pendingSnapshot.messages = append(pendingSnapshot.messages, snapshotMessage(project, args))
+	if id > 0 {
+		pendingSnapshot.ids = append(pendingSnapshot.ids, id)
+	}
+}
+
+// dropSnapshot forgets the commands whose changes were rolled back.
+func dropSnapshot() {
+	pendingSnapshot.messages, pendingSnapshot.ids = nil, nil
+}
+
+// commitSession saves the changes held by the shell or a batch file,
+// snapshots them and sends their webhooks.
+func commitSession() error {
+	if err := baseTracker.Commit(); err != nil {
+		return err
+	}
+	snapshotData()
+	sendWebhooks()
+	return nil
+}
+
+// snapshotData commits the data directory to its git repository, creating
+// it if needed, with the commands noted since the last snapshot as the
+// message, and pushes it if configured. Failures are warnings: the data
+// itself was saved.
+func snapshotData() {
+	messages, ids := pendingSnapshot.messages, pendingSnapshot.ids
+	dropSnapshot()
+	if !config.History.Git || len(messages) == 0 {
+		return
+	}
+	commit, err := gitCommitData(strings.Join(messages, "; "))
+	if err != nil {
+		fmt.Fprintf(stdout, "Warning: could not commit the data to git: %v\n", err)
+		return
+	}
+	if commit == "" {
+		return
+	}
+	if err := setHistoryCommit(ids, commit); err != nil {
+		fmt.Fprintf(stdout, "Warning: could not record history: %v\n", err)
+	}
+	if config.History.Push {
+		if _, err := git("push", "--quiet"); err != nil {
+			fmt.Fprintf(stdout, "Warning: could not push the data: %v\n", err)
+		}
+	}
+}
+
+// gitCommitData commits every change in the data directory and returns the
+// commit, or "" if nothing changed.
+func gitCommitData(message string) (string, error) {
+	if _, err := os.Stat(".git"); os.IsNotExist(err) {
+		if _, err := git("init", "--quiet"); err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(".gitignore", []byte(gitIgnore), 0644); err != nil {
+			return "", fmt.Errorf("error writing file: %w", err)
+		}
+		// Commits need an author; fall back to one for this repository
+		if _, err := git("config", "user.email"); err != nil {
+			git("config", "user.name", "task")
+			git("config", "user.email", "task@localhost")
+		}
+	}
+	if _, err := git("add", "--all", "."); err != nil {
+		return "", err
+	}
+	if _, err := git("diff", "--cached", "--quiet"); err == nil {
+		return "", nil
+	}
+	// Commits are authored by the user in config.json, when set
+	commit := []string{"commit", "--quiet", "--message", message}
+	if config.User != "" {
+		commit = append([]string{"-c", "user.name=" + config.User}, commit...)
+	}
+	if _, err := git(commit...); err != nil {
+		return "", err
+	}
+	return git("rev-parse", "HEAD")
+}
+
+// git runs a git command in the data directory and returns its output.
+func git(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && stderr.Len() > 0 {
+			return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(stderr.String()))
+		}
+		return "", fmt.Errorf("error running git: %w", err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// setHistoryCommit records the commit holding the changes of history
+// entries.
+func setHistoryCommit(ids []int, commit string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	entries, err := loadHistory()
+	if err != nil {
+		return err
+	}
+	for i := range entries {
+		if slices.Contains(ids, entries[i].ID) {
+			entries[i].Commit = commit
+		}
+	}
+	return saveHistory(entries)
+}
+
+// diffHistory shows the changes a history entry made to the data files.
+func diffHistory(id int) error {
+	entries, err := loadHistory()
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(entries, func(e HistoryEntry) bool { return e.ID == id })
+	if i < 0 {
+		return fmt.Errorf("history entry %d %w", id, tracker.ErrNotFound)
+	}
+	if entries[i].Commit == "" {
+		if !config.History.Git {
+			return errors.New("no changes recorded; set history.git in config.json to keep them")
+		}
+		return fmt.Errorf("history entry %d changed no data files", id)
+	}
+
+	cmd := exec.Command("git", "show", "--stat", "--patch", entries[i].Commit)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error running git: %w", err)
+	}
+	return nil
+}
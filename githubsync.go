@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	githubSyncFile = "github.json"  // Issue states as of the last GitHub sync, per project.
+	githubTokenEnv = "GITHUB_TOKEN" // Used when sync.github.token is not set.
+	githubPageSize = 100
+)
+
+var githubRepoPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+$`)
+
+// GitHubSync configures 'task sync github'.
+type GitHubSync struct {
+	Repo  string `json:"repo,omitempty"`  // owner/name, unless --repo is given.
+	Token string `json:"token,omitempty"` // May name a secret; $GITHUB_TOKEN if empty.
+}
+
+// githubIssue is an issue as the GitHub API returns it.
+type githubIssue struct {
+	Number      int                     `json:"number"`
+	Title       string                  `json:"title"`
+	State       string                  `json:"state"`
+	HTMLURL     string                  `json:"html_url"`
+	Labels      []struct{ Name string } `json:"labels"`
+	PullRequest json.RawMessage         `json:"pull_request,omitempty"`
+}
+
+// githubSyncState remembers, by task source, whether each issue was closed
+// at the last sync, to tell which side changed it since.
+type githubSyncState struct {
+	Closed map[string]bool `json:"closed"`
+}
+
+// githubSource identifies the task imported from an issue.
+func githubSource(repo string, number int) string {
+	return fmt.Sprintf("github:%s#%d", repo, number)
+}
+
+// githubClient calls the GitHub REST API with a token.
+type githubClient struct {
+	token string
+}
+
+// do sends a request to the API and decodes the response into out, if set.
+func (c githubClient) do(method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("error marshalling JSON: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, githubAPI+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	client := &http.Client{Timeout: syncTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error contacting GitHub: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var apiErr struct{ Message string }
+		json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&apiErr)
+		return fmt.Errorf("GitHub answered %s to %s %s: %s", resp.Status, method, path, cmp.Or(apiErr.Message, "no details"))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxSyncDocument)).Decode(out); err != nil {
+		return fmt.Errorf("error reading GitHub's response: %w", err)
+	}
+	return nil
+}
+
+// assignedIssues returns the open issues of repo assigned to login, leaving
+// out pull requests.
+func (c githubClient) assignedIssues(repo, login string) ([]githubIssue, error) {
+	var issues []githubIssue
+	for page := 1; ; page++ {
+		var batch []githubIssue
+		path := fmt.Sprintf("/repos/%s/issues?state=open&assignee=%s&per_page=%d&page=%d", repo, url.QueryEscape(login), githubPageSize, page)
+		if err := c.do(http.MethodGet, path, nil, &batch); err != nil {
+			return nil, err
+		}
+		for _, issue := range batch {
+			if issue.PullRequest == nil {
+				issues = append(issues, issue)
+			}
+		}
+		if len(batch) < githubPageSize {
+			return issues, nil
+		}
+	}
+}
+
+// syncGitHub imports the open issues of repo assigned to you as tasks, with
+// their labels as tags and their URL, and keeps the issues and tasks open
+// or closed together: a task completed here closes its issue, an issue
+// closed on GitHub completes its task, and reopening either reopens the
+// other.
+func syncGitHub(repo string) error {
+	repo = cmp.Or(repo, config.Sync.GitHub.Repo)
+	if repo == "" {
+		return usagef("no repository; pass --repo owner/name or set sync.github.repo in %s", configFile)
+	}
+	if !githubRepoPattern.MatchString(repo) {
+		return usagef("invalid repository '%s'; use owner/name", repo)
+	}
+	token, err := resolveSecret(config.Sync.GitHub.Token)
+	if err != nil {
+		return err
+	}
+	token = cmp.Or(token, os.Getenv(githubTokenEnv))
+	if token == "" {
+		return fmt.Errorf("no GitHub token; set sync.github.token in %s or $%s", configFile, githubTokenEnv)
+	}
+	client := githubClient{token: token}
+
+	var user struct{ Login string }
+	if err := client.do(http.MethodGet, "/user", nil, &user); err != nil {
+		return err
+	}
+	issues, err := client.assignedIssues(repo, user.Login)
+	if err != nil {
+		return err
+	}
+
+	var state githubSyncState
+	if err := loadStateDocument(githubSyncFile, &state); err != nil {
+		return err
+	}
+	if state.Closed == nil {
+		state.Closed = map[string]bool{}
+	}
+
+	tasks, err := loadTasks()
+	if err != nil {
+		return err
+	}
+	now := clock()
+	added, updated := 0, 0
+	open := map[string]bool{}
+	for _, issue := range issues {
+		source := githubSource(repo, issue.Number)
+		open[source] = true
+		var tags []string
+		for _, label := range issue.Labels {
+			if tag := normalizeTag(label.Name); tag != "" && !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+
+		i := slices.IndexFunc(tasks, func(t Task) bool { return t.Source == source })
+		if i < 0 {
+			tasks = append(tasks, Task{
+				ID:          getNextID(tasks),
+				Description: issue.Title,
+				Status:      config.Workflow.Initial(),
+				Tags:        tags,
+				URL:         issue.HTMLURL,
+				Source:      source,
+				CreatedAt:   now,
+				UpdatedAt:   now,
+			})
+			state.Closed[source] = false
+			added++
+			continue
+		}
+		task := &tasks[i]
+		changed := task.Description != issue.Title || task.URL != issue.HTMLURL
+		task.Description, task.URL = issue.Title, issue.HTMLURL
+		for _, tag := range tags {
+			if !slices.Contains(task.Tags, tag) {
+				task.Tags = append(task.Tags, tag)
+				changed = true
+			}
+		}
+		if changed {
+			task.UpdatedAt = now
+			updated++
+		}
+	}
+	if added+updated > 0 {
+		if err := saveTasks(tasks); err != nil {
+			return err
+		}
+	}
+
+	// Keep each tracked issue open or closed with its task
+	prefix := "github:" + repo + "#"
+	closedHere, reopenedHere, completed, reopened := 0, 0, 0, 0
+	for _, task := range tasks {
+		number, ok := strings.CutPrefix(task.Source, prefix)
+		if !ok {
+			continue
+		}
+		issueOpen := open[task.Source]
+		if !issueOpen {
+			// Not assigned and open: look it up to tell closed from reassigned
+			var issue githubIssue
+			if err := client.do(http.MethodGet, "/repos/"+repo+"/issues/"+number, nil, &issue); err != nil {
+				fmt.Fprintf(stdout, "Warning: could not check issue #%s: %v\n", number, err)
+				continue
+			}
+			issueOpen = issue.State == "open"
+		}
+		done, wasClosed := config.Workflow.IsDone(task.Status), state.Closed[task.Source]
+
+		var err error
+		switch {
+		case done && issueOpen && !wasClosed:
+			if err = client.do(http.MethodPatch, "/repos/"+repo+"/issues/"+number, map[string]string{"state": "closed"}, nil); err == nil {
+				issueOpen = false
+				closedHere++
+			}
+		case !done && !issueOpen && wasClosed:
+			if err = client.do(http.MethodPatch, "/repos/"+repo+"/issues/"+number, map[string]string{"state": "open"}, nil); err == nil {
+				issueOpen = true
+				reopenedHere++
+			}
+		case !done && !issueOpen:
+			if _, err = tr().SetStatus(task.ID, config.Workflow.DoneStatus()); err == nil {
+				completed++
+			}
+		case done && issueOpen:
+			if _, err = tr().SetStatus(task.ID, config.Workflow.Initial()); err == nil {
+				reopened++
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(stdout, "Warning: could not sync issue #%s with task ID %d: %v\n", number, task.ID, err)
+			continue
+		}
+		state.Closed[task.Source] = !issueOpen
+	}
+	if err := saveDocument(githubSyncFile, state); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "Synced %s: %s added, %d updated; %s closed and %d reopened; %s completed and %d reopened.\n",
+		repo, plural(added, "task"), updated, plural(closedHere, "issue"), reopenedHere, plural(completed, "task"), reopened)
+	return nil
+}
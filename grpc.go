@@ -0,0 +1,489 @@
+package main
+
+import (
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+// maxGRPCRequest limits the size of a gRPC request message.
+const maxGRPCRequest = 1 << 20
+
+// gRPC status codes returned by the services.
+const (
+	grpcOK                 = 0
+	grpcInvalidArgument    = 3
+	grpcNotFound           = 5
+	grpcFailedPrecondition = 9
+	grpcUnimplemented      = 12
+	grpcInternal           = 13
+)
+
+// grpcError is an error returned to a gRPC client with its status code.
+type grpcError struct {
+	code    int
+	message string
+}
+
+func (e *grpcError) Error() string { return e.message }
+
+// grpcErrorf returns a grpcError with a formatted message.
+func grpcErrorf(code int, format string, args ...any) error {
+	return &grpcError{code, fmt.Sprintf(format, args...)}
+}
+
+// grpcMethod handles a call of a gRPC method: it decodes the request
+// message and sends each response message, one for a unary method.
+type grpcMethod func(req protoFields, send func(protoMessage) error) error
+
+// grpcServices lists the services defined in tracker.proto.
+var grpcServices = []string{"tracker.v1.TaskService", "tracker.v1.ExpenseService"}
+
+// grpcMethods maps the paths of the methods in tracker.proto to their
+// handlers.
+var grpcMethods = map[string]grpcMethod{
+	"/tracker.v1.TaskService/ListTasks":       grpcListTasks,
+	"/tracker.v1.TaskService/GetTask":         grpcGetTask,
+	"/tracker.v1.TaskService/AddTask":         grpcAddTask,
+	"/tracker.v1.TaskService/MarkTask":        grpcMarkTask,
+	"/tracker.v1.TaskService/DeleteTask":      grpcDeleteTask,
+	"/tracker.v1.ExpenseService/ListExpenses": grpcListExpenses,
+	"/tracker.v1.ExpenseService/GetExpense":   grpcGetExpense,
+	"/tracker.v1.ExpenseService/AddExpense":   grpcAddExpense,
+}
+
+// grpcWrites holds the paths of the methods that change the data.
+var grpcWrites = map[string]bool{
+	"/tracker.v1.TaskService/AddTask":       true,
+	"/tracker.v1.TaskService/MarkTask":      true,
+	"/tracker.v1.TaskService/DeleteTask":    true,
+	"/tracker.v1.ExpenseService/AddExpense": true,
+}
+
+// handleGRPC serves a gRPC call over HTTP/2, sending its status in the
+// trailers. Compressed requests are not supported.
+func handleGRPC(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/grpc")
+	err := serveGRPC(w, r)
+
+	code, message := grpcOK, ""
+	if err != nil {
+		code, message = grpcCode(err), err.Error()
+	}
+	w.Header().Set(http.TrailerPrefix+"Grpc-Status", strconv.Itoa(code))
+	if message != "" {
+		w.Header().Set(http.TrailerPrefix+"Grpc-Message", url.PathEscape(message))
+	}
+}
+
+// grpcCode returns the gRPC status code for an error, matching the HTTP
+// status the JSON endpoints respond with for it.
+func grpcCode(err error) int {
+	var gerr *grpcError
+	if errors.As(err, &gerr) {
+		return gerr.code
+	}
+	switch code, _ := classifyError(err); code {
+	case "validation":
+		return grpcInvalidArgument
+	case "not_found":
+		return grpcNotFound
+	case "conflict":
+		return grpcFailedPrecondition
+	}
+	return grpcInternal
+}
+
+// serveGRPC reads the request message of a gRPC call and runs its method.
+func serveGRPC(w http.ResponseWriter, r *http.Request) error {
+	method, ok := grpcMethods[r.URL.Path]
+	if !ok {
+		return grpcErrorf(grpcUnimplemented, "unknown method %s", r.URL.Path)
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxGRPCRequest+5))
+	if err != nil {
+		return grpcErrorf(grpcInvalidArgument, "error reading request: %v", err)
+	}
+	if len(body) < 5 || int(binary.BigEndian.Uint32(body[1:5])) != len(body)-5 {
+		return grpcErrorf(grpcInvalidArgument, "malformed request message")
+	}
+	if body[0] != 0 {
+		return grpcErrorf(grpcUnimplemented, "compressed messages are not supported")
+	}
+	req, err := decodeProto(body[5:])
+	if err != nil {
+		return grpcErrorf(grpcInvalidArgument, "malformed request message: %v", err)
+	}
+
+	serveMu.Lock()
+	defer serveMu.Unlock()
+
+	if grpcWrites[r.URL.Path] {
+		defer sendChanges(r)
+	}
+	rc := http.NewResponseController(w)
+	return method(req, func(m protoMessage) error {
+		frame := binary.BigEndian.AppendUint32([]byte{0}, uint32(len(m)))
+		if _, err := w.Write(append(frame, m...)); err != nil {
+			return err
+		}
+		return rc.Flush()
+	})
+}
+
+// inProjects calls fn with the named project selected, or with each
+// project in turn if name is empty.
+func inProjects(name string, fn func(project string) error) error {
+	if name == "" {
+		return forEachProject(fn)
+	}
+	saved := currentProject
+	defer func() { currentProject = saved }()
+	if err := selectProject(name); err != nil {
+		if errors.Is(err, tracker.ErrInvalid) {
+			return grpcErrorf(grpcInvalidArgument, "%v", err)
+		}
+		return grpcErrorf(grpcNotFound, "%v", err)
+	}
+	return fn(name)
+}
+
+// grpcListTasks streams the tasks of a project, or of every project, that
+// match the query in the request.
+func grpcListTasks(req protoFields, send func(protoMessage) error) error {
+	q, err := parseQuery(req.string(1), config.Workflow, clock())
+	if err != nil {
+		return grpcErrorf(grpcInvalidArgument, "invalid query: %v", err)
+	}
+	return inProjects(req.string(2), func(project string) error {
+		tasks, err := loadTasks()
+		if err != nil {
+			return err
+		}
+		for _, task := range tasks {
+			if !q.matches(task) {
+				continue
+			}
+			if err := send(taskProto(apiTask{task, project})); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// grpcGetTask returns the task with the UUID in the request.
+func grpcGetTask(req protoFields, send func(protoMessage) error) error {
+	tasks, err := allTasks()
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		if task.UUID == req.string(1) {
+			return send(taskProto(task))
+		}
+	}
+	return grpcErrorf(grpcNotFound, "task not found")
+}
+
+// grpcAddTask adds a task with the description in the request to its
+// project, or the default project, and returns it.
+func grpcAddTask(req protoFields, send func(protoMessage) error) error {
+	description := strings.TrimSpace(req.string(1))
+	if description == "" {
+		return grpcErrorf(grpcInvalidArgument, "expected a description")
+	}
+	return inProjects(cmp.Or(req.string(2), defaultProject), func(project string) error {
+		task, err := tr().AddTask(description)
+		if err != nil {
+			return err
+		}
+		return send(taskProto(apiTask{task, project}))
+	})
+}
+
+// inTaskProjectOf calls fn with the project of the task with a UUID
+// selected, as inTaskProject does for the JSON endpoints.
+func inTaskProjectOf(uuid string, fn func(task apiTask) error) error {
+	tasks, err := allTasks()
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		if task.UUID == uuid {
+			return inProjects(cmp.Or(task.Project, defaultProject), func(string) error { return fn(task) })
+		}
+	}
+	return grpcErrorf(grpcNotFound, "task not found")
+}
+
+// grpcMarkTask moves the task with the UUID in the request to the status in
+// it, awarding points when it is completed, and returns the task.
+func grpcMarkTask(req protoFields, send func(protoMessage) error) error {
+	status := req.string(2)
+	if err := checkStatus(status); err != nil {
+		return err
+	}
+	return inTaskProjectOf(req.string(1), func(task apiTask) error {
+		change, err := tr().SetStatus(task.ID, status)
+		if err == nil && change.Completed {
+			err = awardPoints(change.Task)
+		}
+		if err != nil {
+			return err
+		}
+		return send(taskProto(apiTask{change.Task, task.Project}))
+	})
+}
+
+// grpcDeleteTask deletes the task with the UUID in the request.
+func grpcDeleteTask(req protoFields, send func(protoMessage) error) error {
+	return inTaskProjectOf(req.string(1), func(task apiTask) error {
+		if err := tr().DeleteTask(task.ID); err != nil {
+			return err
+		}
+		return send(nil)
+	})
+}
+
+// grpcListExpenses streams the expenses of a project, or of every project,
+// in the category in the request if it has one.
+func grpcListExpenses(req protoFields, send func(protoMessage) error) error {
+	category := req.string(2)
+	return inProjects(req.string(1), func(project string) error {
+		expenses, err := loadExpenses()
+		if err != nil {
+			return err
+		}
+		for _, e := range expenses {
+			if category != "" && e.Category != category {
+				continue
+			}
+			if err := send(expenseProto(e, project)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// grpcGetExpense returns the expense of a project with the ID in the request.
+func grpcGetExpense(req protoFields, send func(protoMessage) error) error {
+	project := cmp.Or(req.string(1), defaultProject)
+	return inProjects(project, func(string) error {
+		expenses, err := loadExpenses()
+		if err != nil {
+			return err
+		}
+		id := int(int32(req.int(2)))
+		for _, e := range expenses {
+			if e.ID == id {
+				return send(expenseProto(e, project))
+			}
+		}
+		return grpcErrorf(grpcNotFound, "expense %d not found", id)
+	})
+}
+
+// grpcAddExpense records the expense in the request in its project, or the
+// default project, categorized by the rules if it has no category, and
+// returns it.
+func grpcAddExpense(req protoFields, send func(protoMessage) error) error {
+	e, err := requestedExpense(req.double(1), req.string(2), req.string(3), req.string(4), req.string(5))
+	if err != nil {
+		return grpcErrorf(grpcInvalidArgument, "%v", err)
+	}
+	return inProjects(cmp.Or(req.string(6), defaultProject), func(project string) error {
+		applyCategoryRules(&e)
+		added, err := tr().AddExpense(e)
+		if err != nil {
+			return err
+		}
+		return send(expenseProto(added, project))
+	})
+}
+
+// taskProto encodes a task as a tracker.v1.Task message.
+func taskProto(t apiTask) protoMessage {
+	var m protoMessage
+	m.int(1, t.ID)
+	m.string(2, t.UUID)
+	m.string(3, t.Project)
+	m.string(4, t.Description)
+	m.string(5, t.Status)
+	m.string(6, t.Priority)
+	m.time(7, t.Due)
+	m.time(8, t.Scheduled)
+	m.string(9, t.Recur)
+	m.string(10, t.Assignee)
+	m.double(11, t.Estimate)
+	for _, tag := range t.Tags {
+		m.message(12, protoMessage(tag))
+	}
+	for _, uuid := range t.WaitsOn {
+		m.message(20, protoMessage(uuid))
+	}
+	m.stringMap(14, t.Fields)
+	m.string(15, t.URL)
+	m.time(16, t.StartedAt)
+	m.time(17, t.CompletedAt)
+	m.time(18, &t.CreatedAt)
+	m.time(19, &t.UpdatedAt)
+	return m
+}
+
+// expenseProto encodes an expense of a project as a tracker.v1.Expense
+// message.
+func expenseProto(e Expense, project string) protoMessage {
+	var m protoMessage
+	m.int(1, e.ID)
+	m.string(2, project)
+	m.time(3, &e.Date)
+	m.double(4, e.Amount)
+	m.string(5, e.Currency)
+	m.string(6, e.Description)
+	m.string(7, e.Category)
+	m.string(8, e.Payee)
+	m.string(9, e.Task)
+	m.string(10, e.Account)
+	m.time(11, e.Cleared)
+	m.stringMap(12, e.Fields)
+	m.time(13, &e.CreatedAt)
+	return m
+}
+
+// Protobuf wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// protoMessage is a protobuf message being encoded. As in proto3, fields
+// with zero values are left out.
+type protoMessage []byte
+
+func (m *protoMessage) tag(field, wire int) {
+	*m = binary.AppendUvarint(*m, uint64(field<<3|wire))
+}
+
+func (m *protoMessage) int(field, v int) {
+	if v != 0 {
+		m.tag(field, wireVarint)
+		*m = binary.AppendUvarint(*m, uint64(int64(v)))
+	}
+}
+
+func (m *protoMessage) double(field int, v float64) {
+	if v != 0 {
+		m.tag(field, wireFixed64)
+		*m = binary.LittleEndian.AppendUint64(*m, math.Float64bits(v))
+	}
+}
+
+func (m *protoMessage) string(field int, s string) {
+	if s != "" {
+		m.message(field, protoMessage(s))
+	}
+}
+
+// message appends a length-delimited field, even if empty.
+func (m *protoMessage) message(field int, sub protoMessage) {
+	m.tag(field, wireBytes)
+	*m = binary.AppendUvarint(*m, uint64(len(sub)))
+	*m = append(*m, sub...)
+}
+
+// time appends a google.protobuf.Timestamp, unless t is nil.
+func (m *protoMessage) time(field int, t *time.Time) {
+	if t == nil {
+		return
+	}
+	var ts protoMessage
+	ts.int(1, int(t.Unix()))
+	ts.int(2, t.Nanosecond())
+	m.message(field, ts)
+}
+
+// stringMap appends a map<string, string> field, its entries sorted by key.
+func (m *protoMessage) stringMap(field int, values map[string]string) {
+	for _, key := range sortedKeys(values) {
+		var entry protoMessage
+		entry.string(1, key)
+		entry.string(2, values[key])
+		m.message(field, entry)
+	}
+}
+
+// protoFields holds the decoded fields of a protobuf message, varints as
+// numbers, 64-bit fields as doubles (the only such type tracker.proto uses)
+// and length-delimited fields as strings, by field number. The last
+// occurrence of a field wins.
+type protoFields map[int]any
+
+func (f protoFields) string(field int) string {
+	s, _ := f[field].(string)
+	return s
+}
+
+func (f protoFields) int(field int) uint64 {
+	v, _ := f[field].(uint64)
+	return v
+}
+
+func (f protoFields) double(field int) float64 {
+	v, _ := f[field].(float64)
+	return v
+}
+
+// decodeProto decodes the fields of a protobuf message. 32-bit fields are
+// skipped.
+func decodeProto(b []byte) (protoFields, error) {
+	fields := protoFields{}
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil, errors.New("invalid field tag")
+		}
+		b = b[n:]
+		field := int(key >> 3)
+		switch key & 7 {
+		case wireVarint:
+			v, n := binary.Uvarint(b)
+			if n <= 0 {
+				return nil, fmt.Errorf("invalid varint in field %d", field)
+			}
+			fields[field], b = v, b[n:]
+		case wireBytes:
+			size, n := binary.Uvarint(b)
+			if n <= 0 || size > uint64(len(b)-n) {
+				return nil, fmt.Errorf("invalid length of field %d", field)
+			}
+			fields[field], b = string(b[n:n+int(size)]), b[n+int(size):]
+		case wireFixed64:
+			if len(b) < 8 {
+				return nil, fmt.Errorf("truncated field %d", field)
+			}
+			fields[field], b = math.Float64frombits(binary.LittleEndian.Uint64(b)), b[8:]
+		case wireFixed32:
+			if len(b) < 4 {
+				return nil, fmt.Errorf("truncated field %d", field)
+			}
+			b = b[4:]
+		default:
+			return nil, fmt.Errorf("unsupported wire type %d in field %d", key&7, field)
+		}
+	}
+	return fields, nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import "testing"
+
+// callGRPC calls a gRPC method with a request encoded by build, returning
+// the decoded response messages.
+func callGRPC(t *testing.T, path string, build func(m *protoMessage)) ([]protoFields, error) {
+	t.Helper()
+	var m protoMessage
+	build(&m)
+	req, err := decodeProto(m)
+	if err != nil {
+		t.Fatalf("decoding the request: %v", err)
+	}
+	var resp []protoFields
+	err = grpcMethods[path](req, func(m protoMessage) error {
+		fields, err := decodeProto(m)
+		resp = append(resp, fields)
+		return err
+	})
+	return resp, err
+}
+
+func TestGRPCWrites(t *testing.T) {
+	setupCLI(t)
+	resp, err := callGRPC(t, "/tracker.v1.TaskService/AddTask", func(m *protoMessage) { m.string(1, "Buy milk") })
+	if err != nil || len(resp) != 1 || resp[0].string(4) != "Buy milk" {
+		t.Fatalf("AddTask = %v, %v", resp, err)
+	}
+	uuid := resp[0].string(2)
+
+	resp, err = callGRPC(t, "/tracker.v1.TaskService/MarkTask", func(m *protoMessage) {
+		m.string(1, uuid)
+		m.string(2, "done")
+	})
+	if err != nil || resp[0].string(5) != "done" {
+		t.Errorf("MarkTask = %v, %v", resp, err)
+	}
+	_, err = callGRPC(t, "/tracker.v1.TaskService/MarkTask", func(m *protoMessage) {
+		m.string(1, uuid)
+		m.string(2, "bogus")
+	})
+	if grpcCode(err) != grpcInvalidArgument {
+		t.Errorf("marking with an unknown status: %v, code %d", err, grpcCode(err))
+	}
+
+	if _, err := callGRPC(t, "/tracker.v1.TaskService/DeleteTask", func(m *protoMessage) { m.string(1, uuid) }); err != nil {
+		t.Errorf("DeleteTask: %v", err)
+	}
+	_, err = callGRPC(t, "/tracker.v1.TaskService/DeleteTask", func(m *protoMessage) { m.string(1, uuid) })
+	if grpcCode(err) != grpcNotFound {
+		t.Errorf("deleting again: %v, code %d", err, grpcCode(err))
+	}
+
+	resp, err = callGRPC(t, "/tracker.v1.ExpenseService/AddExpense", func(m *protoMessage) {
+		m.double(1, 4.5)
+		m.string(2, "Coffee")
+		m.string(5, "yesterday")
+	})
+	if err != nil || resp[0].double(4) != 4.5 || resp[0].string(6) != "Coffee" {
+		t.Fatalf("AddExpense = %v, %v", resp, err)
+	}
+	expenses, err := loadExpenses()
+	if err != nil || len(expenses) != 1 || expenses[0].Date.Format(dateLayout) != "2025-03-09" {
+		t.Errorf("expenses = %+v, %v", expenses, err)
+	}
+}
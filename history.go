@@ -0,0 +1,251 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+const (
+	historyFile        = "history.json" // Commands run, shared by all projects.
+	defaultHistorySize = 500            // Entries kept unless configured.
+	historyListLimit   = 20             // Entries shown by default.
+)
+
+// History configures the command history.
+type History struct {
+	Disabled bool `json:"disabled,omitempty"`
+	Size     int  `json:"size,omitempty"` // Entries kept; 500 if unset.
+
+	// Git commits the data directory to a local git repository after every
+	// command that changes it, and Push pushes each commit to its remote.
+	Git  bool `json:"git,omitempty"`
+	Push bool `json:"push,omitempty"`
+}
+
+// HistoryEntry is a command that was run.
+type HistoryEntry struct {
+	ID      int       `json:"id"`
+	Args    []string  `json:"args"`
+	Project string    `json:"project,omitempty"` // The --project flag it was run with.
+	Actor   string    `json:"actor,omitempty"`   // The user in config.json who ran it.
+	RanAt   time.Time `json:"ranAt"`
+	Commit  string    `json:"commit,omitempty"` // Holding its changes, with history.git.
+}
+
+// unrecorded lists the commands that are not recorded in the history.
+var unrecorded = []string{"history", "repeat", "!!", "help", "completion", "__complete", "shell", "dash", "watch", "begin", "commit", "rollback"}
+
+// executeAndRecord runs args and records them in the history and, with
+// history.git, commits their changes; those of the shell and batch files
+// are committed when saved. Commands rejected as invalid are not recorded.
+func executeAndRecord(root *command, project string, args []string) error {
+	err := execute(root, args)
+	var uerr *usageError
+	asksHelp := slices.ContainsFunc(args, func(arg string) bool { return isHelp(arg) && arg != "help" })
+	if errors.As(err, &uerr) || len(args) == 0 || slices.Contains(unrecorded, args[0]) || asksHelp {
+		return err
+	}
+	id, herr := recordHistory(project, args, clock())
+	if herr != nil {
+		fmt.Fprintf(stdout, "Warning: could not record history: %v\n", herr)
+	}
+	noteChange(project, args, id)
+	if session == "" {
+		snapshotData()
+		sendWebhooks()
+	}
+	return err
+}
+
+// recordHistory appends a command to the history unless the history is
+// disabled. It returns the number of the entry, or 0 if none was recorded.
+func recordHistory(project string, args []string, now time.Time) (int, error) {
+	if config.History.Disabled {
+		return 0, nil
+	}
+	entries, err := loadHistory()
+	if err != nil {
+		return 0, err
+	}
+
+	id := 1
+	if len(entries) > 0 {
+		id = entries[len(entries)-1].ID + 1
+	}
+	entries = append(entries, HistoryEntry{ID: id, Args: args, Project: project, Actor: config.User, RanAt: now})
+	size := config.History.Size
+	if size == 0 {
+		size = defaultHistorySize
+	}
+	if len(entries) > size {
+		entries = entries[len(entries)-size:]
+	}
+	return id, saveHistory(entries)
+}
+
+// historyTracker returns the tracker for the history file, which is kept
+// with the default project's data and encrypted along with it. Entries are
+// saved at once, not held with the changes of the shell or a batch file.
+func historyTracker() *tracker.Tracker {
+	return tr().At(projectDir(defaultProject)).Unbuffered()
+}
+
+// loadHistory reads the history file.
+func loadHistory() ([]HistoryEntry, error) {
+	raw, err := historyTracker().ReadDocument(historyFile)
+	if err != nil || raw == nil {
+		return nil, err
+	}
+
+	var entries []HistoryEntry
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, fmt.Errorf("error unmarshalling %s: %w", historyFile, err)
+	}
+	return entries, nil
+}
+
+// saveHistory writes the history file.
+func saveHistory(entries []HistoryEntry) error {
+	return historyTracker().WriteDocument(historyFile, entries)
+}
+
+// commandLine renders an entry as it would be typed.
+func (e HistoryEntry) commandLine() string {
+	words := []string{"task"}
+	if e.Project != "" {
+		words = append(words, "--project", e.Project)
+	}
+	for _, arg := range e.Args {
+		words = append(words, shellQuote(arg))
+	}
+	return strings.Join(words, " ")
+}
+
+// shellQuote quotes s for a POSIX shell if it needs it.
+func shellQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\n'\"\\$`!*?&|;<>()[]{}#~") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// historyCommand returns the history command.
+func historyCommand() *command {
+	return &command{
+		name: "history", args: "[diff <number>]", summary: "Show recently run commands, or the changes one made", group: groupData,
+		complete: positional(fixed("diff"), historyNumbers),
+		setup: func(fs *flag.FlagSet) runFunc {
+			limit := fs.Int("limit", historyListLimit, "number of commands to show")
+			clearAll := fs.Bool("clear", false, "forget all recorded commands")
+			return func(args []string) error {
+				if len(args) > 0 {
+					if args[0] != "diff" || len(args) != 2 {
+						return usagef("expected diff <number>")
+					}
+					id, err := parseID(args[1], "history")
+					if err != nil {
+						return err
+					}
+					return diffHistory(id)
+				}
+				if *clearAll {
+					if err := removeFile(historyFile); err != nil {
+						return fmt.Errorf("error removing %s: %w", historyFile, err)
+					}
+					printDone("History cleared.\n")
+					return nil
+				}
+				if *limit < 1 {
+					return usagef("invalid limit %d", *limit)
+				}
+				return listHistory(*limit)
+			}
+		},
+	}
+}
+
+// listHistory prints the last limit commands, oldest first.
+func listHistory(limit int) error {
+	entries, err := loadHistory()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		if config.History.Disabled {
+			fmt.Fprintln(stdout, "No history recorded; it is disabled in config.json.")
+		} else {
+			fmt.Fprintln(stdout, "No history recorded yet.")
+		}
+		return nil
+	}
+
+	fmt.Fprintln(stdout, "--- History ---")
+	for _, e := range entries[max(0, len(entries)-limit):] {
+		command := e.commandLine()
+		if e.Actor != "" {
+			command = e.Actor + ": " + command
+		}
+		line := fmt.Sprintf("%5d  %s  %s", e.ID, e.RanAt.Format("2006-01-02 15:04"), command)
+		if e.Commit != "" {
+			line += fmt.Sprintf("  [%.7s]", e.Commit)
+		}
+		fmt.Fprintln(stdout, line)
+	}
+	fmt.Fprintln(stdout, "---------------")
+	return nil
+}
+
+// repeatCommand returns a command that runs the last recorded command, or
+// the one with the history number given.
+func repeatCommand(root *command, name string, hidden bool) *command {
+	return &command{
+		name: name, args: "[history number]", summary: "Run the last command again, or the one numbered in 'task history'", group: groupData, hidden: hidden,
+		complete: positional(historyNumbers),
+		setup: run(func(args []string) error {
+			entries, err := loadHistory()
+			if err != nil {
+				return err
+			}
+			if len(entries) == 0 {
+				return errors.New("no commands in history")
+			}
+
+			entry := entries[len(entries)-1]
+			if len(args) > 0 {
+				id, err := parseID(args[0], "history")
+				if err != nil {
+					return err
+				}
+				i := slices.IndexFunc(entries, func(e HistoryEntry) bool { return e.ID == id })
+				if i < 0 {
+					return fmt.Errorf("history entry %d %w", id, tracker.ErrNotFound)
+				}
+				entry = entries[i]
+			}
+
+			fmt.Fprintln(stdout, entry.commandLine())
+			if err := selectProject(entry.Project); err != nil {
+				return err
+			}
+			return executeAndRecord(root, entry.Project, entry.Args)
+		}),
+	}
+}
+
+// historyNumbers offers the numbers of recent history entries.
+func historyNumbers([]string) []candidate {
+	entries, _ := loadHistory()
+	var cs []candidate
+	for _, e := range entries[max(0, len(entries)-historyListLimit):] {
+		cs = append(cs, candidate{strconv.Itoa(e.ID), e.commandLine()})
+	}
+	return cs
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	hookTimeout = 10 * time.Second // How long a hook may run.
+	hooksDir    = "hooks"          // Where hook executables are found by default.
+)
+
+// hookEvents are the events hooks are run on.
+var hookEvents = []string{"add", "modify", "delete"}
+
+// protectedFields are the task fields no hook may change.
+var protectedFields = []string{"id", "uuid", "createdAt"}
+
+// Hooks configures the programs run when tasks are added, changed or
+// deleted, and what they are allowed to change. Besides those listed, the
+// executables in the hooks directory whose names start with on-add,
+// on-modify or on-delete are run, in name order, as in Taskwarrior.
+//
+// A hook reads a JSON object with the event ("add", "modify" or "delete"),
+// the task and, for "modify", the previous task on standard input. It
+// prints the task to save, nothing to leave it as it is, or null to delete
+// it; what a delete hook prints is ignored. A hook that exits with an error
+// cancels the change, with its standard error as the reason.
+type Hooks struct {
+	OnAdd    []string `json:"onAdd,omitempty"`
+	OnModify []string `json:"onModify,omitempty"`
+	OnDelete []string `json:"onDelete,omitempty"`
+
+	// Dir holds hook executables; "hooks" next to config.json if empty.
+	Dir string `json:"dir,omitempty"`
+
+	// Fields lists the task fields, by their JSON names, hooks may change;
+	// every field but id, uuid and createdAt if empty. Other changes are
+	// undone.
+	Fields []string `json:"fields,omitempty"`
+
+	// AllowDelete lets hooks delete tasks.
+	AllowDelete bool `json:"allowDelete,omitempty"`
+}
+
+// hookInput is what a hook reads on standard input.
+type hookInput struct {
+	Event    string `json:"event"`
+	Task     Task   `json:"task"`
+	Previous *Task  `json:"previous,omitempty"`
+}
+
+// configured reports whether any hook is set.
+func (h Hooks) configured() bool {
+	return len(h.OnAdd) > 0 || len(h.OnModify) > 0 || len(h.OnDelete) > 0 || len(h.dirHooks("")) > 0
+}
+
+// programs returns the hooks run for event: those listed, then those in
+// the hooks directory.
+func (h Hooks) programs(event string) []string {
+	var listed []string
+	switch event {
+	case "add":
+		listed = h.OnAdd
+	case "modify":
+		listed = h.OnModify
+	case "delete":
+		listed = h.OnDelete
+	}
+	return append(slices.Clone(listed), h.dirHooks(event)...)
+}
+
+// dirHooks returns the executables in the hooks directory for event, or
+// for any event if event is empty, sorted by name.
+func (h Hooks) dirHooks(event string) []string {
+	dir := cmp.Or(h.Dir, hooksDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var hooks []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, "on-"+event) || !slices.ContainsFunc(hookEvents, func(e string) bool { return strings.HasPrefix(name, "on-"+e) }) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() || (runtime.GOOS != "windows" && info.Mode().Perm()&0o111 == 0) {
+			continue
+		}
+		hooks = append(hooks, filepath.Join(dir, name))
+	}
+	return hooks
+}
+
+// validate checks that the permitted fields are task fields hooks may change.
+func (h Hooks) validate() error {
+	known := taskFields()
+	for _, field := range h.Fields {
+		if slices.Contains(protectedFields, field) {
+			return fmt.Errorf("hooks may not be allowed to change '%s'", field)
+		}
+		if !slices.Contains(known, field) {
+			return fmt.Errorf("unknown task field '%s'; use one of: %s", field, strings.Join(known, ", "))
+		}
+	}
+	return nil
+}
+
+// mayChange reports whether hooks may change a task field.
+func (h Hooks) mayChange(field string) bool {
+	if slices.Contains(protectedFields, field) {
+		return false
+	}
+	return len(h.Fields) == 0 || slices.Contains(h.Fields, field)
+}
+
+// taskFields returns the JSON names of the task fields.
+func taskFields() []string {
+	var fields []string
+	t := reflect.TypeFor[Task]()
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+// runTaskHooks runs the configured hooks on the tasks added or changed
+// since saved, and returns the tasks as the hooks left them.
+func runTaskHooks(saved, tasks []Task) ([]Task, error) {
+	previous := map[int]Task{}
+	for _, task := range saved {
+		previous[task.ID] = task
+	}
+
+	kept := make([]Task, 0, len(tasks))
+	for _, task := range tasks {
+		event := "add"
+		prev, existed := previous[task.ID]
+		if existed {
+			if reflect.DeepEqual(prev, task) {
+				kept = append(kept, task)
+				continue
+			}
+			event = "modify"
+		}
+		hooks := config.Hooks.programs(event)
+
+		deleted := false
+		for _, hook := range hooks {
+			input := hookInput{Event: event, Task: task}
+			if existed {
+				input.Previous = &prev
+			}
+			out, err := runHook(hook, input)
+			if err != nil {
+				return nil, err
+			}
+			if out == nil {
+				if config.Hooks.AllowDelete {
+					deleted = true
+					break
+				}
+				fmt.Fprintf(stdout, "Warning: hook %s may not delete tasks; task ID %d kept.\n", hook, task.ID)
+				continue
+			}
+			if task, err = applyHookOutput(hook, task, out); err != nil {
+				return nil, err
+			}
+		}
+		if !deleted {
+			kept = append(kept, task)
+		}
+	}
+
+	if hooks := config.Hooks.programs("delete"); len(hooks) > 0 {
+		inArchive := archiveChecker()
+		for _, task := range saved {
+			if slices.ContainsFunc(tasks, func(t Task) bool { return t.UUID == task.UUID }) || inArchive(task.UUID) {
+				continue
+			}
+			for _, hook := range hooks {
+				if _, err := runHook(hook, hookInput{Event: "delete", Task: task}); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return kept, nil
+}
+
+// archiveChecker returns a function reporting whether a task is in the
+// current project's archive, so that tasks moved to or from it are not
+// taken for deleted or added ones. The archive is read on first use.
+func archiveChecker() func(uuid string) bool {
+	var archived []Task
+	read := false
+	return func(uuid string) bool {
+		if !read {
+			archived, _ = tr().ArchivedTasks()
+			read = true
+		}
+		return slices.ContainsFunc(archived, func(t Task) bool { return t.UUID == uuid })
+	}
+}
+
+// runHook runs a hook and returns the task object it printed, the input
+// task if it printed nothing, or nil if it deleted the task.
+func runHook(hook string, input hookInput) (map[string]json.RawMessage, error) {
+	in, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling JSON: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, hook)
+	cmd.Stdin = bytes.NewReader(in)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	cmd.Env = append(os.Environ(), "TASK_HOOK_EVENT="+input.Event)
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("hook %s timed out after %s", hook, hookTimeout)
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			reason := strings.TrimSpace(stderr.String())
+			if reason == "" {
+				reason = exitErr.Error()
+			}
+			return nil, fmt.Errorf("hook %s rejected the change: %s", hook, reason)
+		}
+		return nil, fmt.Errorf("error running hook %s: %w", hook, err)
+	}
+
+	out := bytes.TrimSpace(stdout.Bytes())
+	if len(out) == 0 {
+		out, _ = json.Marshal(input.Task)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(out, &fields); err != nil {
+		return nil, fmt.Errorf("hook %s printed invalid JSON: %w", hook, err)
+	}
+	return fields, nil
+}
+
+// applyHookOutput returns task with the changes a hook made to it, undoing
+// those to fields hooks may not change.
+func applyHookOutput(hook string, task Task, out map[string]json.RawMessage) (Task, error) {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return Task{}, fmt.Errorf("error marshalling JSON: %w", err)
+	}
+	var before map[string]json.RawMessage
+	if err := json.Unmarshal(data, &before); err != nil {
+		return Task{}, fmt.Errorf("error unmarshalling JSON: %w", err)
+	}
+
+	var denied []string
+	for _, field := range taskFields() {
+		if sameJSON(before[field], out[field]) || config.Hooks.mayChange(field) {
+			continue
+		}
+		denied = append(denied, field)
+		if value, ok := before[field]; ok {
+			out[field] = value
+		} else {
+			delete(out, field)
+		}
+	}
+	if len(denied) > 0 {
+		fmt.Fprintf(stdout, "Warning: hook %s may not change %s of task ID %d; change undone.\n", hook, strings.Join(denied, ", "), task.ID)
+	}
+
+	if data, err = json.Marshal(out); err != nil {
+		return Task{}, fmt.Errorf("error marshalling JSON: %w", err)
+	}
+	var changed Task
+	if err := json.Unmarshal(data, &changed); err != nil {
+		return Task{}, fmt.Errorf("hook %s printed an invalid task: %w", hook, err)
+	}
+	return changed, nil
+}
+
+// sameJSON reports whether two JSON values are equal, ignoring formatting.
+// A missing value equals null.
+func sameJSON(a, b json.RawMessage) bool {
+	var va, vb any
+	if len(a) > 0 && json.Unmarshal(a, &va) != nil {
+		return false
+	}
+	if len(b) > 0 && json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
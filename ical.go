@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+const (
+	icsProdID         = "-//arijit-gogoi//expense-tracker-go//EN"
+	icsDateTimeLayout = "20060102T150405Z"
+	icsDateLayout     = "20060102"
+	icsLineLimit      = 75 // RFC 5545 recommends folding lines longer than 75 octets.
+)
+
+// exportCommand returns the export command.
+func exportCommand() *command {
+	return &command{
+		name: "export", summary: "Export tasks as iCalendar VTODO entries or JSON, or all records as JSON", group: groupData,
+		setup: func(fs *flag.FlagSet) runFunc {
+			format := fs.String("format", "ics", "export `format` (ics, json, or data for tasks, archive, expenses and income)")
+			output := fs.String("output", "", "write the export to this `file` instead of stdout")
+			sign := fs.Bool("sign", false, "with --output, sign the file with the key from 'task sign keygen'")
+			redact := fs.Bool("redact", false, "mask descriptions, notes, names and amounts, to share the export in a bug report")
+			return func([]string) error {
+				if *sign && *output == "" {
+					return usagef("--sign needs --output")
+				}
+				var export func(io.Writer, []Task) error
+				switch *format {
+				case "ics":
+					export = exportICS
+				case "json":
+					export = exportJSON
+				case "data":
+				default:
+					return usagef("invalid export format '%s'; use ics, json or data", *format)
+				}
+				err := writeOutput(*output, func(w io.Writer) error {
+					if export == nil {
+						return exportData(w, *redact)
+					}
+					tasks, err := loadTasks()
+					if err != nil {
+						return err
+					}
+					if *redact {
+						tasks = redactTasks(tasks)
+					}
+					return export(w, tasks)
+				})
+				if err == nil && *output != "" && export == nil {
+					fmt.Fprintf(stdout, "Data exported to %s\n", *output)
+				} else if err == nil && *output != "" {
+					fmt.Fprintf(stdout, "Tasks exported to %s\n", *output)
+				}
+				if err == nil && *sign {
+					err = signFile(*output)
+				}
+				return err
+			}
+		},
+	}
+}
+
+// exportJSON writes tasks to w as a JSON array, each with its UUID.
+func exportJSON(w io.Writer, tasks []Task) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(tasks); err != nil {
+		return fmt.Errorf("error marshalling JSON: %w", err)
+	}
+	return nil
+}
+
+// exportICS writes tasks to w as an iCalendar document of VTODO entries.
+func exportICS(w io.Writer, tasks []Task) error {
+	bw := bufio.NewWriter(w)
+	writeICSLine(bw, "BEGIN:VCALENDAR")
+	writeICSLine(bw, "VERSION:2.0")
+	writeICSLine(bw, "PRODID:"+icsProdID)
+	writeICSLine(bw, "CALSCALE:GREGORIAN")
+
+	now := clock()
+	for _, task := range tasks {
+		writeICSLine(bw, "BEGIN:VTODO")
+		if task.UUID != "" {
+			writeICSLine(bw, "UID:"+task.UUID)
+		} else {
+			writeICSLine(bw, fmt.Sprintf("UID:task-%d@expense-tracker-go", task.ID))
+		}
+		writeICSLine(bw, "DTSTAMP:"+formatICSTime(now))
+		writeICSLine(bw, "CREATED:"+formatICSTime(task.CreatedAt))
+		writeICSLine(bw, "LAST-MODIFIED:"+formatICSTime(task.UpdatedAt))
+		writeICSLine(bw, "SUMMARY:"+escapeICSText(task.Description))
+		writeICSLine(bw, "STATUS:"+icsStatus(task.Status))
+		if task.Due != nil {
+			if task.Due.Equal(startOfDay(*task.Due)) {
+				writeICSLine(bw, "DUE;VALUE=DATE:"+task.Due.Format(icsDateLayout))
+			} else {
+				writeICSLine(bw, "DUE:"+formatICSTime(*task.Due))
+			}
+		}
+		if len(task.Tags) > 0 {
+			tags := make([]string, len(task.Tags))
+			for i, tag := range task.Tags {
+				tags[i] = escapeICSText(tag)
+			}
+			writeICSLine(bw, "CATEGORIES:"+strings.Join(tags, ","))
+		}
+		if task.Recur != "" {
+			writeICSLine(bw, "RRULE:FREQ="+strings.ToUpper(task.Recur))
+		}
+		if p := icsPriority(task.Priority); p != 0 {
+			writeICSLine(bw, fmt.Sprintf("PRIORITY:%d", p))
+		}
+		if config.Workflow.IsDone(task.Status) {
+			completed := task.UpdatedAt
+			if task.CompletedAt != nil {
+				completed = *task.CompletedAt
+			}
+			writeICSLine(bw, "COMPLETED:"+formatICSTime(completed))
+			writeICSLine(bw, "PERCENT-COMPLETE:100")
+		}
+		writeICSLine(bw, "END:VTODO")
+	}
+
+	writeICSLine(bw, "END:VCALENDAR")
+	return bw.Flush()
+}
+
+// icsStatus maps a task status to its VTODO STATUS value.
+func icsStatus(status string) string {
+	switch {
+	case config.Workflow.IsDone(status):
+		return "COMPLETED"
+	case status == config.Workflow.Initial():
+		return "NEEDS-ACTION"
+	default:
+		return "IN-PROCESS"
+	}
+}
+
+// icsPriority maps a task priority to the 1-9 VTODO scale (0 means undefined).
+func icsPriority(priority string) int {
+	switch priority {
+	case tracker.PriorityHigh:
+		return 1
+	case tracker.PriorityMedium:
+		return 5
+	case tracker.PriorityLow:
+		return 9
+	default:
+		return 0
+	}
+}
+
+// formatICSTime formats t as a UTC iCalendar date-time.
+func formatICSTime(t time.Time) string {
+	return t.UTC().Format(icsDateTimeLayout)
+}
+
+// escapeICSText escapes characters that have special meaning in iCalendar TEXT values.
+func escapeICSText(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return r.Replace(s)
+}
+
+// writeICSLine writes a content line terminated by CRLF, folding it at the
+// octet limit without splitting multi-byte characters.
+func writeICSLine(w *bufio.Writer, line string) {
+	limit := icsLineLimit
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		limit = icsLineLimit - 1 // Continuation lines start with a space.
+	}
+	w.WriteString(line + "\r\n")
+}
+
+// isRuneStart reports whether b is the first byte of a UTF-8 encoded rune.
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+const (
+	importCSV  = "csv"
+	importJSON = "json"
+)
+
+// importFields maps the column names or JSON keys accepted by the task
+// importer, in lower case, to the task field they set.
+var importFields = map[string]string{
+	"description": "description",
+	"title":       "description",
+	"task":        "description",
+	"status":      "status",
+	"priority":    "priority",
+	"due":         "due",
+	"due date":    "due",
+	"tags":        "tags",
+	"tag":         "tags",
+	"assignee":    "assignee",
+	"owner":       "assignee",
+	"estimate":    "estimate",
+	"hours":       "estimate",
+}
+
+// importRecord is one task to import: its fields by name, and the row it
+// came from (the CSV line, or the position in the JSON array).
+type importRecord struct {
+	Row    int
+	Fields map[string]string
+}
+
+// ImportResult is the outcome of importing one row.
+type ImportResult struct {
+	Row   int    `json:"row"`
+	ID    int    `json:"id,omitempty"` // The ID of the added task.
+	UUID  string `json:"uuid,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// importFormat returns the import format for a file name, defaulting to CSV.
+func importFormat(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return importJSON
+	}
+	return importCSV
+}
+
+// parseImport reads task records in format from r.
+func parseImport(r io.Reader, format string) ([]importRecord, error) {
+	switch format {
+	case importCSV:
+		return parseTasksCSV(r)
+	case importJSON:
+		return parseTasksJSON(r)
+	}
+	return nil, fmt.Errorf("invalid import format '%s'; use csv or json", format)
+}
+
+// parseTasksCSV reads task records from CSV with a header row naming the
+// columns. Unknown columns are ignored.
+func parseTasksCSV(r io.Reader) ([]importRecord, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading CSV: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("CSV file is empty")
+	}
+
+	columns := map[int]string{}
+	for i, name := range rows[0] {
+		if field, ok := importFields[strings.ToLower(strings.TrimSpace(name))]; ok {
+			columns[i] = field
+		}
+	}
+
+	records := make([]importRecord, 0, len(rows)-1)
+	for line, row := range rows[1:] {
+		fields := map[string]string{}
+		for i, value := range row {
+			if field, ok := columns[i]; ok {
+				fields[field] = strings.TrimSpace(value)
+			}
+		}
+		records = append(records, importRecord{Row: line + 2, Fields: fields})
+	}
+	return records, nil
+}
+
+// parseTasksJSON reads task records from a JSON array of objects. Values
+// may be strings, numbers, or for tags an array of strings.
+func parseTasksJSON(r io.Reader) ([]importRecord, error) {
+	var objects []map[string]any
+	if err := json.NewDecoder(r).Decode(&objects); err != nil {
+		return nil, fmt.Errorf("error unmarshalling JSON: %w", err)
+	}
+
+	records := make([]importRecord, 0, len(objects))
+	for i, object := range objects {
+		fields := map[string]string{}
+		for key, value := range object {
+			field, ok := importFields[strings.ToLower(key)]
+			if !ok {
+				continue
+			}
+			switch v := value.(type) {
+			case string:
+				fields[field] = strings.TrimSpace(v)
+			case float64:
+				fields[field] = strconv.FormatFloat(v, 'f', -1, 64)
+			case []any:
+				var parts []string
+				for _, part := range v {
+					parts = append(parts, fmt.Sprint(part))
+				}
+				fields[field] = strings.Join(parts, ",")
+			}
+		}
+		records = append(records, importRecord{Row: i + 1, Fields: fields})
+	}
+	return records, nil
+}
+
+// taskFromRecord builds a task from an imported record. Only a description
+// is required; the status defaults to the workflow's initial status.
+func taskFromRecord(rec importRecord, now time.Time) (Task, error) {
+	f := rec.Fields
+	task := Task{
+		Description: f["description"],
+		Status:      config.Workflow.Initial(),
+		Assignee:    f["assignee"],
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if task.Description == "" {
+		return Task{}, errors.New("missing description")
+	}
+
+	if status := strings.ToLower(f["status"]); status != "" && status != task.Status {
+		if !config.Workflow.HasStatus(status) {
+			return Task{}, fmt.Errorf("invalid status '%s'", f["status"])
+		}
+		task.SetStatus(status, now, config.Workflow)
+	}
+	if priority := strings.ToLower(f["priority"]); priority != "" {
+		if !tracker.IsValidPriority(priority) {
+			return Task{}, fmt.Errorf("invalid priority '%s'", f["priority"])
+		}
+		task.Priority = priority
+	}
+	if f["due"] != "" {
+		due, err := parseDate(f["due"], now)
+		if err != nil {
+			return Task{}, err
+		}
+		task.Due = &due
+	}
+	if f["estimate"] != "" {
+		hours, err := strconv.ParseFloat(strings.TrimSuffix(f["estimate"], "h"), 64)
+		if err != nil || hours < 0 {
+			return Task{}, fmt.Errorf("invalid estimate '%s'", f["estimate"])
+		}
+		task.Estimate = hours
+	}
+	for _, tag := range strings.FieldsFunc(f["tags"], func(r rune) bool { return r == ',' || r == ' ' }) {
+		if tag = normalizeTag(strings.TrimPrefix(tag, "#")); tag != "" {
+			task.Tags = append(task.Tags, tag)
+		}
+	}
+	return task, nil
+}
+
+// importTasks adds a task for each valid record and reports the outcome of
+// every row. Invalid rows are skipped; the rest are saved together.
+func importTasks(records []importRecord) ([]ImportResult, error) {
+	tasks, err := loadTasks()
+	if err != nil {
+		return nil, err
+	}
+
+	now := clock()
+	results := make([]ImportResult, 0, len(records))
+	added := 0
+	for _, rec := range records {
+		task, err := taskFromRecord(rec, now)
+		if err != nil {
+			results = append(results, ImportResult{Row: rec.Row, Error: err.Error()})
+			continue
+		}
+		task.ID, task.UUID = getNextID(tasks), tracker.NewUUID()
+		tasks = append(tasks, task)
+		results = append(results, ImportResult{Row: rec.Row, ID: task.ID, UUID: task.UUID})
+		added++
+	}
+
+	if added > 0 {
+		if err := saveTasks(tasks); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// importTasksFile imports tasks from a CSV or JSON file and prints the
+// outcome of every row.
+func importTasksFile(path, format string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("error opening file: %w", err)
+	}
+	defer f.Close()
+
+	if format == "" {
+		format = importFormat(path)
+	}
+	records, err := parseImport(f, format)
+	if err != nil {
+		return err
+	}
+	results, err := importTasks(records)
+	if err != nil {
+		return err
+	}
+
+	added := 0
+	for _, res := range results {
+		if res.Error != "" {
+			fmt.Fprintf(stdout, "Row %d: skipped: %s\n", res.Row, res.Error)
+			continue
+		}
+		fmt.Fprintf(stdout, "Row %d: added task ID %d\n", res.Row, res.ID)
+		added++
+	}
+	fmt.Fprintf(stdout, "Imported tasks: %d added, %d skipped.\n", added, len(results)-added)
+	return nil
+}
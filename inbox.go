@@ -0,0 +1,384 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+const inboxFile = "inbox.json" // Shared by all projects, since items are filed into one.
+
+// Inbox configures the providers 'task inbox' pulls items from besides
+// those captured with 'task inbox add'.
+type Inbox struct {
+	Providers []InboxProvider `json:"providers,omitempty"`
+}
+
+// InboxProvider is a source of inbox items: a command whose output, or a
+// URL whose response, lists them one per line or as a JSON array of
+// {"id", "text", "url"} objects. The URL and token may name a secret
+// ("secret:<name>") instead of holding it.
+type InboxProvider struct {
+	Name    string `json:"name"`
+	Command string `json:"command,omitempty"`
+	URL     string `json:"url,omitempty"`
+	Token   string `json:"token,omitempty"` // Sent as a bearer token, or to a command as $TASK_INBOX_TOKEN.
+}
+
+// validate checks that each provider has a unique name and one source.
+func (in Inbox) validate() error {
+	var names []string
+	for _, p := range in.Providers {
+		if p.Name == "" || slices.Contains(names, p.Name) {
+			return fmt.Errorf("provider names must be set and unique: '%s'", p.Name)
+		}
+		if (p.Command == "") == (p.URL == "") {
+			return fmt.Errorf("provider '%s' needs a command or a url", p.Name)
+		}
+		names = append(names, p.Name)
+	}
+	return nil
+}
+
+// InboxItem is something captured or pulled that is waiting to be filed as
+// a task or deleted.
+type InboxItem struct {
+	ID       int       `json:"id"`
+	Text     string    `json:"text"`
+	URL      string    `json:"url,omitempty"`
+	Provider string    `json:"provider,omitempty"` // Empty for items captured with 'task inbox add'.
+	Ref      string    `json:"ref,omitempty"`      // The provider's ID of the item.
+	AddedAt  time.Time `json:"addedAt"`
+}
+
+// inboxState is the inbox and the provider items pulled into it so far,
+// which are not pulled again once triaged.
+type inboxState struct {
+	Items  []InboxItem         `json:"items"`
+	Pulled map[string][]string `json:"pulled,omitempty"` // Refs by provider.
+}
+
+// inboxCommand returns the inbox command.
+func inboxCommand() *command {
+	return &command{
+		name: "inbox", args: "[add <text> | list]", summary: "Capture items and triage them, with those pulled from providers, into tasks", group: groupTasks,
+		complete: positional(fixed("add", "list")),
+		setup: run(func(args []string) error {
+			if len(args) == 0 {
+				return triageInbox()
+			}
+			switch {
+			case args[0] == "add" && len(args) > 1:
+				return captureInboxItem(strings.Join(args[1:], " "))
+			case args[0] == "list" && len(args) == 1:
+				return listInbox()
+			}
+			return usagef("expected add <text> or list")
+		}),
+	}
+}
+
+// inboxTracker returns the tracker for the shared inbox file, which is kept
+// with the default project's data.
+func inboxTracker() *tracker.Tracker {
+	return tr().At(projectDir(defaultProject))
+}
+
+// loadInbox reads the inbox.
+func loadInbox() (inboxState, error) {
+	state := inboxState{Pulled: map[string][]string{}}
+	raw, err := inboxTracker().ReadDocument(inboxFile)
+	if err != nil || raw == nil {
+		return state, err
+	}
+	if err := json.Unmarshal(raw, &state); err != nil {
+		return state, fmt.Errorf("error unmarshalling JSON: %w", err)
+	}
+	if state.Pulled == nil {
+		state.Pulled = map[string][]string{}
+	}
+	return state, nil
+}
+
+// saveInbox writes the inbox.
+func saveInbox(state inboxState) error {
+	return inboxTracker().WriteDocument(inboxFile, state)
+}
+
+// add appends an item to the inbox, assigning its ID.
+func (s *inboxState) add(item InboxItem) {
+	for _, existing := range s.Items {
+		item.ID = max(item.ID, existing.ID)
+	}
+	item.ID++
+	item.AddedAt = clock()
+	s.Items = append(s.Items, item)
+}
+
+// captureInboxItem adds text to the inbox to be triaged later.
+func captureInboxItem(text string) error {
+	state, err := loadInbox()
+	if err != nil {
+		return err
+	}
+	state.add(InboxItem{Text: text})
+	if err := saveInbox(state); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Captured to the inbox (%s).\n", plural(len(state.Items), "item"))
+	return nil
+}
+
+// pullInbox adds the items of each provider not pulled before. A provider
+// that fails is reported and skipped.
+func pullInbox(state *inboxState) {
+	for _, p := range config.Inbox.Providers {
+		items, err := fetchInboxItems(p)
+		if err != nil {
+			fmt.Fprintf(stdout, "Warning: could not pull from %s: %v\n", p.Name, err)
+			continue
+		}
+		for _, item := range items {
+			if slices.Contains(state.Pulled[p.Name], item.Ref) {
+				continue
+			}
+			item.Provider = p.Name
+			state.add(item)
+			state.Pulled[p.Name] = append(state.Pulled[p.Name], item.Ref)
+		}
+	}
+}
+
+// fetchInboxItems runs or fetches a provider and parses the items it lists.
+// Items given as lines are identified by a hash of their text.
+func fetchInboxItems(p InboxProvider) ([]InboxItem, error) {
+	token, err := resolveSecret(p.Token)
+	if err != nil {
+		return nil, err
+	}
+	var data []byte
+	if p.URL != "" {
+		url, err := resolveSecret(p.URL)
+		if err != nil {
+			return nil, err
+		}
+		data, err = httpGetToken(url, "application/json", token)
+	} else {
+		fields := strings.Fields(p.Command)
+		cmd := exec.Command(fields[0], fields[1:]...)
+		if token != "" {
+			cmd.Env = append(os.Environ(), "TASK_INBOX_TOKEN="+token)
+		}
+		data, err = cmd.Output()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if trimmed := bytes.TrimSpace(data); bytes.HasPrefix(trimmed, []byte("[")) {
+		var objects []struct {
+			ID   any    `json:"id"`
+			Text string `json:"text"`
+			URL  string `json:"url"`
+		}
+		if err := json.Unmarshal(trimmed, &objects); err != nil {
+			return nil, fmt.Errorf("invalid items: %w", err)
+		}
+		var items []InboxItem
+		for _, o := range objects {
+			if o.Text == "" {
+				continue
+			}
+			ref := fmt.Sprint(o.ID)
+			if o.ID == nil {
+				ref = textRef(o.Text)
+			}
+			items = append(items, InboxItem{Text: o.Text, URL: o.URL, Ref: ref})
+		}
+		return items, nil
+	}
+
+	var items []InboxItem
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if text := strings.TrimSpace(scanner.Text()); text != "" {
+			items = append(items, InboxItem{Text: text, Ref: textRef(text)})
+		}
+	}
+	return items, scanner.Err()
+}
+
+// textRef identifies an item without an ID of its own by its text.
+func textRef(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:8])
+}
+
+// listInbox pulls from the providers and prints the inbox.
+func listInbox() error {
+	state, err := loadInbox()
+	if err != nil {
+		return err
+	}
+	pullInbox(&state)
+	if err := saveInbox(state); err != nil {
+		return err
+	}
+	if len(state.Items) == 0 {
+		fmt.Fprintln(stdout, "Inbox is empty.")
+		return nil
+	}
+
+	now := clock()
+	fmt.Fprintln(stdout, "--- Inbox ---")
+	for _, item := range state.Items {
+		fmt.Fprintf(stdout, "[%d] %s%s (%s)\n", item.ID, item.Text, inboxSource(item), relativeTime(item.AddedAt, now))
+	}
+	fmt.Fprintln(stdout, "-------------")
+	return nil
+}
+
+// inboxSource renders where an item came from, if from a provider.
+func inboxSource(item InboxItem) string {
+	if item.Provider == "" {
+		return ""
+	}
+	return " [" + item.Provider + "]"
+}
+
+// triageInbox pulls from the providers and goes through the inbox item by
+// item, filing each as a task, deleting it or leaving it for later. Each
+// answer is saved straight away, so quitting keeps what was done.
+func triageInbox() error {
+	state, err := loadInbox()
+	if err != nil {
+		return err
+	}
+	pullInbox(&state)
+	if err := saveInbox(state); err != nil {
+		return err
+	}
+	if len(state.Items) == 0 {
+		fmt.Fprintln(stdout, "Inbox is empty.")
+		return nil
+	}
+
+	fmt.Fprintf(stdout, "Inbox: %s. Press Enter to file an item in this project, or type\n", plural(len(state.Items), "item"))
+	fmt.Fprintln(stdout, "@project +tag !due to file it with them (e.g. @home +errands !friday 5pm),")
+	fmt.Fprintln(stdout, "s to skip, x to delete or q to quit.")
+	filed, deleted := 0, 0
+	for _, item := range slices.Clone(state.Items) {
+		for {
+			answer, err := readLine(fmt.Sprintf("[%d] %s%s > ", item.ID, item.Text, inboxSource(item)))
+			if err != nil {
+				return err
+			}
+			answer = strings.TrimSpace(answer)
+			if answer == "q" {
+				fmt.Fprintf(stdout, "Filed %d, deleted %d, %d left in the inbox.\n", filed, deleted, len(state.Items))
+				return nil
+			}
+			if answer == "s" {
+				break
+			}
+			if answer != "x" {
+				if err := fileInboxItem(item, answer); err != nil {
+					fmt.Fprintf(stdout, "Error: %v\n", err)
+					continue
+				}
+				filed++
+			} else {
+				deleted++
+			}
+			state.Items = slices.DeleteFunc(state.Items, func(i InboxItem) bool { return i.ID == item.ID })
+			if err := saveInbox(state); err != nil {
+				return err
+			}
+			break
+		}
+	}
+	fmt.Fprintf(stdout, "Filed %d, deleted %d, %d left in the inbox.\n", filed, deleted, len(state.Items))
+	return nil
+}
+
+// fileInboxItem adds an inbox item as a task, in the project, with the tags
+// and due date of a triage answer such as "@home +errands !friday 5pm".
+func fileInboxItem(item InboxItem, answer string) error {
+	project := currentProject
+	var tags, due []string
+	for _, word := range strings.Fields(answer) {
+		switch {
+		case strings.HasPrefix(word, "@"):
+			project = strings.TrimPrefix(word, "@")
+		case strings.HasPrefix(word, "+"):
+			if tag := normalizeTag(strings.TrimPrefix(word, "+")); tag != "" && !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		case strings.HasPrefix(word, "!"):
+			due = []string{strings.TrimPrefix(word, "!")}
+		case due != nil:
+			due = append(due, word)
+		default:
+			return fmt.Errorf("cannot understand '%s'; use @project, +tag or !due", word)
+		}
+	}
+	if !projectExists(project) {
+		return fmt.Errorf("project '%s' does not exist", project)
+	}
+	if project == defaultProject {
+		project = ""
+	}
+
+	now := clock()
+	task := Task{
+		Description: item.Text,
+		Status:      config.Workflow.Initial(),
+		URL:         item.URL,
+		Tags:        tags,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	slices.Sort(task.Tags)
+	if item.Provider != "" {
+		task.Source = item.Provider + ":" + item.Ref
+	}
+	if due != nil {
+		d, err := parseDate(strings.Join(due, " "), now)
+		if err != nil {
+			return err
+		}
+		task.Due = &d
+	}
+
+	saved := currentProject
+	defer func() { currentProject = saved }()
+	currentProject = project
+	tasks, err := loadTasks()
+	if err != nil {
+		return err
+	}
+	task.ID, task.UUID = getNextID(tasks), tracker.NewUUID()
+	if err := saveTasks(append(tasks, task)); err != nil {
+		return err
+	}
+	where := "this project"
+	if project != saved {
+		where = "project " + cmp.Or(project, defaultProject)
+	}
+	fmt.Fprintf(stdout, "  Filed as task ID %d in %s.\n", task.ID, where)
+	if task.Due != nil {
+		warnMeetingConflicts(*task.Due)
+	}
+	return nil
+}
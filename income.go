@@ -0,0 +1,123 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+// incomeCommand returns the income command group.
+func incomeCommand() *command {
+	return &command{
+		name: "income", summary: "Record and list income", group: groupExpenses,
+		subcommands: []*command{
+			{
+				name: "add", args: "<amount> <description>", summary: "Record income", minArgs: 2,
+				setup: func(fs *flag.FlagSet) runFunc {
+					source := fs.String("source", "", "who paid it, such as an employer")
+					currency := fs.String("currency", "", "currency `code` such as USD (default the base currency)")
+					dateStr := fs.String("date", "", "`date` it was received (e.g. 2025-03-01 or \"yesterday\", default today)")
+					account := fs.String("account", "", "bank `account` it was paid into, for 'task expense reconcile'")
+					return func(args []string) error {
+						amount, err := strconv.ParseFloat(args[0], 64)
+						if err != nil || amount < 0 {
+							return usagef("invalid amount '%s'", args[0])
+						}
+						code := ""
+						if *currency != "" {
+							if code, err = parseCurrency(*currency); err != nil {
+								return err
+							}
+						}
+						date := clock()
+						if *dateStr != "" {
+							date, err = parseDate(*dateStr, clock())
+							if err != nil {
+								return usagef("%v", err)
+							}
+						}
+						return addIncome(Income{Date: date, Amount: amount, Currency: code, Description: strings.Join(args[1:], " "), Source: *source, Account: *account})
+					}
+				},
+			},
+			{
+				name: "list", summary: "List income with a total",
+				setup: func(fs *flag.FlagSet) runFunc {
+					source := fs.String("source", "", "only list income from this source")
+					since := fs.String("since", "", "only list income on or after this `date`")
+					until := fs.String("until", "", "only list income on or before this `date`")
+					return func([]string) error {
+						window, err := parseDateRange(*since, *until, clock())
+						if err != nil {
+							return usagef("%v", err)
+						}
+						return listIncome(*source, window)
+					}
+				},
+			},
+			{
+				name: "delete", args: "<id>", summary: "Delete income", minArgs: 1,
+				setup: run(func(args []string) error {
+					id, err := parseID(args[0], "income")
+					if err != nil {
+						return err
+					}
+					return deleteIncome(id)
+				}),
+			},
+		},
+	}
+}
+
+// addIncome records new income.
+func addIncome(in Income) error {
+	income, err := tr().AddIncome(in)
+	if err != nil {
+		return err
+	}
+
+	printDone(msg("Income added successfully (ID: %d)\n"), income.ID)
+	return nil
+}
+
+// deleteIncome deletes income by ID.
+func deleteIncome(id int) error {
+	if err := tr().DeleteIncome(id); err != nil {
+		return err
+	}
+
+	printDone(msg("Income ID %d deleted successfully\n"), id)
+	return nil
+}
+
+// listIncome prints income, optionally filtered by source and date, with a
+// total.
+func listIncome(source string, window DateRange) error {
+	list, err := tr().ListIncome(tracker.IncomeFilter{
+		Source: source,
+		Since:  window.Since,
+		Until:  window.Until,
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(list.Income) == 0 {
+		fmt.Fprintln(stdout, msg("No income found."))
+		return nil
+	}
+
+	fmt.Fprintln(stdout, msg("--- Income ---"))
+	for _, in := range list.Income {
+		fmt.Fprintf(stdout, "[ID: %d] %s %10s %-3s  %s", in.ID, formatDate(in.Date), formatNumber(in.Amount), in.Currency, in.Description)
+		if in.Source != "" {
+			fmt.Fprintf(stdout, " @ %s", in.Source)
+		}
+		fmt.Fprintln(stdout)
+	}
+	fmt.Fprintf(stdout, msg("--- Total: %s ---\n"), formatTotals(list.Totals))
+	return nil
+}
@@ -0,0 +1,49 @@
+// Package expense defines the expense ledger record.
+package expense
+
+import "time"
+
+// Expense represents a single recorded expense.
+type Expense struct {
+	ID          int               `json:"id"`
+	Date        time.Time         `json:"date"`
+	Amount      float64           `json:"amount"`
+	Currency    string            `json:"currency,omitempty"` // ISO 4217 code; the base currency if empty.
+	Description string            `json:"description"`
+	Category    string            `json:"category,omitempty"`
+	Payee       string            `json:"payee,omitempty"`
+	Task        string            `json:"task,omitempty"`    // UUID of the task it was spent on.
+	Account     string            `json:"account,omitempty"` // Bank account it was paid from.
+	Cleared     *time.Time        `json:"cleared,omitempty"` // When it was reconciled with a statement of its account.
+	Fields      map[string]string `json:"fields,omitempty"`  // The user's own metadata, by name.
+	CreatedAt   time.Time         `json:"createdAt"`
+}
+
+// InLocation returns the expense with its times in loc.
+func (e Expense) InLocation(loc *time.Location) Expense {
+	e.Date, e.CreatedAt = e.Date.In(loc), e.CreatedAt.In(loc)
+	if e.Cleared != nil {
+		cleared := e.Cleared.In(loc)
+		e.Cleared = &cleared
+	}
+	return e
+}
+
+// NextID returns the ID for a new expense.
+func NextID(expenses []Expense) int {
+	maxID := 0
+	for _, e := range expenses {
+		maxID = max(maxID, e.ID)
+	}
+	return maxID + 1
+}
+
+// Index returns the position of the expense with id, or -1.
+func Index(expenses []Expense, id int) int {
+	for i, e := range expenses {
+		if e.ID == id {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,45 @@
+package expense
+
+import "time"
+
+// Income represents money received, such as a salary or a refund.
+type Income struct {
+	ID          int        `json:"id"`
+	Date        time.Time  `json:"date"`
+	Amount      float64    `json:"amount"`
+	Currency    string     `json:"currency,omitempty"` // ISO 4217 code; the base currency if empty.
+	Description string     `json:"description"`
+	Source      string     `json:"source,omitempty"`  // Who paid it, such as an employer.
+	Account     string     `json:"account,omitempty"` // Bank account it was paid into.
+	Cleared     *time.Time `json:"cleared,omitempty"` // When it was reconciled with a statement of its account.
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+// InLocation returns the income with its times in loc.
+func (in Income) InLocation(loc *time.Location) Income {
+	in.Date, in.CreatedAt = in.Date.In(loc), in.CreatedAt.In(loc)
+	if in.Cleared != nil {
+		cleared := in.Cleared.In(loc)
+		in.Cleared = &cleared
+	}
+	return in
+}
+
+// NextIncomeID returns the ID for new income.
+func NextIncomeID(income []Income) int {
+	maxID := 0
+	for _, in := range income {
+		maxID = max(maxID, in.ID)
+	}
+	return maxID + 1
+}
+
+// IncomeIndex returns the position of the income with id, or -1.
+func IncomeIndex(income []Income, id int) int {
+	for i, in := range income {
+		if in.ID == id {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,141 @@
+package store
+
+import (
+	"fmt"
+	"os"
+	"slices"
+)
+
+// Buffer holds data files in memory for a Store. Each file is read from
+// disk once, and writes stay in memory until Flush saves them or Discard
+// drops them. Copies of a Store share its Buffer.
+type Buffer struct {
+	fs    FS
+	files map[string]*bufferedFile // By path.
+	dirty []string                 // Paths written since the last Flush, in order.
+}
+
+// bufferedFile is the contents of a file as the store last saw it.
+type bufferedFile struct {
+	data   []byte
+	exists bool
+	perm   os.FileMode
+}
+
+// NewBuffer returns an empty buffer that saves to fsys, or to the disk if
+// fsys is nil.
+func NewBuffer(fsys FS) *Buffer {
+	if fsys == nil {
+		fsys = OSFS{}
+	}
+	return &Buffer{fs: fsys, files: map[string]*bufferedFile{}}
+}
+
+// Pending returns the paths of the files written but not yet saved.
+func (b *Buffer) Pending() []string {
+	return append([]string(nil), b.dirty...)
+}
+
+// Flush saves the written files to disk.
+func (b *Buffer) Flush() error {
+	for len(b.dirty) > 0 {
+		path := b.dirty[0]
+		f := b.files[path]
+		if !f.exists {
+			if err := b.fs.Remove(path); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("error removing file: %w", err)
+			}
+		} else if err := b.fs.WriteFile(path, f.data, f.perm); err != nil {
+			return fmt.Errorf("error writing file: %w", err)
+		}
+		b.dirty = b.dirty[1:]
+	}
+	return nil
+}
+
+// Discard drops the written files, so they are read from disk again.
+func (b *Buffer) Discard() {
+	for _, path := range b.dirty {
+		delete(b.files, path)
+	}
+	b.dirty = nil
+}
+
+// readFile reads a file through the store's buffer, if it has one.
+func (s *Store) readFile(path string) ([]byte, error) {
+	if s.Buffer == nil {
+		return s.fs().ReadFile(path)
+	}
+	f, ok := s.Buffer.files[path]
+	if !ok {
+		data, err := s.fs().ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		f = &bufferedFile{data: data, exists: err == nil}
+		s.Buffer.files[path] = f
+	}
+	if !f.exists {
+		return nil, &os.PathError{Op: "open", Path: path, Err: os.ErrNotExist}
+	}
+	return f.data, nil
+}
+
+// writeFile writes a file through the store's buffer, if it has one.
+func (s *Store) writeFile(path string, data []byte, perm os.FileMode) error {
+	if s.Buffer == nil {
+		return s.fs().WriteFile(path, data, perm)
+	}
+	if !slices.Contains(s.Buffer.dirty, path) {
+		s.Buffer.dirty = append(s.Buffer.dirty, path)
+	}
+	s.Buffer.files[path] = &bufferedFile{data: data, exists: true, perm: perm}
+	return nil
+}
+
+// appendFile appends to a file through the store's buffer, if it has one,
+// creating the file if needed.
+func (s *Store) appendFile(path string, data []byte, perm os.FileMode) error {
+	if s.Buffer == nil {
+		return s.fs().AppendFile(path, data, perm)
+	}
+	existing, err := s.readFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return s.writeFile(path, append(slices.Clip(existing), data...), perm)
+}
+
+// removeFile removes a file through the store's buffer, if it has one.
+// Removing a missing file is not an error.
+func (s *Store) removeFile(path string) error {
+	if s.Buffer == nil {
+		if err := s.fs().Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if !slices.Contains(s.Buffer.dirty, path) {
+		s.Buffer.dirty = append(s.Buffer.dirty, path)
+	}
+	s.Buffer.files[path] = &bufferedFile{}
+	return nil
+}
+
+// fileSize returns the size of a file as the store sees it, or -1 if it
+// does not exist.
+func (s *Store) fileSize(path string) int64 {
+	if s.Buffer != nil {
+		if f, ok := s.Buffer.files[path]; ok {
+			if !f.exists {
+				return -1
+			}
+			return int64(len(f.data))
+		}
+	}
+	size, err := s.fs().Size(path)
+	if err != nil {
+		return -1
+	}
+	return size
+}
@@ -0,0 +1,257 @@
+package store
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+const (
+	cipherName    = "aes-256-gcm"
+	kdfName       = "pbkdf2-sha256"
+	kdfIterations = 600000
+	keySize       = 32
+	saltSize      = 16
+)
+
+// encryptedFile is the on-disk envelope of an encrypted data file.
+type encryptedFile struct {
+	Cipher     string `json:"cipher"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Data       []byte `json:"data"`
+}
+
+// Keyring holds the passphrase and the keys derived from it. One keyring can
+// be shared by several stores so the passphrase is asked for, and each key
+// derived, only once.
+type Keyring struct {
+	prompt     func() (string, error)
+	passphrase string
+	keys       map[string][]byte // Derived keys cached by salt.
+}
+
+// NewKeyring returns a keyring that calls prompt the first time a
+// passphrase is needed.
+func NewKeyring(prompt func() (string, error)) *Keyring {
+	return &Keyring{prompt: prompt, keys: map[string][]byte{}}
+}
+
+// SetPassphrase sets the passphrase, replacing any cached keys.
+func (k *Keyring) SetPassphrase(passphrase string) {
+	k.passphrase = passphrase
+	k.keys = map[string][]byte{}
+}
+
+// key derives the AES key for salt from the passphrase, asking for it once.
+func (k *Keyring) key(salt []byte, iterations int) ([]byte, error) {
+	if k == nil {
+		return nil, errors.New("data is encrypted but no passphrase is available")
+	}
+	if key, ok := k.keys[string(salt)]; ok {
+		return key, nil
+	}
+
+	if k.passphrase == "" {
+		if k.prompt == nil {
+			return nil, errors.New("data is encrypted but no passphrase is available")
+		}
+		p, err := k.prompt()
+		if err != nil {
+			return nil, err
+		}
+		if p == "" {
+			return nil, errors.New("passphrase must not be empty")
+		}
+		k.passphrase = p
+	}
+
+	key, err := pbkdf2.Key(sha256.New, k.passphrase, salt, iterations, keySize)
+	if err != nil {
+		return nil, fmt.Errorf("error deriving key: %w", err)
+	}
+	k.keys[string(salt)] = key
+	return key, nil
+}
+
+// Read reads a data file, transparently decrypting it when needed. A
+// missing file yields nil data and no error.
+func (s *Store) Read(name string) ([]byte, error) {
+	data, err := s.readFile(s.Path(name))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+
+	env, ok := parseEnvelope(data)
+	if !ok {
+		return data, nil
+	}
+	return s.decrypt(env)
+}
+
+// Write writes a data file, encrypting it if the existing file on disk is
+// encrypted.
+func (s *Store) Write(name string, data []byte) error {
+	path := s.Path(name)
+	perm := os.FileMode(0644)
+	existing, err := s.readFile(path)
+	if err == nil {
+		if env, ok := parseEnvelope(existing); ok {
+			data, err = s.encrypt(data, env.Salt)
+			if err != nil {
+				return err
+			}
+			perm = 0600
+		}
+	}
+
+	if err := s.writeFile(path, data, perm); err != nil {
+		return fmt.Errorf("error writing file: %w", err)
+	}
+	return nil
+}
+
+// Encrypted reports whether a data file is an encrypted envelope.
+func (s *Store) Encrypted(name string) bool {
+	data, err := s.readFile(s.Path(name))
+	if err != nil {
+		return false
+	}
+	_, ok := parseEnvelope(data)
+	return ok
+}
+
+// Encrypt encrypts the named data files with the keyring's passphrase and a
+// fresh salt. Missing files are created empty, at the current schema
+// version, so that new records are encrypted too.
+func (s *Store) Encrypt(names []string) error {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("error generating salt: %w", err)
+	}
+
+	for _, name := range names {
+		data, err := s.Read(name)
+		if err != nil {
+			return err
+		}
+		if data == nil {
+			if data, err = emptyDocument(); err != nil {
+				return err
+			}
+		}
+		sealed, err := s.encrypt(data, salt)
+		if err != nil {
+			return err
+		}
+		if err := s.writeFile(s.Path(name), sealed, 0600); err != nil {
+			return fmt.Errorf("error writing file: %w", err)
+		}
+	}
+	return nil
+}
+
+// Decrypt writes the named encrypted data files back as plain JSON.
+func (s *Store) Decrypt(names []string) error {
+	for _, name := range names {
+		if !s.Encrypted(name) {
+			continue
+		}
+		data, err := s.Read(name)
+		if err != nil {
+			return err
+		}
+		if err := s.writeFile(s.Path(name), data, 0644); err != nil {
+			return fmt.Errorf("error writing file: %w", err)
+		}
+	}
+	return nil
+}
+
+// parseEnvelope decodes data as an encrypted envelope. Plain data files hold a
+// JSON array, so anything that is not an object is treated as plaintext.
+func parseEnvelope(data []byte) (encryptedFile, bool) {
+	var env encryptedFile
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return env, false
+	}
+	if err := json.Unmarshal(trimmed, &env); err != nil || env.Cipher == "" {
+		return env, false
+	}
+	return env, true
+}
+
+// encrypt seals plaintext into an encrypted envelope using a key derived from salt.
+func (s *Store) encrypt(plaintext, salt []byte) ([]byte, error) {
+	key, err := s.Keys.key(salt, kdfIterations)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("error generating nonce: %w", err)
+	}
+
+	env := encryptedFile{
+		Cipher:     cipherName,
+		KDF:        kdfName,
+		Iterations: kdfIterations,
+		Salt:       salt,
+		Nonce:      nonce,
+		Data:       gcm.Seal(nil, nonce, plaintext, nil),
+	}
+	data, err := json.MarshalIndent(env, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling JSON: %w", err)
+	}
+	return data, nil
+}
+
+// decrypt opens an encrypted envelope.
+func (s *Store) decrypt(env encryptedFile) ([]byte, error) {
+	if env.Cipher != cipherName || env.KDF != kdfName {
+		return nil, fmt.Errorf("unsupported encryption %s/%s", env.Cipher, env.KDF)
+	}
+
+	key, err := s.Keys.key(env.Salt, env.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := gcm.Open(nil, env.Nonce, env.Data, nil)
+	if err != nil {
+		return nil, errors.New("unable to decrypt data: wrong passphrase or corrupted file")
+	}
+	return plaintext, nil
+}
+
+// newGCM creates an AES-GCM AEAD for key.
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("error creating cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
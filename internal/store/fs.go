@@ -0,0 +1,121 @@
+package store
+
+import (
+	"io/fs"
+	"os"
+	"slices"
+	"sync"
+)
+
+// FS is the file system a Store keeps its data files in.
+type FS interface {
+	ReadFile(path string) ([]byte, error)
+	WriteFile(path string, data []byte, perm os.FileMode) error
+	AppendFile(path string, data []byte, perm os.FileMode) error
+	Remove(path string) error
+	Size(path string) (int64, error)
+}
+
+// OSFS is the operating system's file system.
+type OSFS struct{}
+
+func (OSFS) ReadFile(path string) ([]byte, error) { return os.ReadFile(path) }
+
+// WriteFile writes a file as os.WriteFile does, but narrows the mode of an
+// existing file to perm too, which os.WriteFile leaves alone: a plain file
+// readable by others would otherwise stay so once encrypted.
+func (OSFS) WriteFile(path string, data []byte, perm os.FileMode) error {
+	if info, err := os.Stat(path); err == nil && info.Mode().Perm()&^perm != 0 {
+		if err := os.Chmod(path, info.Mode().Perm()&perm); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(path, data, perm)
+}
+
+func (OSFS) AppendFile(path string, data []byte, perm os.FileMode) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, perm)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (OSFS) Remove(path string) error { return os.Remove(path) }
+
+func (OSFS) Size(path string) (int64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// MemFS keeps files in memory, for tests and for programs that embed the
+// tracker without touching the disk. Directories need not be created. It
+// is safe for concurrent use.
+type MemFS struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+// NewMemFS returns an empty in-memory file system.
+func NewMemFS() *MemFS {
+	return &MemFS{files: map[string][]byte{}}
+}
+
+func (m *MemFS) ReadFile(path string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.files[path]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: path, Err: fs.ErrNotExist}
+	}
+	return slices.Clone(data), nil
+}
+
+func (m *MemFS) WriteFile(path string, data []byte, _ os.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[path] = slices.Clone(data)
+	return nil
+}
+
+func (m *MemFS) AppendFile(path string, data []byte, _ os.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[path] = append(m.files[path], data...)
+	return nil
+}
+
+func (m *MemFS) Remove(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.files[path]; !ok {
+		return &fs.PathError{Op: "remove", Path: path, Err: fs.ErrNotExist}
+	}
+	delete(m.files, path)
+	return nil
+}
+
+func (m *MemFS) Size(path string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.files[path]
+	if !ok {
+		return 0, &fs.PathError{Op: "stat", Path: path, Err: fs.ErrNotExist}
+	}
+	return int64(len(data)), nil
+}
+
+// fs returns the store's file system, the operating system's by default.
+func (s *Store) fs() FS {
+	if s.FS == nil {
+		return OSFS{}
+	}
+	return s.FS
+}
@@ -0,0 +1,40 @@
+package store_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/arijit-gogoi/expense-tracker-go/internal/store"
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker/storetest"
+)
+
+func TestOSFS(t *testing.T) {
+	storetest.TestFS(t, func(t *testing.T) (tracker.FS, string) {
+		return store.OSFS{}, t.TempDir()
+	})
+}
+
+func TestOSFSEncryptNarrowsMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not enforced on Windows")
+	}
+	dir := t.TempDir()
+	s := &store.Store{Dir: dir, Keys: store.NewKeyring(nil)}
+	if err := s.Save("tasks.json", []string{"Write report"}); err != nil {
+		t.Fatal(err)
+	}
+	s.Keys.SetPassphrase("correct horse")
+	if err := s.Encrypt([]string{"tasks.json"}); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(filepath.Join(dir, "tasks.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0o600 {
+		t.Errorf("encrypted file has mode %o, want 600", mode)
+	}
+}
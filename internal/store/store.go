@@ -0,0 +1,209 @@
+// Package store reads and writes the versioned, optionally encrypted JSON
+// data files kept in a directory.
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+)
+
+// SchemaVersion is the data file format written by this build. Version 1 is
+// the original bare JSON array; later versions wrap the records in a
+// versioned document. Version 3 gives every task a UUID, version 4
+// records when done tasks were completed, and version 5 refers to the
+// tasks a task waits on by UUID rather than ID.
+const SchemaVersion = 5
+
+// document is the on-disk layout of a versioned data file.
+type document struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	Items         json.RawMessage `json:"items"`
+}
+
+// Migration upgrades the records of a data file by one schema version.
+type Migration func(items json.RawMessage) (json.RawMessage, error)
+
+// Store gives access to the data files in Dir.
+type Store struct {
+	Dir  string
+	Keys *Keyring // Unlocks encrypted files; required only if any are encrypted.
+
+	// Migrations holds, per data file, the steps that upgrade version n to
+	// n+1 at index n-1. Files without an entry, and nil steps, need no
+	// record changes.
+	Migrations map[string][]Migration
+
+	// OnUpgrade, if set, is called after a file has been migrated.
+	OnUpgrade func(path string, from, to int, backup string)
+
+	// Buffer, if set, holds writes in memory until it is flushed.
+	Buffer *Buffer
+
+	// FS holds the data files; the operating system's file system if nil.
+	FS FS
+}
+
+// Path returns the path of a data file.
+func (s *Store) Path(name string) string {
+	return filepath.Join(s.Dir, name)
+}
+
+// Load reads a data file and returns its records, upgrading older schema
+// versions in place after backing up the original file. A missing or empty
+// file yields nil records and no error.
+func (s *Store) Load(name string) (json.RawMessage, error) {
+	data, err := s.Read(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// If file is empty or only contains whitespace, there are no records
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] == 0 {
+		return nil, nil
+	}
+
+	version := 1
+	items := json.RawMessage(data)
+	if data[0] != '[' {
+		var doc document
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("error unmarshalling JSON: %w", err)
+		}
+		version, items = doc.SchemaVersion, doc.Items
+	}
+
+	if version > SchemaVersion {
+		return nil, fmt.Errorf("%s uses schema version %d but this build only supports up to %d; please upgrade", s.Path(name), version, SchemaVersion)
+	}
+	if version == SchemaVersion {
+		return items, nil
+	}
+
+	backup, err := s.backup(name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	steps := s.Migrations[name]
+	for v := version; v < SchemaVersion; v++ {
+		if v-1 < len(steps) && steps[v-1] != nil {
+			items, err = steps[v-1](items)
+			if err != nil {
+				return nil, fmt.Errorf("error migrating %s from version %d: %w", s.Path(name), v, err)
+			}
+		}
+	}
+
+	if err := s.Save(name, items); err != nil {
+		return nil, err
+	}
+	if s.OnUpgrade != nil {
+		s.OnUpgrade(s.Path(name), version, SchemaVersion, backup)
+	}
+
+	return items, nil
+}
+
+// Save writes records to a data file wrapped in a versioned document.
+func (s *Store) Save(name string, items any) error {
+	raw, err := json.Marshal(items)
+	if err != nil {
+		return fmt.Errorf("error marshalling JSON: %w", err)
+	}
+
+	data, err := json.MarshalIndent(document{SchemaVersion: SchemaVersion, Items: raw}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling JSON: %w", err)
+	}
+
+	return s.Write(name, data)
+}
+
+// emptyDocument returns a data file without records.
+func emptyDocument() ([]byte, error) {
+	data, err := json.MarshalIndent(document{SchemaVersion: SchemaVersion, Items: json.RawMessage("[]")}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling JSON: %w", err)
+	}
+	return data, nil
+}
+
+// Append appends data to a plain data file, such as a journal of changes,
+// creating it if needed. Unlike Write it never encrypts.
+func (s *Store) Append(name string, data []byte) error {
+	if err := s.appendFile(s.Path(name), data, 0644); err != nil {
+		return fmt.Errorf("error writing file: %w", err)
+	}
+	return nil
+}
+
+// Remove removes a data file; removing a missing one is not an error.
+func (s *Store) Remove(name string) error {
+	if err := s.removeFile(s.Path(name)); err != nil {
+		return fmt.Errorf("error removing file: %w", err)
+	}
+	return nil
+}
+
+// Size returns the size in bytes of a data file, or -1 if it does not
+// exist. Comparing sizes tells cheaply whether a file has been written.
+func (s *Store) Size(name string) int64 {
+	return s.fileSize(s.Path(name))
+}
+
+// backup copies a data file, byte for byte, to a backup named after its
+// schema version and returns the backup's path.
+func (s *Store) backup(name string, version int) (string, error) {
+	path := s.Path(name)
+	data, err := s.readFile(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading file: %w", err)
+	}
+
+	backup := fmt.Sprintf("%s.v%d.bak", path, version)
+	if err := s.fs().WriteFile(backup, data, 0600); err != nil {
+		return "", fmt.Errorf("error writing backup: %w", err)
+	}
+	return backup, nil
+}
+
+// SetMissingField returns a migration that sets a key to a value made by
+// value in every record without it.
+func SetMissingField(key string, value func() any) Migration {
+	return func(items json.RawMessage) (json.RawMessage, error) {
+		var records []map[string]json.RawMessage
+		if err := json.Unmarshal(items, &records); err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			if _, ok := record[key]; !ok {
+				v, err := json.Marshal(value())
+				if err != nil {
+					return nil, err
+				}
+				record[key] = v
+			}
+		}
+		return json.Marshal(records)
+	}
+}
+
+// RenameField returns a migration that renames a key in every record.
+func RenameField(from, to string) Migration {
+	return func(items json.RawMessage) (json.RawMessage, error) {
+		var records []map[string]json.RawMessage
+		if err := json.Unmarshal(items, &records); err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			if value, ok := record[from]; ok {
+				record[to] = value
+				delete(record, from)
+			}
+		}
+		return json.Marshal(records)
+	}
+}
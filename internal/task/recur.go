@@ -0,0 +1,60 @@
+package task
+
+import "time"
+
+const (
+	RecurDaily   = "daily"
+	RecurWeekly  = "weekly"
+	RecurMonthly = "monthly"
+	RecurYearly  = "yearly"
+)
+
+// IsValidRecurrence reports whether r is a supported recurrence interval.
+func IsValidRecurrence(r string) bool {
+	switch r {
+	case RecurDaily, RecurWeekly, RecurMonthly, RecurYearly:
+		return true
+	}
+	return false
+}
+
+// NextOccurrence returns the due date following due for recurrence r.
+func NextOccurrence(due time.Time, r string) time.Time {
+	switch r {
+	case RecurDaily:
+		return due.AddDate(0, 0, 1)
+	case RecurWeekly:
+		return due.AddDate(0, 0, 7)
+	case RecurMonthly:
+		return due.AddDate(0, 1, 0)
+	default:
+		return due.AddDate(1, 0, 0)
+	}
+}
+
+// CompleteOccurrence advances a recurring task to its next occurrence
+// instead of leaving it done. It reports whether the task recurred.
+func (t *Task) CompleteOccurrence(w Workflow) bool {
+	if t.Recur == "" || t.Due == nil {
+		return false
+	}
+	next := NextOccurrence(*t.Due, t.Recur)
+	t.Due = &next
+	t.Status = w.Initial()
+	t.StartedAt = nil
+	if len(t.Rotation) > 0 {
+		t.Assignee = NextInRotation(t.Rotation, t.Assignee)
+	}
+	return true
+}
+
+// NextInRotation returns the assignee after current in rotation, wrapping
+// around at the end. An unknown current assignee starts the rotation over.
+func NextInRotation(rotation []string, current string) string {
+	for i, name := range rotation {
+		if name == current {
+			return rotation[(i+1)%len(rotation)]
+		}
+	}
+	return rotation[0]
+}
@@ -0,0 +1,185 @@
+// Package task defines the task record and the rules for moving a task
+// through its workflow and recurrences.
+package task
+
+import (
+	"crypto/rand"
+	"fmt"
+	"time"
+)
+
+// Task represents a single task with its properties
+// JSON tags are used for serialization/deserialization.
+type Task struct {
+	ID          int               `json:"id"`
+	UUID        string            `json:"uuid,omitempty"` // Never changes or is reused, unlike ID.
+	Description string            `json:"description"`
+	Status      string            `json:"status"`
+	Priority    string            `json:"priority,omitempty"`
+	Due         *time.Time        `json:"due,omitempty"`
+	Scheduled   *time.Time        `json:"scheduled,omitempty"`   // When work on the task is planned to start.
+	Wake        *time.Time        `json:"wake,omitempty"`        // When a snoozed task comes back to the task list.
+	URL         string            `json:"url,omitempty"`         // Set for reading list items.
+	Progress    int               `json:"progress,omitempty"`    // Reading progress in percent.
+	ReadMinutes int               `json:"readMinutes,omitempty"` // Estimated reading time.
+	Checklist   []ChecklistItem   `json:"checklist,omitempty"`
+	Recur       string            `json:"recur,omitempty"`      // How often the task repeats.
+	RemindDays  int               `json:"remindDays,omitempty"` // Days before the due date to start reminding.
+	Source      string            `json:"source,omitempty"`     // Identifies imported tasks for deduplication.
+	Pomodoros   []Pomodoro        `json:"pomodoros,omitempty"`
+	Assignee    string            `json:"assignee,omitempty"`
+	Estimate    float64           `json:"estimate,omitempty"` // Estimated hours of work.
+	Rotation    []string          `json:"rotation,omitempty"` // Assignees cycled through on each occurrence.
+	Tags        []string          `json:"tags,omitempty"`
+	StartedAt   *time.Time        `json:"startedAt,omitempty"`   // When the task first left its initial status.
+	Postponed   int               `json:"postponed,omitempty"`   // How many times the due date was pushed back.
+	CompletedAt *time.Time        `json:"completedAt,omitempty"` // When the task last moved to a done status.
+	KeyResult   int               `json:"keyResult,omitempty"`   // ID of the OKR key result the task contributes to.
+	WaitsOn     []string          `json:"waitsOn,omitempty"`     // UUIDs of the tasks to be done before this one.
+	Fields      map[string]string `json:"fields,omitempty"`      // The user's own metadata, by name.
+	ArchivedAt  *time.Time        `json:"archivedAt,omitempty"`  // When the task was moved to the archive.
+	CreatedAt   time.Time         `json:"createdAt"`
+	UpdatedAt   time.Time         `json:"updatedAt"`
+}
+
+// ChecklistItem is a lightweight entry within a task.
+type ChecklistItem struct {
+	Text string `json:"text"`
+	Done bool   `json:"done"`
+}
+
+// Pomodoro is a completed focus session recorded against a task.
+type Pomodoro struct {
+	Start   time.Time `json:"start"`
+	Minutes int       `json:"minutes"`
+}
+
+const (
+	StatusTodo  = "todo"
+	StatusDoing = "doing"
+	StatusDone  = "done"
+
+	PriorityLow    = "low"
+	PriorityMedium = "medium"
+	PriorityHigh   = "high"
+)
+
+// IsValidPriority reports whether p is a supported priority.
+func IsValidPriority(p string) bool {
+	return p == PriorityLow || p == PriorityMedium || p == PriorityHigh
+}
+
+// NextID returns the ID for a new task.
+func NextID(tasks []Task) int {
+	maxID := 0
+	for _, t := range tasks {
+		maxID = max(maxID, t.ID)
+	}
+	return maxID + 1
+}
+
+// NewUUID returns a random (version 4) UUID.
+func NewUUID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// IndexUUID returns the position of the task with uuid, or -1.
+func IndexUUID(tasks []Task, uuid string) int {
+	for i, t := range tasks {
+		if t.UUID == uuid {
+			return i
+		}
+	}
+	return -1
+}
+
+// Index returns the position of the task with id, or -1.
+func Index(tasks []Task, id int) int {
+	for i, t := range tasks {
+		if t.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// Deadline returns the instant a due date passes; date-only due dates last
+// until the end of the day.
+func Deadline(due time.Time) time.Time {
+	if due.Equal(time.Date(due.Year(), due.Month(), due.Day(), 0, 0, 0, 0, due.Location())) {
+		return due.AddDate(0, 0, 1)
+	}
+	return due
+}
+
+// InLocation returns the task with its times in loc, leaving t as it was.
+func (t Task) InLocation(loc *time.Location) Task {
+	in := func(p *time.Time) *time.Time {
+		if p == nil {
+			return nil
+		}
+		v := p.In(loc)
+		return &v
+	}
+	t.Due, t.Scheduled, t.Wake = in(t.Due), in(t.Scheduled), in(t.Wake)
+	t.StartedAt, t.CompletedAt, t.ArchivedAt = in(t.StartedAt), in(t.CompletedAt), in(t.ArchivedAt)
+	t.CreatedAt, t.UpdatedAt = t.CreatedAt.In(loc), t.UpdatedAt.In(loc)
+	if t.Pomodoros != nil {
+		pomodoros := make([]Pomodoro, len(t.Pomodoros))
+		for i, p := range t.Pomodoros {
+			p.Start = p.Start.In(loc)
+			pomodoros[i] = p
+		}
+		t.Pomodoros = pomodoros
+	}
+	return t
+}
+
+// ChecklistDone returns how many items of the task's checklist are done.
+func (t Task) ChecklistDone() int {
+	done := 0
+	for _, item := range t.Checklist {
+		if item.Done {
+			done++
+		}
+	}
+	return done
+}
+
+// Snoozed reports whether the task is snoozed until after now.
+func (t Task) Snoozed(now time.Time) bool {
+	return t.Wake != nil && t.Wake.After(now)
+}
+
+// ScheduledLater reports whether work on the task is planned to start on a
+// day after now's.
+func (t Task) ScheduledLater(now time.Time) bool {
+	tomorrow := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
+	return t.Scheduled != nil && !t.Scheduled.Before(tomorrow)
+}
+
+// Woken reports whether the task's snooze has run out by now and the task
+// has not been changed since.
+func (t Task) Woken(now time.Time) bool {
+	return t.Wake != nil && !t.Wake.After(now) && !t.UpdatedAt.After(*t.Wake)
+}
+
+// SetStatus moves the task to status, recording when it was first acted on
+// and when it was completed. Reopening a task forgets when it was completed.
+func (t *Task) SetStatus(status string, now time.Time, w Workflow) {
+	if t.StartedAt == nil && t.Status == w.Initial() && status != t.Status {
+		t.StartedAt = &now
+	}
+	switch {
+	case w.IsDone(status) && !w.IsDone(t.Status):
+		t.CompletedAt = &now
+	case !w.IsDone(status):
+		t.CompletedAt = nil
+	}
+	t.Status = status
+	t.UpdatedAt = now
+}
@@ -0,0 +1,130 @@
+package task
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Workflow defines the statuses a task moves through. The first status is
+// given to new tasks. Transitions lists, per status, the statuses a task may
+// move to next; a status without an entry may move to any status.
+type Workflow struct {
+	Statuses    []StatusDef         `json:"statuses"`
+	Transitions map[string][]string `json:"transitions,omitempty"`
+}
+
+// StatusDef describes one status of the workflow.
+type StatusDef struct {
+	Name  string `json:"name"`
+	Color string `json:"color,omitempty"` // A display color name; not interpreted here.
+	Done  bool   `json:"done,omitempty"`  // Tasks in this status count as completed.
+}
+
+// DefaultWorkflow returns the todo → doing → done workflow.
+func DefaultWorkflow() Workflow {
+	return Workflow{
+		Statuses: []StatusDef{
+			{Name: StatusTodo},
+			{Name: StatusDoing, Color: "yellow"},
+			{Name: StatusDone, Color: "green", Done: true},
+		},
+	}
+}
+
+// Validate checks that the workflow is usable.
+func (w Workflow) Validate() error {
+	if len(w.Statuses) == 0 {
+		return errors.New("at least one status is required")
+	}
+
+	seen := map[string]bool{}
+	hasDone := false
+	for _, s := range w.Statuses {
+		if s.Name == "" || s.Name != strings.ToLower(s.Name) || strings.ContainsAny(s.Name, " \t") {
+			return fmt.Errorf("status name '%s' must be a single lower-case word", s.Name)
+		}
+		if seen[s.Name] {
+			return fmt.Errorf("status '%s' is defined twice", s.Name)
+		}
+		seen[s.Name] = true
+		hasDone = hasDone || s.Done
+	}
+	if !hasDone {
+		return errors.New("at least one status must be marked done")
+	}
+
+	for from, targets := range w.Transitions {
+		if !seen[from] {
+			return fmt.Errorf("transition from unknown status '%s'", from)
+		}
+		for _, to := range targets {
+			if !seen[to] {
+				return fmt.Errorf("transition from '%s' to unknown status '%s'", from, to)
+			}
+		}
+	}
+	return nil
+}
+
+// StatusNames returns the workflow's statuses in order.
+func (w Workflow) StatusNames() []string {
+	names := make([]string, len(w.Statuses))
+	for i, s := range w.Statuses {
+		names[i] = s.Name
+	}
+	return names
+}
+
+// Find returns the definition of a status.
+func (w Workflow) Find(status string) (StatusDef, bool) {
+	for _, s := range w.Statuses {
+		if s.Name == status {
+			return s, true
+		}
+	}
+	return StatusDef{}, false
+}
+
+// HasStatus reports whether status is part of the workflow.
+func (w Workflow) HasStatus(status string) bool {
+	_, ok := w.Find(status)
+	return ok
+}
+
+// Initial returns the status given to new tasks.
+func (w Workflow) Initial() string {
+	return w.Statuses[0].Name
+}
+
+// IsDone reports whether status counts as completed.
+func (w Workflow) IsDone(status string) bool {
+	s, ok := w.Find(status)
+	return ok && s.Done
+}
+
+// DoneStatus returns the first status that counts as completed.
+func (w Workflow) DoneStatus() string {
+	for _, s := range w.Statuses {
+		if s.Done {
+			return s.Name
+		}
+	}
+	return StatusDone
+}
+
+// CanTransition checks whether a task may move from one status to another.
+func (w Workflow) CanTransition(from, to string) error {
+	if !w.HasStatus(to) {
+		return fmt.Errorf("unknown status '%s'; use one of: %s", to, strings.Join(w.StatusNames(), ", "))
+	}
+	allowed, restricted := w.Transitions[from]
+	if from == to || !restricted || slices.Contains(allowed, to) {
+		return nil
+	}
+	if len(allowed) == 0 {
+		return fmt.Errorf("tasks in '%s' cannot change status", from)
+	}
+	return fmt.Errorf("cannot move a task from '%s' to '%s'; allowed: %s", from, to, strings.Join(allowed, ", "))
+}
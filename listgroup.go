@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+// groupByModes are the fields 'task list --group-by' can group tasks by.
+var groupByModes = []string{"project", "status", "due-week"}
+
+// noDueGroup names the section of tasks without a due date.
+const noDueGroup = "no due date"
+
+// taskGroup is a section of a grouped list.
+type taskGroup struct {
+	name  string
+	tasks []Task
+}
+
+// groupSubtotal sums up a section of a grouped list, or the whole list.
+type groupSubtotal struct {
+	tasks    int
+	estimate float64
+	cost     map[string]float64
+}
+
+// add counts tasks into s, with what was spent on them from costs.
+func (s *groupSubtotal) add(tasks []Task, costs map[string]map[string]float64) {
+	for _, task := range tasks {
+		s.tasks++
+		s.estimate += task.Estimate
+		for currency, amount := range costs[task.UUID] {
+			if s.cost == nil {
+				s.cost = map[string]float64{}
+			}
+			s.cost[currency] += amount
+		}
+	}
+}
+
+// String describes s, such as "3 tasks, 5h estimated, cost $12.00".
+func (s groupSubtotal) String() string {
+	parts := []string{plural(s.tasks, "task")}
+	if s.estimate > 0 {
+		parts = append(parts, formatHours(s.estimate)+" estimated")
+	}
+	if len(s.cost) > 0 {
+		parts = append(parts, "cost "+formatTotals(s.cost))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// listGroupedTasks prints the tasks matching filter in a section per
+// project, status or week due, each headed by its subtotal, and the total
+// after them.
+func listGroupedTasks(filter tracker.TaskFilter, opts listOptions) error {
+	var total groupSubtotal
+	now := clock()
+	section := func(name string, tasks []Task, costs map[string]map[string]float64) {
+		var sub groupSubtotal
+		sub.add(tasks, costs)
+		total.add(tasks, costs)
+		if opts.columns != nil && total.tasks > sub.tasks {
+			fmt.Fprintln(stdout)
+		}
+		fmt.Fprintf(stdout, "--- %s (%s) ---\n", name, sub)
+		printTasks(tasks, opts, costs, now)
+	}
+
+	if opts.groupBy == "project" {
+		err := forEachProject(func(project string) error {
+			tasks, err := tr().ListTasks(filter)
+			if err != nil || len(tasks) == 0 {
+				return err
+			}
+			if !opts.byID && !filter.Archived {
+				if err := sortByUrgency(tasks); err != nil {
+					return err
+				}
+			}
+			costs, err := listCosts(opts)
+			if err != nil {
+				return err
+			}
+			section(project, tasks, costs)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	} else {
+		tasks, err := tr().ListTasks(filter)
+		if err != nil {
+			return err
+		}
+		if !opts.byID && !filter.Archived {
+			if err := sortByUrgency(tasks); err != nil {
+				return err
+			}
+		}
+		costs, err := listCosts(opts)
+		if err != nil {
+			return err
+		}
+		for _, group := range sectionTasks(tasks, opts.groupBy) {
+			section(group.name, group.tasks, costs)
+		}
+	}
+
+	if total.tasks == 0 {
+		printNoTasks(filter)
+		return nil
+	}
+	if opts.columns != nil {
+		fmt.Fprintln(stdout)
+	}
+	fmt.Fprintf(stdout, "--- Total: %s ---\n", total)
+	return nil
+}
+
+// sectionTasks splits tasks by status, in workflow order, or by the week they
+// are due, earliest first and tasks without a due date last.
+func sectionTasks(tasks []Task, by string) []taskGroup {
+	var groups []taskGroup
+	add := func(name string, task Task) {
+		i := slices.IndexFunc(groups, func(g taskGroup) bool { return g.name == name })
+		if i < 0 {
+			groups = append(groups, taskGroup{name: name})
+			i = len(groups) - 1
+		}
+		groups[i].tasks = append(groups[i].tasks, task)
+	}
+
+	switch by {
+	case "status":
+		for _, def := range config.Workflow.Statuses {
+			for _, task := range tasks {
+				if task.Status == def.Name {
+					add(def.Name, task)
+				}
+			}
+		}
+		for _, task := range tasks {
+			if !config.Workflow.HasStatus(task.Status) {
+				add(task.Status, task)
+			}
+		}
+	case "due-week":
+		weeks := map[string]time.Time{}
+		for _, task := range tasks {
+			if task.Due == nil {
+				continue
+			}
+			week := startOfWeek(*task.Due)
+			name := "week of " + week.Format(dateLayout)
+			weeks[name] = week
+			add(name, task)
+		}
+		slices.SortStableFunc(groups, func(a, b taskGroup) int {
+			return weeks[a.name].Compare(weeks[b.name])
+		})
+		for _, task := range tasks {
+			if task.Due == nil {
+				add(noDueGroup, task)
+			}
+		}
+	}
+	return groups
+}
@@ -0,0 +1,366 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// outputLocale holds how a language shows dates and amounts, and the
+// translations of the messages printed in it.
+type outputLocale struct {
+	dateLayout string            // Dates as shown, readable again as a due date in the same locale.
+	decimal    string            // Separates the cents of an amount.
+	group      string            // Separates the thousands of an amount.
+	weekdays   []string          // Names of the days, from Sunday.
+	months     []string          // Names of the months, from January.
+	messages   map[string]string // Translated messages, by the English ones.
+}
+
+// outputLocales are the languages output can be shown in, one for every
+// language dates can be written in. Messages without a translation, and
+// all of them in languages without a catalog yet, are shown in English.
+var outputLocales = map[string]outputLocale{
+	"en": {dateLayout: dateLayout, decimal: ".", group: ","},
+	"de": {
+		dateLayout: "02.01.2006", decimal: ",", group: ".",
+		weekdays: []string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+		months:   []string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+		messages: map[string]string{
+			"Task added successfully (ID: %d)\n":                   "Aufgabe hinzugefügt (ID: %d)\n",
+			"Task not deleted.":                                    "Aufgabe nicht gelöscht.",
+			"Task ID %d deleted successfully\n":                    "Aufgabe ID %d gelöscht\n",
+			"Task ID %d marked as %s.\n":                           "Aufgabe ID %d als %s markiert.\n",
+			"Task ID %d snoozed until %s.\n":                       "Aufgabe ID %d zurückgestellt bis %s.\n",
+			"Task ID %d recurs %s; next due %s.\n":                 "Aufgabe ID %d wiederholt sich %s; nächste Fälligkeit %s.\n",
+			"--- Task List ---":                                    "--- Aufgabenliste ---",
+			"--- Archived Tasks ---":                               "--- Archivierte Aufgaben ---",
+			"--- Task ---":                                         "--- Aufgabe ---",
+			"No tasks found with status: %s\n":                     "Keine Aufgaben mit Status %s gefunden\n",
+			"No archived tasks found with status: %s\n":            "Keine archivierten Aufgaben mit Status %s gefunden\n",
+			"No tasks match the query.\n":                          "Keine Aufgaben entsprechen der Abfrage.\n",
+			"No archived tasks match the query.\n":                 "Keine archivierten Aufgaben entsprechen der Abfrage.\n",
+			"%s snoozed; see them with --snoozed.\n":               "%s zurückgestellt; anzeigen mit --snoozed.\n",
+			"%s scheduled for later; see them with --scheduled.\n": "%s für später geplant; anzeigen mit --scheduled.\n",
+			"  Created: %s | Updated: %s\n":                        "  Erstellt: %s | Geändert: %s\n",
+			"  Due: %s | Priority: %s":                             "  Fällig: %s | Priorität: %s",
+			" | Scheduled: %s":                                     " | Geplant: %s",
+			" | Repeats: %s":                                       " | Wiederholung: %s",
+			" | Assignee: %s":                                      " | Zuständig: %s",
+			" | Estimate: %s":                                      " | Schätzung: %s",
+			"  Tags: %s\n":                                         "  Schlagwörter: %s\n",
+			"  Waits on: %s\n":                                     "  Wartet auf: %s\n",
+			"  Fields: %s\n":                                       "  Felder: %s\n",
+			"  Cost: %s\n":                                         "  Kosten: %s\n",
+			"(snoozed until %s)":                                   "(zurückgestellt bis %s)",
+			"(woke %s)":                                            "(geweckt %s)",
+			"due %s":                                               "fällig %s",
+
+			"Expense added successfully (ID: %d)\n": "Ausgabe hinzugefügt (ID: %d)\n",
+			"Expense not deleted.":                  "Ausgabe nicht gelöscht.",
+			"Expense ID %d deleted successfully\n":  "Ausgabe ID %d gelöscht\n",
+			"No expenses found.":                    "Keine Ausgaben gefunden.",
+			"--- Expenses ---":                      "--- Ausgaben ---",
+			"--- Expense ---":                       "--- Ausgabe ---",
+			"--- Total: %s ---\n":                   "--- Summe: %s ---\n",
+			"--- Cost: %s ---\n":                    "--- Kosten: %s ---\n",
+			"  Category: %s | Payee: %s\n":          "  Kategorie: %s | Empfänger: %s\n",
+			"  Task: %d %s\n":                       "  Aufgabe: %d %s\n",
+			" (task %d)":                            " (Aufgabe %d)",
+			"Expense Summary":                       "Ausgabenübersicht",
+			"Expense Summary in %s":                 "Ausgabenübersicht in %s",
+			"Income added successfully (ID: %d)\n":  "Einnahme hinzugefügt (ID: %d)\n",
+			"Income ID %d deleted successfully\n":   "Einnahme ID %d gelöscht\n",
+			"No income found.":                      "Keine Einnahmen gefunden.",
+			"--- Income ---":                        "--- Einnahmen ---",
+			"Today, %s":                             "Heute, %s",
+			"Tomorrow, %s":                          "Morgen, %s",
+			"Nothing planned.":                      "Nichts geplant.",
+			"Overdue":                               "Überfällig",
+			"Morning":                               "Vormittag",
+			"Afternoon":                             "Nachmittag",
+			"Evening":                               "Abend",
+			"Any time":                              "Jederzeit",
+			"due":                                   "fällig",
+			"scheduled":                             "geplant",
+			"wakes":                                 "wird geweckt",
+			"repeats %s":                            "wiederholt sich %s",
+			"just now":                              "gerade eben",
+			"in %s":                                 "in %s",
+			"%s ago":                                "vor %s",
+			"overdue by %s":                         "seit %s überfällig",
+			"today":                                 "heute",
+			"tomorrow":                              "morgen",
+			"%s at %s":                              "%s um %s",
+			"1 minute":                              "1 Minute",
+			"%d minutes":                            "%d Minuten",
+			"1 hour":                                "1 Stunde",
+			"%d hours":                              "%d Stunden",
+			"1 day":                                 "1 Tag",
+			"%d days":                               "%d Tagen",
+			"1 month":                               "1 Monat",
+			"%d months":                             "%d Monaten",
+			"1 year":                                "1 Jahr",
+			"%d years":                              "%d Jahren",
+			"1 task":                                "1 Aufgabe",
+			"%d tasks":                              "%d Aufgaben",
+		},
+	},
+	"fr": {
+		dateLayout: "02/01/2006", decimal: ",", group: " ",
+		weekdays: []string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+		months:   []string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+		messages: map[string]string{
+			"Task added successfully (ID: %d)\n":                   "Tâche ajoutée (ID : %d)\n",
+			"Task not deleted.":                                    "Tâche non supprimée.",
+			"Task ID %d deleted successfully\n":                    "Tâche ID %d supprimée\n",
+			"Task ID %d marked as %s.\n":                           "Tâche ID %d marquée %s.\n",
+			"Task ID %d snoozed until %s.\n":                       "Tâche ID %d reportée jusqu'au %s.\n",
+			"Task ID %d recurs %s; next due %s.\n":                 "Tâche ID %d se répète %s ; prochaine échéance %s.\n",
+			"--- Task List ---":                                    "--- Liste des tâches ---",
+			"--- Archived Tasks ---":                               "--- Tâches archivées ---",
+			"--- Task ---":                                         "--- Tâche ---",
+			"No tasks found with status: %s\n":                     "Aucune tâche trouvée avec le statut : %s\n",
+			"No archived tasks found with status: %s\n":            "Aucune tâche archivée trouvée avec le statut : %s\n",
+			"No tasks match the query.\n":                          "Aucune tâche ne correspond à la requête.\n",
+			"No archived tasks match the query.\n":                 "Aucune tâche archivée ne correspond à la requête.\n",
+			"%s snoozed; see them with --snoozed.\n":               "%s en sommeil ; voir avec --snoozed.\n",
+			"%s scheduled for later; see them with --scheduled.\n": "%s planifiée(s) pour plus tard ; voir avec --scheduled.\n",
+			"  Created: %s | Updated: %s\n":                        "  Créée : %s | Modifiée : %s\n",
+			"  Due: %s | Priority: %s":                             "  Échéance : %s | Priorité : %s",
+			" | Scheduled: %s":                                     " | Planifiée : %s",
+			" | Repeats: %s":                                       " | Répétition : %s",
+			" | Assignee: %s":                                      " | Responsable : %s",
+			" | Estimate: %s":                                      " | Estimation : %s",
+			"  Tags: %s\n":                                         "  Étiquettes : %s\n",
+			"  Waits on: %s\n":                                     "  Attend : %s\n",
+			"  Fields: %s\n":                                       "  Champs : %s\n",
+			"  Cost: %s\n":                                         "  Coût : %s\n",
+			"(snoozed until %s)":                                   "(reportée jusqu'au %s)",
+			"(woke %s)":                                            "(réveillée %s)",
+			"due %s":                                               "échéance %s",
+
+			"Expense added successfully (ID: %d)\n": "Dépense ajoutée (ID : %d)\n",
+			"Expense not deleted.":                  "Dépense non supprimée.",
+			"Expense ID %d deleted successfully\n":  "Dépense ID %d supprimée\n",
+			"No expenses found.":                    "Aucune dépense trouvée.",
+			"--- Expenses ---":                      "--- Dépenses ---",
+			"--- Expense ---":                       "--- Dépense ---",
+			"--- Total: %s ---\n":                   "--- Total : %s ---\n",
+			"--- Cost: %s ---\n":                    "--- Coût : %s ---\n",
+			"  Category: %s | Payee: %s\n":          "  Catégorie : %s | Bénéficiaire : %s\n",
+			"  Task: %d %s\n":                       "  Tâche : %d %s\n",
+			" (task %d)":                            " (tâche %d)",
+			"Expense Summary":                       "Résumé des dépenses",
+			"Expense Summary in %s":                 "Résumé des dépenses en %s",
+			"Income added successfully (ID: %d)\n":  "Revenu ajouté (ID : %d)\n",
+			"Income ID %d deleted successfully\n":   "Revenu ID %d supprimé\n",
+			"No income found.":                      "Aucun revenu trouvé.",
+			"--- Income ---":                        "--- Revenus ---",
+			"Today, %s":                             "Aujourd'hui, %s",
+			"Tomorrow, %s":                          "Demain, %s",
+			"Nothing planned.":                      "Rien de prévu.",
+			"Overdue":                               "En retard",
+			"Morning":                               "Matin",
+			"Afternoon":                             "Après-midi",
+			"Evening":                               "Soir",
+			"Any time":                              "À tout moment",
+			"due":                                   "échéance",
+			"scheduled":                             "planifiée",
+			"wakes":                                 "se réveille",
+			"repeats %s":                            "se répète %s",
+			"just now":                              "à l'instant",
+			"in %s":                                 "dans %s",
+			"%s ago":                                "il y a %s",
+			"overdue by %s":                         "en retard de %s",
+			"today":                                 "aujourd'hui",
+			"tomorrow":                              "demain",
+			"%s at %s":                              "%s à %s",
+			"1 minute":                              "1 minute",
+			"%d minutes":                            "%d minutes",
+			"1 hour":                                "1 heure",
+			"%d hours":                              "%d heures",
+			"1 day":                                 "1 jour",
+			"%d days":                               "%d jours",
+			"1 month":                               "1 mois",
+			"%d months":                             "%d mois",
+			"1 year":                                "1 an",
+			"%d years":                              "%d ans",
+			"1 task":                                "1 tâche",
+			"%d tasks":                              "%d tâches",
+		},
+	},
+	"es": {
+		dateLayout: "02/01/2006", decimal: ",", group: ".",
+		weekdays: []string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"},
+		months:   []string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		messages: map[string]string{
+			"Task added successfully (ID: %d)\n":                   "Tarea añadida (ID: %d)\n",
+			"Task not deleted.":                                    "Tarea no eliminada.",
+			"Task ID %d deleted successfully\n":                    "Tarea ID %d eliminada\n",
+			"Task ID %d marked as %s.\n":                           "Tarea ID %d marcada como %s.\n",
+			"Task ID %d snoozed until %s.\n":                       "Tarea ID %d pospuesta hasta %s.\n",
+			"Task ID %d recurs %s; next due %s.\n":                 "Tarea ID %d se repite %s; próximo vencimiento %s.\n",
+			"--- Task List ---":                                    "--- Lista de tareas ---",
+			"--- Archived Tasks ---":                               "--- Tareas archivadas ---",
+			"--- Task ---":                                         "--- Tarea ---",
+			"No tasks found with status: %s\n":                     "No se encontraron tareas con estado: %s\n",
+			"No archived tasks found with status: %s\n":            "No se encontraron tareas archivadas con estado: %s\n",
+			"No tasks match the query.\n":                          "Ninguna tarea coincide con la consulta.\n",
+			"No archived tasks match the query.\n":                 "Ninguna tarea archivada coincide con la consulta.\n",
+			"%s snoozed; see them with --snoozed.\n":               "%s pospuesta(s); véalas con --snoozed.\n",
+			"%s scheduled for later; see them with --scheduled.\n": "%s planificada(s) para más tarde; véalas con --scheduled.\n",
+			"  Created: %s | Updated: %s\n":                        "  Creada: %s | Modificada: %s\n",
+			"  Due: %s | Priority: %s":                             "  Vence: %s | Prioridad: %s",
+			" | Scheduled: %s":                                     " | Planificada: %s",
+			" | Repeats: %s":                                       " | Se repite: %s",
+			" | Assignee: %s":                                      " | Responsable: %s",
+			" | Estimate: %s":                                      " | Estimación: %s",
+			"  Tags: %s\n":                                         "  Etiquetas: %s\n",
+			"  Waits on: %s\n":                                     "  Espera a: %s\n",
+			"  Fields: %s\n":                                       "  Campos: %s\n",
+			"  Cost: %s\n":                                         "  Coste: %s\n",
+			"(snoozed until %s)":                                   "(pospuesta hasta %s)",
+			"(woke %s)":                                            "(despertó %s)",
+			"due %s":                                               "vence %s",
+
+			"Expense added successfully (ID: %d)\n": "Gasto añadido (ID: %d)\n",
+			"Expense not deleted.":                  "Gasto no eliminado.",
+			"Expense ID %d deleted successfully\n":  "Gasto ID %d eliminado\n",
+			"No expenses found.":                    "No se encontraron gastos.",
+			"--- Expenses ---":                      "--- Gastos ---",
+			"--- Expense ---":                       "--- Gasto ---",
+			"--- Total: %s ---\n":                   "--- Total: %s ---\n",
+			"--- Cost: %s ---\n":                    "--- Coste: %s ---\n",
+			"  Category: %s | Payee: %s\n":          "  Categoría: %s | Beneficiario: %s\n",
+			"  Task: %d %s\n":                       "  Tarea: %d %s\n",
+			" (task %d)":                            " (tarea %d)",
+			"Expense Summary":                       "Resumen de gastos",
+			"Expense Summary in %s":                 "Resumen de gastos en %s",
+			"Income added successfully (ID: %d)\n":  "Ingreso añadido (ID: %d)\n",
+			"Income ID %d deleted successfully\n":   "Ingreso ID %d eliminado\n",
+			"No income found.":                      "No se encontraron ingresos.",
+			"--- Income ---":                        "--- Ingresos ---",
+			"Today, %s":                             "Hoy, %s",
+			"Tomorrow, %s":                          "Mañana, %s",
+			"Nothing planned.":                      "Nada previsto.",
+			"Overdue":                               "Vencidas",
+			"Morning":                               "Mañana",
+			"Afternoon":                             "Tarde",
+			"Evening":                               "Noche",
+			"Any time":                              "En cualquier momento",
+			"due":                                   "vence",
+			"scheduled":                             "planificada",
+			"wakes":                                 "despierta",
+			"repeats %s":                            "se repite %s",
+			"just now":                              "ahora mismo",
+			"in %s":                                 "en %s",
+			"%s ago":                                "hace %s",
+			"overdue by %s":                         "vencida hace %s",
+			"today":                                 "hoy",
+			"tomorrow":                              "mañana",
+			"%s at %s":                              "%s a las %s",
+			"1 minute":                              "1 minuto",
+			"%d minutes":                            "%d minutos",
+			"1 hour":                                "1 hora",
+			"%d hours":                              "%d horas",
+			"1 day":                                 "1 día",
+			"%d days":                               "%d días",
+			"1 month":                               "1 mes",
+			"%d months":                             "%d meses",
+			"1 year":                                "1 año",
+			"%d years":                              "%d años",
+			"1 task":                                "1 tarea",
+			"%d tasks":                              "%d tareas",
+		},
+	},
+	"it": {dateLayout: "02/01/2006", decimal: ",", group: "."},
+	"nl": {dateLayout: "02-01-2006", decimal: ",", group: "."},
+	"pt": {dateLayout: "02/01/2006", decimal: ",", group: "."},
+}
+
+// envLocale returns the locale set for a category such as "LC_TIME": the
+// one in config.json, otherwise the first of LC_ALL, the category and LANG
+// that names a known language, or "" if none does.
+func envLocale(category string) string {
+	for _, locale := range []string{config.Locale, os.Getenv("LC_ALL"), os.Getenv(category), os.Getenv("LANG")} {
+		if _, ok := dateLocales[localeLanguage(locale)]; ok {
+			return locale
+		}
+	}
+	return ""
+}
+
+// currentLocale returns how output of a category, such as "LC_MESSAGES",
+// is shown, in English unless a locale says otherwise.
+func currentLocale(category string) outputLocale {
+	if l, ok := outputLocales[localeLanguage(envLocale(category))]; ok {
+		return l
+	}
+	return outputLocales["en"]
+}
+
+// msg returns the translation of a message, or the message itself if its
+// language has none.
+func msg(english string) string {
+	if s, ok := currentLocale("LC_MESSAGES").messages[english]; ok {
+		return s
+	}
+	return english
+}
+
+// localPlural returns a count of unit, such as "3 days", in the language of
+// messages.
+func localPlural(n int, unit string) string {
+	if n == 1 {
+		return msg("1 " + unit)
+	}
+	return fmt.Sprintf(msg("%d "+unit+"s"), n)
+}
+
+// formatDate renders the day of t as dates are written in the locale, such
+// as "24.12.2025" in German.
+func formatDate(t time.Time) string {
+	return t.Format(currentLocale("LC_TIME").dateLayout)
+}
+
+// formatLocalTime formats t with layout, naming days and months in the
+// language of the locale.
+func formatLocalTime(t time.Time, layout string) string {
+	s := t.Format(layout)
+	l := currentLocale("LC_TIME")
+	if len(l.weekdays) == 0 {
+		return s
+	}
+	var names []string
+	if strings.Contains(layout, "Monday") {
+		names = append(names, t.Weekday().String(), l.weekdays[t.Weekday()])
+	}
+	if strings.Contains(layout, "January") {
+		names = append(names, t.Month().String(), l.months[t.Month()-1])
+	}
+	return strings.NewReplacer(names...).Replace(s)
+}
+
+// formatNumber renders an amount with two decimals and the separators of
+// the locale, such as "1.234,50" in German.
+func formatNumber(amount float64) string {
+	l := currentLocale("LC_MONETARY")
+	digits := strconv.FormatFloat(math.Abs(amount), 'f', 2, 64)
+	whole, cents := digits[:len(digits)-3], digits[len(digits)-2:]
+	var b strings.Builder
+	if amount < 0 && digits != "0.00" {
+		b.WriteByte('-')
+	}
+	for i, d := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(l.group)
+		}
+		b.WriteRune(d)
+	}
+	b.WriteString(l.decimal + cents)
+	return b.String()
+}
@@ -0,0 +1,22 @@
+//go:build !linux && !darwin && !windows
+
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+// errLocked is returned when a lock is held elsewhere and not waited for.
+var errLocked = errors.New("locked")
+
+// lockFileExclusive does nothing: file locks are not supported on this
+// platform.
+func lockFileExclusive(*os.File, bool) error {
+	return nil
+}
+
+// unlockFile does nothing.
+func unlockFile(*os.File) error {
+	return nil
+}
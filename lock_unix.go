@@ -0,0 +1,31 @@
+//go:build linux || darwin
+
+package main
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// errLocked is returned when a lock is held elsewhere and not waited for.
+var errLocked = errors.New("locked")
+
+// lockFileExclusive takes an exclusive lock on f, waiting for it if wait is
+// set and otherwise failing with errLocked.
+func lockFileExclusive(f *os.File, wait bool) error {
+	how := syscall.LOCK_EX
+	if !wait {
+		how |= syscall.LOCK_NB
+	}
+	err := syscall.Flock(int(f.Fd()), how)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return errLocked
+	}
+	return err
+}
+
+// unlockFile releases the lock on f.
+func unlockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
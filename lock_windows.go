@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	lockfileFailImmediately = 0x1
+	lockfileExclusiveLock   = 0x2
+	errorLockViolation      = syscall.Errno(33)
+)
+
+var (
+	procLockFileEx   = kernel32.NewProc("LockFileEx")
+	procUnlockFileEx = kernel32.NewProc("UnlockFileEx")
+)
+
+// errLocked is returned when a lock is held elsewhere and not waited for.
+var errLocked = errors.New("locked")
+
+// lockFileExclusive takes an exclusive lock on f with LockFileEx, waiting
+// for it if wait is set and otherwise failing with errLocked.
+func lockFileExclusive(f *os.File, wait bool) error {
+	flags := uintptr(lockfileExclusiveLock)
+	if !wait {
+		flags |= lockfileFailImmediately
+	}
+	var ol syscall.Overlapped
+	r, _, err := procLockFileEx.Call(f.Fd(), flags, 0, 1, 0, uintptr(unsafe.Pointer(&ol)))
+	if r != 0 {
+		return nil
+	}
+	if errors.Is(err, errorLockViolation) {
+		return errLocked
+	}
+	return err
+}
+
+// unlockFile releases the lock on f.
+func unlockFile(f *os.File) error {
+	var ol syscall.Overlapped
+	r, _, err := procUnlockFileEx.Call(f.Fd(), 0, 1, 0, uintptr(unsafe.Pointer(&ol)))
+	if r == 0 {
+		return err
+	}
+	return nil
+}
@@ -1,309 +1,974 @@
 package main
 
 import (
-	"encoding/json"
+	"bufio"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
-)
-
-// Task represents a single task with its properties
-// JSON tags are used for serialization/deserialization.
-type Task struct {
-	ID          int       `json:"id"`
-	Description string    `json:"description"`
-	Status      string    `json:"status"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAT"`
-}
 
-const (
-	tasksFile   = "tasks.json" // The name of the saved task file.
-	statusTodo  = "todo"
-	statusDone  = "done"
-	statusDoing = "doing"
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
 )
 
+const dateLayout = "2006-01-02" // The format accepted for due dates.
+
 func main() {
-	// Check for a command argument
-	if len(os.Args) < 2 {
-		printUsage()
-		os.Exit(1)
+	// Strip the global --project flag so commands see their usual arguments
+	args := extractGlobalFlags(os.Args[1:])
+	args, project, err := extractProjectFlag(args)
+	if err != nil {
+		exitWith(err)
 	}
+	args = extractGlobalFlags(args)
 
-	// The first argument is the command (e.g., "add", "list")
-	command := os.Args[1]
-
-	var err error
-
-	switch command {
-	case "add":
-		// Usage: task add "Description"
-		if len(os.Args) < 3 {
-			fmt.Println("Usage: task add <description>")
-			printUsage()
-			os.Exit(1)
-		}
-		err = addTask(os.Args[2])
-
-	case "update":
-		// Usage: task update ID "New Description"
-		if len(os.Args) < 4 {
-			fmt.Println("Usage: task update <id> <new description>")
-			os.Exit(1)
-		}
-		id, parseErr := strconv.Atoi(os.Args[2])
-		if parseErr != nil {
-			fmt.Printf("Error: Invalid task ID '%s'.\n", os.Args[2])
-			os.Exit(1)
-		}
-		err = updateTask(id, os.Args[3])
-
-	case "delete":
-		// Usage: task delete ID
-		if len(os.Args) < 3 {
-			fmt.Println("Usage: task delete <id>")
-			os.Exit(1)
-		}
-		id, parseErr := strconv.Atoi(os.Args[2])
-		if parseErr != nil {
-			fmt.Printf("Error: Invalid task ID '%s'.\n", os.Args[2])
-			os.Exit(1)
-		}
-		err = deleteTask(id)
-
-	case "mark":
-		// Usage: task mark <status> <id>
-		if len(os.Args) < 4 {
-			fmt.Println("Usage: task mark <status> <id>")
-			os.Exit(1)
-		}
+	if err := useDataDir(); err != nil {
+		exitWith(err)
+	}
 
-		status := strings.ToLower(os.Args[2])
-		if status != statusDone && status != statusTodo && status != statusDoing {
-			fmt.Printf("Invalid mark status '%s'. Use 'todo', 'doing', or 'done'.\n", status)
-			os.Exit(1)
-		}
+	// Check for a command argument
+	root := rootCommand()
+	if len(args) == 0 {
+		printCommandHelp(stdout, root, []string{root.name})
+		os.Exit(1)
+	}
 
-		id, parseErr := strconv.Atoi(os.Args[3])
-		if parseErr != nil {
-			fmt.Printf("Error: Invalid task ID '%s'.\n", os.Args[2])
-			os.Exit(1)
-		}
+	// Project commands must keep working even if the saved project is gone
+	if err := selectProject(project); err != nil && args[0] != "project" && args[0] != "__complete" {
+		exitWith(err)
+	}
 
-		err = updateTaskStatus(id, status)
-		if err == nil {
-			fmt.Printf("Task ID %d marked as %s.\n", id, status)
-		}
+	if err := loadConfig(); err != nil {
+		exitWith(err)
+	}
 
-	case "list":
-		// Usage: task list <status>
-		filter := ""
-		if len(os.Args) == 3 {
-			filter = os.Args[2]
-			// Basic validation for list filters
-			if filter != statusDone && filter != statusTodo && filter != statusDoing {
-				fmt.Printf("Invalid list status filter '%s'. Use 'done', 'todo', or 'doing'.\n", filter)
-				os.Exit(1)
-			}
+	// Keep other task commands from changing the data files meanwhile
+	if holdsDataLock(args[0]) {
+		unlock, err := lockData()
+		if err != nil {
+			exitWith(err)
 		}
-		err = listTasks(filter)
-
-	default:
-		fmt.Printf("Error: Unknown command '%s'\n", command)
-		printUsage()
-		os.Exit(1)
+		defer unlock()
 	}
 
 	// Graceful error handling for task operations
+	showOutput := pageOutput(root, args)
+	if dryRun {
+		err = executeDryRun(root, args)
+	} else {
+		err = executeAndRecord(root, project, args)
+	}
+	showOutput()
 	if err != nil {
-		fmt.Printf("Operation Failed: %v\n", err)
-		os.Exit(1)
+		os.Exit(reportError(err))
 	}
 }
 
-// printUsage displays the application's command usage instructions.
-func printUsage() {
-	fmt.Println("\nUsage: task <command> [arguments]")
-	fmt.Println("\nCommands:")
-	fmt.Println("  add \"<description>\"                    - Add a new task")
-	fmt.Println("  update <ID> \"<new description>\"        - Update a task's description")
-	fmt.Println("  delete <ID>                            - Delete a task")
-	fmt.Println("  mark <status> <ID>                     - Mark a task with a status (todo, doing, done)")
-	fmt.Println("  list <status>                          - List all tasks or filter by status (todo, doing, done)")
-	fmt.Println()
+// rootCommand returns the command tree of the CLI.
+func rootCommand() *command {
+	root := &command{
+		name: "task",
+		subcommands: []*command{
+			{
+				name: "add", args: "[description]", summary: "Add a new task, or one from a template", group: groupTasks,
+				completeFlags: map[string]func() []candidate{"template": templateCandidates},
+				setup: func(fs *flag.FlagSet) runFunc {
+					fields := fieldFlag{}
+					fs.Var(fields, "field", "set one of your own fields, as `name=value`; repeat for more")
+					template := fs.String("template", "", "add the task the `template` in templates.json describes, instead of a description")
+					vars := templateVars{}
+					fs.Var(vars, "var", "fill in the template's {name} with value, as `name=value`; repeat for more")
+					return func(args []string) error {
+						switch {
+						case *template != "" && len(args) > 0:
+							return usagef("give either a description or --template")
+						case *template != "":
+							return addTaskFromTemplate(*template, vars, fields)
+						case len(vars) > 0:
+							return usagef("--var needs --template")
+						case len(args) == 0:
+							return usagef("expected a description")
+						}
+						return addTask(strings.Join(args, " "), fields)
+					}
+				},
+			},
+			{
+				name: "update", args: "<id> <new description>", summary: "Update a task's description", group: groupTasks, minArgs: 2,
+				complete: positional(taskIDs),
+				setup: run(func(args []string) error {
+					id, err := parseID(args[0], "task")
+					if err != nil {
+						return err
+					}
+					return updateTask(id, strings.Join(args[1:], " "))
+				}),
+			},
+			{
+				name: "delete", args: "<id>", summary: "Delete a task, after showing it and asking", group: groupTasks, minArgs: 1,
+				complete: positional(taskIDs),
+				setup: func(fs *flag.FlagSet) runFunc {
+					yes := fs.Bool("yes", false, "delete without asking for confirmation")
+					return func(args []string) error {
+						id, err := parseID(args[0], "task")
+						if err != nil {
+							return err
+						}
+						return deleteTask(id, *yes)
+					}
+				},
+			},
+			{
+				name: "mark", args: "<status> <id>", summary: "Mark a task with a status (todo, doing, done by default)", group: groupTasks, minArgs: 2,
+				complete: positional(statuses, taskIDsToMark),
+				setup: run(func(args []string) error {
+					status := strings.ToLower(args[0])
+					if err := checkStatus(status); err != nil {
+						return err
+					}
+					id, err := parseID(args[1], "task")
+					if err != nil {
+						return err
+					}
+					if err := updateTaskStatus(id, status); err != nil {
+						return err
+					}
+					printDone(msg("Task ID %d marked as %s.\n"), id, status)
+					return nil
+				}),
+			},
+			{
+				name: "list", args: "[status|filter|query]", summary: "List all tasks, or those matching a status, saved filter or query", group: groupTasks,
+				complete:      positional(func(args []string) []candidate { return append(statuses(args), filterCandidates(args)...) }),
+				completeFlags: map[string]func() []candidate{"status": func() []candidate { return statuses(nil) }, "tag": tagCandidates},
+				setup: func(fs *flag.FlagSet) runFunc {
+					status := fs.String("status", "", "only list tasks with this status")
+					tag := fs.String("tag", "", "only list tasks with this tag")
+					query := fs.String("query", "", "only list tasks matching this `query`, such as \"status:doing and (#home or priority:high)\"")
+					fs.StringVar(query, "q", "", "short for --query")
+					since := fs.String("since", "", "only list tasks due on or after this `date`")
+					until := fs.String("until", "", "only list tasks due on or before this `date`")
+					doneSince := fs.String("done-since", "", "only list tasks completed on or after this `date`")
+					doneUntil := fs.String("done-until", "", "only list tasks completed on or before this `date`")
+					archived := fs.Bool("archived", false, "list archived tasks instead")
+					all := fs.Bool("all", false, "list snoozed tasks and those scheduled for later too")
+					snoozed := fs.Bool("snoozed", false, "only list tasks snoozed until later")
+					scheduled := fs.Bool("scheduled", false, "only list tasks scheduled to start on a later day, soonest first")
+					mine := fs.Bool("mine", false, "only list tasks assigned to you, the user in config.json")
+					absolute := fs.Bool("absolute", false, "show timestamps instead of relative times")
+					relative := fs.Bool("relative", false, "show relative times even if config.json prefers timestamps")
+					withCost := fs.Bool("with-cost", false, "show what was spent on each task")
+					sortBy := fs.String("sort", "urgency", "order tasks by `order`: urgency (most urgent first) or id")
+					groupBy := fs.String("group-by", "", "list tasks in sections by `field`: "+strings.Join(groupByModes, ", ")+", with subtotals")
+					columnList := fs.String("columns", "", "show a table of these comma-separated `columns`: "+strings.Join(columnNames(), ","))
+					return func(args []string) error {
+						// The arguments are a status, a saved filter or a query. A
+						// lone word that is neither is more likely a mistyped status
+						// than a search, which --query is there for.
+						queries := []string{*query}
+						if len(args) == 1 && config.Workflow.HasStatus(args[0]) {
+							*status = args[0]
+						} else if len(args) > 0 {
+							filters, err := savedFilters()
+							if err != nil {
+								return err
+							}
+							saved, ok := filters[args[0]]
+							if len(args) == 1 && !ok && !strings.ContainsAny(args[0], " :<>=#()'\"") {
+								names := append(config.Workflow.StatusNames(), sortedKeys(filters)...)
+								return usagef("unknown status or filter '%s'; use one of: %s, or search descriptions with --query %s", args[0], strings.Join(names, ", "), args[0])
+							}
+							if len(args) > 1 || !ok {
+								saved = strings.Join(args, " ")
+							}
+							queries = append(queries, saved)
+						}
+						var q taskQuery
+						for _, query := range queries {
+							parsed, err := parseQuery(query, config.Workflow, clock())
+							if err != nil {
+								return usagef("%v", err)
+							}
+							q = q.and(parsed)
+						}
+						var match func(Task) bool
+						if q != nil {
+							match = q.matches
+						}
+						if *status != "" {
+							if err := checkStatus(*status); err != nil {
+								return err
+							}
+						}
+						assignee := ""
+						if *mine {
+							var err error
+							if assignee, err = identity(); err != nil {
+								return err
+							}
+						}
+						window, err := parseDateRange(*since, *until, clock())
+						if err != nil {
+							return usagef("%v", err)
+						}
+						done, err := parseFlagRange("done-since", *doneSince, "done-until", *doneUntil, clock())
+						if err != nil {
+							return usagef("%v", err)
+						}
+						relativeTimes := (config.Display.relativeTimes() || *relative) && !*absolute
+						var columns []taskColumn
+						if names := config.Display.Columns; *columnList != "" || len(names) > 0 {
+							if *columnList != "" {
+								names = strings.Split(*columnList, ",")
+							}
+							if columns, err = parseColumns(names); err != nil {
+								return err
+							}
+						}
+						if *sortBy != "urgency" && *sortBy != "id" {
+							return usagef("invalid order '%s'; use urgency or id", *sortBy)
+						}
+						if *groupBy != "" && !slices.Contains(groupByModes, *groupBy) {
+							return usagef("invalid grouping '%s'; use %s", *groupBy, strings.Join(groupByModes, ", "))
+						}
+						return listTasks(tracker.TaskFilter{
+							Status:    *status,
+							Tag:       normalizeTag(strings.TrimPrefix(*tag, "#")),
+							Assignee:  assignee,
+							Archived:  *archived,
+							DueAfter:  window.Since,
+							DueBefore: window.Until,
+
+							CompletedAfter:  done.Since,
+							CompletedBefore: done.Until,
+
+							Snoozed:     *snoozed,
+							WithSnoozed: *all || *scheduled,
+
+							Scheduled:     *scheduled,
+							WithScheduled: *all || *snoozed,
+
+							Match: match,
+						}, listOptions{columns: columns, relativeTimes: relativeTimes, withCost: *withCost, groupBy: *groupBy, byID: *sortBy == "id"})
+					}
+				},
+			},
+			checklistCommand(),
+			filterCommand(),
+			logCommand(),
+			watchCommand(),
+			nextCommand(),
+			todayCommand(),
+			agendaCommand(),
+			boardCommand(),
+			overviewCommand(),
+			digestCommand(),
+			daemonCommand(),
+			{
+				name: "show", args: "<id>", summary: "Show a task with the expenses spent on it and its attachments", group: groupTasks, minArgs: 1,
+				complete: positional(taskIDs),
+				setup: run(func(args []string) error {
+					id, err := parseID(args[0], "task")
+					if err != nil {
+						return err
+					}
+					return showTask(id)
+				}),
+			},
+			{
+				name: "archive", args: "[id...]", summary: "Move tasks to the archive to keep the list short", group: groupTasks,
+				complete: positional(taskIDs),
+				setup: func(fs *flag.FlagSet) runFunc {
+					done := fs.Bool("done", false, "archive tasks in a done status")
+					olderThan := fs.String("older-than", "", "archive tasks not updated for this long, e.g. 30d, 2w or 12h")
+					return func(args []string) error {
+						var filter tracker.ArchiveFilter
+						for _, arg := range args {
+							id, err := parseID(arg, "task")
+							if err != nil {
+								return err
+							}
+							filter.IDs = append(filter.IDs, id)
+						}
+						filter.Done = *done
+						if *olderThan != "" {
+							age, err := parseAge(*olderThan)
+							if err != nil {
+								return usagef("%v", err)
+							}
+							before := clock().Add(-age)
+							filter.UpdatedBefore = &before
+						}
+						if len(filter.IDs) == 0 && !filter.Done && filter.UpdatedBefore == nil {
+							return usagef("give task IDs, --done or --older-than")
+						}
+						return archiveTasks(filter)
+					}
+				},
+			},
+			{
+				name: "unarchive", args: "<id>", summary: "Move an archived task back to the task list", group: groupTasks, minArgs: 1,
+				complete: positional(archivedTaskIDs),
+				setup: run(func(args []string) error {
+					id, err := parseID(args[0], "task")
+					if err != nil {
+						return err
+					}
+					return unarchiveTask(id)
+				}),
+			},
+			{
+				name: "due", args: "<id> <date>", summary: "Set a task's due date (e.g. \"tomorrow 5pm\", \"next monday\")", group: groupTasks, minArgs: 2,
+				complete: positional(taskIDs),
+				setup: run(func(args []string) error {
+					id, err := parseID(args[0], "task")
+					if err != nil {
+						return err
+					}
+					due, err := parseDate(strings.Join(args[1:], " "), clock())
+					if err != nil {
+						return usagef("%v", err)
+					}
+					if err := updateTaskDue(id, due); err != nil {
+						return err
+					}
+					warnMeetingConflicts(due)
+					return nil
+				}),
+			},
+			{
+				name: "snooze", args: "<id> [until] <date>", summary: "Leave a task out of the task list until a date (e.g. \"until monday\")", group: groupTasks, minArgs: 2,
+				complete: positional(taskIDs),
+				setup: run(func(args []string) error {
+					id, err := parseID(args[0], "task")
+					if err != nil {
+						return err
+					}
+					when := args[1:]
+					if strings.EqualFold(when[0], "until") {
+						when = when[1:]
+					}
+					wake, err := parseDate(strings.Join(when, " "), clock())
+					if err != nil {
+						return usagef("%v", err)
+					}
+					if !wake.After(clock()) {
+						return usagef("'%s' is not in the future", strings.Join(when, " "))
+					}
+					return snoozeTask(id, &wake)
+				}),
+			},
+			{
+				name: "wake", args: "<id>", summary: "Bring a snoozed task back to the task list", group: groupTasks, minArgs: 1,
+				complete: positional(taskIDs),
+				setup: run(func(args []string) error {
+					id, err := parseID(args[0], "task")
+					if err != nil {
+						return err
+					}
+					return snoozeTask(id, nil)
+				}),
+			},
+			{
+				name: "depends", args: "<id> [<id>...]", summary: "Set the tasks a task waits on; with none, clear them", group: groupTasks, minArgs: 1,
+				complete: positional(taskIDs),
+				setup: run(func(args []string) error {
+					id, err := parseID(args[0], "task")
+					if err != nil {
+						return err
+					}
+					var on []int
+					for _, arg := range args[1:] {
+						other, err := parseID(arg, "task")
+						if err != nil {
+							return err
+						}
+						on = append(on, other)
+					}
+					return setDependencies(id, on)
+				}),
+			},
+			{
+				name: "field", args: "<id> <name=value>...", summary: "Set your own fields of a task; an empty value removes one", group: groupTasks, minArgs: 2,
+				complete: positional(taskIDs),
+				setup: run(func(args []string) error {
+					id, err := parseID(args[0], "task")
+					if err != nil {
+						return err
+					}
+					fields, err := parseFieldArgs(args[1:])
+					if err != nil {
+						return err
+					}
+					task, err := tr().SetFields(id, fields)
+					if err != nil {
+						return err
+					}
+					printFieldsSet("Task", task.ID, task.Fields)
+					return nil
+				}),
+			},
+			scheduleCommand(),
+			inboxCommand(),
+			attachCommand(),
+			{
+				name: "priority", args: "<id> <low|medium|high>", summary: "Set a task's priority", group: groupTasks, minArgs: 2,
+				complete: positional(taskIDs, fixed(tracker.PriorityLow, tracker.PriorityMedium, tracker.PriorityHigh)),
+				setup: run(func(args []string) error {
+					id, err := parseID(args[0], "task")
+					if err != nil {
+						return err
+					}
+					priority := strings.ToLower(args[1])
+					if !tracker.IsValidPriority(priority) {
+						return usagef("invalid priority '%s'; use low, medium or high", priority)
+					}
+					return updateTaskPriority(id, priority)
+				}),
+			},
+			{
+				name: "tag", args: "<id> <+tag|-tag>...", summary: "Add or remove tags on a task", group: groupTasks, minArgs: 2,
+				complete: positional(taskIDs, tagChanges),
+				setup: run(func(args []string) error {
+					id, err := parseID(args[0], "task")
+					if err != nil {
+						return err
+					}
+					return updateTaskTags(id, args[1:])
+				}),
+			},
+			{
+				name: "assign", args: "<id> <name|me|none>", summary: "Assign a task to someone", group: groupTasks, minArgs: 2,
+				complete: positional(taskIDs),
+				setup: run(func(args []string) error {
+					id, err := parseID(args[0], "task")
+					if err != nil {
+						return err
+					}
+					assignee := strings.Join(args[1:], " ")
+					switch assignee {
+					case "none":
+						assignee = ""
+					case "me":
+						if assignee, err = identity(); err != nil {
+							return err
+						}
+					}
+					return updateTaskAssignee(id, assignee)
+				}),
+			},
+			{
+				name: "estimate", args: "<id> <hours>", summary: "Estimate the hours of work a task needs (0 clears it)", group: groupTasks, minArgs: 2,
+				complete: positional(taskIDs),
+				setup: run(func(args []string) error {
+					id, err := parseID(args[0], "task")
+					if err != nil {
+						return err
+					}
+					hours, err := strconv.ParseFloat(strings.TrimSuffix(args[1], "h"), 64)
+					if err != nil || hours < 0 {
+						return usagef("invalid estimate '%s'; use a number of hours", args[1])
+					}
+					return updateTaskEstimate(id, hours)
+				}),
+			},
+			{
+				name: "recur", args: "<id> <daily|weekly|monthly|yearly|none>", summary: "Repeat a task, optionally rotating assignees", group: groupTasks, minArgs: 2,
+				complete: positional(taskIDs, fixed(tracker.RecurDaily, tracker.RecurWeekly, tracker.RecurMonthly, tracker.RecurYearly, "none")),
+				setup: func(fs *flag.FlagSet) runFunc {
+					rotate := fs.String("rotate", "", "comma-separated `assignees` to cycle through each occurrence")
+					return func(args []string) error {
+						id, err := parseID(args[0], "task")
+						if err != nil {
+							return err
+						}
+						recur := strings.ToLower(args[1])
+						if recur == "none" {
+							recur = ""
+						} else if !tracker.IsValidRecurrence(recur) {
+							return usagef("invalid recurrence '%s'; use daily, weekly, monthly, yearly or none", recur)
+						}
+						var rotation []string
+						for _, name := range strings.Split(*rotate, ",") {
+							if name = strings.TrimSpace(name); name != "" {
+								rotation = append(rotation, name)
+							}
+						}
+						return updateTaskRecurrence(id, recur, rotation)
+					}
+				},
+			},
+			{name: "chores", summary: "Show whose turn it is for rotating chores", group: groupTasks, setup: run(func([]string) error { return listChores() })},
+			{name: "reminders", summary: "Show tasks whose reminder lead time has started", group: groupTasks, setup: run(func([]string) error { return listReminders() })},
+			{name: "statuses", summary: "Show the status workflow from config.json", group: groupTasks, setup: run(func([]string) error { return printWorkflow() })},
+			pomoCommand(),
+			dashCommand(),
+			{name: "stats", summary: "Show task and pomodoro statistics", group: groupTasks, setup: run(func([]string) error { return printStats() })},
+			{name: "score", summary: "Show points, level and streaks (if enabled)", group: groupTasks, setup: run(func([]string) error { return printScore() })},
+			reportCommand(),
+			chartCommand(),
+			retroCommand(),
+			reviewCommand(),
+			okrCommand(),
+			readCommand(),
+			expenseCommand(),
+			incomeCommand(),
+			planCommand(),
+			packCommand(),
+			shopCommand(),
+			medCommand(),
+			projectCommand(),
+			importCommand(),
+			packsCommand(),
+			exportCommand(),
+			signCommand(),
+			secretCommand(),
+			debugCommand(),
+			encryptCommand(),
+			serveCommand(),
+			syncCommand(),
+			historyCommand(),
+			completionCommand(),
+		},
+	}
+	root.subcommands = append(root.subcommands,
+		shellCommand(root),
+		batchCommand(root))
+	root.subcommands = append(root.subcommands, transactionCommands()...)
+	root.subcommands = append(root.subcommands,
+		repeatCommand(root, "repeat", false),
+		repeatCommand(root, "!!", true),
+		completeCommand(root))
+	return root
 }
 
-// loadTasks reads tasks from the saved JSON file.
-func loadTasks() ([]Task, error) {
-	if _, err := os.Stat(tasksFile); os.IsNotExist(err) {
-		return []Task{}, nil
+// checkStatus returns a usage error unless status is in the workflow.
+func checkStatus(status string) error {
+	if !config.Workflow.HasStatus(status) {
+		return usagef("invalid status '%s'; use one of: %s", status, strings.Join(config.Workflow.StatusNames(), ", "))
 	}
+	return nil
+}
 
-	data, err := os.ReadFile(tasksFile)
+// addTask adds a new task in the workflow's initial status, with fields.
+func addTask(description string, fields map[string]string) error {
+	task, err := tr().AddTask(description)
 	if err != nil {
-		return nil, fmt.Errorf("error reading file: %w", err)
+		return err
 	}
-
-	// If file is empty or only contains whitespace, return an empty slice
-	if len(data) == 0 || len(data) > 0 && data[0] == 0 {
-		return []Task{}, nil
+	if len(fields) > 0 {
+		if _, err := tr().SetFields(task.ID, fields); err != nil {
+			return err
+		}
 	}
 
-	var tasks []Task
-	if err := json.Unmarshal(data, &tasks); err != nil {
-		return nil, fmt.Errorf("error unmarshlling JSON: %w", err)
-	}
+	printDone(msg("Task added successfully (ID: %d)\n"), task.ID)
+	return nil
+}
 
-	return tasks, nil
+// updateTask updates the description of a task by ID.
+func updateTask(id int, description string) error {
+	_, err := tr().UpdateTask(id, description)
+	return err
 }
 
-// getNextID creates a new ID.
-func getNextID(tasks []Task) int {
-	maxID := 0
-	for _, task := range tasks {
-		if task.ID > maxID {
-			maxID = task.ID
+// deleteTask deletes a task by ID, once the user has confirmed it is the
+// task they mean unless yes is set.
+func deleteTask(id int, yes bool) error {
+	if !yes {
+		task, err := tr().Task(id)
+		if err != nil {
+			return err
+		}
+		ok, err := confirm(fmt.Sprintf("Delete task %d [%s] %q?", id, task.Status, task.Description))
+		if err != nil || !ok {
+			if err == nil {
+				fmt.Fprintln(stdout, msg("Task not deleted."))
+			}
+			return err
 		}
 	}
-	return maxID + 1
+	if err := tr().DeleteTask(id); err != nil {
+		return err
+	}
+
+	printDone(msg("Task ID %d deleted successfully\n"), id)
+	return nil
 }
 
-// addTask adds a new task with "todo" status.
-func addTask(description string) error {
-	tasks, err := loadTasks()
+// updateTaskStatus changes the status of a task by ID, awarding points when
+// it is completed.
+func updateTaskStatus(id int, newStatus string) error {
+	change, err := tr().SetStatus(id, newStatus)
 	if err != nil {
-		fmt.Printf("Error loading tasks: %v\n", err)
-		os.Exit(1)
+		return err
 	}
-
-	now := time.Now()
-
-	newTask := Task{
-		ID:          getNextID(tasks),
-		Description: description,
-		Status:      statusTodo,
-		CreatedAt:   now,
-		UpdatedAt:   now,
+	if change.Recurred {
+		fmt.Fprintf(stdout, msg("Task ID %d recurs %s; next due %s.\n"), id, change.Task.Recur, formatDue(*change.Task.Due))
+	}
+	if change.Completed {
+		return awardPoints(change.Task)
 	}
+	return nil
+}
 
-	tasks = append(tasks, newTask)
-	err = saveTasks(tasks)
+// updateTaskDue sets the due date of a task by ID.
+func updateTaskDue(id int, due time.Time) error {
+	_, err := tr().SetDue(id, due)
+	return err
+}
+
+// snoozeTask snoozes a task by ID until wake, or wakes it if wake is nil.
+func snoozeTask(id int, wake *time.Time) error {
+	task, err := tr().Snooze(id, wake)
 	if err != nil {
 		return err
 	}
-
-	fmt.Printf("Task added successfully (ID: %d)\n", newTask.ID)
+	if wake == nil {
+		fmt.Fprintf(stdout, "Task ID %d is back in the task list.\n", task.ID)
+		return nil
+	}
+	fmt.Fprintf(stdout, msg("Task ID %d snoozed until %s.\n"), task.ID, formatDue(*wake))
 	return nil
 }
 
-// saveTasks writes the tasks slice to the JSON file.
-func saveTasks(tasks []Task) error {
-	data, err := json.MarshalIndent(tasks, "", "  ")
+// setDependencies sets the tasks a task waits on.
+func setDependencies(id int, on []int) error {
+	task, err := tr().SetDependencies(id, on)
 	if err != nil {
-		return fmt.Errorf("error marshalling JSON: %w", err)
+		return err
 	}
+	if len(on) == 0 {
+		fmt.Fprintf(stdout, "Task ID %d waits on no other tasks.\n", task.ID)
+		return nil
+	}
+	fmt.Fprintf(stdout, "Task ID %d waits on %s.\n", task.ID, formatIDs(slices.Compact(slices.Sorted(slices.Values(on)))))
+	return nil
+}
 
-	err = os.WriteFile(tasksFile, data, 0644)
+// updateTaskPriority sets the priority of a task by ID.
+func updateTaskPriority(id int, priority string) error {
+	_, err := tr().SetPriority(id, priority)
+	return err
+}
+
+// archiveTasks moves the tasks matching filter to the archive.
+func archiveTasks(filter tracker.ArchiveFilter) error {
+	moved, err := tr().Archive(filter)
 	if err != nil {
-		return fmt.Errorf("error writing file: %w", err)
+		return err
 	}
+	if len(moved) == 0 {
+		fmt.Fprintln(stdout, "No tasks to archive.")
+		return nil
+	}
+	fmt.Fprintf(stdout, "Archived %s. See them with 'task list --archived'.\n", plural(len(moved), "task"))
 	return nil
 }
 
-// updateTask updates the description of a task by ID.
-func updateTask(id int, description string) error {
-	tasks, err := loadTasks()
+// unarchiveTask moves an archived task back to the task list.
+func unarchiveTask(id int) error {
+	task, err := tr().Unarchive(id)
 	if err != nil {
 		return err
 	}
-
-	for i, task := range tasks {
-		if task.ID == id {
-			tasks[i].Description = description
-			tasks[i].UpdatedAt = time.Now()
-			return saveTasks(tasks)
-		}
+	if task.ID != id {
+		fmt.Fprintf(stdout, "Task restored as ID %d (ID %d is in use).\n", task.ID, id)
+		return nil
 	}
+	fmt.Fprintf(stdout, "Task ID %d restored.\n", id)
+	return nil
+}
+
+// updateTaskAssignee sets who a task is assigned to by ID.
+func updateTaskAssignee(id int, assignee string) error {
+	_, err := tr().SetAssignee(id, assignee)
+	return err
+}
+
+// updateTaskEstimate sets the estimated hours of a task by ID.
+func updateTaskEstimate(id int, hours float64) error {
+	_, err := tr().SetEstimate(id, hours)
+	return err
+}
 
-	return fmt.Errorf("task with ID %d not found", id)
+// listOptions are how 'task list' shows tasks.
+type listOptions struct {
+	columns       []taskColumn // Shown as a table if any.
+	relativeTimes bool         // Times relative to now rather than timestamps.
+	withCost      bool         // Add what was spent on each task.
+	groupBy       string       // One of groupByModes, or "" for one list.
+	byID          bool         // In ID order rather than most urgent first.
 }
 
-// deleteTask deletes a task by ID.
-func deleteTask(id int) error {
-	tasks, err := loadTasks()
+// listTasks prints the tasks matching filter as opts ask for.
+func listTasks(filter tracker.TaskFilter, opts listOptions) error {
+	if opts.groupBy != "" {
+		return listGroupedTasks(filter, opts)
+	}
+	filteredTasks, err := tr().ListTasks(filter)
 	if err != nil {
 		return err
 	}
-
-	for i, task := range tasks {
-		if task.ID == id {
-			// Remove the task by slicing (efficient way to delete from a slice)
-			tasks = append(tasks[:i], tasks[i+1:]...)
-			if err := saveTasks(tasks); err != nil {
-				return err
-			}
-			fmt.Printf("Task ID %d deleted successfully\n", id)
-			return nil
+	switch {
+	case opts.byID || filter.Archived:
+	case filter.Scheduled:
+		slices.SortStableFunc(filteredTasks, func(a, b Task) int { return a.Scheduled.Compare(*b.Scheduled) })
+	default:
+		if err := sortByUrgency(filteredTasks); err != nil {
+			return err
 		}
 	}
+	if len(filteredTasks) == 0 {
+		printNoTasks(filter)
+		printHiddenCounts(filter)
+		return nil
+	}
+	costs, err := listCosts(opts)
+	if err != nil {
+		return err
+	}
+	if opts.columns != nil {
+		printTasks(filteredTasks, opts, costs, clock())
+		return nil
+	}
 
-	return fmt.Errorf("Task with ID %d not found", id)
+	if filter.Archived {
+		fmt.Fprintln(stdout, msg("--- Archived Tasks ---"))
+	} else {
+		fmt.Fprintln(stdout, msg("--- Task List ---"))
+	}
+	printTasks(filteredTasks, opts, costs, clock())
+	fmt.Fprintln(stdout, "-----------------")
+	printHiddenCounts(filter)
+	return nil
 }
 
-// updateTaskStatus changes the status of a task by ID.
-func updateTaskStatus(id int, newStatus string) error {
-	tasks, err := loadTasks()
-	if err != nil {
-		return err
+// printHiddenCounts says how many tasks that would match filter it left
+// out because they are snoozed or scheduled to start later.
+func printHiddenCounts(filter tracker.TaskFilter) {
+	if filter.Archived {
+		return
+	}
+	if !filter.Snoozed && !filter.WithSnoozed {
+		f := filter
+		f.Snoozed, f.Scheduled, f.WithScheduled = true, false, true
+		if snoozed, err := tr().ListTasks(f); err == nil && len(snoozed) > 0 {
+			fmt.Fprintf(stdout, msg("%s snoozed; see them with --snoozed.\n"), localPlural(len(snoozed), "task"))
+		}
 	}
+	if !filter.Scheduled && !filter.WithScheduled {
+		f := filter
+		f.Scheduled, f.Snoozed, f.WithSnoozed = true, false, true
+		if scheduled, err := tr().ListTasks(f); err == nil && len(scheduled) > 0 {
+			fmt.Fprintf(stdout, msg("%s scheduled for later; see them with --scheduled.\n"), localPlural(len(scheduled), "task"))
+		}
+	}
+}
 
-	for i, task := range tasks {
-		if task.ID == id {
-			tasks[i].Status = newStatus
-			tasks[i].UpdatedAt = time.Now()
-			return saveTasks(tasks)
+// printNoTasks says that no tasks match filter.
+func printNoTasks(filter tracker.TaskFilter) {
+	if filter.Match != nil {
+		if filter.Archived {
+			fmt.Fprint(stdout, msg("No archived tasks match the query.\n"))
+			return
 		}
+		fmt.Fprint(stdout, msg("No tasks match the query.\n"))
+		return
+	}
+	statusMsg := "all"
+	if filter.Status != "" {
+		statusMsg = filter.Status
+	}
+	if filter.Tag != "" {
+		statusMsg += ", tag: #" + filter.Tag
 	}
+	if filter.Archived {
+		fmt.Fprintf(stdout, msg("No archived tasks found with status: %s\n"), statusMsg)
+		return
+	}
+	fmt.Fprintf(stdout, msg("No tasks found with status: %s\n"), statusMsg)
+}
+
+// listCosts returns what was spent on each task of the current project, by
+// task UUID and currency, if opts ask for costs.
+func listCosts(opts listOptions) (map[string]map[string]float64, error) {
+	if !opts.withCost {
+		return nil, nil
+	}
+	return taskCosts()
+}
 
-	return fmt.Errorf("task with ID %d not found", id)
+// printTasks prints tasks in full or as a table, as opts ask for, with what
+// was spent on each from costs if opts ask for that.
+func printTasks(tasks []Task, opts listOptions, costs map[string]map[string]float64, now time.Time) {
+	if opts.columns != nil {
+		columns := opts.columns
+		if opts.withCost {
+			columns = append(slices.Clip(columns), taskColumn{name: "cost", header: "COST", value: func(task Task, _ time.Time, _ bool) string {
+				if len(costs[task.UUID]) == 0 {
+					return "-"
+				}
+				return formatTotals(costs[task.UUID])
+			}})
+		}
+		printTaskTable(tasks, columns, now, opts.relativeTimes, tableWidth())
+		return
+	}
+	for _, task := range tasks {
+		printTask(task, now, opts.relativeTimes)
+		if opts.withCost && len(costs[task.UUID]) > 0 {
+			fmt.Fprintf(stdout, msg("  Cost: %s\n"), formatTotals(costs[task.UUID]))
+		}
+	}
 }
 
-// listTasks prints tasks based on the filter.
-func listTasks(filter string) error {
-	tasks, err := loadTasks()
+// showTask prints a task with its checklist and the expenses spent on it,
+// with their total.
+func showTask(id int) error {
+	task, err := tr().Task(id)
+	if err != nil {
+		return err
+	}
+	list, err := tr().ListExpenses(tracker.ExpenseFilter{Task: task.UUID})
 	if err != nil {
 		return err
 	}
 
-	var filteredTasks []Task
-	for _, task := range tasks {
-		if filter == "" || task.Status == filter {
-			filteredTasks = append(filteredTasks, task)
+	fmt.Fprintln(stdout, msg("--- Task ---"))
+	printTask(task, clock(), config.Display.relativeTimes())
+	printChecklist(task)
+	if task.URL != "" {
+		fmt.Fprintf(stdout, "  Link: %s\n", task.URL)
+	}
+	if len(list.Expenses) > 0 {
+		fmt.Fprintln(stdout, msg("--- Expenses ---"))
+		for _, e := range list.Expenses {
+			fmt.Fprintf(stdout, "[ID: %d] %s %10s %-3s  %s", e.ID, formatDate(e.Date), formatNumber(e.Amount), e.Currency, e.Description)
+			if e.Payee != "" {
+				fmt.Fprintf(stdout, " @ %s", e.Payee)
+			}
+			fmt.Fprintln(stdout)
 		}
+		fmt.Fprintf(stdout, msg("--- Cost: %s ---\n"), formatTotals(list.Totals))
+	}
+	if err := printAttachments(taskRecord(task)); err != nil {
+		return err
+	}
+	if len(list.Expenses) == 0 {
+		fmt.Fprintln(stdout, "-----------------")
 	}
+	return nil
+}
 
-	if len(filteredTasks) == 0 {
-		statusMsg := "all"
-		if filter != "" {
-			statusMsg = filter
+// printTask prints a task as listed, with its details and how much of its
+// checklist is done.
+func printTask(task Task, now time.Time, relativeTimes bool) {
+	createdAt := formatListTime(task.CreatedAt, now, relativeTimes)
+	updatedAt := formatListTime(task.UpdatedAt, now, relativeTimes)
+
+	fmt.Fprintf(stdout, "[ID: %d] [%s] %s", task.ID, colorStatus(task.Status), task.Description)
+	if progress := checklistProgress(task); progress != "" {
+		fmt.Fprint(stdout, " "+progress)
+	}
+	switch {
+	case task.Snoozed(now):
+		fmt.Fprint(stdout, " "+colorize(fmt.Sprintf(msg("(snoozed until %s)"), formatDue(*task.Wake)), "gray"))
+	case task.Woken(now):
+		fmt.Fprint(stdout, " "+colorize(fmt.Sprintf(msg("(woke %s)"), relativeTime(*task.Wake, now)), "yellow"))
+	}
+	fmt.Fprintln(stdout)
+	fmt.Fprintf(stdout, msg("  Created: %s | Updated: %s\n"), createdAt, updatedAt)
+	if task.Due != nil || task.Scheduled != nil || task.Priority != "" || task.Assignee != "" || task.Estimate != 0 || task.KeyResult != 0 {
+		due := "-"
+		if task.Due != nil {
+			due = formatDue(*task.Due)
+			if relativeTimes && !config.Workflow.IsDone(task.Status) {
+				due = relativeDue(*task.Due, now)
+			}
 		}
-		fmt.Printf("No tasks found with status: %s\n", statusMsg)
-		return nil
+		priority := "-"
+		if task.Priority != "" {
+			priority = task.Priority
+		}
+		fmt.Fprintf(stdout, msg("  Due: %s | Priority: %s"), due, priority)
+		if task.Scheduled != nil {
+			fmt.Fprintf(stdout, msg(" | Scheduled: %s"), formatDue(*task.Scheduled))
+		}
+		if task.Recur != "" {
+			fmt.Fprintf(stdout, msg(" | Repeats: %s"), task.Recur)
+		}
+		if task.Assignee != "" {
+			fmt.Fprintf(stdout, msg(" | Assignee: %s"), task.Assignee)
+		}
+		if task.Estimate != 0 {
+			fmt.Fprintf(stdout, msg(" | Estimate: %s"), formatHours(task.Estimate))
+		}
+		if task.KeyResult != 0 {
+			fmt.Fprintf(stdout, " | KR: %d", task.KeyResult)
+		}
+		fmt.Fprintln(stdout)
+	}
+	if len(task.Tags) > 0 {
+		fmt.Fprintf(stdout, msg("  Tags: %s\n"), formatTags(task.Tags))
 	}
+	if ids, _ := tr().WaitsOn(task); len(ids) > 0 {
+		fmt.Fprintf(stdout, msg("  Waits on: %s\n"), formatIDs(ids))
+	}
+	if len(task.Fields) > 0 {
+		fmt.Fprintf(stdout, msg("  Fields: %s\n"), formatFields(task.Fields))
+	}
+}
 
-	fmt.Println("--- Task List ---")
-	for _, task := range filteredTasks {
-		// Use a simple formatting for date/time
-		createdAt := task.CreatedAt.Format("2006-01-02 15:04:05")
-		updatedAt := task.UpdatedAt.Format("2006-01-02 15:04:05")
+// formatIDs lists task IDs, such as "3, 5 and 8".
+func formatIDs(ids []int) string {
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = strconv.Itoa(id)
+	}
+	if len(s) == 1 {
+		return s[0]
+	}
+	return strings.Join(s[:len(s)-1], ", ") + " and " + s[len(s)-1]
+}
+
+// stdin, stdout and clock are where commands read input, write output and
+// get the time from. Tests swap them, along with baseTracker, to run
+// commands against a fake clock and an in-memory tracker.
+var (
+	stdin            = bufio.NewReader(os.Stdin)
+	stdout io.Writer = os.Stdout
+	clock            = time.Now
+)
 
-		fmt.Printf("[ID: %d] [%s] %s\n", task.ID, task.Status, task.Description)
-		fmt.Printf("  Created: %s | Updated: %s\n", createdAt, updatedAt)
+// readLine prints prompt and reads a single line from standard input.
+func readLine(prompt string) (string, error) {
+	if pageBuffer != nil {
+		fmt.Fprint(os.Stdout, prompt) // Output held back for the pager would hide the prompt.
+	} else {
+		fmt.Fprint(stdout, prompt)
 	}
-	fmt.Println("-----------------")
+	line, err := stdin.ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("error reading input: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
 
-	return nil
+// confirm asks a yes or no question, defaulting to no. A dry run saves
+// nothing, so it does not ask. Without an answer to read, it fails rather
+// than go ahead, pointing to the --yes flag that skips asking.
+func confirm(question string) (bool, error) {
+	if dryRun {
+		return true, nil
+	}
+	answer, err := readLine(question + " [y/N] ")
+	if err != nil {
+		return false, fmt.Errorf("%w; use --yes to go ahead without asking", err)
+	}
+	a := strings.ToLower(strings.TrimSpace(answer))
+	return a == "y" || a == "yes", nil
 }
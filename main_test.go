@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+// testNow is the time the fake clock of the tests is stopped at, a Monday.
+var testNow = time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)
+
+// setupCLI runs the test in an empty directory, with the data files in
+// memory, the clock stopped at testNow and the output going to the buffer
+// it returns. It puts everything back when the test ends.
+func setupCLI(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	t.Chdir(t.TempDir())
+
+	savedStdin, savedStdout, savedClock, savedLocal := stdin, stdout, clock, time.Local
+	savedConfig, savedTracker, savedSecrets := config, baseTracker, secretsTracker
+	savedProject, savedDryRun := currentProject, dryRun
+	t.Cleanup(func() {
+		stdin, stdout, clock, time.Local = savedStdin, savedStdout, savedClock, savedLocal
+		config, baseTracker, secretsTracker = savedConfig, savedTracker, savedSecrets
+		currentProject, dryRun = savedProject, savedDryRun
+	})
+
+	out := &bytes.Buffer{}
+	stdin = bufio.NewReader(strings.NewReader(""))
+	stdout = out
+	clock = func() time.Time { return testNow }
+	time.Local = time.UTC
+	config = defaultConfig()
+	baseTracker = tracker.New(tracker.Options{FS: tracker.NewMemFS(), Now: clock, Location: time.UTC, Workflow: config.Workflow})
+	secretsTracker = nil
+	currentProject, dryRun = "", false
+	return out
+}
+
+// runCLI runs a command as the task command would, returning its output.
+func runCLI(t *testing.T, out *bytes.Buffer, args ...string) (string, error) {
+	t.Helper()
+	out.Reset()
+	var err error
+	if dryRun {
+		err = executeDryRun(rootCommand(), args)
+	} else {
+		err = execute(rootCommand(), args)
+	}
+	return out.String(), err
+}
+
+// mustRunCLI runs a command, failing the test if it fails.
+func mustRunCLI(t *testing.T, out *bytes.Buffer, args ...string) string {
+	t.Helper()
+	s, err := runCLI(t, out, args...)
+	if err != nil {
+		t.Fatalf("task %s: %v", strings.Join(args, " "), err)
+	}
+	return s
+}
+
+func TestAddAndList(t *testing.T) {
+	out := setupCLI(t)
+	mustRunCLI(t, out, "add", "Buy milk")
+	mustRunCLI(t, out, "add", "Call mom")
+	mustRunCLI(t, out, "mark", "done", "2")
+
+	s := mustRunCLI(t, out, "list", "todo")
+	if !strings.Contains(s, "Buy milk") || strings.Contains(s, "Call mom") {
+		t.Errorf("list todo:\n%s", s)
+	}
+	tasks, err := loadTasks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 2 || !tasks[0].CreatedAt.Equal(testNow) {
+		t.Errorf("tasks = %+v, want two created at %v", tasks, testNow)
+	}
+	if _, err := os.Stat(tasksFile); !os.IsNotExist(err) {
+		t.Errorf("%s was written to disk", tasksFile)
+	}
+}
+
+func TestExpenseDates(t *testing.T) {
+	out := setupCLI(t)
+	mustRunCLI(t, out, "expense", "add", "4.50", "Coffee")
+	mustRunCLI(t, out, "expense", "add", "--date", "yesterday", "12", "Lunch")
+
+	expenses, err := loadExpenses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(expenses) != 2 {
+		t.Fatalf("got %d expenses, want 2", len(expenses))
+	}
+	if !expenses[0].Date.Equal(testNow) {
+		t.Errorf("Coffee dated %v, want %v", expenses[0].Date, testNow)
+	}
+	if got := expenses[1].Date.Format(dateLayout); got != "2025-03-09" {
+		t.Errorf("Lunch dated %s, want 2025-03-09", got)
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	out := setupCLI(t)
+	mustRunCLI(t, out, "add", "Buy milk")
+
+	dryRun = true
+	s := mustRunCLI(t, out, "delete", "1")
+	if strings.Contains(s, "successfully") {
+		t.Errorf("dry run claimed success:\n%s", s)
+	}
+	dryRun = false
+
+	tasks, err := loadTasks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 1 {
+		t.Errorf("dry run deleted the task: %+v", tasks)
+	}
+}
+
+func TestListRejectsUnknownStatus(t *testing.T) {
+	out := setupCLI(t)
+	mustRunCLI(t, out, "add", "Buy milk")
+
+	if _, err := runCLI(t, out, "list", "bogus"); err == nil {
+		t.Error("list bogus succeeded")
+	}
+	if s := mustRunCLI(t, out, "list", "--query", "bogus"); !strings.Contains(s, "No tasks match the query") {
+		t.Errorf("list --query bogus:\n%s", s)
+	}
+	if s := mustRunCLI(t, out, "list", "milk or bread"); !strings.Contains(s, "Buy milk") {
+		t.Errorf("list milk or bread:\n%s", s)
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const mealsFile = "meals.json" // The meal plan template file.
+
+// Ingredient is a shopping list entry needed by a recipe.
+type Ingredient struct {
+	Name     string `json:"name"`
+	Quantity int    `json:"quantity"`
+	Store    string `json:"store,omitempty"`
+	Aisle    string `json:"aisle,omitempty"`
+}
+
+// Recipe is a named meal and the ingredients required to cook it.
+type Recipe struct {
+	Ingredients []Ingredient `json:"ingredients"`
+}
+
+// MealPlan is the template week: recipes by name and the recipes cooked on
+// each weekday (keyed by lower-case weekday name).
+type MealPlan struct {
+	Recipes map[string]Recipe   `json:"recipes"`
+	Week    map[string][]string `json:"week"`
+}
+
+// exampleMealPlan is written when no template exists yet.
+var exampleMealPlan = MealPlan{
+	Recipes: map[string]Recipe{
+		"pasta": {Ingredients: []Ingredient{
+			{Name: "spaghetti", Quantity: 1, Aisle: "pasta"},
+			{Name: "tomato sauce", Quantity: 1, Aisle: "canned"},
+		}},
+		"stir fry": {Ingredients: []Ingredient{
+			{Name: "rice", Quantity: 1, Aisle: "grains"},
+			{Name: "vegetables", Quantity: 2, Aisle: "produce"},
+		}},
+	},
+	Week: map[string][]string{
+		"monday":    {"pasta"},
+		"wednesday": {"stir fry"},
+		"friday":    {"pasta"},
+	},
+}
+
+// loadMealPlan reads the meal plan template, writing an example template
+// and returning an error if none exists yet.
+func loadMealPlan() (MealPlan, error) {
+	var plan MealPlan
+
+	data, err := os.ReadFile(mealsFile)
+	if os.IsNotExist(err) {
+		example, err := json.MarshalIndent(exampleMealPlan, "", "  ")
+		if err != nil {
+			return plan, fmt.Errorf("error marshalling JSON: %w", err)
+		}
+		if err := writeFile(mealsFile, example, 0644); err != nil {
+			return plan, fmt.Errorf("error writing file: %w", err)
+		}
+		return plan, fmt.Errorf("no meal plan template found; an example was written to %s, edit it and run again", mealsFile)
+	}
+	if err != nil {
+		return plan, fmt.Errorf("error reading file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return plan, fmt.Errorf("error unmarshalling %s: %w", mealsFile, err)
+	}
+	return plan, nil
+}
+
+// planCommand returns the plan command group.
+func planCommand() *command {
+	return &command{
+		name: "plan", summary: "Plan from templates", group: groupHousehold,
+		subcommands: []*command{
+			{
+				name: "meals", summary: "Plan a week of cooking from meals.json and fill the shopping list",
+				setup: func(fs *flag.FlagSet) runFunc {
+					startStr := fs.String("start", "", "first `day` of the week to plan (e.g. 2025-03-03 or \"next monday\", default next Monday)")
+					return func([]string) error {
+						start := nextMonday(clock())
+						if *startStr != "" {
+							var err error
+							start, err = parseDate(*startStr, clock())
+							if err != nil {
+								return usagef("%v", err)
+							}
+							start = startOfDay(start)
+						}
+						return planMeals(start)
+					}
+				},
+			},
+		},
+	}
+}
+
+// planMeals instantiates a week of cooking tasks from the meal plan template
+// starting on start, and adds the combined ingredients to the shopping list.
+// Cooking tasks that already exist for a day are not duplicated.
+func planMeals(start time.Time) error {
+	plan, err := loadMealPlan()
+	if err != nil {
+		return err
+	}
+
+	tasks, err := loadTasks()
+	if err != nil {
+		return err
+	}
+
+	type ingredientKey struct{ name, store, aisle string }
+	needed := map[ingredientKey]int{}
+	var order []ingredientKey
+
+	now := clock()
+	created := 0
+	for day := 0; day < 7; day++ {
+		date := start.AddDate(0, 0, day)
+		weekday := strings.ToLower(date.Weekday().String())
+		for _, name := range plan.Week[weekday] {
+			recipe, ok := plan.Recipes[name]
+			if !ok {
+				return fmt.Errorf("recipe '%s' planned for %s is not defined", name, weekday)
+			}
+
+			description := "Cook " + name
+			if hasTaskDueOn(tasks, description, date) {
+				continue
+			}
+
+			due := date
+			tasks = append(tasks, Task{
+				ID:          getNextID(tasks),
+				Description: description,
+				Status:      config.Workflow.Initial(),
+				Due:         &due,
+				CreatedAt:   now,
+				UpdatedAt:   now,
+			})
+			created++
+
+			for _, ing := range recipe.Ingredients {
+				key := ingredientKey{strings.ToLower(ing.Name), strings.ToLower(ing.Store), strings.ToLower(ing.Aisle)}
+				if _, seen := needed[key]; !seen {
+					order = append(order, key)
+				}
+				needed[key] += max(ing.Quantity, 1)
+			}
+		}
+	}
+
+	if created == 0 {
+		return errors.New("nothing to plan: the week is already planned or the template is empty")
+	}
+
+	items, err := loadShopping()
+	if err != nil {
+		return err
+	}
+	for _, key := range order {
+		merged := false
+		for i, item := range items {
+			if !item.Bought && strings.EqualFold(item.Name, key.name) && item.Store == key.store && item.Aisle == key.aisle {
+				items[i].Quantity += needed[key]
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			items = append(items, ShopItem{
+				ID:        getNextShopID(items),
+				Name:      key.name,
+				Quantity:  needed[key],
+				Store:     key.store,
+				Aisle:     key.aisle,
+				CreatedAt: now,
+			})
+		}
+	}
+
+	if err := saveTasks(tasks); err != nil {
+		return err
+	}
+	if err := saveShopping(items); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "Planned %d meal(s) for the week of %s; %d ingredient(s) added to the shopping list.\n",
+		created, start.Format(dateLayout), len(order))
+	return nil
+}
+
+// hasTaskDueOn reports whether a task with description is already due on date.
+func hasTaskDueOn(tasks []Task, description string, date time.Time) bool {
+	for _, task := range tasks {
+		if task.Description == description && task.Due != nil && sameDay(*task.Due, date) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameDay reports whether a and b fall on the same calendar day.
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// nextMonday returns the start of the next Monday after t, or t's day if it is a Monday.
+func nextMonday(t time.Time) time.Time {
+	day := startOfDay(t)
+	offset := (int(time.Monday) - int(day.Weekday()) + 7) % 7
+	return day.AddDate(0, 0, offset)
+}
@@ -0,0 +1,438 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+const (
+	medsFile        = "meds.json" // The name of the saved medication file.
+	doseTimeLayout  = "15:04"
+	defaultMedGrace = time.Hour // How late a dose may be before it counts as missed.
+)
+
+// Medication is a medication or supplement taken on a daily schedule.
+type Medication struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Dose      string    `json:"dose,omitempty"`
+	Times     []string  `json:"times"` // Daily dose times as HH:MM.
+	WithFood  bool      `json:"withFood,omitempty"`
+	Log       []DoseLog `json:"log,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// DoseLog records what happened to a single scheduled dose.
+type DoseLog struct {
+	Slot     time.Time  `json:"slot"` // The scheduled time of the dose.
+	TakenAt  *time.Time `json:"takenAt,omitempty"`
+	Notified bool       `json:"notified,omitempty"` // A missed-dose notification was sent.
+}
+
+// medCommand returns the med command group.
+func medCommand() *command {
+	return &command{
+		name: "med", summary: "Track medication schedules and doses", group: groupHousehold,
+		subcommands: []*command{
+			{
+				name: "add", args: "<name>", summary: "Add a medication schedule", minArgs: 1,
+				setup: func(fs *flag.FlagSet) runFunc {
+					times := fs.String("times", "", "comma-separated daily dose `times` (HH:MM, required)")
+					dose := fs.String("dose", "", "dose to take, e.g. 500mg")
+					withFood := fs.Bool("with-food", false, "take with food")
+					return func(args []string) error {
+						if *times == "" {
+							return usagef("missing --times")
+						}
+						var slots []string
+						for _, t := range strings.Split(*times, ",") {
+							t = strings.TrimSpace(t)
+							parsed, err := time.Parse(doseTimeLayout, t)
+							if err != nil {
+								return usagef("invalid dose time '%s'; use HH:MM", t)
+							}
+							slots = append(slots, parsed.Format(doseTimeLayout))
+						}
+						return addMedication(strings.Join(args, " "), *dose, slots, *withFood)
+					}
+				},
+			},
+			{
+				name: "take", args: "<id>", summary: "Log a dose as taken", minArgs: 1,
+				setup: func(fs *flag.FlagSet) runFunc {
+					slot := fs.String("time", "", "scheduled dose `time` being taken (HH:MM, default: nearest)")
+					return func(args []string) error {
+						id, err := parseID(args[0], "medication")
+						if err != nil {
+							return err
+						}
+						return takeDose(id, *slot, clock())
+					}
+				},
+			},
+			{
+				name: "list", summary: "Show today's doses",
+				setup: run(func([]string) error { return listMedications(clock()) }),
+			},
+			{
+				name: "report", summary: "Show adherence and streaks",
+				setup: func(fs *flag.FlagSet) runFunc {
+					days := fs.Int("days", 30, "number of days to report on")
+					return func([]string) error { return reportAdherence(*days, clock()) }
+				},
+			},
+			{
+				name: "check", summary: "Notify about missed doses (run from cron)",
+				setup: func(fs *flag.FlagSet) runFunc {
+					grace := fs.Duration("grace", defaultMedGrace, "how late a dose may be before it counts as missed")
+					return func([]string) error { return checkMissedDoses(*grace, clock()) }
+				},
+			},
+			{
+				name: "remove", args: "<id>", summary: "Remove a medication", minArgs: 1,
+				setup: run(func(args []string) error {
+					id, err := parseID(args[0], "medication")
+					if err != nil {
+						return err
+					}
+					return removeMedication(id)
+				}),
+			},
+		},
+	}
+}
+
+// loadMedications reads medications from the saved JSON file.
+func loadMedications() ([]Medication, error) {
+	raw, err := loadDocument(medsFile)
+	if err != nil || raw == nil {
+		return []Medication{}, err
+	}
+
+	var meds []Medication
+	if err := json.Unmarshal(raw, &meds); err != nil {
+		return nil, fmt.Errorf("error unmarshalling JSON: %w", err)
+	}
+
+	return meds, nil
+}
+
+// saveMedications writes the medications slice to the JSON file.
+func saveMedications(meds []Medication) error {
+	return saveDocument(medsFile, meds)
+}
+
+// getNextMedID creates a new medication ID.
+func getNextMedID(meds []Medication) int {
+	maxID := 0
+	for _, med := range meds {
+		if med.ID > maxID {
+			maxID = med.ID
+		}
+	}
+	return maxID + 1
+}
+
+// addMedication adds a medication with its daily dose schedule.
+func addMedication(name, dose string, times []string, withFood bool) error {
+	meds, err := loadMedications()
+	if err != nil {
+		return err
+	}
+
+	med := Medication{
+		ID:        getNextMedID(meds),
+		Name:      name,
+		Dose:      dose,
+		Times:     times,
+		WithFood:  withFood,
+		CreatedAt: clock(),
+	}
+
+	meds = append(meds, med)
+	if err := saveMedications(meds); err != nil {
+		return err
+	}
+
+	printDone("Medication added successfully (ID: %d)\n", med.ID)
+	return nil
+}
+
+// removeMedication deletes a medication and its log by ID.
+func removeMedication(id int) error {
+	meds, err := loadMedications()
+	if err != nil {
+		return err
+	}
+
+	for i, med := range meds {
+		if med.ID == id {
+			meds = append(meds[:i], meds[i+1:]...)
+			if err := saveMedications(meds); err != nil {
+				return err
+			}
+			printDone("Medication ID %d removed successfully\n", id)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("medication with ID %d %w", id, tracker.ErrNotFound)
+}
+
+// slotsOn returns the scheduled dose times of med on day.
+func (med Medication) slotsOn(day time.Time) []time.Time {
+	var slots []time.Time
+	for _, t := range med.Times {
+		parsed, err := time.Parse(doseTimeLayout, t)
+		if err != nil {
+			continue
+		}
+		slots = append(slots, time.Date(day.Year(), day.Month(), day.Day(), parsed.Hour(), parsed.Minute(), 0, 0, day.Location()))
+	}
+	return slots
+}
+
+// logFor returns the log entry for slot, or nil if there is none.
+func (med *Medication) logFor(slot time.Time) *DoseLog {
+	for i := range med.Log {
+		if med.Log[i].Slot.Equal(slot) {
+			return &med.Log[i]
+		}
+	}
+	return nil
+}
+
+// taken reports whether the dose scheduled at slot was taken.
+func (med *Medication) taken(slot time.Time) bool {
+	entry := med.logFor(slot)
+	return entry != nil && entry.TakenAt != nil
+}
+
+// takeDose logs a dose of a medication as taken. Without an explicit time it
+// is matched to the closest of today's doses that has not been taken yet.
+func takeDose(id int, slotTime string, now time.Time) error {
+	meds, err := loadMedications()
+	if err != nil {
+		return err
+	}
+
+	for i := range meds {
+		med := &meds[i]
+		if med.ID != id {
+			continue
+		}
+
+		var slot time.Time
+		found := false
+		for _, s := range med.slotsOn(now) {
+			if med.taken(s) {
+				continue
+			}
+			if slotTime != "" {
+				if s.Format(doseTimeLayout) == slotTime {
+					slot, found = s, true
+					break
+				}
+				continue
+			}
+			if !found || absDuration(s.Sub(now)) < absDuration(slot.Sub(now)) {
+				slot, found = s, true
+			}
+		}
+		if !found {
+			return fmt.Errorf("no untaken dose of %s is scheduled today", med.Name)
+		}
+
+		takenAt := now
+		if entry := med.logFor(slot); entry != nil {
+			entry.TakenAt = &takenAt
+		} else {
+			med.Log = append(med.Log, DoseLog{Slot: slot, TakenAt: &takenAt})
+		}
+		if err := saveMedications(meds); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(stdout, "Logged %s dose of %s.\n", slot.Format(doseTimeLayout), med.Name)
+		return nil
+	}
+
+	return fmt.Errorf("medication with ID %d %w", id, tracker.ErrNotFound)
+}
+
+// listMedications prints each medication's schedule and today's doses.
+func listMedications(now time.Time) error {
+	meds, err := loadMedications()
+	if err != nil {
+		return err
+	}
+
+	if len(meds) == 0 {
+		fmt.Fprintln(stdout, "No medications found.")
+		return nil
+	}
+
+	fmt.Fprintln(stdout, "--- Medications ---")
+	for i := range meds {
+		med := &meds[i]
+		details := med.Dose
+		if med.WithFood {
+			details = strings.TrimSpace(details + " with food")
+		}
+		if details != "" {
+			details = " (" + details + ")"
+		}
+		fmt.Fprintf(stdout, "[ID: %d] %s%s\n", med.ID, med.Name, details)
+
+		var today []string
+		for _, slot := range med.slotsOn(now) {
+			mark := " "
+			switch {
+			case med.taken(slot):
+				mark = "x"
+			case slot.Before(now):
+				mark = "!"
+			}
+			today = append(today, fmt.Sprintf("[%s] %s", mark, slot.Format(doseTimeLayout)))
+		}
+		fmt.Fprintf(stdout, "  Today: %s\n", strings.Join(today, "  "))
+	}
+	fmt.Fprintln(stdout, "-------------------")
+
+	return nil
+}
+
+// reportAdherence prints, for each medication, the share of doses taken over
+// the last days and the current streak of days with every dose taken.
+func reportAdherence(days int, now time.Time) error {
+	meds, err := loadMedications()
+	if err != nil {
+		return err
+	}
+
+	if len(meds) == 0 {
+		fmt.Fprintln(stdout, "No medications found.")
+		return nil
+	}
+
+	today := startOfDay(now)
+	fmt.Fprintf(stdout, "--- Adherence (last %d days) ---\n", days)
+	for i := range meds {
+		med := &meds[i]
+		first := startOfDay(med.CreatedAt)
+		if from := today.AddDate(0, 0, -(days - 1)); first.Before(from) {
+			first = from
+		}
+
+		expected, taken := 0, 0
+		for day := first; !day.After(today); day = day.AddDate(0, 0, 1) {
+			for _, slot := range med.slotsOn(day) {
+				if slot.After(now) {
+					continue
+				}
+				expected++
+				if med.taken(slot) {
+					taken++
+				}
+			}
+		}
+
+		streak := 0
+		for day := today; !day.Before(startOfDay(med.CreatedAt)); day = day.AddDate(0, 0, -1) {
+			complete, due := true, 0
+			for _, slot := range med.slotsOn(day) {
+				if slot.After(now) {
+					continue
+				}
+				due++
+				if !med.taken(slot) {
+					complete = false
+				}
+			}
+			if !complete {
+				break
+			}
+			if due > 0 {
+				streak++
+			}
+		}
+
+		rate := 100.0
+		if expected > 0 {
+			rate = float64(taken) * 100 / float64(expected)
+		}
+		fmt.Fprintf(stdout, "[ID: %d] %-20s %5.1f%% (%d/%d doses)  streak: %d day(s)\n", med.ID, med.Name, rate, taken, expected, streak)
+	}
+	fmt.Fprintln(stdout, "--------------------------------")
+
+	return nil
+}
+
+// checkMissedDoses sends a notification for each of today's doses that is
+// more than grace overdue. Each missed dose is only notified once, so the
+// check can run repeatedly from cron or the reminder daemon.
+func checkMissedDoses(grace time.Duration, now time.Time) error {
+	missed, err := notifyMissedDoses(grace, now)
+	if err != nil {
+		return err
+	}
+	if len(missed) == 0 {
+		fmt.Fprintln(stdout, "No missed doses.")
+		return nil
+	}
+	for _, message := range missed {
+		fmt.Fprintln(stdout, "Missed dose: "+message)
+	}
+	return nil
+}
+
+// notifyMissedDoses notifies of each of today's doses in the current
+// project that is more than grace overdue and not notified of yet, and
+// returns what it notified of.
+func notifyMissedDoses(grace time.Duration, now time.Time) ([]string, error) {
+	meds, err := loadMedications()
+	if err != nil {
+		return nil, err
+	}
+
+	var missed []string
+	for i := range meds {
+		med := &meds[i]
+		for _, slot := range med.slotsOn(now) {
+			if med.taken(slot) || now.Sub(slot) < grace {
+				continue
+			}
+			entry := med.logFor(slot)
+			if entry != nil && entry.Notified {
+				continue
+			}
+			if entry == nil {
+				med.Log = append(med.Log, DoseLog{Slot: slot})
+				entry = &med.Log[len(med.Log)-1]
+			}
+			entry.Notified = true
+
+			message := fmt.Sprintf("%s dose of %s was not logged", slot.Format(doseTimeLayout), med.Name)
+			notify("Missed dose", message)
+			missed = append(missed, message)
+		}
+	}
+
+	if len(missed) == 0 {
+		return nil, nil
+	}
+	return missed, saveMedications(meds)
+}
+
+// absDuration returns the absolute value of d.
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
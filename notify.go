@@ -0,0 +1,48 @@
+//go:build !android
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// notify shows a desktop notification using the platform's native tool,
+// falling back to ringing the terminal bell when none is available.
+func notify(title, message string) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %q with title %q", message, title)
+		cmd = exec.Command("osascript", "-e", script)
+	case "windows":
+		cmd = exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", windowsToast)
+		cmd.Env = append(os.Environ(), toastTitleEnv+"="+title, toastMessageEnv+"="+message)
+	default:
+		cmd = exec.Command("notify-send", title, message)
+	}
+
+	if err := cmd.Run(); err != nil {
+		fmt.Fprint(stdout, "\a")
+	}
+}
+
+// toastTitleEnv and toastMessageEnv pass the title and message of a toast
+// to windowsToast. Spliced into the script, they would have to be quoted
+// against every quote character PowerShell knows, typographic ones included.
+const (
+	toastTitleEnv   = "TASK_TOAST_TITLE"
+	toastMessageEnv = "TASK_TOAST_MESSAGE"
+)
+
+// windowsToast is a PowerShell script showing a toast notification with
+// the title and message in its environment. Toasts need a registered
+// application ID; this is the one Windows shows PowerShell's under.
+const windowsToast = `[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] | Out-Null; ` +
+	`$xml = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02); ` +
+	`$text = $xml.GetElementsByTagName('text'); ` +
+	`$text.Item(0).AppendChild($xml.CreateTextNode($env:` + toastTitleEnv + `)) | Out-Null; ` +
+	`$text.Item(1).AppendChild($xml.CreateTextNode($env:` + toastMessageEnv + `)) | Out-Null; ` +
+	`[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe').Show([Windows.UI.Notifications.ToastNotification]::new($xml))`
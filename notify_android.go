@@ -0,0 +1,20 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// notify posts a notification from Termux: with termux-notification
+// when the Termux:API add-on is installed, otherwise with Android's own
+// notification service through the cmd tool, a sibling of am. The terminal
+// bell is the last resort.
+func notify(title, message string) {
+	if exec.Command("termux-notification", "--title", title, "--content", message).Run() == nil {
+		return
+	}
+	if exec.Command("cmd", "notification", "post", "-S", "bigtext", "-t", title, "task", message).Run() == nil {
+		return
+	}
+	fmt.Fprint(stdout, "\a")
+}
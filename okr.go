@@ -0,0 +1,440 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+// Objective is a quarterly goal measured by its key results.
+type Objective struct {
+	ID         int         `json:"id"`
+	Title      string      `json:"title"`
+	Quarter    string      `json:"quarter"` // e.g. "2025-Q1".
+	KeyResults []KeyResult `json:"keyResults,omitempty"`
+	CreatedAt  time.Time   `json:"createdAt"`
+}
+
+// KeyResult is a measurable outcome of an objective. With a Target its
+// progress is Current/Target; without one it is the share of linked tasks
+// that are done. IDs are unique across all objectives so tasks can link to
+// a key result by ID alone.
+type KeyResult struct {
+	ID      int     `json:"id"`
+	Title   string  `json:"title"`
+	Target  float64 `json:"target,omitempty"`
+	Current float64 `json:"current,omitempty"`
+	Unit    string  `json:"unit,omitempty"`
+}
+
+const okrFile = "okrs.json" // The name of the saved objectives file.
+
+var quarterPattern = regexp.MustCompile(`^(\d{4})-[Qq]([1-4])$`)
+
+// okrCommand returns the okr command group.
+func okrCommand() *command {
+	return &command{
+		name: "okr", summary: "Set quarterly objectives and key results", group: groupPlanning,
+		subcommands: []*command{
+			{
+				name: "add", args: "<objective>", summary: "Add a quarterly objective", minArgs: 1,
+				setup: func(fs *flag.FlagSet) runFunc {
+					quarter := fs.String("quarter", "", "`quarter` the objective belongs to (YYYY-QN, default this quarter)")
+					return func(args []string) error {
+						q, err := parseQuarter(*quarter)
+						if err != nil {
+							return usagef("%v", err)
+						}
+						return addObjective(strings.Join(args, " "), q)
+					}
+				},
+			},
+			{
+				name: "kr", args: "<objective-id> <key result>", summary: "Add a key result to an objective", minArgs: 2,
+				setup: func(fs *flag.FlagSet) runFunc {
+					target := fs.Float64("target", 0, "numeric target; omit to measure by linked task completion")
+					unit := fs.String("unit", "", "unit of the target, e.g. users or km")
+					return func(args []string) error {
+						id, err := parseID(args[0], "objective")
+						if err != nil {
+							return err
+						}
+						if *target < 0 {
+							return usagef("invalid target %g", *target)
+						}
+						return addKeyResult(id, strings.Join(args[1:], " "), *target, *unit)
+					}
+				},
+			},
+			{
+				name: "set", args: "<kr-id> <value>", summary: "Record progress on a numeric key result", minArgs: 2,
+				setup: run(func(args []string) error {
+					id, err := parseID(args[0], "key result")
+					if err != nil {
+						return err
+					}
+					value, err := strconv.ParseFloat(args[1], 64)
+					if err != nil {
+						return usagef("invalid value '%s'", args[1])
+					}
+					return setKeyResult(id, value)
+				}),
+			},
+			{
+				name: "link", args: "<task-id> <kr-id>", summary: "Link a task to a key result", minArgs: 2,
+				complete: positional(taskIDs),
+				setup: run(func(args []string) error {
+					taskID, err := parseID(args[0], "task")
+					if err != nil {
+						return err
+					}
+					krID, err := parseID(args[1], "key result")
+					if err != nil {
+						return err
+					}
+					return linkTask(taskID, krID)
+				}),
+			},
+			{
+				name: "unlink", args: "<task-id>", summary: "Unlink a task from its key result", minArgs: 1,
+				complete: positional(taskIDs),
+				setup: run(func(args []string) error {
+					taskID, err := parseID(args[0], "task")
+					if err != nil {
+						return err
+					}
+					return linkTask(taskID, 0)
+				}),
+			},
+			{
+				name: "delete", args: "<objective-id>", summary: "Delete an objective", minArgs: 1,
+				setup: run(func(args []string) error {
+					id, err := parseID(args[0], "objective")
+					if err != nil {
+						return err
+					}
+					return deleteObjective(id)
+				}),
+			},
+			{
+				name: "status", summary: "Show the quarterly OKR scorecard",
+				setup: func(fs *flag.FlagSet) runFunc {
+					quarter := fs.String("quarter", "", "`quarter` to score (YYYY-QN, default this quarter)")
+					return func([]string) error {
+						q, err := parseQuarter(*quarter)
+						if err != nil {
+							return usagef("%v", err)
+						}
+						return printScorecard(q)
+					}
+				},
+			},
+		},
+	}
+}
+
+// loadObjectives reads the objectives from the saved JSON file.
+func loadObjectives() ([]Objective, error) {
+	raw, err := loadDocument(okrFile)
+	if err != nil || raw == nil {
+		return []Objective{}, err
+	}
+
+	var objectives []Objective
+	if err := json.Unmarshal(raw, &objectives); err != nil {
+		return nil, fmt.Errorf("error unmarshalling JSON: %w", err)
+	}
+
+	return objectives, nil
+}
+
+// saveObjectives writes the objectives to the JSON file.
+func saveObjectives(objectives []Objective) error {
+	return saveDocument(okrFile, objectives)
+}
+
+// parseQuarter validates a YYYY-QN quarter, defaulting to the current one.
+func parseQuarter(s string) (string, error) {
+	if s == "" {
+		return quarterOf(clock()), nil
+	}
+	m := quarterPattern.FindStringSubmatch(s)
+	if m == nil {
+		return "", fmt.Errorf("invalid quarter '%s'; use YYYY-QN, e.g. 2025-Q1", s)
+	}
+	return m[1] + "-Q" + m[2], nil
+}
+
+// quarterOf returns the quarter containing t.
+func quarterOf(t time.Time) string {
+	return fmt.Sprintf("%d-Q%d", t.Year(), (int(t.Month())-1)/3+1)
+}
+
+// addObjective adds an objective for quarter.
+func addObjective(title, quarter string) error {
+	objectives, err := loadObjectives()
+	if err != nil {
+		return err
+	}
+
+	maxID := 0
+	for _, o := range objectives {
+		maxID = max(maxID, o.ID)
+	}
+	objective := Objective{
+		ID:        maxID + 1,
+		Title:     title,
+		Quarter:   quarter,
+		CreatedAt: clock(),
+	}
+
+	objectives = append(objectives, objective)
+	if err := saveObjectives(objectives); err != nil {
+		return err
+	}
+
+	printDone("Objective added successfully (ID: %d, %s)\n", objective.ID, quarter)
+	return nil
+}
+
+// addKeyResult adds a key result to an objective.
+func addKeyResult(objectiveID int, title string, target float64, unit string) error {
+	objectives, err := loadObjectives()
+	if err != nil {
+		return err
+	}
+
+	maxID := 0
+	for _, o := range objectives {
+		for _, kr := range o.KeyResults {
+			maxID = max(maxID, kr.ID)
+		}
+	}
+
+	for i, o := range objectives {
+		if o.ID == objectiveID {
+			kr := KeyResult{ID: maxID + 1, Title: title, Target: target, Unit: unit}
+			objectives[i].KeyResults = append(objectives[i].KeyResults, kr)
+			if err := saveObjectives(objectives); err != nil {
+				return err
+			}
+			printDone("Key result added successfully (ID: %d)\n", kr.ID)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("objective with ID %d %w", objectiveID, tracker.ErrNotFound)
+}
+
+// findKeyResult returns the key result with id and its objective.
+func findKeyResult(objectives []Objective, id int) (*Objective, *KeyResult) {
+	for i := range objectives {
+		for j := range objectives[i].KeyResults {
+			if objectives[i].KeyResults[j].ID == id {
+				return &objectives[i], &objectives[i].KeyResults[j]
+			}
+		}
+	}
+	return nil, nil
+}
+
+// setKeyResult records the current value of a numeric key result.
+func setKeyResult(id int, value float64) error {
+	objectives, err := loadObjectives()
+	if err != nil {
+		return err
+	}
+
+	_, kr := findKeyResult(objectives, id)
+	if kr == nil {
+		return fmt.Errorf("key result with ID %d %w", id, tracker.ErrNotFound)
+	}
+	if kr.Target == 0 {
+		return fmt.Errorf("key result %d has no target; its progress comes from linked tasks", id)
+	}
+	kr.Current = value
+	if err := saveObjectives(objectives); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "Key result %d: %g/%g %s\n", id, value, kr.Target, kr.Unit)
+	return nil
+}
+
+// linkTask links a task to a key result, or unlinks it when krID is 0.
+func linkTask(taskID, krID int) error {
+	if krID != 0 {
+		objectives, err := loadObjectives()
+		if err != nil {
+			return err
+		}
+		if _, kr := findKeyResult(objectives, krID); kr == nil {
+			return fmt.Errorf("key result with ID %d %w", krID, tracker.ErrNotFound)
+		}
+	}
+
+	tasks, err := loadTasks()
+	if err != nil {
+		return err
+	}
+
+	for i, task := range tasks {
+		if task.ID == taskID {
+			tasks[i].KeyResult = krID
+			tasks[i].UpdatedAt = clock()
+			if err := saveTasks(tasks); err != nil {
+				return err
+			}
+			if krID == 0 {
+				fmt.Fprintf(stdout, "Task ID %d unlinked.\n", taskID)
+			} else {
+				fmt.Fprintf(stdout, "Task ID %d linked to key result %d.\n", taskID, krID)
+			}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("task with ID %d %w", taskID, tracker.ErrNotFound)
+}
+
+// deleteObjective removes an objective and unlinks tasks from its key results.
+func deleteObjective(id int) error {
+	objectives, err := loadObjectives()
+	if err != nil {
+		return err
+	}
+
+	for i, o := range objectives {
+		if o.ID != id {
+			continue
+		}
+		objectives = append(objectives[:i], objectives[i+1:]...)
+		if err := saveObjectives(objectives); err != nil {
+			return err
+		}
+
+		krs := map[int]bool{}
+		for _, kr := range o.KeyResults {
+			krs[kr.ID] = true
+		}
+		tasks, err := loadTasks()
+		if err != nil {
+			return err
+		}
+		unlinked := 0
+		for j := range tasks {
+			if krs[tasks[j].KeyResult] {
+				tasks[j].KeyResult = 0
+				unlinked++
+			}
+		}
+		if unlinked > 0 {
+			if err := saveTasks(tasks); err != nil {
+				return err
+			}
+		}
+
+		printDone("Objective ID %d deleted successfully (%d task(s) unlinked)\n", id, unlinked)
+		return nil
+	}
+
+	return fmt.Errorf("objective with ID %d %w", id, tracker.ErrNotFound)
+}
+
+// krProgress returns a key result's progress between 0 and 1, along with the
+// number of linked tasks that are done and linked in total.
+func krProgress(kr KeyResult, tasks []Task) (float64, int, int) {
+	done, linked := 0, 0
+	for _, task := range tasks {
+		if task.KeyResult == kr.ID {
+			linked++
+			if config.Workflow.IsDone(task.Status) {
+				done++
+			}
+		}
+	}
+
+	switch {
+	case kr.Target > 0:
+		return min(kr.Current/kr.Target, 1), done, linked
+	case linked > 0:
+		return float64(done) / float64(linked), done, linked
+	}
+	return 0, done, linked
+}
+
+// printScorecard prints each objective of quarter with its key results'
+// progress. An objective's score is the average of its key results.
+func printScorecard(quarter string) error {
+	objectives, err := loadObjectives()
+	if err != nil {
+		return err
+	}
+	tasks, err := loadTasks()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "--- OKR Scorecard %s ---\n", quarter)
+	found := false
+	for _, o := range objectives {
+		if o.Quarter != quarter {
+			continue
+		}
+		found = true
+
+		lines := make([]string, 0, len(o.KeyResults))
+		total := 0.0
+		for _, kr := range o.KeyResults {
+			progress, done, linked := krProgress(kr, tasks)
+			total += progress
+
+			measure := fmt.Sprintf("%d/%d tasks", done, linked)
+			if kr.Target > 0 {
+				measure = strings.TrimSpace(fmt.Sprintf("%g/%g %s", kr.Current, kr.Target, kr.Unit))
+				if linked > 0 {
+					measure += fmt.Sprintf(", %d/%d tasks", done, linked)
+				}
+			}
+			lines = append(lines, fmt.Sprintf("  [KR %d] %s %s %s (%s)",
+				kr.ID, progressBar(int(progress*100), 100, 10), scoreColor(progress), kr.Title, measure))
+		}
+
+		score := 0.0
+		if len(o.KeyResults) > 0 {
+			score = total / float64(len(o.KeyResults))
+		}
+		fmt.Fprintf(stdout, "[ID: %d] %s %s\n", o.ID, scoreColor(score), o.Title)
+		if len(lines) == 0 {
+			fmt.Fprintln(stdout, "  (no key results)")
+		}
+		for _, line := range lines {
+			fmt.Fprintln(stdout, line)
+		}
+	}
+	if !found {
+		fmt.Fprintln(stdout, "No objectives for this quarter.")
+	}
+	fmt.Fprintln(stdout, "-------------------------------")
+
+	return nil
+}
+
+// scoreColor formats a 0-1 score, colored green when on track (0.7 and
+// above), yellow when behind and red below 0.4.
+func scoreColor(score float64) string {
+	s := fmt.Sprintf("%.2f", score)
+	switch {
+	case score >= 0.7:
+		return colorize(s, "green")
+	case score >= 0.4:
+		return colorize(s, "yellow")
+	}
+	return colorize(s, "red")
+}
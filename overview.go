@@ -0,0 +1,124 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+// overviewCommand returns the overview command.
+func overviewCommand() *command {
+	return &command{
+		name: "overview", summary: "Sum up open tasks by project and tag, with the command listing each", group: groupTasks,
+		setup: run(func([]string) error { return printOverview() }),
+	}
+}
+
+// rollup sums up the open tasks of a project or tag.
+type rollup struct {
+	name    string
+	open    int
+	overdue int
+	nextDue *time.Time
+	command string // Lists the tasks summed up.
+}
+
+// add counts an open task into r.
+func (r *rollup) add(task Task, now time.Time) {
+	r.open++
+	if task.Due == nil {
+		return
+	}
+	if now.After(tracker.Deadline(*task.Due)) {
+		r.overdue++
+	}
+	if r.nextDue == nil || task.Due.Before(*r.nextDue) {
+		r.nextDue = task.Due
+	}
+}
+
+// printOverview prints how many tasks are open in each project and with
+// each tag of the current project, how many are overdue and when the next
+// is due, each with the command that lists them. It is the way into lists
+// too long to read through.
+func printOverview() error {
+	now := clock()
+	current := cmp.Or(currentProject, defaultProject)
+	var projects []*rollup
+	var tags map[string]*rollup
+	err := forEachProject(func(project string) error {
+		tasks, err := tr().Tasks()
+		if err != nil {
+			return err
+		}
+		r := &rollup{name: project, command: "task --project " + shellQuote(project) + " list"}
+		projectTags := map[string]*rollup{}
+		for _, task := range tasks {
+			if config.Workflow.IsDone(task.Status) {
+				continue
+			}
+			r.add(task, now)
+			for _, tag := range task.Tags {
+				if projectTags[tag] == nil {
+					projectTags[tag] = &rollup{name: "#" + tag, command: "task list --tag " + shellQuote(tag)}
+					if project != defaultProject {
+						projectTags[tag].command = "task --project " + shellQuote(project) + " list --tag " + shellQuote(tag)
+					}
+				}
+				projectTags[tag].add(task, now)
+			}
+		}
+		projects = append(projects, r)
+		if project == current {
+			tags = projectTags
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(stdout, "--- Projects ---")
+	printRollups(projects, now)
+	if len(tags) > 0 {
+		fmt.Fprintf(stdout, "--- Tags in %s ---\n", current)
+		var rollups []*rollup
+		for _, tag := range sortedKeys(tags) {
+			rollups = append(rollups, tags[tag])
+		}
+		// The busiest tags first
+		slices.SortStableFunc(rollups, func(a, b *rollup) int { return b.open - a.open })
+		printRollups(rollups, now)
+	}
+	fmt.Fprintln(stdout, "-----------------")
+	return nil
+}
+
+// printRollups prints a line per rollup, lining up their commands.
+func printRollups(rollups []*rollup, now time.Time) {
+	tw := tabwriter.NewWriter(stdout, 0, 0, 3, ' ', 0)
+	for _, r := range rollups {
+		counts := []string{fmt.Sprintf("%d open", r.open)}
+		if r.overdue > 0 {
+			counts = append(counts, fmt.Sprintf("%d overdue", r.overdue))
+		}
+		next := "-"
+		if r.nextDue != nil {
+			next = "earliest due " + formatDue(*r.nextDue)
+			if config.Display.relativeTimes() {
+				label := relativeDue(*r.nextDue, now)
+				next = "earliest due " + label
+				if tracker.Deadline(*r.nextDue).Before(now) {
+					next = "earliest " + label
+				}
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.name, strings.Join(counts, ", "), next, r.command)
+	}
+	tw.Flush()
+}
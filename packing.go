@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+)
+
+const packingFile = "packing.json" // The packing list template file.
+
+// PackingEntry is one line of a packing template. The packed quantity is
+// Quantity plus PerDay for every day of the trip, capped at Max when set.
+type PackingEntry struct {
+	Item     string `json:"item"`
+	Quantity int    `json:"quantity,omitempty"`
+	PerDay   int    `json:"perDay,omitempty"`
+	Max      int    `json:"max,omitempty"`
+}
+
+// examplePackingTemplates is written when no template file exists yet.
+var examplePackingTemplates = map[string][]PackingEntry{
+	"travel": {
+		{Item: "passport", Quantity: 1},
+		{Item: "phone charger", Quantity: 1},
+		{Item: "toothbrush", Quantity: 1},
+		{Item: "socks", PerDay: 1, Quantity: 1},
+		{Item: "underwear", PerDay: 1, Quantity: 1},
+		{Item: "shirts", PerDay: 1, Max: 7},
+	},
+}
+
+// loadPackingTemplates reads the packing templates, writing an example file
+// and returning an error if none exists yet.
+func loadPackingTemplates() (map[string][]PackingEntry, error) {
+	data, err := os.ReadFile(packingFile)
+	if os.IsNotExist(err) {
+		example, err := json.MarshalIndent(examplePackingTemplates, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("error marshalling JSON: %w", err)
+		}
+		if err := writeFile(packingFile, example, 0644); err != nil {
+			return nil, fmt.Errorf("error writing file: %w", err)
+		}
+		return nil, fmt.Errorf("no packing templates found; an example was written to %s, edit it and run again", packingFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+
+	var templates map[string][]PackingEntry
+	if err := json.Unmarshal(data, &templates); err != nil {
+		return nil, fmt.Errorf("error unmarshalling %s: %w", packingFile, err)
+	}
+	return templates, nil
+}
+
+// packingQuantity returns how many of an entry to pack for a trip of days.
+func packingQuantity(entry PackingEntry, days int) int {
+	qty := entry.Quantity + entry.PerDay*days
+	if entry.Max > 0 && qty > entry.Max {
+		qty = entry.Max
+	}
+	return qty
+}
+
+// packCommand returns the pack command.
+func packCommand() *command {
+	return &command{
+		name: "pack", summary: "Create a packing list task from packing.json", group: groupHousehold,
+		setup: func(fs *flag.FlagSet) runFunc {
+			template := fs.String("template", "", "packing `template` to expand (required)")
+			days := fs.Int("days", 1, "length of the trip in days")
+			return func([]string) error {
+				if *template == "" {
+					return usagef("missing --template")
+				}
+				if *days < 1 {
+					return usagef("invalid number of days %d", *days)
+				}
+				return createPackingList(*template, *days)
+			}
+		},
+	}
+}
+
+// createPackingList expands a packing template for a trip of days into a new
+// task whose checklist holds the items to pack.
+func createPackingList(template string, days int) error {
+	templates, err := loadPackingTemplates()
+	if err != nil {
+		return err
+	}
+
+	entries, ok := templates[template]
+	if !ok {
+		return fmt.Errorf("packing template '%s' not found in %s", template, packingFile)
+	}
+
+	var checklist []ChecklistItem
+	for _, entry := range entries {
+		qty := packingQuantity(entry, days)
+		if qty <= 0 {
+			continue
+		}
+		text := entry.Item
+		if qty > 1 {
+			text = fmt.Sprintf("%d x %s", qty, entry.Item)
+		}
+		checklist = append(checklist, ChecklistItem{Text: text})
+	}
+
+	tasks, err := loadTasks()
+	if err != nil {
+		return err
+	}
+
+	now := clock()
+	newTask := Task{
+		ID:          getNextID(tasks),
+		Description: fmt.Sprintf("Pack for %s (%d days)", template, days),
+		Status:      config.Workflow.Initial(),
+		Checklist:   checklist,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	tasks = append(tasks, newTask)
+	if err := saveTasks(tasks); err != nil {
+		return err
+	}
+
+	printDone("Packing list added successfully (ID: %d, %d items)\n", newTask.ID, len(checklist))
+	return nil
+}
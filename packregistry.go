@@ -0,0 +1,301 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	packsFile        = "packs.json" // Installed shared packs, shared by all projects.
+	packManifest     = "pack.json"  // The pack file at the root of a pack repository.
+	packFetchTimeout = 30 * time.Second
+
+	githubAPI = "https://api.github.com"
+	githubRaw = "https://raw.githubusercontent.com"
+)
+
+// Valid GitHub repositories ("user/repo") and refs of pack sources.
+var (
+	repoPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+$`)
+	refPattern  = regexp.MustCompile(`^[A-Za-z0-9_.][A-Za-z0-9_./-]*$`)
+)
+
+// InstalledPack records a shared pack that was installed and where from.
+type InstalledPack struct {
+	Source      string     `json:"source"`           // As given to install, without the @ref.
+	Ref         string     `json:"ref,omitempty"`    // The tag, branch or commit asked for.
+	Commit      string     `json:"commit,omitempty"` // The commit installed, for GitHub sources.
+	SHA256      string     `json:"sha256"`           // Of the pack file installed.
+	InstalledAt time.Time  `json:"installedAt"`
+	Pack        SharedPack `json:"pack"`
+}
+
+// packsCommand returns the packs command group.
+func packsCommand() *command {
+	return &command{
+		name: "packs", summary: "Install shared packs of templates, recipes, filters and rules", group: groupData,
+		subcommands: []*command{
+			{
+				name: "install", args: "<github.com/user/repo[@version]|url|file>", summary: "Install or update a pack, pinned to the version fetched", minArgs: 1,
+				setup: func(fs *flag.FlagSet) runFunc {
+					yes := fs.Bool("yes", false, "install without asking for confirmation")
+					return func(args []string) error { return installPack(args[0], *yes) }
+				},
+			},
+			{
+				name: "list", summary: "List the installed packs",
+				setup: run(func([]string) error { return listPacks() }),
+			},
+			{
+				name: "remove", args: "<name>", summary: "Remove a pack's templates, recipes, filters and rules", minArgs: 1,
+				complete: positional(installedPackNames),
+				setup:    run(func(args []string) error { return removePack(args[0]) }),
+			},
+		},
+	}
+}
+
+// loadInstalledPacks reads the installed packs.
+func loadInstalledPacks() ([]InstalledPack, error) {
+	var packs []InstalledPack
+	if err := readTemplateFile(packsFile, &packs); err != nil {
+		return nil, err
+	}
+	return packs, nil
+}
+
+// installPack fetches a pack, shows what it would change and installs it
+// once confirmed, recording the exact version installed. Templates,
+// recipes, filters and rules an earlier version added but this one drops
+// are removed.
+func installPack(source string, yes bool) error {
+	data, installed, err := fetchPack(source)
+	if err != nil {
+		return err
+	}
+	pack, err := parseSharedPack(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	installed.SHA256 = hex.EncodeToString(sum[:])
+	installed.InstalledAt = clock()
+	installed.Pack = pack
+
+	packs, err := loadInstalledPacks()
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(packs, func(p InstalledPack) bool { return p.Pack.Name == pack.Name })
+	if i >= 0 && packs[i].SHA256 == installed.SHA256 {
+		fmt.Fprintf(stdout, "Pack '%s' is already installed at this version.\n", pack.Name)
+		return nil
+	}
+
+	var previous SharedPack
+	if i >= 0 {
+		previous = packs[i].Pack
+	}
+	ok, err := offerSharedPack(pack, previous, yes)
+	if err != nil || !ok {
+		return err
+	}
+	if i >= 0 {
+		if err := uninstallSharedPack(packs[i].Pack, pack); err != nil {
+			return err
+		}
+		packs[i] = installed
+	} else {
+		packs = append(packs, installed)
+	}
+	return writeTemplateFile(packsFile, packs)
+}
+
+// fetchPack downloads the pack file named by source: a GitHub repository,
+// optionally followed by @ and a tag, branch or commit; an http(s) URL; or a
+// local file or directory. GitHub sources are pinned to the commit fetched.
+func fetchPack(source string) ([]byte, InstalledPack, error) {
+	installed := InstalledPack{Source: source}
+	switch {
+	case strings.HasPrefix(source, "github.com/"):
+		repo, ref, _ := strings.Cut(strings.TrimPrefix(source, "github.com/"), "@")
+		if !repoPattern.MatchString(repo) || strings.Contains(repo, "..") {
+			return nil, installed, usagef("invalid repository '%s'; use github.com/user/repo", source)
+		}
+		if ref != "" && (!refPattern.MatchString(ref) || strings.Contains(ref, "..")) {
+			return nil, installed, usagef("invalid version '%s'", ref)
+		}
+		installed.Source, installed.Ref = "github.com/"+repo, ref
+		if ref == "" {
+			ref = "HEAD"
+		}
+		commit, err := httpGet(fmt.Sprintf("%s/repos/%s/commits/%s", githubAPI, repo, ref), "application/vnd.github.sha")
+		if err != nil {
+			return nil, installed, fmt.Errorf("error resolving %s@%s: %w", repo, ref, err)
+		}
+		installed.Commit = strings.TrimSpace(string(commit))
+		data, err := httpGet(fmt.Sprintf("%s/%s/%s/%s", githubRaw, repo, installed.Commit, packManifest), "")
+		if err != nil {
+			return nil, installed, fmt.Errorf("error fetching %s from %s: %w", packManifest, repo, err)
+		}
+		return data, installed, nil
+
+	case strings.HasPrefix(source, "https://") || strings.HasPrefix(source, "http://"):
+		data, err := httpGet(source, "")
+		if err != nil {
+			return nil, installed, fmt.Errorf("error fetching pack: %w", err)
+		}
+		return data, installed, nil
+	}
+
+	path := source
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, packManifest)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, installed, fmt.Errorf("error opening file: %w", err)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxPackSize+1))
+	if err != nil {
+		return nil, installed, fmt.Errorf("error reading file: %w", err)
+	}
+	return data, installed, nil
+}
+
+// httpGet fetches url, reading at most one byte more than a pack may hold.
+func httpGet(url, accept string) ([]byte, error) {
+	return httpGetToken(url, accept, "")
+}
+
+// httpGetToken is httpGet sending token, if any, as a bearer token.
+func httpGetToken(url, accept, token string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	client := &http.Client{Timeout: packFetchTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxPackSize+1))
+}
+
+// uninstallSharedPack removes the templates, recipes, filters and rules old
+// added that next does not, unless they were changed since.
+func uninstallSharedPack(old, next SharedPack) error {
+	t, err := loadPackTargets()
+	if err != nil {
+		return err
+	}
+
+	var changed []string
+	if uninstallNamed("packing template", old.Packing, next.Packing, t.packing) {
+		changed = append(changed, packingFile)
+	}
+	if uninstallNamed("recipe", old.Recipes, next.Recipes, t.plan.Recipes) {
+		changed = append(changed, mealsFile)
+	}
+	if uninstallNamed("task template", old.Templates, next.Templates, t.templates) {
+		changed = append(changed, templatesFile)
+	}
+	if uninstallNamed("filter", old.Filters, next.Filters, t.filters) {
+		changed = append(changed, filtersFile)
+	}
+	// A rule changed since is another rule, so only those left as they were
+	// are found to remove.
+	rules := slices.DeleteFunc(slices.Clone(t.rules), func(r CategoryRule) bool {
+		return slices.Contains(old.Rules, r) && !slices.Contains(next.Rules, r)
+	})
+	if len(rules) < len(t.rules) {
+		t.rules = rules
+		changed = append(changed, configFile)
+	}
+	return t.save(changed)
+}
+
+// removePack removes an installed pack and what it added.
+func removePack(name string) error {
+	packs, err := loadInstalledPacks()
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(packs, func(p InstalledPack) bool { return p.Pack.Name == name })
+	if i < 0 {
+		return fmt.Errorf("pack '%s' is not installed", name)
+	}
+
+	if err := uninstallSharedPack(packs[i].Pack, SharedPack{}); err != nil {
+		return err
+	}
+	if err := writeTemplateFile(packsFile, slices.Delete(packs, i, i+1)); err != nil {
+		return err
+	}
+	printDone("Removed pack '%s'.\n", name)
+	return nil
+}
+
+// listPacks prints the installed packs and where they came from.
+func listPacks() error {
+	packs, err := loadInstalledPacks()
+	if err != nil {
+		return err
+	}
+	if len(packs) == 0 {
+		fmt.Fprintln(stdout, "No packs installed.")
+		return nil
+	}
+
+	fmt.Fprintln(stdout, "--- Installed Packs ---")
+	for _, p := range packs {
+		title := p.Pack.Name
+		if p.Pack.Version != "" {
+			title += " " + p.Pack.Version
+		}
+		source := p.Source
+		if p.Ref != "" {
+			source += "@" + p.Ref
+		}
+		if p.Commit != "" {
+			source += fmt.Sprintf(" (commit %.12s)", p.Commit)
+		}
+		fmt.Fprintf(stdout, "%s: %s, %s\n", title, plural(len(p.Pack.Packing), "packing template"), plural(len(p.Pack.Recipes), "recipe"))
+		fmt.Fprintf(stdout, "  From %s, installed %s\n", source, p.InstalledAt.Format("2006-01-02"))
+	}
+	fmt.Fprintln(stdout, "-----------------------")
+	return nil
+}
+
+// installedPackNames offers the names of the installed packs.
+func installedPackNames([]string) []candidate {
+	packs, _ := loadInstalledPacks()
+	var cs []candidate
+	for _, p := range packs {
+		cs = append(cs, candidate{p.Pack.Name, p.Source})
+	}
+	return cs
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+const pagerEnvVar = "TASK_PAGER" // Overrides $PAGER for task's output.
+
+// noPager is set by the global --no-pager flag.
+var noPager bool
+
+// pagedCommands lists the commands whose output is paged when it does not
+// fit the terminal, besides every list command.
+var pagedCommands = []string{"history", "overview", "report", "show", "summary"}
+
+// pageBuffer holds a paged command's output until it has finished.
+var pageBuffer *bytes.Buffer
+
+// pageOutput starts holding back the output of a command that lists things,
+// if it writes to a terminal, and returns the function that shows it once
+// the command is done: through the pager if it is longer than the terminal,
+// or as it is. Output that is not to a terminal is never paged, nor is it
+// colored (see useColor), so it can be piped and parsed.
+func pageOutput(root *command, args []string) func() {
+	if noPager || session != "" || !isTerminal(os.Stdout) || os.Getenv("TERM") == "dumb" || !pagedCommand(root, args) {
+		return func() {}
+	}
+	pager := pagerCommand()
+	rows := terminalHeight(os.Stdout)
+	if pager == nil || rows == 0 {
+		return func() {}
+	}
+
+	pageBuffer = &bytes.Buffer{}
+	stdout = pageBuffer
+	return func() {
+		out := pageBuffer.Bytes()
+		stdout, pageBuffer = os.Stdout, nil
+		if bytes.Count(out, []byte("\n")) < rows {
+			os.Stdout.Write(out)
+			return
+		}
+		pager.Stdin = bytes.NewReader(out)
+		pager.Stdout, pager.Stderr = os.Stdout, os.Stderr
+		if err := pager.Run(); err != nil && pager.ProcessState == nil {
+			os.Stdout.Write(out) // The pager could not be started.
+		}
+	}
+}
+
+// pagedCommand reports whether the command args run lists things.
+func pagedCommand(root *command, args []string) bool {
+	cmd := root
+	for _, arg := range args {
+		sub := cmd.find(arg)
+		if sub == nil {
+			break
+		}
+		cmd = sub
+	}
+	return cmd != root && (cmd.name == "list" || slices.Contains(pagedCommands, cmd.name))
+}
+
+// pagerCommand returns the pager to run: $TASK_PAGER, $PAGER, or less,
+// which is told to keep colors. It returns nil if there is none, or if the
+// pager is set to cat or to nothing at all.
+func pagerCommand() *exec.Cmd {
+	pager, set := os.LookupEnv(pagerEnvVar)
+	if !set {
+		pager, set = os.LookupEnv("PAGER")
+	}
+	if !set {
+		pager = "less"
+		if runtime.GOOS == "windows" {
+			pager = "more"
+		}
+	}
+	fields := strings.Fields(pager)
+	if len(fields) == 0 || fields[0] == "cat" {
+		return nil
+	}
+	path, err := exec.LookPath(fields[0])
+	if err != nil {
+		return nil
+	}
+	cmd := exec.Command(path, fields[1:]...)
+	if _, set := os.LookupEnv("LESS"); !set {
+		cmd.Env = append(os.Environ(), "LESS=FRX")
+	}
+	return cmd
+}
@@ -0,0 +1,206 @@
+package tracker
+
+import (
+	"cmp"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Kinds of anomaly.
+const (
+	AnomalyLarge     = "large"     // An expense far above its category's usual amount.
+	AnomalyDuplicate = "duplicate" // The same charge twice within a few days.
+	AnomalySpike     = "spike"     // A month's spending in a category far above the months before.
+)
+
+// AnomalyOptions tune FindAnomalies; zero fields take the defaults.
+type AnomalyOptions struct {
+	Since          time.Time // Only anomalies from this instant on; all if zero.
+	LargeFactor    float64   // Times the category median an expense must reach to be large; 3.
+	MinSamples     int       // Other expenses a category needs before any is large; 5.
+	DuplicateDays  int       // Days apart the same charge may be to be a duplicate; 3.
+	SpikeFactor    float64   // Times the trailing average a month must reach to be a spike; 1.5.
+	TrailingMonths int       // Months the trailing average is taken over; 3.
+}
+
+// Anomaly is something unusual in the expenses. Amounts of different
+// currencies are never compared: each currency is looked at on its own.
+type Anomaly struct {
+	Kind     string    `json:"kind"`
+	Expenses []Expense `json:"expenses"` // The expense, both charges, or those of the month.
+	Category string    `json:"category,omitempty"`
+	Currency string    `json:"currency,omitempty"` // "" is the base currency.
+	Month    time.Time `json:"month,omitzero"`     // Of a spike.
+	Amount   float64   `json:"amount"`             // The expense's amount, or the month's total.
+	Baseline float64   `json:"baseline"`           // The usual amount, or the trailing average.
+}
+
+// withDefaults fills in the zero fields of o.
+func (o AnomalyOptions) withDefaults() AnomalyOptions {
+	o.LargeFactor = cmp.Or(o.LargeFactor, 3)
+	o.MinSamples = cmp.Or(o.MinSamples, 5)
+	o.DuplicateDays = cmp.Or(o.DuplicateDays, 3)
+	o.SpikeFactor = cmp.Or(o.SpikeFactor, 1.5)
+	o.TrailingMonths = cmp.Or(o.TrailingMonths, 3)
+	return o
+}
+
+// FindAnomalies looks through expenses for unusually large ones, charges
+// recorded twice and months of unusual spending in a category, as of now.
+// Anomalies are returned oldest first.
+func FindAnomalies(expenses []Expense, opts AnomalyOptions, now time.Time) []Anomaly {
+	opts = opts.withDefaults()
+	expenses = slices.Clone(expenses)
+	slices.SortStableFunc(expenses, func(a, b Expense) int { return a.Date.Compare(b.Date) })
+	inWindow := func(t time.Time) bool { return !t.Before(opts.Since) && !t.After(now) }
+
+	anomalies := findLarge(expenses, opts, inWindow)
+	anomalies = append(anomalies, findDuplicates(expenses, opts, inWindow)...)
+	anomalies = append(anomalies, findSpikes(expenses, opts, now)...)
+	slices.SortStableFunc(anomalies, func(a, b Anomaly) int {
+		return cmp.Or(a.when().Compare(b.when()), strings.Compare(a.Category, b.Category), strings.Compare(a.Currency, b.Currency))
+	})
+	return anomalies
+}
+
+// when returns the date an anomaly is listed under.
+func (a Anomaly) when() time.Time {
+	if !a.Month.IsZero() {
+		return a.Month
+	}
+	return a.Expenses[len(a.Expenses)-1].Date
+}
+
+// groupKey is the category and currency of an expense.
+type groupKey struct{ category, currency string }
+
+// findLarge returns the expenses of at least LargeFactor times the median
+// of the others in their category and currency.
+func findLarge(expenses []Expense, opts AnomalyOptions, inWindow func(time.Time) bool) []Anomaly {
+	amounts := map[groupKey][]float64{}
+	for _, e := range expenses {
+		key := groupKey{e.Category, e.Currency}
+		amounts[key] = append(amounts[key], e.Amount)
+	}
+
+	var anomalies []Anomaly
+	for _, e := range expenses {
+		if !inWindow(e.Date) {
+			continue
+		}
+		key := groupKey{e.Category, e.Currency}
+		others := slices.Clone(amounts[key])
+		others = slices.Delete(others, slices.Index(others, e.Amount), slices.Index(others, e.Amount)+1)
+		if len(others) < opts.MinSamples {
+			continue
+		}
+		usual := median(others)
+		if usual > 0 && e.Amount >= opts.LargeFactor*usual {
+			anomalies = append(anomalies, Anomaly{
+				Kind: AnomalyLarge, Expenses: []Expense{e},
+				Category: e.Category, Currency: e.Currency, Amount: e.Amount, Baseline: usual,
+			})
+		}
+	}
+	return anomalies
+}
+
+// findDuplicates returns the pairs of expenses with the same amount and
+// payee, or description if there is no payee, at most DuplicateDays apart.
+func findDuplicates(expenses []Expense, opts AnomalyOptions, inWindow func(time.Time) bool) []Anomaly {
+	window := time.Duration(opts.DuplicateDays) * 24 * time.Hour
+	var anomalies []Anomaly
+	for i, later := range expenses {
+		if !inWindow(later.Date) {
+			continue
+		}
+		for _, earlier := range slices.Backward(expenses[:i]) {
+			if later.Date.Sub(earlier.Date) > window {
+				break
+			}
+			if earlier.Amount == later.Amount && earlier.Currency == later.Currency && sameCharge(earlier, later) {
+				anomalies = append(anomalies, Anomaly{
+					Kind: AnomalyDuplicate, Expenses: []Expense{earlier, later},
+					Category: later.Category, Currency: later.Currency, Amount: later.Amount, Baseline: earlier.Amount,
+				})
+				break
+			}
+		}
+	}
+	return anomalies
+}
+
+// sameCharge reports whether two expenses were paid to the same payee, or
+// have the same description if either has no payee.
+func sameCharge(a, b Expense) bool {
+	if a.Payee != "" && b.Payee != "" {
+		return strings.EqualFold(a.Payee, b.Payee)
+	}
+	return strings.EqualFold(strings.TrimSpace(a.Description), strings.TrimSpace(b.Description))
+}
+
+// findSpikes returns the months since opts.Since whose spending in a
+// category reached SpikeFactor times its average over the months before.
+// Months without spending count towards the average, but a category must
+// have had some spending in them, and have been spent on for the whole
+// trailing window, to spike.
+func findSpikes(expenses []Expense, opts AnomalyOptions, now time.Time) []Anomaly {
+	monthOf := func(t time.Time) time.Time { return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location()) }
+	totals := map[groupKey]map[time.Time]float64{}
+	months := map[groupKey]map[time.Time][]Expense{}
+	for _, e := range expenses {
+		key, month := groupKey{e.Category, e.Currency}, monthOf(e.Date)
+		if totals[key] == nil {
+			totals[key], months[key] = map[time.Time]float64{}, map[time.Time][]Expense{}
+		}
+		totals[key][month] += e.Amount
+		months[key][month] = append(months[key][month], e)
+	}
+
+	var anomalies []Anomaly
+	for key, byMonth := range totals {
+		first := slices.MinFunc(slices.Collect(maps.Keys(byMonth)), time.Time.Compare)
+		for month, total := range byMonth {
+			// A month needs a full trailing window of history to compare to
+			if month.After(now) || monthOf(opts.Since).After(month) || first.After(month.AddDate(0, -opts.TrailingMonths, 0)) {
+				continue
+			}
+			trailing := 0.0
+			for i := 1; i <= opts.TrailingMonths; i++ {
+				trailing += byMonth[month.AddDate(0, -i, 0)]
+			}
+			average := trailing / float64(opts.TrailingMonths)
+			if average > 0 && total >= opts.SpikeFactor*average {
+				anomalies = append(anomalies, Anomaly{
+					Kind: AnomalySpike, Expenses: months[key][month],
+					Category: key.category, Currency: key.currency, Month: month,
+					Amount: total, Baseline: math.Round(average*100) / 100,
+				})
+			}
+		}
+	}
+	return anomalies
+}
+
+// median returns the middle of values, which must not be empty.
+func median(values []float64) float64 {
+	values = slices.Clone(values)
+	slices.Sort(values)
+	n := len(values)
+	if n%2 == 1 {
+		return values[n/2]
+	}
+	return (values[n/2-1] + values[n/2]) / 2
+}
+
+// Anomalies looks through the tracker's expenses for anomalies.
+func (t *Tracker) Anomalies(opts AnomalyOptions) ([]Anomaly, error) {
+	expenses, err := t.Expenses()
+	if err != nil {
+		return nil, err
+	}
+	return FindAnomalies(expenses, opts, t.now()), nil
+}
@@ -0,0 +1,130 @@
+package tracker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/internal/task"
+)
+
+// ArchiveFile is the name of the saved archived task file.
+const ArchiveFile = "archive.json"
+
+// ArchiveFilter selects tasks in Archive. At least one field must be set;
+// tasks must match all of those that are.
+type ArchiveFilter struct {
+	IDs           []int
+	Done          bool       // Only tasks in a done status.
+	UpdatedBefore *time.Time // Only tasks last updated before this instant.
+}
+
+// ArchivedTasks returns all archived tasks.
+func (t *Tracker) ArchivedTasks() ([]Task, error) {
+	items, err := t.store.Load(ArchiveFile)
+	if err != nil || items == nil {
+		return []Task{}, err
+	}
+
+	var tasks []Task
+	if err := json.Unmarshal(items, &tasks); err != nil {
+		return nil, fmt.Errorf("error unmarshalling JSON: %w", err)
+	}
+
+	return inLocation(tasks, t.loc), nil
+}
+
+// saveArchive replaces all archived tasks. The archive is encrypted along
+// with the task file even if encryption was enabled before it existed.
+func (t *Tracker) saveArchive(tasks []Task) error {
+	if t.Encrypted() && !t.store.Encrypted(ArchiveFile) {
+		if err := t.store.Encrypt([]string{ArchiveFile}); err != nil {
+			return err
+		}
+	}
+	return t.store.Save(ArchiveFile, inLocation(tasks, time.UTC))
+}
+
+// Archive moves the tasks matching filter to the archive file and returns
+// them. Archived tasks keep their IDs.
+func (t *Tracker) Archive(filter ArchiveFilter) ([]Task, error) {
+	if len(filter.IDs) == 0 && !filter.Done && filter.UpdatedBefore == nil {
+		return nil, Invalid(errors.New("no tasks selected to archive"))
+	}
+
+	tasks, err := t.Tasks()
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range filter.IDs {
+		if task.Index(tasks, id) < 0 {
+			return nil, fmt.Errorf("task with ID %d %w", id, ErrNotFound)
+		}
+	}
+
+	now := t.now()
+	var kept, moved []Task
+	for _, tk := range tasks {
+		if (len(filter.IDs) > 0 && !slices.Contains(filter.IDs, tk.ID)) ||
+			(filter.Done && !t.workflow.IsDone(tk.Status)) ||
+			(filter.UpdatedBefore != nil && !tk.UpdatedAt.Before(*filter.UpdatedBefore)) {
+			kept = append(kept, tk)
+			continue
+		}
+		tk.ArchivedAt = &now
+		moved = append(moved, tk)
+	}
+	if len(moved) == 0 {
+		return nil, nil
+	}
+
+	archived, err := t.ArchivedTasks()
+	if err != nil {
+		return nil, err
+	}
+	// Write the archive first so an interrupted archive duplicates tasks
+	// rather than losing them.
+	if err := t.saveArchive(append(archived, moved...)); err != nil {
+		return nil, err
+	}
+	if kept == nil {
+		kept = []Task{}
+	}
+	if err := t.SaveTasks(kept); err != nil {
+		return nil, err
+	}
+	return moved, nil
+}
+
+// Unarchive moves an archived task back to the task list. If its ID has
+// been given to a new task since, it gets the next free ID.
+func (t *Tracker) Unarchive(id int) (Task, error) {
+	archived, err := t.ArchivedTasks()
+	if err != nil {
+		return Task{}, err
+	}
+	i := task.Index(archived, id)
+	if i < 0 {
+		return Task{}, fmt.Errorf("archived task with ID %d %w", id, ErrNotFound)
+	}
+
+	tasks, err := t.Tasks()
+	if err != nil {
+		return Task{}, err
+	}
+	restored := archived[i]
+	restored.ArchivedAt = nil
+	if task.Index(tasks, id) >= 0 {
+		restored.ID = task.NextID(tasks)
+	}
+
+	if err := t.SaveTasks(append(tasks, restored)); err != nil {
+		return Task{}, err
+	}
+	if err := t.saveArchive(append(archived[:i], archived[i+1:]...)); err != nil {
+		return Task{}, err
+	}
+	return restored, nil
+}
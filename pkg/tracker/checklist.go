@@ -0,0 +1,50 @@
+package tracker
+
+import (
+	"fmt"
+	"slices"
+)
+
+// AddChecklistItem appends an item to a task's checklist. Its text is
+// cleaned up and checked as a description is.
+func (t *Tracker) AddChecklistItem(id int, text string) (Task, error) {
+	text = CleanText(text)
+	if err := t.checkDescription(text); err != nil {
+		return Task{}, err
+	}
+	return t.updateTask(id, func(tk *Task) error {
+		tk.Checklist = append(tk.Checklist, ChecklistItem{Text: text})
+		tk.UpdatedAt = t.now()
+		return nil
+	})
+}
+
+// CheckItem marks the nth item, counting from 1, of a task's checklist
+// done, or not done.
+func (t *Tracker) CheckItem(id, n int, done bool) (Task, error) {
+	return t.updateTask(id, func(tk *Task) error {
+		if n < 1 || n > len(tk.Checklist) {
+			return fmt.Errorf("checklist item %d of task %d %w", n, id, ErrNotFound)
+		}
+		tk.Checklist = slices.Clone(tk.Checklist)
+		tk.Checklist[n-1].Done = done
+		tk.UpdatedAt = t.now()
+		return nil
+	})
+}
+
+// RemoveChecklistItem removes the nth item, counting from 1, of a task's
+// checklist.
+func (t *Tracker) RemoveChecklistItem(id, n int) (Task, error) {
+	return t.updateTask(id, func(tk *Task) error {
+		if n < 1 || n > len(tk.Checklist) {
+			return fmt.Errorf("checklist item %d of task %d %w", n, id, ErrNotFound)
+		}
+		tk.Checklist = slices.Delete(slices.Clone(tk.Checklist), n-1, n)
+		if len(tk.Checklist) == 0 {
+			tk.Checklist = nil
+		}
+		tk.UpdatedAt = t.now()
+		return nil
+	})
+}
@@ -0,0 +1,89 @@
+package tracker
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// ValidCurrency reports whether code looks like an ISO 4217 currency code,
+// such as "EUR".
+func ValidCurrency(code string) bool {
+	return currencyPattern.MatchString(code)
+}
+
+// Rates are exchange rates against a base currency: PerBase[code] units of
+// a currency buy one unit of Base.
+type Rates struct {
+	Base    string             `json:"base"`
+	PerBase map[string]float64 `json:"perBase"`
+	AsOf    time.Time          `json:"asOf,omitzero"`
+}
+
+// RateProvider supplies exchange rates, from a fixed table or a service.
+type RateProvider interface {
+	Rates() (Rates, error)
+}
+
+// StaticRates is a RateProvider with a fixed table of rates.
+type StaticRates Rates
+
+// Rates returns the table.
+func (s StaticRates) Rates() (Rates, error) {
+	return Rates(s), nil
+}
+
+// rate returns the units of code that buy one unit of the base currency.
+func (r Rates) rate(code string) (float64, bool) {
+	if code == r.Base {
+		return 1, true
+	}
+	rate, ok := r.PerBase[code]
+	return rate, ok && rate > 0
+}
+
+// Convert converts amount from one currency to another.
+func (r Rates) Convert(amount float64, from, to string) (float64, error) {
+	if from == to {
+		return amount, nil
+	}
+	fromRate, ok := r.rate(from)
+	if !ok {
+		return 0, fmt.Errorf("no exchange rate for %s", from)
+	}
+	toRate, ok := r.rate(to)
+	if !ok {
+		return 0, fmt.Errorf("no exchange rate for %s", to)
+	}
+	return amount / fromRate * toRate, nil
+}
+
+// ConvertTotals sums totals by currency, in which "" stands for base, in
+// the currency to. All currencies without a rate are named in the error.
+func (r Rates) ConvertTotals(totals map[string]float64, base, to string) (float64, error) {
+	if _, ok := r.rate(to); !ok {
+		return 0, fmt.Errorf("no exchange rate for %s", to)
+	}
+	sum := 0.0
+	var missing []string
+	for code, amount := range totals {
+		if code == "" {
+			code = base
+		}
+		converted, err := r.Convert(amount, code, to)
+		if err != nil {
+			missing = append(missing, code)
+			continue
+		}
+		sum += converted
+	}
+	if len(missing) > 0 {
+		slices.Sort(missing)
+		return 0, fmt.Errorf("no exchange rate for %s", strings.Join(missing, ", "))
+	}
+	return sum, nil
+}
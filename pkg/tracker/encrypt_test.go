@@ -0,0 +1,35 @@
+package tracker_test
+
+import (
+	"testing"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+func TestEnableEncryptionCreatesCurrentFiles(t *testing.T) {
+	fsys := tracker.NewMemFS()
+	tr := tracker.New(tracker.Options{
+		Dir: "data",
+		FS:  fsys,
+		OnUpgrade: func(path string, from, to int, backup string) {
+			t.Errorf("%s upgraded from schema version %d to %d", path, from, to)
+		},
+	})
+	if err := tr.EnableEncryption("correct horse", "extra.json"); err != nil {
+		t.Fatalf("EnableEncryption: %v", err)
+	}
+	for _, name := range []string{tracker.TasksFile, tracker.ExpensesFile, tracker.IncomeFile, tracker.ArchiveFile, "extra.json"} {
+		if !tr.DocumentEncrypted(name) {
+			t.Errorf("%s was not encrypted", name)
+		}
+		if items, err := tr.ReadDocument(name); err != nil || string(items) != "[]" {
+			t.Errorf("ReadDocument(%s) = %s, %v; want no records", name, items, err)
+		}
+	}
+	if _, err := tr.AddTask("Write report"); err != nil {
+		t.Fatalf("AddTask: %v", err)
+	}
+	if _, err := fsys.ReadFile("data/" + tracker.TasksFile + ".v1.bak"); err == nil {
+		t.Errorf("a backup of %s was made", tracker.TasksFile)
+	}
+}
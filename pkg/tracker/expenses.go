@@ -0,0 +1,187 @@
+package tracker
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/internal/expense"
+)
+
+// ExpenseFilter selects expenses in ListExpenses. Zero fields match every
+// expense.
+type ExpenseFilter struct {
+	Category string
+	Task     string            // UUID of the task the expenses were spent on.
+	Since    *time.Time        // Only expenses dated at or after this instant.
+	Until    *time.Time        // Only expenses dated at or before this instant.
+	Fields   map[string]string // Only expenses with these values of their fields, in any case.
+}
+
+// ExpenseList is the result of ListExpenses.
+type ExpenseList struct {
+	Expenses []Expense
+	Total    float64            // Of the amounts, whatever their currency.
+	Totals   map[string]float64 // By currency; "" is the base currency.
+}
+
+// Expenses returns all saved expenses.
+func (t *Tracker) Expenses() ([]Expense, error) {
+	items, err := t.store.Load(ExpensesFile)
+	if err != nil || items == nil {
+		return []Expense{}, err
+	}
+
+	var expenses []Expense
+	if err := json.Unmarshal(items, &expenses); err != nil {
+		return nil, fmt.Errorf("error unmarshalling JSON: %w", err)
+	}
+
+	return inLocation(expenses, t.loc), nil
+}
+
+// SaveExpenses replaces all saved expenses, after Options.ReviewExpenses if
+// set.
+func (t *Tracker) SaveExpenses(expenses []Expense) error {
+	if t.reviewEx != nil {
+		saved, err := t.Expenses()
+		if err != nil {
+			return err
+		}
+		if expenses, err = t.reviewEx(saved, expenses); err != nil {
+			return err
+		}
+	}
+	return t.store.Save(ExpensesFile, inLocation(expenses, time.UTC))
+}
+
+// AddExpense records e, assigning its ID and creation time, and returns the
+// stored record. Its text is cleaned up, and it must pass the validation.
+func (t *Tracker) AddExpense(e Expense) (Expense, error) {
+	if err := t.cleanExpense(&e); err != nil {
+		return Expense{}, err
+	}
+	expenses, err := t.Expenses()
+	if err != nil {
+		return Expense{}, err
+	}
+
+	e.ID = expense.NextID(expenses)
+	e.CreatedAt = t.now()
+	if err := t.SaveExpenses(append(expenses, e)); err != nil {
+		return Expense{}, err
+	}
+	return e, nil
+}
+
+// AddExpenses records several expenses at once, assigning their IDs and
+// creation times, and returns the stored records. None are recorded if one
+// fails the validation.
+func (t *Tracker) AddExpenses(added []Expense) ([]Expense, error) {
+	for i := range added {
+		if err := t.cleanExpense(&added[i]); err != nil {
+			return nil, fmt.Errorf("expense %d of %d: %w", i+1, len(added), err)
+		}
+	}
+	expenses, err := t.Expenses()
+	if err != nil {
+		return nil, err
+	}
+
+	now := t.now()
+	for i := range added {
+		added[i].ID = expense.NextID(expenses)
+		added[i].CreatedAt = now
+		expenses = append(expenses, added[i])
+	}
+	if err := t.SaveExpenses(expenses); err != nil {
+		return nil, err
+	}
+	return added, nil
+}
+
+// Expense returns the expense with id.
+func (t *Tracker) Expense(id int) (Expense, error) {
+	expenses, err := t.Expenses()
+	if err != nil {
+		return Expense{}, err
+	}
+
+	i := expense.Index(expenses, id)
+	if i < 0 {
+		return Expense{}, fmt.Errorf("expense with ID %d %w", id, ErrNotFound)
+	}
+	return expenses[i], nil
+}
+
+// DeleteExpense deletes an expense.
+func (t *Tracker) DeleteExpense(id int) error {
+	expenses, err := t.Expenses()
+	if err != nil {
+		return err
+	}
+
+	i := expense.Index(expenses, id)
+	if i < 0 {
+		return fmt.Errorf("expense with ID %d %w", id, ErrNotFound)
+	}
+	return t.SaveExpenses(append(expenses[:i], expenses[i+1:]...))
+}
+
+// SetExpenseFields sets the named fields of an expense to their values,
+// removing those set to an empty value.
+func (t *Tracker) SetExpenseFields(id int, fields map[string]string) (Expense, error) {
+	expenses, err := t.Expenses()
+	if err != nil {
+		return Expense{}, err
+	}
+	i := expense.Index(expenses, id)
+	if i < 0 {
+		return Expense{}, fmt.Errorf("expense with ID %d %w", id, ErrNotFound)
+	}
+	expenses[i].Fields = mergeFields(expenses[i].Fields, fields)
+	if err := t.SaveExpenses(expenses); err != nil {
+		return Expense{}, err
+	}
+	return expenses[i], nil
+}
+
+// hasFields reports whether fields has each of the values of want, in any
+// case.
+func hasFields(fields, want map[string]string) bool {
+	for name, value := range want {
+		if !strings.EqualFold(fields[name], value) {
+			return false
+		}
+	}
+	return true
+}
+
+// ListExpenses returns the expenses matching filter and their total.
+func (t *Tracker) ListExpenses(filter ExpenseFilter) (ExpenseList, error) {
+	expenses, err := t.Expenses()
+	if err != nil {
+		return ExpenseList{}, err
+	}
+
+	list := ExpenseList{Totals: map[string]float64{}}
+	for _, e := range expenses {
+		if filter.Category != "" && e.Category != filter.Category {
+			continue
+		}
+		if filter.Task != "" && e.Task != filter.Task {
+			continue
+		}
+		if (filter.Since != nil && e.Date.Before(*filter.Since)) || (filter.Until != nil && e.Date.After(*filter.Until)) {
+			continue
+		}
+		if !hasFields(e.Fields, filter.Fields) {
+			continue
+		}
+		list.Expenses = append(list.Expenses, e)
+		list.Total += e.Amount
+		list.Totals[e.Currency] += e.Amount
+	}
+	return list, nil
+}
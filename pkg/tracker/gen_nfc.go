@@ -0,0 +1,170 @@
+//go:build ignore
+
+// Gen_nfc writes nfc_tables.go, the tables CleanText composes text to NFC
+// with, from UnicodeData.txt and CompositionExclusions.txt of the Unicode
+// Character Database (https://www.unicode.org/Public/UCD/latest/ucd/):
+//
+//	go run gen_nfc.go -ucd <dir with the two files> -version 15.0.0
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	ucd := flag.String("ucd", ".", "`directory` holding UnicodeData.txt and CompositionExclusions.txt")
+	version := flag.String("version", "", "Unicode `version` of the files, noted in the output")
+	flag.Parse()
+
+	classes := map[rune]uint64{}
+	decompositions := map[rune][]rune{}
+	err := eachLine(filepath.Join(*ucd, "UnicodeData.txt"), func(fields []string) error {
+		if len(fields) < 6 {
+			return fmt.Errorf("expected 15 fields, got %d", len(fields))
+		}
+		r, err := parseRune(fields[0])
+		if err != nil {
+			return err
+		}
+		class, err := strconv.ParseUint(fields[3], 10, 8)
+		if err != nil {
+			return err
+		}
+		if class != 0 {
+			classes[r] = class
+		}
+		// Compatibility decompositions, tagged "<font>" and the like, are
+		// not applied by NFC.
+		if d := fields[5]; d != "" && !strings.HasPrefix(d, "<") {
+			for _, code := range strings.Fields(d) {
+				c, err := parseRune(code)
+				if err != nil {
+					return err
+				}
+				decompositions[r] = append(decompositions[r], c)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var exclusions []rune
+	err = eachLine(filepath.Join(*ucd, "CompositionExclusions.txt"), func(fields []string) error {
+		r, err := parseRune(fields[0])
+		exclusions = append(exclusions, r)
+		return err
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by gen_nfc.go from the Unicode Character Database %s. DO NOT EDIT.\n\n", *version)
+	b.WriteString("package tracker\n\n")
+	b.WriteString("// combiningClasses maps the characters whose canonical combining class\n// is not 0 to it.\n")
+	b.WriteString("var combiningClasses = map[rune]uint8{\n")
+	writeEntries(&b, classes, func(c uint64) string { return strconv.FormatUint(c, 10) })
+	b.WriteString("}\n\n")
+	b.WriteString("// decompositions maps characters to their canonical decomposition, which\n// may hold characters that decompose in turn. Hangul syllables are\n// decomposed by rule instead.\n")
+	b.WriteString("var decompositions = map[rune]string{\n")
+	writeEntries(&b, decompositions, func(d []rune) string { return quoteRunes(d) })
+	b.WriteString("}\n\n")
+	b.WriteString("// compositionExclusions lists the characters that NFC leaves decomposed\n// although their decomposition is a pair starting with a starter.\n")
+	b.WriteString("var compositionExclusions = []rune{\n")
+	slices.Sort(exclusions)
+	for i, r := range exclusions {
+		if i%8 == 0 {
+			b.WriteString("\t")
+		}
+		fmt.Fprintf(&b, "0x%04X, ", r)
+		if i%8 == 7 || i == len(exclusions)-1 {
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("}\n")
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("nfc_tables.go", src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// eachLine calls fn with the semicolon-separated fields of each line of a
+// UCD file that is not a comment.
+func eachLine(path string, fn func(fields []string) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		fields := strings.Split(line, ";")
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		if err := fn(fields); err != nil {
+			return fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// parseRune parses a code point written in hex, such as "00C0".
+func parseRune(code string) (rune, error) {
+	r, err := strconv.ParseUint(code, 16, 32)
+	return rune(r), err
+}
+
+// writeEntries writes the entries of m in code point order, four to a line.
+func writeEntries[V any](b *bytes.Buffer, m map[rune]V, value func(V) string) {
+	keys := make([]rune, 0, len(m))
+	for r := range m {
+		keys = append(keys, r)
+	}
+	slices.Sort(keys)
+	for i, r := range keys {
+		if i%4 == 0 {
+			b.WriteString("\t")
+		}
+		fmt.Fprintf(b, "0x%04X: %s, ", r, value(m[r]))
+		if i%4 == 3 || i == len(keys)-1 {
+			b.WriteString("\n")
+		}
+	}
+}
+
+// quoteRunes returns a Go string literal of runes, each escaped.
+func quoteRunes(runes []rune) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range runes {
+		if r > 0xFFFF {
+			fmt.Fprintf(&b, `\U%08X`, r)
+		} else {
+			fmt.Fprintf(&b, `\u%04X`, r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,122 @@
+package tracker
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/internal/expense"
+)
+
+// IncomeFilter selects income in ListIncome. Zero fields match all income.
+type IncomeFilter struct {
+	Source string
+	Since  *time.Time // Only income dated at or after this instant.
+	Until  *time.Time // Only income dated at or before this instant.
+}
+
+// IncomeList is the result of ListIncome.
+type IncomeList struct {
+	Income []Income
+	Totals map[string]float64 // By currency; "" is the base currency.
+}
+
+// Income returns all saved income.
+func (t *Tracker) Income() ([]Income, error) {
+	items, err := t.store.Load(IncomeFile)
+	if err != nil || items == nil {
+		return []Income{}, err
+	}
+
+	var income []Income
+	if err := json.Unmarshal(items, &income); err != nil {
+		return nil, fmt.Errorf("error unmarshalling JSON: %w", err)
+	}
+	return inLocation(income, t.loc), nil
+}
+
+// SaveIncome replaces all saved income.
+func (t *Tracker) SaveIncome(income []Income) error {
+	return t.store.Save(IncomeFile, inLocation(income, time.UTC))
+}
+
+// AddIncome records in, assigning its ID and creation time, and returns
+// the stored record. Its text is cleaned up, and it must pass the
+// validation.
+func (t *Tracker) AddIncome(in Income) (Income, error) {
+	if err := t.cleanIncome(&in); err != nil {
+		return Income{}, err
+	}
+	income, err := t.Income()
+	if err != nil {
+		return Income{}, err
+	}
+
+	in.ID = expense.NextIncomeID(income)
+	in.CreatedAt = t.now()
+	if err := t.SaveIncome(append(income, in)); err != nil {
+		return Income{}, err
+	}
+	return in, nil
+}
+
+// AddIncomes records several incomes at once, assigning their IDs and
+// creation times, and returns the stored records. None are recorded if one
+// fails the validation.
+func (t *Tracker) AddIncomes(added []Income) ([]Income, error) {
+	for i := range added {
+		if err := t.cleanIncome(&added[i]); err != nil {
+			return nil, fmt.Errorf("income %d of %d: %w", i+1, len(added), err)
+		}
+	}
+	income, err := t.Income()
+	if err != nil {
+		return nil, err
+	}
+
+	now := t.now()
+	for i := range added {
+		added[i].ID = expense.NextIncomeID(income)
+		added[i].CreatedAt = now
+		income = append(income, added[i])
+	}
+	if err := t.SaveIncome(income); err != nil {
+		return nil, err
+	}
+	return added, nil
+}
+
+// DeleteIncome deletes income by ID.
+func (t *Tracker) DeleteIncome(id int) error {
+	income, err := t.Income()
+	if err != nil {
+		return err
+	}
+
+	i := expense.IncomeIndex(income, id)
+	if i < 0 {
+		return fmt.Errorf("income with ID %d %w", id, ErrNotFound)
+	}
+	return t.SaveIncome(append(income[:i], income[i+1:]...))
+}
+
+// ListIncome returns the income matching filter and its totals.
+func (t *Tracker) ListIncome(filter IncomeFilter) (IncomeList, error) {
+	income, err := t.Income()
+	if err != nil {
+		return IncomeList{}, err
+	}
+
+	list := IncomeList{Totals: map[string]float64{}}
+	for _, in := range income {
+		if filter.Source != "" && in.Source != filter.Source {
+			continue
+		}
+		if (filter.Since != nil && in.Date.Before(*filter.Since)) || (filter.Until != nil && in.Date.After(*filter.Until)) {
+			continue
+		}
+		list.Income = append(list.Income, in)
+		list.Totals[in.Currency] += in.Amount
+	}
+	return list, nil
+}
@@ -0,0 +1,244 @@
+package tracker
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	// JournalFile holds the changes to tasks.json not yet compacted into
+	// it, one JSON object per line.
+	JournalFile = TasksFile + ".journal"
+
+	// journalMinEntries is the journal length below which it is never
+	// compacted; above it, it is compacted once it has more entries than
+	// there are tasks, so that compaction costs O(1) per change.
+	journalMinEntries = 100
+)
+
+// journalEntry is a line of the journal: a task added or changed, or the
+// UUID of a task deleted.
+type journalEntry struct {
+	Put    json.RawMessage `json:"put,omitempty"`
+	Delete string          `json:"delete,omitempty"`
+}
+
+// journalState is shared by a tracker and those returned by At.
+type journalState struct {
+	enabled bool
+	loaded  map[string]*taskSnapshot // By the path of the tasks file.
+}
+
+// taskSnapshot is the tasks as last loaded or saved, and the sizes of the
+// files they came from, which tell whether the files have been written
+// since.
+type taskSnapshot struct {
+	records  map[string]string // Compact JSON by UUID.
+	order    []string          // UUIDs in file order.
+	entries  int               // Lines in the journal.
+	taskSize int64
+	jrnlSize int64
+}
+
+// loadTasks reads the tasks file and replays the journal on top of it.
+// With the journal enabled, it remembers what it read so that the next
+// save only appends what changed.
+func (t *Tracker) loadTasks() ([]Task, error) {
+	items, err := t.store.Load(TasksFile)
+	if err != nil {
+		return nil, err
+	}
+	var raws []json.RawMessage
+	if items != nil {
+		if err := json.Unmarshal(items, &raws); err != nil {
+			return nil, fmt.Errorf("error unmarshalling JSON: %w", err)
+		}
+	}
+	journal, err := t.store.Read(JournalFile)
+	if err != nil {
+		return nil, err
+	}
+	if !t.journal.enabled && len(journal) == 0 {
+		tasks := []Task{}
+		if items != nil {
+			if err := json.Unmarshal(items, &tasks); err != nil {
+				return nil, fmt.Errorf("error unmarshalling JSON: %w", err)
+			}
+		}
+		return inLocation(tasks, t.loc), nil
+	}
+
+	tasks := make([]Task, len(raws))
+	index := make(map[string]int, len(raws))
+	snap := &taskSnapshot{records: make(map[string]string, len(raws))}
+	for i, raw := range raws {
+		if err := json.Unmarshal(raw, &tasks[i]); err != nil {
+			return nil, fmt.Errorf("error unmarshalling JSON: %w", err)
+		}
+		var compact bytes.Buffer
+		json.Compact(&compact, raw)
+		index[tasks[i].UUID] = i
+		snap.records[tasks[i].UUID] = compact.String()
+	}
+
+	deleted := map[int]bool{}
+	scanner := bufio.NewScanner(bytes.NewReader(journal))
+	scanner.Buffer(nil, 16<<20)
+	for line := 1; scanner.Scan(); line++ {
+		var entry journalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("error reading %s line %d: %w", t.store.Path(JournalFile), line, err)
+		}
+		snap.entries++
+		if entry.Delete != "" {
+			if i, ok := index[entry.Delete]; ok {
+				deleted[i] = true
+				delete(index, entry.Delete)
+				delete(snap.records, entry.Delete)
+			}
+			continue
+		}
+		var tk Task
+		if err := json.Unmarshal(entry.Put, &tk); err != nil {
+			return nil, fmt.Errorf("error reading %s line %d: %w", t.store.Path(JournalFile), line, err)
+		}
+		if i, ok := index[tk.UUID]; ok {
+			tasks[i] = tk
+		} else {
+			index[tk.UUID] = len(tasks)
+			tasks = append(tasks, tk)
+		}
+		snap.records[tk.UUID] = string(entry.Put)
+	}
+	if len(deleted) > 0 {
+		kept := tasks[:0]
+		for i, tk := range tasks {
+			if !deleted[i] {
+				kept = append(kept, tk)
+			}
+		}
+		tasks = kept
+	}
+
+	if t.journal.enabled && !t.store.Encrypted(TasksFile) {
+		for _, tk := range tasks {
+			snap.order = append(snap.order, tk.UUID)
+		}
+		snap.taskSize, snap.jrnlSize = t.store.Size(TasksFile), t.store.Size(JournalFile)
+		t.journal.loaded[t.store.Path(TasksFile)] = snap
+	}
+	return inLocation(tasks, t.loc), nil
+}
+
+// saveTasks writes tasks. With the journal enabled, and the files as they
+// were last loaded, only the tasks added, changed or deleted since are
+// appended to the journal; otherwise, or when the journal is due to be
+// compacted, tasks.json is rewritten and the journal removed. Encrypted
+// task files are always rewritten, as loading them takes no snapshot.
+// Times are saved in UTC.
+func (t *Tracker) saveTasks(tasks []Task) error {
+	tasks = inLocation(tasks, time.UTC)
+	path := t.store.Path(TasksFile)
+	snap := t.journal.loaded[path]
+	delete(t.journal.loaded, path)
+	if !t.journal.enabled || snap == nil || snap.taskSize != t.store.Size(TasksFile) || snap.jrnlSize != t.store.Size(JournalFile) {
+		return t.rewriteTasks(tasks)
+	}
+
+	var lines bytes.Buffer
+	entries := 0
+	records := make(map[string]string, len(tasks))
+	order := make([]string, len(tasks))
+	for i, tk := range tasks {
+		data, err := json.Marshal(tk)
+		if err != nil {
+			return fmt.Errorf("error marshalling JSON: %w", err)
+		}
+		records[tk.UUID], order[i] = string(data), tk.UUID
+		if snap.records[tk.UUID] != string(data) {
+			line, _ := json.Marshal(journalEntry{Put: data})
+			lines.Write(append(line, '\n'))
+			entries++
+		}
+	}
+	for _, uuid := range snap.order {
+		if _, ok := records[uuid]; !ok {
+			line, _ := json.Marshal(journalEntry{Delete: uuid})
+			lines.Write(append(line, '\n'))
+			entries++
+		}
+	}
+	if !replaysInOrder(snap, order, records) || snap.entries+entries > max(journalMinEntries, len(tasks)) {
+		return t.rewriteTasks(tasks)
+	}
+	if entries == 0 {
+		t.journal.loaded[path] = snap
+		return nil
+	}
+
+	if err := t.store.Append(JournalFile, lines.Bytes()); err != nil {
+		return err
+	}
+	snap.records, snap.order, snap.entries = records, order, snap.entries+entries
+	snap.jrnlSize = t.store.Size(JournalFile)
+	t.journal.loaded[path] = snap
+	return nil
+}
+
+// replaysInOrder reports whether replaying the journal keeps tasks in
+// order: the tasks kept must stay in their order, and new ones, which
+// replaying appends, must come after them.
+func replaysInOrder(snap *taskSnapshot, order []string, records map[string]string) bool {
+	i := 0
+	for _, uuid := range snap.order {
+		if _, ok := records[uuid]; !ok {
+			continue
+		}
+		if i >= len(order) || order[i] != uuid {
+			return false
+		}
+		i++
+	}
+	return true
+}
+
+// rewriteTasks writes all tasks to tasks.json and removes the journal.
+func (t *Tracker) rewriteTasks(tasks []Task) error {
+	if err := t.store.Save(TasksFile, tasks); err != nil {
+		return err
+	}
+	if t.store.Size(JournalFile) >= 0 {
+		if err := t.store.Remove(JournalFile); err != nil {
+			return err
+		}
+	}
+	if t.journal.enabled && !t.store.Encrypted(TasksFile) {
+		snap := &taskSnapshot{records: make(map[string]string, len(tasks))}
+		for _, tk := range tasks {
+			data, err := json.Marshal(tk)
+			if err != nil {
+				return fmt.Errorf("error marshalling JSON: %w", err)
+			}
+			snap.records[tk.UUID] = string(data)
+			snap.order = append(snap.order, tk.UUID)
+		}
+		snap.taskSize, snap.jrnlSize = t.store.Size(TasksFile), -1
+		t.journal.loaded[t.store.Path(TasksFile)] = snap
+	}
+	return nil
+}
+
+// CompactJournal folds the journal into tasks.json.
+func (t *Tracker) CompactJournal() error {
+	if t.store.Size(JournalFile) < 0 {
+		return nil
+	}
+	tasks, err := t.loadTasks()
+	if err != nil {
+		return err
+	}
+	return t.rewriteTasks(inLocation(tasks, time.UTC))
+}
@@ -0,0 +1,14 @@
+package tracker_test
+
+import (
+	"testing"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker/storetest"
+)
+
+func TestMemFS(t *testing.T) {
+	storetest.TestFS(t, func(t *testing.T) (tracker.FS, string) {
+		return tracker.NewMemFS(), "data"
+	})
+}
@@ -0,0 +1,134 @@
+package tracker
+
+//go:generate go run gen_nfc.go -ucd ucd -version 14.0.0
+
+import (
+	"slices"
+	"unicode/utf8"
+)
+
+// Hangul syllables are composed and decomposed by rule, not from the tables.
+const (
+	hangulBase   = 0xAC00 // The first syllable.
+	hangulLBase  = 0x1100 // The first leading consonant.
+	hangulVBase  = 0x1161 // The first vowel.
+	hangulTBase  = 0x11A7 // One before the first trailing consonant.
+	hangulLCount = 19
+	hangulVCount = 21
+	hangulTCount = 28
+	hangulNCount = hangulVCount * hangulTCount
+	hangulSCount = hangulLCount * hangulNCount
+)
+
+// compositions maps the pairs of characters NFC composes to the character
+// they become: every canonical decomposition into a starter and one more
+// character, but for the composition exclusions.
+var compositions = func() map[[2]rune]rune {
+	excluded := map[rune]bool{}
+	for _, r := range compositionExclusions {
+		excluded[r] = true
+	}
+	m := map[[2]rune]rune{}
+	for r, d := range decompositions {
+		pair := []rune(d)
+		if len(pair) != 2 || excluded[r] || combiningClasses[r] != 0 || combiningClasses[pair[0]] != 0 {
+			continue
+		}
+		m[[2]rune{pair[0], pair[1]}] = r
+	}
+	return m
+}()
+
+// nfc returns s in Unicode Normalization Form C: decomposed, with the
+// combining marks of each character in their canonical order, and composed
+// again wherever a single character stands for a sequence.
+func nfc(s string) string {
+	ascii := true
+	for i := 0; i < len(s) && ascii; i++ {
+		ascii = s[i] < utf8.RuneSelf
+	}
+	if ascii {
+		return s
+	}
+
+	var runes []rune
+	for _, r := range s {
+		runes = decompose(runes, r)
+	}
+	sortMarks(runes)
+	return string(compose(runes))
+}
+
+// decompose appends the full canonical decomposition of r to runes.
+func decompose(runes []rune, r rune) []rune {
+	if s := r - hangulBase; s >= 0 && s < hangulSCount {
+		runes = append(runes, hangulLBase+s/hangulNCount, hangulVBase+s%hangulNCount/hangulTCount)
+		if t := s % hangulTCount; t != 0 {
+			runes = append(runes, hangulTBase+t)
+		}
+		return runes
+	}
+	d, ok := decompositions[r]
+	if !ok {
+		return append(runes, r)
+	}
+	for _, c := range d {
+		runes = decompose(runes, c)
+	}
+	return runes
+}
+
+// sortMarks puts each run of combining marks in the order of their
+// combining classes, keeping marks of the same class as they were.
+func sortMarks(runes []rune) {
+	for start := 0; start < len(runes); {
+		if combiningClasses[runes[start]] == 0 {
+			start++
+			continue
+		}
+		end := start
+		for end < len(runes) && combiningClasses[runes[end]] != 0 {
+			end++
+		}
+		slices.SortStableFunc(runes[start:end], func(a, b rune) int {
+			return int(combiningClasses[a]) - int(combiningClasses[b])
+		})
+		start = end
+	}
+}
+
+// compose composes the decomposed runes in place: each character is
+// combined with the last starter before it, unless a character between them
+// blocks it by having a combining class of 0 or of at least its own.
+func compose(runes []rune) []rune {
+	out := runes[:0]
+	starter := -1
+	var last uint8 // The combining class of the last character kept after the starter.
+	for _, r := range runes {
+		class := combiningClasses[r]
+		if starter >= 0 && (starter == len(out)-1 || last != 0 && last < class) {
+			if c, ok := composePair(out[starter], r); ok {
+				out[starter] = c
+				continue
+			}
+		}
+		if class == 0 {
+			starter = len(out)
+		}
+		last = class
+		out = append(out, r)
+	}
+	return out
+}
+
+// composePair returns the character a and b compose to, if any.
+func composePair(a, b rune) (rune, bool) {
+	if l, v := a-hangulLBase, b-hangulVBase; l >= 0 && l < hangulLCount && v >= 0 && v < hangulVCount {
+		return hangulBase + (l*hangulVCount+v)*hangulTCount, true
+	}
+	if s, t := a-hangulBase, b-hangulTBase; s >= 0 && s < hangulSCount && s%hangulTCount == 0 && t > 0 && t < hangulTCount {
+		return a + t, true
+	}
+	c, ok := compositions[[2]rune{a, b}]
+	return c, ok
+}
@@ -0,0 +1,109 @@
+package tracker
+
+import (
+	"slices"
+	"time"
+)
+
+// SlotStep is the granularity start times are proposed at.
+const SlotStep = 15 * time.Minute
+
+// Interval is a busy block of time.
+type Interval struct {
+	Start, End time.Time
+}
+
+// WorkHours are the hours of the day, and the days of the week, that work
+// can be scheduled in. Start and End are offsets from midnight.
+type WorkHours struct {
+	Start, End time.Duration
+	Days       []time.Weekday
+}
+
+// DefaultWorkHours are 9:00 to 17:00, Monday to Friday.
+func DefaultWorkHours() WorkHours {
+	return WorkHours{
+		Start: 9 * time.Hour, End: 17 * time.Hour,
+		Days: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	}
+}
+
+// SetScheduled sets when work on a task is planned to start; nil clears it.
+func (t *Tracker) SetScheduled(id int, scheduled *time.Time) (Task, error) {
+	return t.updateTask(id, func(tk *Task) error {
+		tk.Scheduled = scheduled
+		tk.UpdatedAt = t.now()
+		return nil
+	})
+}
+
+// BusyTasks returns the time blocked by the scheduled tasks that are not
+// done, other than the one with skipID: from each task's scheduled start
+// for its estimate, or for an hour without one.
+func BusyTasks(tasks []Task, w Workflow, skipID int) []Interval {
+	var busy []Interval
+	for _, tk := range tasks {
+		if tk.Scheduled == nil || tk.ID == skipID || w.IsDone(tk.Status) {
+			continue
+		}
+		busy = append(busy, Interval{Start: *tk.Scheduled, End: tk.Scheduled.Add(TaskLength(tk))})
+	}
+	return busy
+}
+
+// TaskLength returns how long a task is expected to take: its estimate, or
+// an hour without one.
+func TaskLength(tk Task) time.Duration {
+	if tk.Estimate <= 0 {
+		return time.Hour
+	}
+	return time.Duration(tk.Estimate * float64(time.Hour)).Round(time.Minute)
+}
+
+// NextFreeSlot returns the earliest start at or after from, on a step of
+// SlotStep, at which length fits in the work hours of a day without
+// overlapping busy, looking no further than until. Work longer than a day
+// only needs to start at the beginning of a free day.
+func NextFreeSlot(from time.Time, length time.Duration, busy []Interval, hours WorkHours, until time.Time) (time.Time, bool) {
+	busy = slices.Clone(busy)
+	slices.SortFunc(busy, func(a, b Interval) int { return a.Start.Compare(b.Start) })
+	length = min(length, hours.End-hours.Start)
+
+	for day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location()); day.Before(until); day = day.AddDate(0, 0, 1) {
+		if !slices.Contains(hours.Days, day.Weekday()) {
+			continue
+		}
+		start, end := day.Add(hours.Start), day.Add(hours.End)
+		candidate := roundUp(later(start, from), day)
+		for _, b := range busy {
+			if !b.End.After(candidate) || !b.Start.Before(end) {
+				continue
+			}
+			if !b.Start.Before(candidate.Add(length)) {
+				break
+			}
+			candidate = roundUp(b.End, day)
+		}
+		if !candidate.Add(length).After(end) && candidate.Before(until) {
+			return candidate, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// roundUp rounds t up to the next SlotStep since midnight of day.
+func roundUp(t, day time.Time) time.Time {
+	offset := t.Sub(day)
+	if rem := offset % SlotStep; rem != 0 {
+		offset += SlotStep - rem
+	}
+	return day.Add(offset)
+}
+
+// later returns the later of two times.
+func later(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
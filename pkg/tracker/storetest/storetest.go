@@ -0,0 +1,328 @@
+// Package storetest checks that a file system the tracker's data files are
+// kept in behaves as the tracker expects. Run it from a test of the
+// implementation:
+//
+//	func TestMyFS(t *testing.T) {
+//		storetest.TestFS(t, func(t *testing.T) (tracker.FS, string) {
+//			return myfs.Open(t.TempDir()), "data"
+//		})
+//	}
+package storetest
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+const (
+	// Operations run against the model file system by the property check.
+	propertyOps = 2000
+
+	// Goroutines and operations per goroutine of the concurrency checks.
+	workers      = 8
+	workerWrites = 50
+)
+
+// NewFS returns an empty file system and a directory in it the checks may
+// create files in. It is called once for each check.
+type NewFS func(t *testing.T) (fsys tracker.FS, dir string)
+
+// TestFS runs every check against the file systems newFS returns.
+func TestFS(t *testing.T, newFS NewFS) {
+	t.Run("RoundTrip", func(t *testing.T) { testRoundTrip(t, newFS) })
+	t.Run("Missing", func(t *testing.T) { testMissing(t, newFS) })
+	t.Run("Append", func(t *testing.T) { testAppend(t, newFS) })
+	t.Run("Properties", func(t *testing.T) { testProperties(t, newFS) })
+	t.Run("ConcurrentAppends", func(t *testing.T) { testConcurrentAppends(t, newFS) })
+	t.Run("ConcurrentFiles", func(t *testing.T) { testConcurrentFiles(t, newFS) })
+	t.Run("Tracker", func(t *testing.T) { testTracker(t, newFS) })
+}
+
+// testRoundTrip checks that what is written is read back byte for byte,
+// including empty, binary and large contents, and that writing replaces
+// the file rather than adding to it.
+func testRoundTrip(t *testing.T, newFS NewFS) {
+	fsys, dir := newFS(t)
+	name := path.Join(dir, "round.json")
+	binary := make([]byte, 256)
+	for i := range binary {
+		binary[i] = byte(i)
+	}
+	for _, data := range [][]byte{
+		[]byte(`[{"description":"Buy milk ✓"}]`),
+		{},
+		binary,
+		bytes.Repeat([]byte("0123456789abcdef"), 1<<16),
+		[]byte("short"),
+	} {
+		if err := fsys.WriteFile(name, data, 0644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+		got, err := fsys.ReadFile(name)
+		if err != nil {
+			t.Fatalf("ReadFile: %v", err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Fatalf("ReadFile after writing %d bytes returned %d different bytes", len(data), len(got))
+		}
+		if size, err := fsys.Size(name); err != nil || size != int64(len(data)) {
+			t.Fatalf("Size = %d, %v; want %d", size, err, len(data))
+		}
+	}
+
+	// Changing what was written or read must not change the file.
+	data := []byte("original")
+	if err := fsys.WriteFile(name, data, 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	data[0] = 'X'
+	got, _ := fsys.ReadFile(name)
+	got[1] = 'X'
+	if again, _ := fsys.ReadFile(name); string(again) != "original" {
+		t.Fatalf("file changed with the slices passed to or returned by the file system: %q", again)
+	}
+}
+
+// testMissing checks that a missing file is reported as not existing by
+// every method that reads or removes it, as the tracker treats a missing
+// data file as an empty one.
+func testMissing(t *testing.T, newFS NewFS) {
+	fsys, dir := newFS(t)
+	name := path.Join(dir, "missing.json")
+	if _, err := fsys.ReadFile(name); !os.IsNotExist(err) {
+		t.Errorf("ReadFile of a missing file: %v; want an error satisfying os.IsNotExist", err)
+	}
+	if _, err := fsys.Size(name); !os.IsNotExist(err) {
+		t.Errorf("Size of a missing file: %v; want an error satisfying os.IsNotExist", err)
+	}
+	if err := fsys.Remove(name); !os.IsNotExist(err) {
+		t.Errorf("Remove of a missing file: %v; want an error satisfying os.IsNotExist", err)
+	}
+
+	if err := fsys.WriteFile(name, []byte("x"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := fsys.Remove(name); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if _, err := fsys.ReadFile(name); !os.IsNotExist(err) {
+		t.Errorf("ReadFile of a removed file: %v; want an error satisfying os.IsNotExist", err)
+	}
+}
+
+// testAppend checks that appending creates a missing file and keeps what
+// is appended in order.
+func testAppend(t *testing.T, newFS NewFS) {
+	fsys, dir := newFS(t)
+	name := path.Join(dir, "tasks.json.journal")
+	var want []byte
+	for i := range 100 {
+		line := []byte(fmt.Sprintf("{\"put\":%d}\n", i))
+		if err := fsys.AppendFile(name, line, 0644); err != nil {
+			t.Fatalf("AppendFile: %v", err)
+		}
+		want = append(want, line...)
+	}
+	got, err := fsys.ReadFile(name)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("appended lines read back as %q; want %q", firstLines(got), firstLines(want))
+	}
+}
+
+// testProperties runs random writes, appends and removes against the file
+// system and a map standing for one, checking after each that every file
+// reads back as the map says.
+func testProperties(t *testing.T, newFS NewFS) {
+	fsys, dir := newFS(t)
+	seed := uint64(time.Now().UnixNano())
+	rng := rand.New(rand.NewPCG(seed, seed))
+	names := []string{"tasks.json", "expenses.json", "archive.json", "tasks.json.journal"}
+	model := map[string][]byte{}
+
+	for i := range propertyOps {
+		name := path.Join(dir, names[rng.IntN(len(names))])
+		data := make([]byte, rng.IntN(64))
+		for j := range data {
+			data[j] = byte(rng.IntN(256))
+		}
+		var op string
+		var err error
+		switch rng.IntN(3) {
+		case 0:
+			op, err = "WriteFile", fsys.WriteFile(name, data, 0644)
+			model[name] = data
+		case 1:
+			op, err = "AppendFile", fsys.AppendFile(name, data, 0644)
+			model[name] = append(slices.Clone(model[name]), data...)
+		case 2:
+			op, err = "Remove", fsys.Remove(name)
+			if _, ok := model[name]; !ok && os.IsNotExist(err) {
+				err = nil
+			}
+			delete(model, name)
+		}
+		if err != nil {
+			t.Fatalf("operation %d (seed %d): %s %s: %v", i, seed, op, name, err)
+		}
+
+		for _, n := range names {
+			n = path.Join(dir, n)
+			got, err := fsys.ReadFile(n)
+			want, exists := model[n]
+			switch {
+			case !exists && !os.IsNotExist(err):
+				t.Fatalf("operation %d (seed %d, after %s %s): %s should not exist; ReadFile returned %d bytes, %v", i, seed, op, name, n, len(got), err)
+			case exists && err != nil:
+				t.Fatalf("operation %d (seed %d, after %s %s): ReadFile %s: %v", i, seed, op, name, n, err)
+			case exists && !bytes.Equal(got, want):
+				t.Fatalf("operation %d (seed %d, after %s %s): %s has %d bytes; want %d", i, seed, op, name, n, len(got), len(want))
+			}
+		}
+	}
+}
+
+// testConcurrentAppends checks that appends from several goroutines to one
+// file are neither lost nor interleaved.
+func testConcurrentAppends(t *testing.T, newFS NewFS) {
+	fsys, dir := newFS(t)
+	name := path.Join(dir, "tasks.json.journal")
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range workerWrites {
+				line := fmt.Sprintf("{\"worker\":%d,\"write\":%d}\n", w, i)
+				if err := fsys.AppendFile(name, []byte(line), 0644); err != nil {
+					t.Errorf("AppendFile: %v", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	data, err := fsys.ReadFile(name)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != workers*workerWrites {
+		t.Fatalf("%d lines after concurrent appends; want %d", len(lines), workers*workerWrites)
+	}
+	next := make([]int, workers)
+	for _, line := range lines {
+		var w, i int
+		if _, err := fmt.Sscanf(line, "{\"worker\":%d,\"write\":%d}", &w, &i); err != nil || w < 0 || w >= workers {
+			t.Fatalf("interleaved line %q", line)
+		}
+		if i != next[w] {
+			t.Fatalf("worker %d's write %d came before its write %d", w, i, next[w])
+		}
+		next[w]++
+	}
+}
+
+// testConcurrentFiles checks that goroutines writing and reading their own
+// files do not see each other's data.
+func testConcurrentFiles(t *testing.T, newFS NewFS) {
+	fsys, dir := newFS(t)
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			name := path.Join(dir, fmt.Sprintf("worker-%d.json", w))
+			for i := range workerWrites {
+				want := []byte(fmt.Sprintf("[%d,%d]", w, i))
+				if err := fsys.WriteFile(name, want, 0644); err != nil {
+					t.Errorf("WriteFile: %v", err)
+					return
+				}
+				got, err := fsys.ReadFile(name)
+				if err != nil || !bytes.Equal(got, want) {
+					t.Errorf("ReadFile %s = %q, %v; want %q", name, got, err, want)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// testTracker checks that a tracker keeping its files in the file system
+// saves and loads tasks and expenses faithfully, with and without the
+// journal, and that dropped transactions leave nothing behind.
+func testTracker(t *testing.T, newFS NewFS) {
+	for _, journal := range []bool{false, true} {
+		t.Run(fmt.Sprintf("journal=%t", journal), func(t *testing.T) {
+			fsys, dir := newFS(t)
+			now := time.Date(2025, 3, 14, 9, 26, 53, 0, time.UTC)
+			tr := tracker.New(tracker.Options{Dir: dir, FS: fsys, Journal: journal, Now: func() time.Time { return now }})
+
+			descriptions := []string{"Write report", "Pay rent – €950", "Call \"Mum\"", ""}
+			for _, d := range descriptions[:3] {
+				if _, err := tr.AddTask(d); err != nil {
+					t.Fatalf("AddTask: %v", err)
+				}
+			}
+			if _, err := tr.SetStatus(2, tracker.StatusDone); err != nil {
+				t.Fatalf("SetStatus: %v", err)
+			}
+			if err := tr.DeleteTask(1); err != nil {
+				t.Fatalf("DeleteTask: %v", err)
+			}
+			if _, err := tr.AddExpense(tracker.Expense{Amount: 12.5, Description: "Lunch", Date: now}); err != nil {
+				t.Fatalf("AddExpense: %v", err)
+			}
+
+			tr.Begin()
+			if _, err := tr.AddTask("Dropped"); err != nil {
+				t.Fatalf("AddTask: %v", err)
+			}
+			tr.Rollback()
+
+			reopened := tracker.New(tracker.Options{Dir: dir, FS: fsys, Journal: journal})
+			tasks, err := reopened.Tasks()
+			if err != nil {
+				t.Fatalf("Tasks: %v", err)
+			}
+			var got []string
+			for _, task := range tasks {
+				got = append(got, fmt.Sprintf("%d %s %s %s", task.ID, task.Status, task.Description, task.CreatedAt.Format(time.RFC3339)))
+			}
+			want := []string{
+				"2 done Pay rent – €950 2025-03-14T09:26:53Z",
+				"3 todo Call \"Mum\" 2025-03-14T09:26:53Z",
+			}
+			if !slices.Equal(got, want) {
+				t.Fatalf("tasks read back as %q; want %q", got, want)
+			}
+			expenses, err := reopened.Expenses()
+			if err != nil {
+				t.Fatalf("Expenses: %v", err)
+			}
+			if len(expenses) != 1 || expenses[0].Amount != 12.5 || expenses[0].Description != "Lunch" || !expenses[0].Date.Equal(now) {
+				t.Fatalf("expenses read back as %+v", expenses)
+			}
+		})
+	}
+}
+
+// firstLines returns the start of data, for error messages.
+func firstLines(data []byte) []byte {
+	return data[:min(len(data), 200)]
+}
@@ -0,0 +1,106 @@
+package tracker
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/internal/task"
+)
+
+// Conflict is a task changed differently on both sides of a sync. A nil
+// side deleted the task.
+type Conflict struct {
+	UUID   string `json:"uuid"`
+	Local  *Task  `json:"local,omitempty"`
+	Remote *Task  `json:"remote,omitempty"`
+}
+
+// Merge is the outcome of merging two copies of the tasks.
+type Merge struct {
+	Tasks     []Task
+	Conflicts []Conflict
+	Pulled    int // Tasks added, changed or deleted locally by the merge.
+	Pushed    int // Local additions, changes and deletions the remote lacks.
+}
+
+// MergeTasks merges the tasks of two copies that were last in sync at base,
+// matching tasks by UUID. A task changed or deleted on one side only takes
+// that side's change. A task changed differently on both sides is a
+// conflict: the local version is kept, or the remote one if the task was
+// deleted locally, and both are returned for resolving by hand.
+//
+// Local tasks keep their IDs; tasks only the remote has get new ones, so
+// numeric IDs can differ between copies while UUIDs stay the same.
+func MergeTasks(base, local, remote []Task) Merge {
+	baseByUUID := byUUID(base)
+	remoteByUUID := byUUID(remote)
+
+	var m Merge
+	seen := map[string]bool{}
+	for _, l := range local {
+		seen[l.UUID] = true
+		b, inBase := baseByUUID[l.UUID]
+		r, inRemote := remoteByUUID[l.UUID]
+		switch {
+		case !inRemote && !inBase:
+			m.Tasks = append(m.Tasks, l) // Added locally.
+			m.Pushed++
+		case !inRemote && sameTask(l, b):
+			m.Pulled++ // Deleted remotely.
+		case !inRemote:
+			m.Conflicts = append(m.Conflicts, Conflict{UUID: l.UUID, Local: &l})
+			m.Tasks = append(m.Tasks, l)
+		case sameTask(l, r):
+			m.Tasks = append(m.Tasks, l)
+		case inBase && sameTask(l, b):
+			r.ID = l.ID
+			m.Tasks = append(m.Tasks, r)
+			m.Pulled++
+		case inBase && sameTask(r, b):
+			m.Tasks = append(m.Tasks, l)
+			m.Pushed++
+		default:
+			m.Conflicts = append(m.Conflicts, Conflict{UUID: l.UUID, Local: &l, Remote: &r})
+			m.Tasks = append(m.Tasks, l)
+		}
+	}
+
+	for _, r := range remote {
+		if seen[r.UUID] {
+			continue
+		}
+		b, inBase := baseByUUID[r.UUID]
+		switch {
+		case !inBase:
+			m.Pulled++ // Added remotely.
+		case !sameTask(r, b):
+			m.Conflicts = append(m.Conflicts, Conflict{UUID: r.UUID, Remote: &r})
+		default:
+			m.Pushed++ // Deleted locally.
+			continue
+		}
+		r.ID = task.NextID(m.Tasks)
+		m.Tasks = append(m.Tasks, r)
+	}
+	return m
+}
+
+// byUUID indexes tasks by UUID.
+func byUUID(tasks []Task) map[string]Task {
+	m := make(map[string]Task, len(tasks))
+	for _, t := range tasks {
+		m[t.UUID] = t
+	}
+	return m
+}
+
+// sameTask reports whether two tasks are equal but for their numeric IDs.
+// They are compared as JSON in UTC, since times read on machines in
+// different time zones differ in location but not in the instant saved.
+func sameTask(a, b Task) bool {
+	a.ID, b.ID = 0, 0
+	ja, errA := json.Marshal(a.InLocation(time.UTC))
+	jb, errB := json.Marshal(b.InLocation(time.UTC))
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
@@ -0,0 +1,412 @@
+package tracker
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/internal/task"
+)
+
+// TaskFilter selects tasks in ListTasks. Zero fields match every task.
+type TaskFilter struct {
+	Status    string
+	Tag       string     // Only tasks carrying this tag.
+	Priority  string     // Only tasks with this priority.
+	Assignee  string     // Only tasks assigned to this person, in any case.
+	Archived  bool       // List archived tasks instead of the task list.
+	DueAfter  *time.Time // Only tasks due at or after this instant.
+	DueBefore *time.Time // Only tasks due at or before this instant.
+
+	CompletedAfter  *time.Time // Only tasks completed at or after this instant.
+	CompletedBefore *time.Time // Only tasks completed at or before this instant.
+
+	Snoozed     bool // Only tasks snoozed until later, which are otherwise left out.
+	WithSnoozed bool // Tasks snoozed until later as well as the others.
+
+	Scheduled     bool // Only tasks scheduled to start on a later day, which are otherwise left out.
+	WithScheduled bool // Tasks scheduled to start later as well as the others.
+
+	Match func(Task) bool // If set, only tasks it accepts.
+}
+
+// StatusChange describes the outcome of SetStatus.
+type StatusChange struct {
+	Task      Task   // The task as saved.
+	Previous  string // The status before the change.
+	Completed bool   // The task moved into a done status.
+	Recurred  bool   // The task was rolled over to its next occurrence.
+}
+
+// Tasks returns all saved tasks.
+func (t *Tracker) Tasks() ([]Task, error) {
+	return t.loadTasks()
+}
+
+// SaveTasks replaces all saved tasks, after Options.ReviewTasks if set.
+// Tasks without a UUID are given one.
+func (t *Tracker) SaveTasks(tasks []Task) error {
+	for i := range tasks {
+		if tasks[i].UUID == "" {
+			tasks[i].UUID = task.NewUUID()
+		}
+	}
+	if t.review != nil {
+		saved, err := t.Tasks()
+		if err != nil {
+			return err
+		}
+		if tasks, err = t.review(saved, tasks); err != nil {
+			return err
+		}
+	}
+	return t.saveTasks(tasks)
+}
+
+// NextTaskID returns the ID a new task appended to tasks should get.
+func NextTaskID(tasks []Task) int {
+	return task.NextID(tasks)
+}
+
+// NewUUID returns a new task UUID.
+func NewUUID() string {
+	return task.NewUUID()
+}
+
+// AddTask adds a new task in the workflow's initial status. The
+// description is cleaned up and must pass the validation.
+func (t *Tracker) AddTask(description string) (Task, error) {
+	return t.AddTaskFrom(Task{Description: description})
+}
+
+// AddTaskFrom adds tk, with its other fields as set, as a new task in the
+// workflow's initial status, assigning its ID, UUID and times. Its
+// description is cleaned up and must pass the validation, as for AddTask.
+func (t *Tracker) AddTaskFrom(tk Task) (Task, error) {
+	tk.Description = CleanText(tk.Description)
+	if err := t.checkDescription(tk.Description); err != nil {
+		return Task{}, err
+	}
+	tasks, err := t.Tasks()
+	if err != nil {
+		return Task{}, err
+	}
+
+	now := t.now()
+	tk.ID, tk.UUID = task.NextID(tasks), task.NewUUID()
+	tk.Status = t.workflow.Initial()
+	tk.CreatedAt, tk.UpdatedAt = now, now
+
+	if err := t.SaveTasks(append(tasks, tk)); err != nil {
+		return Task{}, err
+	}
+	return tk, nil
+}
+
+// Task returns the task with id.
+func (t *Tracker) Task(id int) (Task, error) {
+	tasks, err := t.Tasks()
+	if err != nil {
+		return Task{}, err
+	}
+
+	i := task.Index(tasks, id)
+	if i < 0 {
+		return Task{}, fmt.Errorf("task with ID %d %w", id, ErrNotFound)
+	}
+	return tasks[i], nil
+}
+
+// updateTask applies change to the task with id and saves it.
+func (t *Tracker) updateTask(id int, change func(*Task) error) (Task, error) {
+	tasks, err := t.Tasks()
+	if err != nil {
+		return Task{}, err
+	}
+
+	i := task.Index(tasks, id)
+	if i < 0 {
+		return Task{}, fmt.Errorf("task with ID %d %w", id, ErrNotFound)
+	}
+	if err := change(&tasks[i]); err != nil {
+		return Task{}, err
+	}
+	if err := t.SaveTasks(tasks); err != nil {
+		return Task{}, err
+	}
+	return tasks[i], nil
+}
+
+// UpdateTask changes the description of a task, as AddTask sets it.
+func (t *Tracker) UpdateTask(id int, description string) (Task, error) {
+	description = CleanText(description)
+	if err := t.checkDescription(description); err != nil {
+		return Task{}, err
+	}
+	return t.updateTask(id, func(tk *Task) error {
+		tk.Description = description
+		tk.UpdatedAt = t.now()
+		return nil
+	})
+}
+
+// DeleteTask deletes a task.
+func (t *Tracker) DeleteTask(id int) error {
+	tasks, err := t.Tasks()
+	if err != nil {
+		return err
+	}
+
+	i := task.Index(tasks, id)
+	if i < 0 {
+		return fmt.Errorf("task with ID %d %w", id, ErrNotFound)
+	}
+	uuid := tasks[i].UUID
+	tasks = append(tasks[:i], tasks[i+1:]...)
+
+	// No task is left waiting on one that is gone
+	for j := range tasks {
+		if slices.Contains(tasks[j].WaitsOn, uuid) {
+			tasks[j].WaitsOn = slices.DeleteFunc(slices.Clone(tasks[j].WaitsOn), func(u string) bool { return u == uuid })
+			if len(tasks[j].WaitsOn) == 0 {
+				tasks[j].WaitsOn = nil
+			}
+		}
+	}
+	return t.SaveTasks(tasks)
+}
+
+// SetStatus moves a task to status if the workflow allows it. Completing a
+// recurring task rolls it over to its next occurrence.
+func (t *Tracker) SetStatus(id int, status string) (StatusChange, error) {
+	var change StatusChange
+	saved, err := t.updateTask(id, func(tk *Task) error {
+		if err := t.workflow.CanTransition(tk.Status, status); err != nil {
+			return Invalid(err)
+		}
+		change.Previous = tk.Status
+		change.Completed = t.workflow.IsDone(status) && !t.workflow.IsDone(tk.Status)
+		tk.SetStatus(status, t.now(), t.workflow)
+		if t.workflow.IsDone(status) {
+			change.Recurred = tk.CompleteOccurrence(t.workflow)
+		}
+		return nil
+	})
+	if err != nil {
+		return StatusChange{}, err
+	}
+	change.Task = saved
+	return change, nil
+}
+
+// SetDue sets a task's due date, counting it as a postponement when an
+// existing due date is pushed back.
+func (t *Tracker) SetDue(id int, due time.Time) (Task, error) {
+	return t.updateTask(id, func(tk *Task) error {
+		if tk.Due != nil && due.After(*tk.Due) {
+			tk.Postponed++
+		}
+		tk.Due = &due
+		tk.UpdatedAt = t.now()
+		return nil
+	})
+}
+
+// Snooze leaves a task out of ListTasks until the given time; nil wakes it.
+func (t *Tracker) Snooze(id int, until *time.Time) (Task, error) {
+	return t.updateTask(id, func(tk *Task) error {
+		tk.Wake = until
+		tk.UpdatedAt = t.now()
+		return nil
+	})
+}
+
+// SetDependencies sets the tasks that must be done before a task, by ID;
+// none clears them. They are kept by UUID, so they stay the same tasks
+// whatever IDs they are given later. A task cannot wait on itself, on a
+// task that does not exist or on a task that waits on it.
+func (t *Tracker) SetDependencies(id int, on []int) (Task, error) {
+	tasks, err := t.Tasks()
+	if err != nil {
+		return Task{}, err
+	}
+	i := task.Index(tasks, id)
+	if i < 0 {
+		return Task{}, fmt.Errorf("task with ID %d %w", id, ErrNotFound)
+	}
+	waitsOn := func(from, to string) bool {
+		seen := map[string]bool{}
+		next := []string{from}
+		for len(next) > 0 {
+			n := next[len(next)-1]
+			next = next[:len(next)-1]
+			if n == to {
+				return true
+			}
+			if j := task.IndexUUID(tasks, n); j >= 0 && !seen[n] {
+				seen[n] = true
+				next = append(next, tasks[j].WaitsOn...)
+			}
+		}
+		return false
+	}
+	var uuids []string
+	for _, other := range slices.Compact(slices.Sorted(slices.Values(on))) {
+		j := task.Index(tasks, other)
+		switch {
+		case other == id:
+			return Task{}, Invalid(fmt.Errorf("task %d cannot wait on itself", id))
+		case j < 0:
+			return Task{}, fmt.Errorf("task with ID %d %w", other, ErrNotFound)
+		case waitsOn(tasks[j].UUID, tasks[i].UUID):
+			return Task{}, Invalid(fmt.Errorf("task %d already waits on task %d", other, id))
+		}
+		uuids = append(uuids, tasks[j].UUID)
+	}
+	return t.updateTask(id, func(tk *Task) error {
+		tk.WaitsOn = uuids
+		tk.UpdatedAt = t.now()
+		return nil
+	})
+}
+
+// WaitsOn returns the IDs of the tasks in the task list that tk waits on.
+// Those since archived are left out, having been done.
+func (t *Tracker) WaitsOn(tk Task) ([]int, error) {
+	if len(tk.WaitsOn) == 0 {
+		return nil, nil
+	}
+	tasks, err := t.Tasks()
+	if err != nil {
+		return nil, err
+	}
+	var ids []int
+	for _, uuid := range tk.WaitsOn {
+		if i := task.IndexUUID(tasks, uuid); i >= 0 {
+			ids = append(ids, tasks[i].ID)
+		}
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
+
+// SetFields sets the named fields of a task to their values, removing
+// those set to an empty value.
+func (t *Tracker) SetFields(id int, fields map[string]string) (Task, error) {
+	return t.updateTask(id, func(tk *Task) error {
+		tk.Fields = mergeFields(tk.Fields, fields)
+		tk.UpdatedAt = t.now()
+		return nil
+	})
+}
+
+// mergeFields returns fields with changes applied: an empty value removes
+// the field, and nil stands for no fields.
+func mergeFields(fields, changes map[string]string) map[string]string {
+	merged := maps.Clone(fields)
+	for name, value := range changes {
+		if value == "" {
+			delete(merged, name)
+			continue
+		}
+		if merged == nil {
+			merged = map[string]string{}
+		}
+		merged[name] = value
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+	return merged
+}
+
+// SetPriority sets a task's priority.
+func (t *Tracker) SetPriority(id int, priority string) (Task, error) {
+	if !task.IsValidPriority(priority) {
+		return Task{}, Invalid(fmt.Errorf("invalid priority '%s'", priority))
+	}
+	return t.updateTask(id, func(tk *Task) error {
+		tk.Priority = priority
+		tk.UpdatedAt = t.now()
+		return nil
+	})
+}
+
+// SetAssignee sets who a task is assigned to; an empty assignee unassigns it.
+func (t *Tracker) SetAssignee(id int, assignee string) (Task, error) {
+	return t.updateTask(id, func(tk *Task) error {
+		tk.Assignee = assignee
+		tk.UpdatedAt = t.now()
+		return nil
+	})
+}
+
+// SetEstimate sets the estimated hours of work left on a task; zero clears it.
+func (t *Tracker) SetEstimate(id int, hours float64) (Task, error) {
+	if hours < 0 {
+		return Task{}, Invalid(fmt.Errorf("invalid estimate %g", hours))
+	}
+	return t.updateTask(id, func(tk *Task) error {
+		tk.Estimate = hours
+		tk.UpdatedAt = t.now()
+		return nil
+	})
+}
+
+// ListTasks returns the tasks matching filter. A due date range excludes
+// tasks without a due date. Tasks snoozed until later, or scheduled to
+// start on a later day, are left out unless the filter asks for them.
+func (t *Tracker) ListTasks(filter TaskFilter) ([]Task, error) {
+	list := t.Tasks
+	if filter.Archived {
+		list = t.ArchivedTasks
+	}
+	tasks, err := list()
+	if err != nil {
+		return nil, err
+	}
+
+	now := t.now()
+	var matched []Task
+	for _, tk := range tasks {
+		if !filter.Archived && !filter.WithSnoozed && filter.Snoozed != tk.Snoozed(now) {
+			continue
+		}
+		if !filter.Archived && !filter.WithScheduled && filter.Scheduled != tk.ScheduledLater(now) {
+			continue
+		}
+		if filter.Status != "" && tk.Status != filter.Status {
+			continue
+		}
+		if filter.Tag != "" && !slices.Contains(tk.Tags, filter.Tag) {
+			continue
+		}
+		if filter.Priority != "" && tk.Priority != filter.Priority {
+			continue
+		}
+		if filter.Assignee != "" && !strings.EqualFold(tk.Assignee, filter.Assignee) {
+			continue
+		}
+		if filter.DueAfter != nil || filter.DueBefore != nil {
+			if tk.Due == nil ||
+				(filter.DueAfter != nil && tk.Due.Before(*filter.DueAfter)) ||
+				(filter.DueBefore != nil && tk.Due.After(*filter.DueBefore)) {
+				continue
+			}
+		}
+		if filter.CompletedAfter != nil || filter.CompletedBefore != nil {
+			if tk.CompletedAt == nil ||
+				(filter.CompletedAfter != nil && tk.CompletedAt.Before(*filter.CompletedAfter)) ||
+				(filter.CompletedBefore != nil && tk.CompletedAt.After(*filter.CompletedBefore)) {
+				continue
+			}
+		}
+		if filter.Match != nil && !filter.Match(tk) {
+			continue
+		}
+		matched = append(matched, tk)
+	}
+	return matched, nil
+}
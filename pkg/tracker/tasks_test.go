@@ -0,0 +1,62 @@
+package tracker_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+func TestDependencies(t *testing.T) {
+	tr := tracker.New(tracker.Options{Dir: "data", FS: tracker.NewMemFS()})
+	for _, d := range []string{"Buy paint", "Paint the fence", "Sand the fence"} {
+		if _, err := tr.AddTask(d); err != nil {
+			t.Fatalf("AddTask: %v", err)
+		}
+	}
+	if _, err := tr.SetDependencies(2, []int{3, 1}); err != nil {
+		t.Fatalf("SetDependencies: %v", err)
+	}
+	if _, err := tr.SetDependencies(1, []int{2}); err == nil {
+		t.Error("SetDependencies allowed a cycle")
+	}
+
+	// Deleting task 3 frees its ID; the task added next takes it, and must
+	// not be waited on in its place.
+	if err := tr.DeleteTask(3); err != nil {
+		t.Fatalf("DeleteTask: %v", err)
+	}
+	if _, err := tr.AddTask("Call the neighbours"); err != nil {
+		t.Fatalf("AddTask: %v", err)
+	}
+	task, err := tr.Task(2)
+	if err != nil {
+		t.Fatalf("Task: %v", err)
+	}
+	if len(task.WaitsOn) != 1 {
+		t.Errorf("task 2 waits on %q, want only task 1", task.WaitsOn)
+	}
+	ids, err := tr.WaitsOn(task)
+	if err != nil || !slices.Equal(ids, []int{1}) {
+		t.Errorf("WaitsOn = %v, %v; want [1]", ids, err)
+	}
+}
+
+func TestWaitsOnMigration(t *testing.T) {
+	fsys := tracker.NewMemFS()
+	old := `{"schemaVersion": 4, "items": [
+  {"id": 1, "uuid": "a", "description": "Buy paint", "status": "todo"},
+  {"id": 2, "uuid": "b", "description": "Paint the fence", "status": "todo", "dependsOn": [1, 7]}
+]}`
+	if err := fsys.WriteFile("data/"+tracker.TasksFile, []byte(old), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tr := tracker.New(tracker.Options{Dir: "data", FS: fsys})
+	task, err := tr.Task(2)
+	if err != nil {
+		t.Fatalf("Task: %v", err)
+	}
+	if !slices.Equal(task.WaitsOn, []string{"a"}) {
+		t.Errorf("task 2 waits on %q after the upgrade, want [a]", task.WaitsOn)
+	}
+}
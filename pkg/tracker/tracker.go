@@ -0,0 +1,411 @@
+// Package tracker is the embeddable core of the task and expense tracker.
+// A Tracker manages the data files in one directory; its methods return
+// typed results and errors and never print, so other Go programs can build
+// on it the same way the task CLI does.
+//
+//	t := tracker.New(tracker.Options{Dir: "."})
+//	added, err := t.AddTask("Write report")
+//	...
+//	tasks, err := t.ListTasks(tracker.TaskFilter{Status: tracker.StatusTodo})
+package tracker
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/internal/expense"
+	"github.com/arijit-gogoi/expense-tracker-go/internal/store"
+	"github.com/arijit-gogoi/expense-tracker-go/internal/task"
+)
+
+type (
+	Task          = task.Task
+	ChecklistItem = task.ChecklistItem
+	Pomodoro      = task.Pomodoro
+	Workflow      = task.Workflow
+	StatusDef     = task.StatusDef
+	Expense       = expense.Expense
+	Income        = expense.Income
+)
+
+const (
+	TasksFile    = "tasks.json"    // The name of the saved task file.
+	ExpensesFile = "expenses.json" // The name of the saved expense file.
+	IncomeFile   = "income.json"   // The name of the saved income file.
+
+	StatusTodo  = task.StatusTodo
+	StatusDoing = task.StatusDoing
+	StatusDone  = task.StatusDone
+
+	PriorityLow    = task.PriorityLow
+	PriorityMedium = task.PriorityMedium
+	PriorityHigh   = task.PriorityHigh
+
+	RecurDaily   = task.RecurDaily
+	RecurWeekly  = task.RecurWeekly
+	RecurMonthly = task.RecurMonthly
+	RecurYearly  = task.RecurYearly
+)
+
+// ErrNotFound is wrapped by errors for a task or expense ID that does not exist.
+var ErrNotFound = errors.New("not found")
+
+// ErrInvalid is matched by errors for a value that is not allowed, such as
+// an unknown priority or a status the workflow does not allow moving to.
+var ErrInvalid = errors.New("invalid")
+
+// invalidError is an error for an invalid value: its message is the
+// wrapped error's, and it matches ErrInvalid.
+type invalidError struct{ err error }
+
+func (e invalidError) Error() string        { return e.err.Error() }
+func (e invalidError) Unwrap() error        { return e.err }
+func (e invalidError) Is(target error) bool { return target == ErrInvalid }
+
+// Invalid marks err as being about an invalid value, so that it matches
+// ErrInvalid while keeping its message. It returns nil for a nil err.
+func Invalid(err error) error {
+	if err == nil {
+		return nil
+	}
+	return invalidError{err}
+}
+
+// migrations returns the steps that upgrade the tracker's own data files
+// between schema versions, for tasks in workflow w.
+func migrations(w Workflow) map[string][]store.Migration {
+	return map[string][]store.Migration{
+		TasksFile: {
+			store.RenameField("updatedAT", "updatedAt"),
+			store.SetMissingField("uuid", func() any { return task.NewUUID() }),
+			completedAtMigration(w),
+			waitsOnMigration,
+		},
+		ArchiveFile: {
+			nil,
+			store.SetMissingField("uuid", func() any { return task.NewUUID() }),
+			completedAtMigration(w),
+			waitsOnMigration,
+		},
+	}
+}
+
+// completedAtMigration takes the last update of the done tasks saved before
+// completion times were recorded as when they were completed, before later
+// edits move it on.
+func completedAtMigration(w Workflow) store.Migration {
+	return func(items json.RawMessage) (json.RawMessage, error) {
+		var records []map[string]json.RawMessage
+		if err := json.Unmarshal(items, &records); err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			var status string
+			if err := json.Unmarshal(record["status"], &status); err != nil {
+				continue
+			}
+			if _, ok := record["completedAt"]; !ok && w.IsDone(status) && record["updatedAt"] != nil {
+				record["completedAt"] = record["updatedAt"]
+			}
+		}
+		return json.Marshal(records)
+	}
+}
+
+// waitsOnMigration replaces the IDs of the tasks a task waits on with their
+// UUIDs, which, unlike IDs, are never reused or renumbered. IDs of tasks
+// not in the same file are dropped.
+func waitsOnMigration(items json.RawMessage) (json.RawMessage, error) {
+	var records []map[string]json.RawMessage
+	if err := json.Unmarshal(items, &records); err != nil {
+		return nil, err
+	}
+	uuids := map[int]string{}
+	for _, record := range records {
+		var id int
+		var uuid string
+		if json.Unmarshal(record["id"], &id) == nil && json.Unmarshal(record["uuid"], &uuid) == nil {
+			uuids[id] = uuid
+		}
+	}
+	for _, record := range records {
+		var ids []int
+		if err := json.Unmarshal(record["dependsOn"], &ids); err != nil {
+			continue
+		}
+		delete(record, "dependsOn")
+		var on []string
+		for _, id := range ids {
+			if uuid, ok := uuids[id]; ok {
+				on = append(on, uuid)
+			}
+		}
+		if len(on) > 0 {
+			raw, err := json.Marshal(on)
+			if err != nil {
+				return nil, err
+			}
+			record["waitsOn"] = raw
+		}
+	}
+	return json.Marshal(records)
+}
+
+// Options configure a Tracker.
+type Options struct {
+	// Dir holds the data files; the current directory if empty.
+	Dir string
+
+	// Workflow defines the task statuses; DefaultWorkflow if it has none.
+	Workflow Workflow
+
+	// Passphrase is called the first time an encrypted file must be opened.
+	Passphrase func() (string, error)
+
+	// OnUpgrade, if set, is called after a data file written by an older
+	// version has been migrated, with the path of the original's backup.
+	OnUpgrade func(path string, from, to int, backup string)
+
+	// ReviewTasks, if set, is called before tasks are saved with the tasks
+	// saved so far and the ones about to replace them, and returns the
+	// tasks to save instead. An error cancels the save. Hook runners that
+	// let other programs adjust changes plug in here.
+	ReviewTasks func(saved, tasks []Task) ([]Task, error)
+
+	// ReviewExpenses is ReviewTasks for expenses.
+	ReviewExpenses func(saved, expenses []Expense) ([]Expense, error)
+
+	// Journal, if set, saves changes to tasks by appending the tasks added,
+	// changed or deleted to tasks.json.journal, which is compacted into
+	// tasks.json from time to time, rather than rewriting tasks.json.
+	// Saves then write in proportion to the change, not to the number of
+	// tasks. The journal is read whether or not this is set.
+	Journal bool
+
+	// FS holds the data files; the disk if nil. NewMemFS returns one that
+	// keeps them in memory, for tests and programs that embed the tracker.
+	FS FS
+
+	// Now returns the time changes are stamped with; time.Now if nil.
+	Now func() time.Time
+
+	// Location is the time zone the times of the tasks and expenses read
+	// are in; UTC if nil. Times are always saved in UTC.
+	Location *time.Location
+
+	// MaxDescription is the most characters a description may have;
+	// DefaultMaxDescription if zero. MaxAmount is the largest amount of an
+	// expense or income; DefaultMaxAmount if zero.
+	MaxDescription int
+	MaxAmount      float64
+
+	// Currencies lists the codes accepted besides the ISO 4217 ones, such
+	// as those of the user's own exchange rates.
+	Currencies []string
+}
+
+// FS is a file system the data files are kept in.
+type FS = store.FS
+
+// NewMemFS returns an empty file system held in memory.
+func NewMemFS() FS {
+	return store.NewMemFS()
+}
+
+// Tracker manages the tasks and expenses stored in one directory.
+type Tracker struct {
+	store    *store.Store
+	workflow Workflow
+	review   func(saved, tasks []Task) ([]Task, error)
+	reviewEx func(saved, expenses []Expense) ([]Expense, error)
+	journal  *journalState
+	now      func() time.Time
+	loc      *time.Location
+	limits   limits
+}
+
+// limits are the checks added and changed records must pass.
+type limits struct {
+	maxDescription int
+	maxAmount      float64
+	currencies     []string
+}
+
+// New returns a Tracker for the data files in opts.Dir.
+func New(opts Options) *Tracker {
+	if opts.Dir == "" {
+		opts.Dir = "."
+	}
+	if len(opts.Workflow.Statuses) == 0 {
+		opts.Workflow = DefaultWorkflow()
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	if opts.Location == nil {
+		opts.Location = time.UTC
+	}
+	return &Tracker{
+		store: &store.Store{
+			Dir:        opts.Dir,
+			Keys:       store.NewKeyring(opts.Passphrase),
+			Migrations: migrations(opts.Workflow),
+			OnUpgrade:  opts.OnUpgrade,
+			FS:         opts.FS,
+		},
+		workflow: opts.Workflow,
+		review:   opts.ReviewTasks,
+		reviewEx: opts.ReviewExpenses,
+		journal:  &journalState{enabled: opts.Journal, loaded: map[string]*taskSnapshot{}},
+		now:      opts.Now,
+		loc:      opts.Location,
+		limits: limits{
+			maxDescription: cmp.Or(opts.MaxDescription, DefaultMaxDescription),
+			maxAmount:      cmp.Or(opts.MaxAmount, DefaultMaxAmount),
+			currencies:     opts.Currencies,
+		},
+	}
+}
+
+// inLocation returns records with their times in loc.
+func inLocation[T interface{ InLocation(*time.Location) T }](records []T, loc *time.Location) []T {
+	in := make([]T, len(records))
+	for i, r := range records {
+		in[i] = r.InLocation(loc)
+	}
+	return in
+}
+
+// At returns a Tracker for the data files in dir that shares t's settings
+// and passphrase.
+func (t *Tracker) At(dir string) *Tracker {
+	s := *t.store
+	s.Dir = dir
+	return &Tracker{store: &s, workflow: t.workflow, review: t.review, reviewEx: t.reviewEx, journal: t.journal, now: t.now, loc: t.loc, limits: t.limits}
+}
+
+// Unbuffered returns a Tracker for the same data files whose changes are
+// saved at once, even while t holds its changes since Begin. It keeps files
+// that a transaction should not hold back or drop, such as a log of what
+// was done.
+func (t *Tracker) Unbuffered() *Tracker {
+	u := t.At(t.store.Dir)
+	u.store.Buffer = nil
+	return u
+}
+
+// Dir returns the directory holding the data files.
+func (t *Tracker) Dir() string {
+	return t.store.Dir
+}
+
+// Workflow returns the task workflow.
+func (t *Tracker) Workflow() Workflow {
+	return t.workflow
+}
+
+// Path returns the path of a data file.
+func (t *Tracker) Path(name string) string {
+	return t.store.Path(name)
+}
+
+// ReadDocument returns the records of any versioned data file in the
+// tracker's directory, or nil if the file does not exist. Programs
+// embedding the tracker can keep their own records alongside tasks and
+// expenses this way and have them encrypted and migrated the same way.
+func (t *Tracker) ReadDocument(name string) (json.RawMessage, error) {
+	return t.store.Load(name)
+}
+
+// WriteDocument writes items as the records of a versioned data file.
+func (t *Tracker) WriteDocument(name string, items any) error {
+	return t.store.Save(name, items)
+}
+
+// Encrypted reports whether the tracker's data files are encrypted.
+func (t *Tracker) Encrypted() bool {
+	return t.store.Encrypted(TasksFile)
+}
+
+// EnableEncryption encrypts the task, archive, expense and income files,
+// and any extra data files, with passphrase.
+func (t *Tracker) EnableEncryption(passphrase string, extra ...string) error {
+	if t.Encrypted() {
+		return errors.New("encryption is already enabled")
+	}
+	if passphrase == "" {
+		return Invalid(errors.New("passphrase must not be empty"))
+	}
+	if err := t.CompactJournal(); err != nil {
+		return err
+	}
+	t.store.Keys.SetPassphrase(passphrase)
+	return t.store.Encrypt(append([]string{TasksFile, ExpensesFile, IncomeFile, ArchiveFile}, extra...))
+}
+
+// DisableEncryption decrypts the task, archive, expense and income files,
+// and any extra data files, back to plain JSON.
+func (t *Tracker) DisableEncryption(extra ...string) error {
+	if !t.Encrypted() {
+		return errors.New("encryption is not enabled")
+	}
+	return t.store.Decrypt(append([]string{TasksFile, ExpensesFile, IncomeFile, ArchiveFile}, extra...))
+}
+
+// CheckPassphrase returns an error unless passphrase opens the encrypted
+// task file, leaving the passphrase in use as it is.
+func (t *Tracker) CheckPassphrase(passphrase string) error {
+	s := *t.store
+	s.Keys = store.NewKeyring(nil)
+	s.Keys.SetPassphrase(passphrase)
+	_, err := s.Read(TasksFile)
+	return err
+}
+
+// DocumentEncrypted reports whether a data file is encrypted.
+func (t *Tracker) DocumentEncrypted(name string) bool {
+	return t.store.Encrypted(name)
+}
+
+// EncryptDocument encrypts a single data file with passphrase, creating it
+// empty if it is missing, whether or not the other files are encrypted.
+func (t *Tracker) EncryptDocument(name, passphrase string) error {
+	if passphrase == "" {
+		return Invalid(errors.New("passphrase must not be empty"))
+	}
+	t.store.Keys.SetPassphrase(passphrase)
+	return t.store.Encrypt([]string{name})
+}
+
+// DefaultWorkflow returns the todo → doing → done workflow.
+func DefaultWorkflow() Workflow {
+	return task.DefaultWorkflow()
+}
+
+// IsValidPriority reports whether p is a supported priority.
+func IsValidPriority(p string) bool {
+	return task.IsValidPriority(p)
+}
+
+// IsValidRecurrence reports whether r is a supported recurrence interval.
+func IsValidRecurrence(r string) bool {
+	return task.IsValidRecurrence(r)
+}
+
+// NextOccurrence returns the date following due for recurrence r.
+func NextOccurrence(due time.Time, r string) time.Time {
+	return task.NextOccurrence(due, r)
+}
+
+// NextInRotation returns the assignee after current in rotation.
+func NextInRotation(rotation []string, current string) string {
+	return task.NextInRotation(rotation, current)
+}
+
+// Deadline returns the instant a due date passes; date-only due dates last
+// until the end of the day.
+func Deadline(due time.Time) time.Time {
+	return task.Deadline(due)
+}
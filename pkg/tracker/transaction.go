@@ -0,0 +1,47 @@
+package tracker
+
+import "github.com/arijit-gogoi/expense-tracker-go/internal/store"
+
+// Begin starts holding changes in memory: until Commit or Rollback, data
+// files are read from disk once and writes are kept back, so a series of
+// changes runs quickly and is saved, or dropped, together. Trackers
+// returned by At share the changes held by t.
+func (t *Tracker) Begin() {
+	if t.store.Buffer == nil {
+		t.store.Buffer = store.NewBuffer(t.store.FS)
+	}
+}
+
+// InTransaction reports whether changes are being held since Begin.
+func (t *Tracker) InTransaction() bool {
+	return t.store.Buffer != nil
+}
+
+// Pending returns the paths of the data files changed since Begin.
+func (t *Tracker) Pending() []string {
+	if t.store.Buffer == nil {
+		return nil
+	}
+	return t.store.Buffer.Pending()
+}
+
+// Commit saves the changes held since Begin and stops holding changes.
+func (t *Tracker) Commit() error {
+	if t.store.Buffer == nil {
+		return nil
+	}
+	if err := t.store.Buffer.Flush(); err != nil {
+		return err
+	}
+	t.store.Buffer = nil
+	return nil
+}
+
+// Rollback drops the changes held since Begin and stops holding changes.
+func (t *Tracker) Rollback() {
+	if t.store.Buffer != nil {
+		t.store.Buffer.Discard()
+		t.store.Buffer = nil
+		clear(t.journal.loaded)
+	}
+}
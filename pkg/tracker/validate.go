@@ -0,0 +1,138 @@
+package tracker
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Limits on the tasks and expenses added or changed, unless Options set
+// others.
+const (
+	DefaultMaxDescription = 500 // Characters.
+	DefaultMaxAmount      = 1e9
+)
+
+// isoCurrencies lists the ISO 4217 codes of the currencies in use.
+var isoCurrencies = strings.Fields(`
+	AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BHD BIF BMD BND
+	BOB BRL BSD BTN BWP BYN BZD CAD CDF CHF CLP CNY COP CRC CUP CVE CZK DJF
+	DKK DOP DZD EGP ERN ETB EUR FJD FKP GBP GEL GHS GIP GMD GNF GTQ GYD HKD
+	HNL HTG HUF IDR ILS INR IQD IRR ISK JMD JOD JPY KES KGS KHR KMF KPW KRW
+	KWD KYD KZT LAK LBP LKR LRD LSL LYD MAD MDL MGA MKD MMK MNT MOP MRU MUR
+	MVR MWK MXN MYR MZN NAD NGN NIO NOK NPR NZD OMR PAB PEN PGK PHP PKR PLN
+	PYG QAR RON RSD RUB RWF SAR SBD SCR SDG SEK SGD SHP SLE SLL SOS SRD SSP
+	STN SVC SYP SZL THB TJS TMT TND TOP TRY TTD TWD TZS UAH UGX USD UYU UZS
+	VES VND VUV WST XAF XCD XCG XOF XPF YER ZAR ZMW ZWG ZWL`)
+
+// KnownCurrency reports whether code is the ISO 4217 code of a currency in
+// use, such as "EUR".
+func KnownCurrency(code string) bool {
+	_, found := slices.BinarySearch(isoCurrencies, code)
+	return found
+}
+
+// CleanText trims s and cleans it up the same way for every record: runs
+// of spaces and tabs, including Unicode spaces, become a single space,
+// invisible zero-width characters are dropped and the text is put in
+// Unicode Normalization Form C, so that an accented letter typed as a
+// letter and combining marks is stored as the single character it stands
+// for. Other control characters are kept, for the validation to reject.
+func CleanText(s string) string {
+	var out []rune
+	space := false
+	for _, r := range strings.TrimSpace(s) {
+		switch {
+		case r == '\u200b' || r == '\u2060' || r == '\ufeff':
+			continue
+		case r == '\t' || unicode.IsSpace(r) && !unicode.IsControl(r):
+			space = true
+			continue
+		}
+		if space {
+			out = append(out, ' ')
+			space = false
+		}
+		out = append(out, r)
+	}
+	return nfc(string(out))
+}
+
+// checkText returns an error if s, a cleaned up description or other text
+// of a record, holds control characters or is longer than maxLen
+// characters.
+func checkText(what, s string, maxLen int) error {
+	if strings.IndexFunc(s, unicode.IsControl) >= 0 {
+		return Invalid(fmt.Errorf("%s contains control characters", what))
+	}
+	if n := utf8.RuneCountInString(s); n > maxLen {
+		return Invalid(fmt.Errorf("%s is %d characters long; the limit is %d", what, n, maxLen))
+	}
+	return nil
+}
+
+// checkDescription returns an error if the cleaned up description is empty
+// or not allowed.
+func (t *Tracker) checkDescription(description string) error {
+	if description == "" {
+		return Invalid(fmt.Errorf("description cannot be empty"))
+	}
+	return checkText("description", description, t.limits.maxDescription)
+}
+
+// checkAmount returns an error for an amount that is not a number or whose
+// size exceeds the limit.
+func (t *Tracker) checkAmount(amount float64) error {
+	if math.IsNaN(amount) || math.IsInf(amount, 0) || math.Abs(amount) > t.limits.maxAmount {
+		return Invalid(fmt.Errorf("invalid amount %g; the limit is %g", amount, t.limits.maxAmount))
+	}
+	return nil
+}
+
+// checkCurrency returns an error for a currency that is neither known nor
+// one of Options.Currencies. The empty code, for the base currency, is
+// allowed.
+func (t *Tracker) checkCurrency(code string) error {
+	if code == "" || KnownCurrency(code) || slices.Contains(t.limits.currencies, code) {
+		return nil
+	}
+	return Invalid(fmt.Errorf("unknown currency '%s'", code))
+}
+
+// cleanExpense cleans up the text of e and checks it can be saved.
+func (t *Tracker) cleanExpense(e *Expense) error {
+	e.Description, e.Category = CleanText(e.Description), CleanText(e.Category)
+	e.Payee, e.Account = CleanText(e.Payee), CleanText(e.Account)
+	if err := t.checkDescription(e.Description); err != nil {
+		return err
+	}
+	for _, text := range [][2]string{{"category", e.Category}, {"payee", e.Payee}, {"account", e.Account}} {
+		if err := checkText(text[0], text[1], t.limits.maxDescription); err != nil {
+			return err
+		}
+	}
+	if err := t.checkAmount(e.Amount); err != nil {
+		return err
+	}
+	return t.checkCurrency(e.Currency)
+}
+
+// cleanIncome cleans up the text of in and checks it can be saved.
+func (t *Tracker) cleanIncome(in *Income) error {
+	in.Description, in.Source, in.Account = CleanText(in.Description), CleanText(in.Source), CleanText(in.Account)
+	if err := t.checkDescription(in.Description); err != nil {
+		return err
+	}
+	for _, text := range [][2]string{{"source", in.Source}, {"account", in.Account}} {
+		if err := checkText(text[0], text[1], t.limits.maxDescription); err != nil {
+			return err
+		}
+	}
+	if err := t.checkAmount(in.Amount); err != nil {
+		return err
+	}
+	return t.checkCurrency(in.Currency)
+}
@@ -0,0 +1,26 @@
+package tracker_test
+
+import (
+	"testing"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+func TestCleanText(t *testing.T) {
+	for in, want := range map[string]string{
+		"  Buy \u00a0 milk ": "Buy milk",
+		"Caf\u200be":         "Cafe",
+		"Cafe\u0301":         "Caf\u00e9",
+		"Zu\u0308rich":       "Z\u00fcrich",
+		"Ha\u0323noi":        "H\u1ea1noi",
+		"Vie\u0302\u0323t":   "Vi\u1ec7t", // The marks are put in order to compose.
+		"\u1100\u1161":       "\uac00",    // Hangul jamo.
+		"\u212b":             "\u00c5",    // Angstrom sign.
+		"Tab\t\there":        "Tab here",
+		"Line\nbreak":        "Line\nbreak",
+	} {
+		if got := tracker.CleanText(in); got != want {
+			t.Errorf("CleanText(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
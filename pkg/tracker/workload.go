@@ -0,0 +1,74 @@
+package tracker
+
+import (
+	"sort"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/internal/task"
+)
+
+// Unassigned is the assignee open tasks without one are counted under.
+const Unassigned = "(unassigned)"
+
+// Workload summarizes the open tasks of one assignee.
+type Workload struct {
+	Assignee       string  `json:"assignee"`
+	Open           int     `json:"open"`
+	EstimatedHours float64 `json:"estimatedHours"`
+	Unestimated    int     `json:"unestimated"` // Open tasks without an estimate.
+	Overdue        int     `json:"overdue"`
+}
+
+// SummarizeWorkload totals the open tasks per assignee, busiest first by
+// estimated hours and then by open tasks. Tasks in a done status of w are
+// not counted.
+func SummarizeWorkload(tasks []Task, w Workflow, now time.Time) []Workload {
+	byAssignee := map[string]*Workload{}
+	for _, tk := range tasks {
+		if w.IsDone(tk.Status) {
+			continue
+		}
+		name := tk.Assignee
+		if name == "" {
+			name = Unassigned
+		}
+		load := byAssignee[name]
+		if load == nil {
+			load = &Workload{Assignee: name}
+			byAssignee[name] = load
+		}
+		load.Open++
+		load.EstimatedHours += tk.Estimate
+		if tk.Estimate == 0 {
+			load.Unestimated++
+		}
+		if tk.Due != nil && now.After(task.Deadline(*tk.Due)) {
+			load.Overdue++
+		}
+	}
+
+	loads := make([]Workload, 0, len(byAssignee))
+	for _, load := range byAssignee {
+		loads = append(loads, *load)
+	}
+	sort.Slice(loads, func(i, j int) bool {
+		a, b := loads[i], loads[j]
+		if a.EstimatedHours != b.EstimatedHours {
+			return a.EstimatedHours > b.EstimatedHours
+		}
+		if a.Open != b.Open {
+			return a.Open > b.Open
+		}
+		return a.Assignee < b.Assignee
+	})
+	return loads
+}
+
+// Workload totals the tracker's open tasks per assignee.
+func (t *Tracker) Workload(now time.Time) ([]Workload, error) {
+	tasks, err := t.Tasks()
+	if err != nil {
+		return nil, err
+	}
+	return SummarizeWorkload(tasks, t.workflow, now), nil
+}
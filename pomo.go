@@ -0,0 +1,115 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+const defaultPomodoroLength = 25 * time.Minute
+
+// pomoCommand returns the pomo command.
+func pomoCommand() *command {
+	return &command{
+		name: "pomo", args: "<id>", summary: "Run a pomodoro timer for a task", group: groupTasks, minArgs: 1,
+		complete: positional(taskIDs),
+		setup: func(fs *flag.FlagSet) runFunc {
+			length := fs.Duration("length", defaultPomodoroLength, "length of the pomodoro")
+			return func(args []string) error {
+				id, err := parseID(args[0], "task")
+				if err != nil {
+					return err
+				}
+				if *length <= 0 {
+					return usagef("invalid pomodoro length '%s'", *length)
+				}
+				return runPomodoro(id, *length)
+			}
+		},
+	}
+}
+
+// runPomodoro counts down length while working on a task, then records the
+// completed pomodoro against it and sends a notification. Interrupting the
+// countdown with Ctrl+C abandons the pomodoro without recording it.
+func runPomodoro(id int, length time.Duration) error {
+	tasks, err := loadTasks()
+	if err != nil {
+		return err
+	}
+
+	index := -1
+	for i, task := range tasks {
+		if task.ID == id {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		return fmt.Errorf("task with ID %d %w", id, tracker.ErrNotFound)
+	}
+	description := tasks[index].Description
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	start := clock()
+	end := start.Add(length)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	progress := newTimerProgress(config.Timer, description, length)
+	defer progress.done()
+
+	fmt.Fprintf(stdout, "Pomodoro started for task ID %d: %s\n", id, description)
+	for remaining := length; remaining > 0; remaining = time.Until(end) {
+		fmt.Fprintf(stdout, "\r  %s remaining ", formatCountdown(remaining))
+		progress.update(remaining)
+		select {
+		case <-ticker.C:
+		case <-interrupt:
+			fmt.Fprintln(stdout, "\nPomodoro abandoned.")
+			return nil
+		}
+	}
+	fmt.Fprintf(stdout, "\r  %s remaining \n", formatCountdown(0))
+
+	// Reload in case the task list changed while the timer was running.
+	unlock, err := lockData()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	tasks, err = loadTasks()
+	if err != nil {
+		return err
+	}
+	for i, task := range tasks {
+		if task.ID == id {
+			tasks[i].Pomodoros = append(tasks[i].Pomodoros, Pomodoro{Start: start, Minutes: int(length.Round(time.Minute) / time.Minute)})
+			if tasks[i].Status == config.Workflow.Initial() && config.Workflow.HasStatus(tracker.StatusDoing) {
+				tasks[i].SetStatus(tracker.StatusDoing, clock(), config.Workflow)
+			}
+			tasks[i].UpdatedAt = clock()
+			if err := saveTasks(tasks); err != nil {
+				return err
+			}
+			notify("Pomodoro complete", fmt.Sprintf("Time for a break! (%s)", description))
+			fmt.Fprintf(stdout, "Pomodoro completed for task ID %d (%d total).\n", id, len(tasks[i].Pomodoros))
+			return nil
+		}
+	}
+
+	return fmt.Errorf("task with ID %d %w", id, tracker.ErrNotFound)
+}
+
+// formatCountdown formats d as MM:SS.
+func formatCountdown(d time.Duration) string {
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%02d:%02d", int(d/time.Minute), int(d%time.Minute/time.Second))
+}
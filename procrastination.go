@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+const procrastinationTop = 10 // Number of tasks listed per section.
+
+// projectTask is a task together with the project it belongs to.
+type projectTask struct {
+	Task
+	Project string
+}
+
+// reportProcrastination prints, across all projects, the tasks that waited
+// longest before first being acted on, the most postponed tasks, and the
+// average number of postponements per tag and per project.
+func reportProcrastination() error {
+	var all []projectTask
+	err := forEachProject(func(project string) error {
+		tasks, err := loadTasks()
+		if err != nil {
+			return err
+		}
+		for _, task := range tasks {
+			all = append(all, projectTask{Task: task, Project: project})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(all) == 0 {
+		fmt.Fprintln(stdout, "No tasks found.")
+		return nil
+	}
+
+	now := clock()
+	waited := func(t projectTask) time.Duration {
+		if t.StartedAt != nil {
+			return t.StartedAt.Sub(t.CreatedAt)
+		}
+		return now.Sub(t.CreatedAt)
+	}
+
+	fmt.Fprintln(stdout, "--- Procrastination Report ---")
+
+	byWait := make([]projectTask, 0, len(all))
+	for _, t := range all {
+		// Tasks completed before start times were recorded have no known wait.
+		if t.StartedAt != nil || !config.Workflow.IsDone(t.Status) {
+			byWait = append(byWait, t)
+		}
+	}
+	sort.SliceStable(byWait, func(i, j int) bool { return waited(byWait[i]) > waited(byWait[j]) })
+	fmt.Fprintln(stdout, "Longest wait before first action:")
+	for _, t := range byWait[:min(len(byWait), procrastinationTop)] {
+		note := ""
+		if t.StartedAt == nil {
+			note = " (not started)"
+		}
+		fmt.Fprintf(stdout, "  %8s  [%s ID: %d] %s%s\n", formatDays(waited(t)), t.Project, t.ID, t.Description, note)
+	}
+
+	var postponed []projectTask
+	for _, t := range all {
+		if t.Postponed > 0 {
+			postponed = append(postponed, t)
+		}
+	}
+	sort.SliceStable(postponed, func(i, j int) bool { return postponed[i].Postponed > postponed[j].Postponed })
+	fmt.Fprintln(stdout, "Most postponed:")
+	if len(postponed) == 0 {
+		fmt.Fprintln(stdout, "  (none)")
+	}
+	for _, t := range postponed[:min(len(postponed), procrastinationTop)] {
+		fmt.Fprintf(stdout, "  %3dx  [%s ID: %d] %s\n", t.Postponed, t.Project, t.ID, t.Description)
+	}
+
+	byTag := map[string][]int{}
+	byProject := map[string][]int{}
+	for _, t := range all {
+		byProject[t.Project] = append(byProject[t.Project], t.Postponed)
+		for _, tag := range t.Tags {
+			byTag[tag] = append(byTag[tag], t.Postponed)
+		}
+	}
+	printAveragePostponements("Average postponements by project:", byProject)
+	if len(byTag) > 0 {
+		printAveragePostponements("Average postponements by tag:", byTag)
+	}
+	fmt.Fprintln(stdout, "------------------------------")
+
+	return nil
+}
+
+// printAveragePostponements prints the average of each group's counts.
+func printAveragePostponements(title string, groups map[string][]int) {
+	fmt.Fprintln(stdout, title)
+	for _, name := range sortedKeys(groups) {
+		counts := groups[name]
+		total := 0
+		for _, c := range counts {
+			total += c
+		}
+		fmt.Fprintf(stdout, "  %-16s %5.2f (%d tasks)\n", name, float64(total)/float64(len(counts)), len(counts))
+	}
+}
+
+// formatDays renders a duration as whole days, or hours when under a day.
+func formatDays(d time.Duration) string {
+	if d < 24*time.Hour {
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd", int(d.Hours()/24))
+}
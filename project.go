@@ -0,0 +1,232 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+const (
+	projectsDir        = "projects"         // Directory holding one sub-directory per project.
+	currentProjectFile = ".current-project" // Names the project commands apply to by default.
+	defaultProject     = "default"          // The project whose files live in the top-level directory.
+)
+
+// currentProject is the project commands operate on. The default project is
+// represented by the empty string.
+var currentProject string
+
+var projectNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// projectDir returns the directory holding a project's data files.
+func projectDir(name string) string {
+	if name == "" || name == defaultProject {
+		return "."
+	}
+	return filepath.Join(projectsDir, name)
+}
+
+// selectProject makes name the project for this invocation. An empty name
+// falls back to the project saved by 'task project switch'. A name that
+// could not have been created, such as "../x", is refused before it is
+// used as a path.
+func selectProject(name string) error {
+	if name == "" {
+		data, err := os.ReadFile(currentProjectFile)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error reading file: %w", err)
+		}
+		name = strings.TrimSpace(string(data))
+	}
+
+	if name == "" || name == defaultProject {
+		currentProject = ""
+		return nil
+	}
+	if err := validateProjectName(name); err != nil {
+		return tracker.Invalid(err)
+	}
+	if !projectExists(name) {
+		return fmt.Errorf("project '%s' does not exist; create it with 'task project create %s'", name, name)
+	}
+	currentProject = name
+	return nil
+}
+
+// projectExists reports whether a project has been created.
+func projectExists(name string) bool {
+	if name == defaultProject {
+		return true
+	}
+	if !projectNamePattern.MatchString(name) {
+		return false
+	}
+	info, err := os.Stat(projectDir(name))
+	return err == nil && info.IsDir()
+}
+
+// validateProjectName checks that name is usable as a project directory.
+func validateProjectName(name string) error {
+	if name == defaultProject {
+		return fmt.Errorf("'%s' is reserved for the top-level project", defaultProject)
+	}
+	if !projectNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid project name '%s'; use letters, digits, '.', '-' and '_'", name)
+	}
+	return nil
+}
+
+// projectCommand returns the project command group.
+func projectCommand() *command {
+	return &command{
+		name: "project", summary: "Keep separate tasks and expenses per project", group: groupData,
+		subcommands: []*command{
+			{
+				name: "create", args: "<name>", summary: "Create a project with its own tasks and expenses", minArgs: 1,
+				setup: run(func(args []string) error { return createProject(args[0]) }),
+			},
+			{
+				name: "switch", args: "<name>", summary: "Make a project current ('default' for the top level)", minArgs: 1,
+				setup: run(func(args []string) error { return switchProject(args[0]) }),
+			},
+			{
+				name: "list", summary: "List projects",
+				setup: run(func([]string) error { return listProjects() }),
+			},
+		},
+	}
+}
+
+// createProject creates an empty project.
+func createProject(name string) error {
+	if err := validateProjectName(name); err != nil {
+		return err
+	}
+	if projectExists(name) {
+		return fmt.Errorf("project '%s' already exists", name)
+	}
+
+	// Encrypted data stays so in a new project, with the same passphrase
+	passphrase := ""
+	if base := tr().At(projectDir(defaultProject)); base.Encrypted() {
+		var err error
+		if passphrase, err = existingPassphrase(base); err != nil {
+			return err
+		}
+	}
+	if err := makeDir(projectDir(name), 0755); err != nil {
+		return fmt.Errorf("error creating project: %w", err)
+	}
+	if passphrase != "" {
+		t := tr().At(projectDir(name))
+		if err := t.EnableEncryption(passphrase, encryptedDataFiles(t)...); err != nil {
+			return err
+		}
+	}
+
+	printDone("Project '%s' created. Switch to it with 'task project switch %s'.\n", name, name)
+	return nil
+}
+
+// switchProject saves name as the project later commands apply to.
+func switchProject(name string) error {
+	if !projectExists(name) {
+		return fmt.Errorf("project '%s' does not exist", name)
+	}
+
+	if name == defaultProject {
+		if err := removeFile(currentProjectFile); err != nil {
+			return fmt.Errorf("error writing file: %w", err)
+		}
+	} else if err := writeFile(currentProjectFile, []byte(name+"\n"), 0644); err != nil {
+		return fmt.Errorf("error writing file: %w", err)
+	}
+
+	printDone("Switched to project '%s'.\n", name)
+	return nil
+}
+
+// projectNames returns the default project followed by all created projects.
+func projectNames() ([]string, error) {
+	names := []string{defaultProject}
+	entries, err := os.ReadDir(projectsDir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("error reading projects: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+// forEachProject calls fn with each project selected in turn, restoring the
+// current project afterwards.
+func forEachProject(fn func(project string) error) error {
+	names, err := projectNames()
+	if err != nil {
+		return err
+	}
+
+	saved := currentProject
+	defer func() { currentProject = saved }()
+
+	for _, name := range names {
+		currentProject = name
+		if name == defaultProject {
+			currentProject = ""
+		}
+		if err := fn(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listProjects prints all projects, marking the current one.
+func listProjects() error {
+	names, err := projectNames()
+	if err != nil {
+		return err
+	}
+
+	current := currentProject
+	if current == "" {
+		current = defaultProject
+	}
+
+	fmt.Fprintln(stdout, "--- Projects ---")
+	for _, name := range names {
+		marker := " "
+		if name == current {
+			marker = "*"
+		}
+		fmt.Fprintf(stdout, "%s %s\n", marker, name)
+	}
+	fmt.Fprintln(stdout, "----------------")
+	return nil
+}
+
+// extractProjectFlag removes a leading --project flag from args, returning
+// the remaining arguments and the project name, if any.
+func extractProjectFlag(args []string) ([]string, string, error) {
+	if len(args) == 0 {
+		return args, "", nil
+	}
+	switch {
+	case args[0] == "--project" || args[0] == "-project":
+		if len(args) < 2 {
+			return nil, "", errors.New("--project requires a project name")
+		}
+		return args[2:], args[1], nil
+	case strings.HasPrefix(args[0], "--project="):
+		return args[1:], strings.TrimPrefix(args[0], "--project="), nil
+	}
+	return args, "", nil
+}
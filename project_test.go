@@ -0,0 +1,21 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+func TestSelectProjectRejectsPaths(t *testing.T) {
+	setupCLI(t)
+	for _, name := range []string{"../victim", "a/../../b", ".hidden"} {
+		if err := selectProject(name); !errors.Is(err, tracker.ErrInvalid) {
+			t.Errorf("selectProject(%q) = %v, want ErrInvalid", name, err)
+		}
+	}
+	if w := serveRequest(t, "GET", "/sync/..%2Fvictim", "", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("GET /sync/..%%2Fvictim: %d %s", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+const (
+	wordsPerMinute = 238              // Average adult silent reading speed.
+	fetchTimeout   = 15 * time.Second // Upper bound for fetching an article.
+	maxFetchBytes  = 5 << 20          // Ignore anything past the first 5 MiB.
+)
+
+var (
+	titlePattern  = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	scriptPattern = regexp.MustCompile(`(?is)<(script|style|noscript)[^>]*>.*?</(script|style|noscript)>`)
+	tagPattern    = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+// readCommand returns the read command group.
+func readCommand() *command {
+	return &command{
+		name: "read", summary: "Keep a reading list", group: groupPlanning,
+		subcommands: []*command{
+			{
+				name: "add", args: "<url> [title]", summary: "Add an article to the reading list", minArgs: 1,
+				setup: run(func(args []string) error {
+					return addReading(args[0], strings.Join(args[1:], " "))
+				}),
+			},
+			{
+				name: "progress", args: "<id> <percent>", summary: "Record reading progress", minArgs: 2,
+				setup: run(func(args []string) error {
+					id, err := parseID(args[0], "task")
+					if err != nil {
+						return err
+					}
+					percent, err := strconv.Atoi(strings.TrimSuffix(args[1], "%"))
+					if err != nil || percent < 0 || percent > 100 {
+						return usagef("invalid progress '%s'; use a percentage from 0 to 100", args[1])
+					}
+					return updateReadingProgress(id, percent)
+				}),
+			},
+			{
+				name: "list", summary: "Show the reading list",
+				setup: run(func([]string) error { return listReading() }),
+			},
+		},
+	}
+}
+
+// addReading adds a reading list item for url, estimating its reading time
+// from the fetched page. A fetch failure is reported but does not prevent
+// the item from being added.
+func addReading(url, title string) error {
+	tasks, err := loadTasks()
+	if err != nil {
+		return err
+	}
+
+	pageTitle, words, fetchErr := fetchArticle(url)
+	if fetchErr != nil {
+		fmt.Fprintf(stdout, "Warning: could not fetch %s: %v\n", url, fetchErr)
+	}
+	if title == "" {
+		title = pageTitle
+	}
+	if title == "" {
+		title = url
+	}
+
+	now := clock()
+	newTask := Task{
+		ID:          getNextID(tasks),
+		Description: title,
+		Status:      config.Workflow.Initial(),
+		URL:         url,
+		ReadMinutes: readingMinutes(words),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	tasks = append(tasks, newTask)
+	if err := saveTasks(tasks); err != nil {
+		return err
+	}
+
+	printDone("Reading item added successfully (ID: %d)\n", newTask.ID)
+	return nil
+}
+
+// updateReadingProgress records how far through a reading item the user is.
+// Any progress moves the item to doing and 100% marks it done.
+func updateReadingProgress(id, percent int) error {
+	tasks, err := loadTasks()
+	if err != nil {
+		return err
+	}
+
+	for i, task := range tasks {
+		if task.ID == id {
+			if task.URL == "" {
+				return fmt.Errorf("task with ID %d is not a reading item", id)
+			}
+			now := clock()
+			tasks[i].Progress = percent
+			switch {
+			case percent >= 100:
+				tasks[i].SetStatus(config.Workflow.DoneStatus(), now, config.Workflow)
+			case percent > 0 && config.Workflow.HasStatus(tracker.StatusDoing):
+				tasks[i].SetStatus(tracker.StatusDoing, now, config.Workflow)
+			case percent == 0:
+				tasks[i].SetStatus(config.Workflow.Initial(), now, config.Workflow)
+			}
+			tasks[i].UpdatedAt = now
+			return saveTasks(tasks)
+		}
+	}
+
+	return fmt.Errorf("task with ID %d %w", id, tracker.ErrNotFound)
+}
+
+// listReading prints the reading list with progress and remaining reading time.
+func listReading() error {
+	tasks, err := loadTasks()
+	if err != nil {
+		return err
+	}
+
+	var items []Task
+	for _, task := range tasks {
+		if task.URL != "" {
+			items = append(items, task)
+		}
+	}
+
+	if len(items) == 0 {
+		fmt.Fprintln(stdout, "Reading list is empty.")
+		return nil
+	}
+
+	totalLeft := 0
+	fmt.Fprintln(stdout, "--- Reading List ---")
+	for _, item := range items {
+		left := item.ReadMinutes * (100 - item.Progress) / 100
+		if !config.Workflow.IsDone(item.Status) {
+			totalLeft += left
+		}
+
+		estimate := "unknown length"
+		if item.ReadMinutes > 0 {
+			estimate = fmt.Sprintf("%d min, %d min left", item.ReadMinutes, left)
+		}
+		fmt.Fprintf(stdout, "[ID: %d] [%3d%%] %s\n", item.ID, item.Progress, item.Description)
+		fmt.Fprintf(stdout, "  %s (%s)\n", item.URL, estimate)
+	}
+	fmt.Fprintf(stdout, "--- %d min of reading left ---\n", totalLeft)
+
+	return nil
+}
+
+// fetchArticle downloads url and returns its title and word count.
+func fetchArticle(url string) (string, int, error) {
+	client := &http.Client{Timeout: fetchTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFetchBytes))
+	if err != nil {
+		return "", 0, err
+	}
+	if len(body) == 0 {
+		return "", 0, errors.New("empty response")
+	}
+
+	page := string(body)
+	title := ""
+	if m := titlePattern.FindStringSubmatch(page); m != nil {
+		title = strings.Join(strings.Fields(html.UnescapeString(m[1])), " ")
+	}
+
+	text := scriptPattern.ReplaceAllString(page, " ")
+	text = tagPattern.ReplaceAllString(text, " ")
+	words := len(strings.Fields(html.UnescapeString(text)))
+
+	return title, words, nil
+}
+
+// readingMinutes estimates the reading time for a word count, rounding up.
+func readingMinutes(words int) int {
+	if words == 0 {
+		return 0
+	}
+	return (words + wordsPerMinute - 1) / wordsPerMinute
+}
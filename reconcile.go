@@ -0,0 +1,318 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const reconciliationsFile = "reconciliations.json" // The name of the saved reconciliations file.
+
+// Reconciliation is a checkpoint of an account: its balance on a statement,
+// once the entries up to the statement's date were cleared against it.
+type Reconciliation struct {
+	ID         int       `json:"id"`
+	Account    string    `json:"account"`
+	Date       time.Time `json:"date"`    // Date of the statement.
+	Balance    float64   `json:"balance"` // Closing balance on the statement.
+	Cleared    int       `json:"cleared"` // Entries cleared by this reconciliation.
+	Difference float64   `json:"difference,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// reconcileCommand returns the expense reconcile command.
+func reconcileCommand() *command {
+	return &command{
+		name: "reconcile", summary: "Clear an account's entries against a bank statement and record its balance",
+		setup: func(fs *flag.FlagSet) runFunc {
+			account := fs.String("account", "", "`account` to reconcile, as given to add and import with --account")
+			balance := fs.String("statement-balance", "", "closing `balance` on the statement (default list the account's reconciliations)")
+			dateStr := fs.String("date", "", "`date` of the statement; later entries are left for the next one (default today)")
+			opening := fs.Float64("opening", 0, "`balance` the account started with, for its first reconciliation")
+			yes := fs.Bool("yes", false, "clear every entry without asking")
+			force := fs.Bool("force", false, "record the reconciliation even if the account does not balance")
+			return func([]string) error {
+				if *account == "" {
+					return usagef("expected --account")
+				}
+				if *balance == "" {
+					return listReconciliations(*account)
+				}
+				b, err := strconv.ParseFloat(*balance, 64)
+				if err != nil {
+					return usagef("invalid balance '%s'", *balance)
+				}
+				date := clock()
+				if *dateStr != "" {
+					if date, err = parseDate(*dateStr, clock()); err != nil {
+						return usagef("%v", err)
+					}
+				}
+				var start *float64
+				fs.Visit(func(f *flag.Flag) {
+					if f.Name == "opening" {
+						start = opening
+					}
+				})
+				return reconcileAccount(*account, b, date, start, !*yes, *force)
+			}
+		},
+	}
+}
+
+// loadReconciliations reads the reconciliations.
+func loadReconciliations() ([]Reconciliation, error) {
+	raw, err := loadDocument(reconciliationsFile)
+	if err != nil || raw == nil {
+		return []Reconciliation{}, err
+	}
+
+	var reconciliations []Reconciliation
+	if err := json.Unmarshal(raw, &reconciliations); err != nil {
+		return nil, fmt.Errorf("error unmarshalling JSON: %w", err)
+	}
+	return reconciliations, nil
+}
+
+// ledgerEntry is a transaction of an account: an expense or income, with
+// the amount signed as it moves the balance.
+type ledgerEntry struct {
+	kind        string // "expense" or "income".
+	id          int
+	date        time.Time
+	amount      float64
+	description string
+	payee       string
+	cleared     bool
+}
+
+// accountEntries returns the entries of account, oldest first.
+func accountEntries(account string, expenses []Expense, income []Income) []ledgerEntry {
+	var entries []ledgerEntry
+	for _, e := range expenses {
+		if strings.EqualFold(e.Account, account) {
+			entries = append(entries, ledgerEntry{"expense", e.ID, e.Date, -e.Amount, e.Description, e.Payee, e.Cleared != nil})
+		}
+	}
+	for _, in := range income {
+		if strings.EqualFold(in.Account, account) {
+			entries = append(entries, ledgerEntry{"income", in.ID, in.Date, in.Amount, in.Description, in.Source, in.Cleared != nil})
+		}
+	}
+	slices.SortStableFunc(entries, func(a, b ledgerEntry) int { return a.date.Compare(b.date) })
+	return entries
+}
+
+// reconcileAccount walks the uncleared entries of account dated up to the
+// statement's date, asking which appear on the statement unless ask is
+// false, and works out the balance they bring the account to from its last
+// reconciliation, or from opening for the first. If that is the statement's
+// balance, the entries are marked cleared and the reconciliation recorded.
+// If not, the difference is flagged with the entries that may explain it,
+// and nothing is saved unless force is set.
+func reconcileAccount(account string, balance float64, date time.Time, opening *float64, ask, force bool) error {
+	reconciliations, err := loadReconciliations()
+	if err != nil {
+		return err
+	}
+	start := 0.0
+	last := -1
+	for i, r := range reconciliations {
+		if strings.EqualFold(r.Account, account) && (last < 0 || !r.Date.Before(reconciliations[last].Date)) {
+			last = i
+		}
+	}
+	if last >= 0 {
+		if opening != nil {
+			return usagef("account %s was reconciled before; --opening is only for its first reconciliation", account)
+		}
+		start = reconciliations[last].Balance
+	} else if opening != nil {
+		start = *opening
+	}
+
+	expenses, err := loadExpenses()
+	if err != nil {
+		return err
+	}
+	income, err := tr().Income()
+	if err != nil {
+		return err
+	}
+	entries := accountEntries(account, expenses, income)
+	if len(entries) == 0 && last < 0 {
+		return fmt.Errorf("no entries of account '%s'; record them with --account", account)
+	}
+
+	end := startOfDay(date).AddDate(0, 0, 1)
+	var pending, later []ledgerEntry
+	for _, e := range entries {
+		switch {
+		case e.cleared:
+		case e.date.Before(end):
+			pending = append(pending, e)
+		default:
+			later = append(later, e)
+		}
+	}
+
+	ask = ask && isTerminal(os.Stdin)
+	var cleared, outstanding []ledgerEntry
+walk:
+	for i, e := range pending {
+		if ask {
+			answer, err := readLine(fmt.Sprintf("%s %10.2f  %s (%s %d)  On the statement? [Y/n/q] ",
+				e.date.Format(dateLayout), e.amount, e.description, e.kind, e.id))
+			if err != nil {
+				return err
+			}
+			switch strings.ToLower(strings.TrimSpace(answer)) {
+			case "n", "no":
+				outstanding = append(outstanding, e)
+				continue
+			case "q":
+				outstanding = append(outstanding, pending[i:]...)
+				break walk
+			}
+		}
+		cleared = append(cleared, e)
+	}
+
+	in, out := 0.0, 0.0
+	for _, e := range cleared {
+		if e.amount > 0 {
+			in += e.amount
+		} else {
+			out -= e.amount
+		}
+	}
+	reached := roundCents(start + in - out)
+	difference := roundCents(balance - reached)
+
+	fmt.Fprintf(stdout, "--- Reconciling %s to %s ---\n", account, date.Format(dateLayout))
+	fmt.Fprintf(stdout, "  %-20s %12.2f\n", "Opening balance", start)
+	fmt.Fprintf(stdout, "  %-20s %12.2f\n", "Cleared in", in)
+	fmt.Fprintf(stdout, "  %-20s %12.2f\n", "Cleared out", -out)
+	fmt.Fprintf(stdout, "  %-20s %12.2f\n", "Cleared balance", reached)
+	fmt.Fprintf(stdout, "  %-20s %12.2f\n", "Statement balance", balance)
+	fmt.Fprintf(stdout, "  %-20s %s\n", "Difference", signedColumn(difference))
+	fmt.Fprintln(stdout, "-----------------")
+	fmt.Fprintf(stdout, "%s cleared, %s outstanding.\n", plural(len(cleared), "transaction"), plural(len(outstanding), "transaction"))
+
+	if difference != 0 {
+		fmt.Fprintf(stdout, "Warning: the cleared balance is %.2f off the statement.\n", difference)
+		flagDiscrepancy(difference, cleared, outstanding, later)
+		if !force {
+			return fmt.Errorf("account %s does not balance; nothing was saved (--force records it anyway)", account)
+		}
+	}
+
+	now := clock()
+	for _, e := range cleared {
+		if e.kind == "expense" {
+			expenses[slices.IndexFunc(expenses, func(x Expense) bool { return x.ID == e.id })].Cleared = &now
+		} else {
+			income[slices.IndexFunc(income, func(x Income) bool { return x.ID == e.id })].Cleared = &now
+		}
+	}
+	if err := tr().SaveExpenses(expenses); err != nil {
+		return err
+	}
+	if err := tr().SaveIncome(income); err != nil {
+		return err
+	}
+	r := Reconciliation{Account: account, Date: startOfDay(date), Balance: balance, Cleared: len(cleared), Difference: difference, CreatedAt: now}
+	for _, existing := range reconciliations {
+		r.ID = max(r.ID, existing.ID)
+	}
+	r.ID++
+	if err := saveDocument(reconciliationsFile, append(reconciliations, r)); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Account %s reconciled at %.2f (ID: %d)\n", account, balance, r.ID)
+	return nil
+}
+
+// flagDiscrepancy names the entries that could explain a difference
+// between the statement and the cleared balance: entries left outstanding
+// or dated after the statement that account for it, cleared entries that
+// would if the statement does not have them, and cleared entries that look
+// like duplicates.
+func flagDiscrepancy(difference float64, cleared, outstanding, later []ledgerEntry) {
+	describe := func(e ledgerEntry) string {
+		return fmt.Sprintf("%s %d (%s, %.2f, %s)", e.kind, e.id, e.date.Format(dateLayout), e.amount, e.description)
+	}
+	for _, e := range outstanding {
+		if math.Abs(e.amount-difference) < 0.005 {
+			fmt.Fprintf(stdout, "  Left outstanding, for the same amount: %s\n", describe(e))
+		}
+	}
+	for _, e := range later {
+		if math.Abs(e.amount-difference) < 0.005 {
+			fmt.Fprintf(stdout, "  Dated after the statement, for the same amount: %s\n", describe(e))
+		}
+	}
+	seen := map[string]bool{}
+	for _, e := range cleared {
+		if math.Abs(e.amount+difference) < 0.005 {
+			fmt.Fprintf(stdout, "  Cleared, but the statement may not have it: %s\n", describe(e))
+		}
+		key := duplicateKey(e.date, e.amount, cmp.Or(e.payee, e.description))
+		if seen[key] {
+			fmt.Fprintf(stdout, "  Possibly recorded twice: %s\n", describe(e))
+		}
+		seen[key] = true
+	}
+}
+
+// listReconciliations prints the reconciliations of account and what is
+// not cleared yet.
+func listReconciliations(account string) error {
+	reconciliations, err := loadReconciliations()
+	if err != nil {
+		return err
+	}
+	expenses, err := loadExpenses()
+	if err != nil {
+		return err
+	}
+	income, err := tr().Income()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "--- Reconciliations of %s ---\n", account)
+	found := false
+	for _, r := range reconciliations {
+		if !strings.EqualFold(r.Account, account) {
+			continue
+		}
+		found = true
+		fmt.Fprintf(stdout, "[ID: %d] %s  balance %.2f, %s cleared", r.ID, r.Date.Format(dateLayout), r.Balance, plural(r.Cleared, "transaction"))
+		if r.Difference != 0 {
+			fmt.Fprintf(stdout, ", %.2f unexplained", r.Difference)
+		}
+		fmt.Fprintln(stdout)
+	}
+	if !found {
+		fmt.Fprintln(stdout, "Not reconciled yet.")
+	}
+	uncleared := 0
+	total := 0.0
+	for _, e := range accountEntries(account, expenses, income) {
+		if !e.cleared {
+			uncleared++
+			total += e.amount
+		}
+	}
+	fmt.Fprintln(stdout, "-----------------")
+	fmt.Fprintf(stdout, "%s not cleared, totalling %.2f.\n", plural(uncleared, "transaction"), total)
+	return nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+// updateTaskRecurrence sets how often a task repeats and, optionally, the
+// assignees it rotates through, starting with the first. An empty recurrence
+// stops the task from repeating.
+func updateTaskRecurrence(id int, recur string, rotation []string) error {
+	tasks, err := loadTasks()
+	if err != nil {
+		return err
+	}
+
+	for i, task := range tasks {
+		if task.ID == id {
+			if recur != "" && task.Due == nil {
+				return fmt.Errorf("task with ID %d needs a due date before it can recur", id)
+			}
+			if recur == "" && len(rotation) > 0 {
+				return fmt.Errorf("only recurring tasks can rotate assignees")
+			}
+			tasks[i].Recur = recur
+			if recur == "" || len(rotation) > 0 {
+				tasks[i].Rotation = rotation
+			}
+			if len(rotation) > 0 {
+				tasks[i].Assignee = rotation[0]
+			}
+			tasks[i].UpdatedAt = clock()
+			return saveTasks(tasks)
+		}
+	}
+
+	return fmt.Errorf("task with ID %d %w", id, tracker.ErrNotFound)
+}
+
+// inReminderWindow reports whether now falls within a task's lead-time
+// reminder window, i.e. between RemindDays before the due date and the due date.
+func inReminderWindow(task Task, now time.Time) bool {
+	if task.Due == nil || task.RemindDays <= 0 || config.Workflow.IsDone(task.Status) {
+		return false
+	}
+	today := startOfDay(now)
+	due := startOfDay(*task.Due)
+	return !today.Before(due.AddDate(0, 0, -task.RemindDays)) && !today.After(due)
+}
+
+// listReminders prints tasks whose reminder window is open today.
+func listReminders() error {
+	tasks, err := loadTasks()
+	if err != nil {
+		return err
+	}
+
+	now := clock()
+	found := false
+	for _, task := range tasks {
+		if !inReminderWindow(task, now) {
+			continue
+		}
+		if !found {
+			fmt.Fprintln(stdout, "--- Reminders ---")
+			found = true
+		}
+		fmt.Fprintf(stdout, "[ID: %d] %s (%s, %s)\n", task.ID, task.Description, reminderWhen(*task.Due, now), formatDue(*task.Due))
+	}
+
+	if !found {
+		fmt.Fprintln(stdout, "No reminders for today.")
+	} else {
+		fmt.Fprintln(stdout, "-----------------")
+	}
+	return nil
+}
+
+// reminderWhen says when a task due on due is due, as seen from now:
+// "today", "tomorrow" or "in N days".
+func reminderWhen(due, now time.Time) string {
+	switch days := int(startOfDay(due).Sub(startOfDay(now)).Hours() / 24); days {
+	case 0:
+		return "today"
+	case 1:
+		return "tomorrow"
+	default:
+		return fmt.Sprintf("in %d days", days)
+	}
+}
+
+// startOfDay returns midnight at the start of t's day.
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
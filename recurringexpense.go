@@ -0,0 +1,257 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+const recurringFile = "recurring.json" // The name of the saved recurring expense file.
+
+// everyRecurrence maps the --every intervals of recurring expenses to
+// recurrences, and perYear counts their charges in a year.
+var (
+	everyRecurrence = map[string]string{
+		"day": tracker.RecurDaily, "week": tracker.RecurWeekly,
+		"month": tracker.RecurMonthly, "year": tracker.RecurYearly,
+	}
+	perYear = map[string]float64{
+		tracker.RecurDaily: 365, tracker.RecurWeekly: 52,
+		tracker.RecurMonthly: 12, tracker.RecurYearly: 1,
+	}
+)
+
+// RecurringExpense is a charge that repeats, such as a subscription. Each
+// charge is recorded as an expense once its date has come.
+type RecurringExpense struct {
+	ID          int        `json:"id"`
+	Description string     `json:"description"`
+	Amount      float64    `json:"amount"`
+	Currency    string     `json:"currency,omitempty"`
+	Category    string     `json:"category,omitempty"`
+	Payee       string     `json:"payee,omitempty"`
+	Recur       string     `json:"recur"`
+	Next        time.Time  `json:"next"`            // The date of the next charge.
+	Until       *time.Time `json:"until,omitempty"` // No charges after this date; ended once past it.
+	Debt        int        `json:"debt,omitempty"`  // ID of the debt a payoff plan pays with it.
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+// recurringExpenseCommand returns the expense recurring command group.
+func recurringExpenseCommand() *command {
+	return &command{
+		name: "recurring", summary: "Track subscriptions and other repeating charges",
+		subcommands: []*command{
+			{
+				name: "add", args: "<description> <amount>", summary: "Add a repeating charge, recorded as an expense when due", minArgs: 2,
+				setup: func(fs *flag.FlagSet) runFunc {
+					every := fs.String("every", "month", "how often it is charged: day, week, month or year")
+					start := fs.String("start", "", "`date` of the first charge (default today)")
+					category := fs.String("category", "", "expense category")
+					payee := fs.String("payee", "", "who is paid")
+					currency := fs.String("currency", "", "currency `code` such as USD (default the base currency)")
+					return func(args []string) error {
+						n := len(args)
+						amount, err := strconv.ParseFloat(args[n-1], 64)
+						if err != nil || amount < 0 {
+							return usagef("invalid amount '%s'", args[n-1])
+						}
+						recur, ok := everyRecurrence[strings.TrimSuffix(*every, "ly")]
+						if !ok && *every == "daily" {
+							recur, ok = tracker.RecurDaily, true
+						}
+						if !ok {
+							return usagef("invalid interval '%s'; use day, week, month or year", *every)
+						}
+						next := startOfDay(clock())
+						if *start != "" {
+							if next, err = parseDate(*start, clock()); err != nil {
+								return usagef("%v", err)
+							}
+						}
+						r := RecurringExpense{
+							Description: strings.Join(args[:n-1], " "), Amount: amount,
+							Category: *category, Payee: *payee, Recur: recur, Next: startOfDay(next),
+						}
+						if *currency != "" {
+							if r.Currency, err = parseCurrency(*currency); err != nil {
+								return err
+							}
+						}
+						return addRecurringExpense(r)
+					}
+				},
+			},
+			{
+				name: "list", summary: "Show upcoming charges and their yearly cost",
+				setup: run(func([]string) error { return listRecurringExpenses(clock()) }),
+			},
+			{
+				name: "delete", args: "<id>", summary: "Stop a repeating charge", minArgs: 1,
+				setup: run(func(args []string) error {
+					id, err := parseID(args[0], "recurring expense")
+					if err != nil {
+						return err
+					}
+					return deleteRecurringExpense(id)
+				}),
+			},
+			{
+				name: "run", summary: "Record the charges that are due (run from cron)",
+				setup: run(func([]string) error {
+					n, err := chargeRecurringExpenses(clock())
+					if err == nil && n == 0 {
+						fmt.Fprintln(stdout, "No charges due.")
+					}
+					return err
+				}),
+			},
+		},
+	}
+}
+
+// loadRecurringExpenses reads the recurring expenses.
+func loadRecurringExpenses() ([]RecurringExpense, error) {
+	raw, err := loadDocument(recurringFile)
+	if err != nil || raw == nil {
+		return []RecurringExpense{}, err
+	}
+
+	var recurring []RecurringExpense
+	if err := json.Unmarshal(raw, &recurring); err != nil {
+		return nil, fmt.Errorf("error unmarshalling JSON: %w", err)
+	}
+	return recurring, nil
+}
+
+// addRecurringExpense saves a new recurring expense and records the
+// charges already due.
+func addRecurringExpense(r RecurringExpense) error {
+	recurring, err := loadRecurringExpenses()
+	if err != nil {
+		return err
+	}
+	for _, existing := range recurring {
+		r.ID = max(r.ID, existing.ID)
+	}
+	r.ID++
+	r.CreatedAt = clock()
+	if err := saveDocument(recurringFile, append(recurring, r)); err != nil {
+		return err
+	}
+
+	printDone("Recurring expense added successfully (ID: %d)\n", r.ID)
+	_, err = chargeRecurringExpenses(clock())
+	return err
+}
+
+// deleteRecurringExpense stops a recurring expense. The expenses already
+// recorded for it are kept.
+func deleteRecurringExpense(id int) error {
+	recurring, err := loadRecurringExpenses()
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(recurring, func(r RecurringExpense) bool { return r.ID == id })
+	if i < 0 {
+		return fmt.Errorf("recurring expense with ID %d %w", id, tracker.ErrNotFound)
+	}
+	if err := saveDocument(recurringFile, slices.Delete(recurring, i, i+1)); err != nil {
+		return err
+	}
+
+	printDone("Recurring expense ID %d deleted successfully\n", id)
+	return nil
+}
+
+// chargeRecurringExpenses records an expense for every charge due by now,
+// catching up on those missed since the last run, and returns how many it
+// recorded.
+func chargeRecurringExpenses(now time.Time) (int, error) {
+	recurring, err := loadRecurringExpenses()
+	if err != nil || len(recurring) == 0 {
+		return 0, err
+	}
+
+	charged := 0
+	for i := range recurring {
+		r := &recurring[i]
+		for !r.Next.After(now) && !r.ended() {
+			e := Expense{Date: r.Next, Amount: r.Amount, Currency: r.Currency, Description: r.Description, Category: r.Category, Payee: r.Payee}
+			if _, err := tr().AddExpense(e); err != nil {
+				return charged, err
+			}
+			fmt.Fprintf(stdout, "Recorded %s charge of %s for %s.\n", r.Description, formatAmount(r.Amount, r.Currency), r.Next.Format(dateLayout))
+			r.Next = tracker.NextOccurrence(r.Next, r.Recur)
+			charged++
+			// Saved after each charge, so an error part way through leaves the
+			// schedule in step with the expenses recorded.
+			if err := saveDocument(recurringFile, recurring); err != nil {
+				return charged, err
+			}
+		}
+	}
+	if slices.ContainsFunc(recurring, RecurringExpense.ended) {
+		if err := saveDocument(recurringFile, slices.DeleteFunc(recurring, RecurringExpense.ended)); err != nil {
+			return charged, err
+		}
+	}
+	return charged, nil
+}
+
+// ended reports whether r has made its last charge.
+func (r RecurringExpense) ended() bool {
+	return r.Until != nil && r.Next.After(*r.Until)
+}
+
+// listRecurringExpenses prints the recurring expenses by next charge, with
+// what each costs in a year, or until it ends within one.
+func listRecurringExpenses(now time.Time) error {
+	if _, err := chargeRecurringExpenses(now); err != nil {
+		return err
+	}
+	recurring, err := loadRecurringExpenses()
+	if err != nil {
+		return err
+	}
+	if len(recurring) == 0 {
+		fmt.Fprintln(stdout, "No recurring expenses.")
+		return nil
+	}
+
+	slices.SortStableFunc(recurring, func(a, b RecurringExpense) int { return a.Next.Compare(b.Next) })
+	yearly := map[string]float64{}
+	fmt.Fprintln(stdout, "--- Recurring Expenses ---")
+	for _, r := range recurring {
+		currency := r.Currency
+		if currency == "" {
+			currency = config.Currency.Base
+		}
+		cost := r.Amount * perYear[r.Recur]
+		if r.Until != nil && r.Until.Before(r.Next.AddDate(1, 0, 0)) {
+			// Only the charges left count
+			cost = 0
+			for next := r.Next; !next.After(*r.Until); next = tracker.NextOccurrence(next, r.Recur) {
+				cost += r.Amount
+			}
+		}
+		yearly[currency] += cost
+		fmt.Fprintf(stdout, "[ID: %d] %-20s %s %s, next %s (%s) | %s a year", r.ID, r.Description, formatAmount(r.Amount, r.Currency), r.Recur,
+			r.Next.Format(dateLayout), relativeDue(r.Next, now), formatAmount(cost, currency))
+		if r.Until != nil {
+			fmt.Fprintf(stdout, " until %s", r.Until.Format(dateLayout))
+		}
+		if r.Category != "" {
+			fmt.Fprintf(stdout, " [%s]", r.Category)
+		}
+		fmt.Fprintln(stdout)
+	}
+	fmt.Fprintf(stdout, "--- Yearly: %s ---\n", formatTotals(yearly))
+	return nil
+}
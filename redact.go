@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// dataExport is every record of a project, as 'task export --format data'
+// writes it.
+type dataExport struct {
+	Tasks    []Task    `json:"tasks"`
+	Archive  []Task    `json:"archive"`
+	Expenses []Expense `json:"expenses"`
+	Income   []Income  `json:"income"`
+}
+
+// loadDataExport reads the current project's tasks, archived tasks,
+// expenses and income.
+func loadDataExport() (dataExport, error) {
+	var data dataExport
+	var err error
+	t := tr()
+	if data.Tasks, err = t.Tasks(); err != nil {
+		return data, err
+	}
+	if data.Archive, err = t.ArchivedTasks(); err != nil {
+		return data, err
+	}
+	if data.Expenses, err = t.Expenses(); err != nil {
+		return data, err
+	}
+	data.Income, err = t.Income()
+	return data, err
+}
+
+// exportData writes the current project's records to w as one JSON
+// document, redacted if asked.
+func exportData(w io.Writer, redact bool) error {
+	data, err := loadDataExport()
+	if err != nil {
+		return err
+	}
+	if redact {
+		data = redactData(data)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(data); err != nil {
+		return fmt.Errorf("error marshalling JSON: %w", err)
+	}
+	return nil
+}
+
+// redactData masks the personal content of every record in data.
+func redactData(data dataExport) dataExport {
+	data.Tasks, data.Archive = redactTasks(data.Tasks), redactTasks(data.Archive)
+	data.Expenses = slices.Clone(data.Expenses)
+	for i := range data.Expenses {
+		e := &data.Expenses[i]
+		e.Description, e.Payee, e.Account, e.Amount = redactText(e.Description), redactText(e.Payee), redactText(e.Account), 0
+		e.Fields = redactFields(e.Fields)
+	}
+	data.Income = slices.Clone(data.Income)
+	for i := range data.Income {
+		in := &data.Income[i]
+		in.Description, in.Source, in.Account, in.Amount = redactText(in.Description), redactText(in.Source), redactText(in.Account), 0
+	}
+	return data
+}
+
+// redactTasks returns copies of tasks with what was written in them masked:
+// descriptions, checklists, URLs, tags, people and the values of the user's
+// fields. IDs, statuses,
+// priorities, dates and the shape of the text are kept, so a redacted
+// export still shows a bug.
+func redactTasks(tasks []Task) []Task {
+	redacted := make([]Task, len(tasks))
+	for i, task := range tasks {
+		task.Description = redactText(task.Description)
+		task.URL = redactText(task.URL)
+		task.Source = redactText(task.Source)
+		task.Assignee = redactText(task.Assignee)
+		task.Rotation = redactAll(task.Rotation)
+		task.Tags = redactAll(task.Tags)
+		task.Fields = redactFields(task.Fields)
+		task.Checklist = slices.Clone(task.Checklist)
+		for j := range task.Checklist {
+			task.Checklist[j].Text = redactText(task.Checklist[j].Text)
+		}
+		redacted[i] = task
+	}
+	return redacted
+}
+
+// redactAll masks each of texts.
+func redactAll(texts []string) []string {
+	if texts == nil {
+		return nil
+	}
+	redacted := make([]string, len(texts))
+	for i, s := range texts {
+		redacted[i] = redactText(s)
+	}
+	return redacted
+}
+
+// redactFields masks the values of the user's fields, keeping their names,
+// which describe the data rather than hold it.
+func redactFields(fields map[string]string) map[string]string {
+	if fields == nil {
+		return nil
+	}
+	redacted := make(map[string]string, len(fields))
+	for name, value := range fields {
+		redacted[name] = redactText(value)
+	}
+	return redacted
+}
+
+// redactText masks letters as x or X and digits as 9, keeping spaces and
+// punctuation so that the length and layout of the text survive.
+func redactText(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsUpper(r):
+			return 'X'
+		case unicode.IsLetter(r):
+			return 'x'
+		case unicode.IsDigit(r):
+			return '9'
+		}
+		return r
+	}, s)
+}
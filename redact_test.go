@@ -0,0 +1,22 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExportRedact(t *testing.T) {
+	out := setupCLI(t)
+	mustRunCLI(t, out, "add", "--field", "client=ACME", "Quarterly review")
+	mustRunCLI(t, out, "expense", "add", "--field", "client=ACME", "--payee", "Globex", "--account", "Checking", "120", "Hotel")
+
+	s := mustRunCLI(t, out, "export", "--format", "data", "--redact")
+	for _, text := range []string{"ACME", "Quarterly", "Globex", "Checking", "Hotel"} {
+		if strings.Contains(s, text) {
+			t.Errorf("redacted export holds %q:\n%s", text, s)
+		}
+	}
+	if !strings.Contains(s, `"client": "XXXX"`) {
+		t.Errorf("redacted export lost the field names:\n%s", s)
+	}
+}
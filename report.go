@@ -0,0 +1,110 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"time"
+)
+
+// reportCommand returns the report command group.
+func reportCommand() *command {
+	return &command{
+		name: "report", summary: "Show reports across projects", group: groupPlanning,
+		subcommands: []*command{
+			{
+				name: "procrastination", summary: "Show slow starts and postponed tasks across projects",
+				setup: run(func([]string) error { return reportProcrastination() }),
+			},
+			{
+				name: "workload", summary: "Show open tasks, estimated hours and overdue tasks per assignee",
+				setup: run(func([]string) error { return reportWorkload() }),
+			},
+			{
+				name: "balance", summary: "Show income, expenses and savings rate per month across projects",
+				setup: func(fs *flag.FlagSet) runFunc {
+					monthStr := fs.String("month", "", "only show this `month` (YYYY-MM, default the last 12 months)")
+					return func([]string) error {
+						var month *time.Time
+						if *monthStr != "" {
+							m, err := time.ParseInLocation(monthLayout, *monthStr, time.Local)
+							if err != nil {
+								return usagef("invalid month '%s'; use YYYY-MM", *monthStr)
+							}
+							month = &m
+						}
+						return reportBalance(month, clock())
+					}
+				},
+			},
+			{
+				name: "rollup", summary: "Total tracked time and linked expenses per tag or project and period across projects",
+				completeFlags: map[string]func() []candidate{
+					"by":     func() []candidate { return fixed(rollupByTag, rollupByProject)(nil) },
+					"period": func() []candidate { return fixed(reportWeek, reportMonth, rollupYear)(nil) },
+				},
+				setup: func(fs *flag.FlagSet) runFunc {
+					by := fs.String("by", rollupByTag, "roll up by `tag` or project")
+					period := fs.String("period", reportMonth, "`period` to total per: week, month or year")
+					since := fs.String("since", "", "only count time and expenses on or after this `date`")
+					until := fs.String("until", "", "only count time and expenses on or before this `date`")
+					return func([]string) error {
+						if *by != rollupByTag && *by != rollupByProject {
+							return usagef("invalid rollup '%s'; use tag or project", *by)
+						}
+						if *period != reportWeek && *period != reportMonth && *period != rollupYear {
+							return usagef("invalid period '%s'; use week, month or year", *period)
+						}
+						window, err := parseDateRange(*since, *until, clock())
+						if err != nil {
+							return usagef("%v", err)
+						}
+						return reportRollup(*by, *period, window)
+					}
+				},
+			},
+			{
+				name: "email", summary: "Email an HTML summary of last week's or month's tasks and expenses",
+				setup: func(fs *flag.FlagSet) runFunc {
+					to := fs.String("to", "", "comma-separated `addresses` to send to (default smtp.to in config.json)")
+					period := fs.String("period", reportWeek, "`period` to sum up: week (Monday to Sunday) or month, the last full one")
+					output := fs.String("output", "", "write the HTML to this `file` instead of sending it")
+					return func([]string) error {
+						if *period != reportWeek && *period != reportMonth {
+							return usagef("invalid period '%s'; use week or month", *period)
+						}
+						if *output != "" {
+							return writeEmailReport(*output, *period, clock())
+						}
+						return emailReportTo(*to, *period, clock())
+					}
+				},
+			},
+			{
+				name: "generate", summary: "Write a shareable HTML or Markdown report of last week's or month's tasks and money, with charts",
+				completeFlags: map[string]func() []candidate{
+					"format": func() []candidate { return fixed(formatHTML, formatMarkdown)(nil) },
+					"period": func() []candidate { return fixed(reportWeek, reportMonth)(nil) },
+				},
+				setup: func(fs *flag.FlagSet) runFunc {
+					format := fs.String("format", formatHTML, "`format` to write: html or md")
+					period := fs.String("period", reportMonth, "`period` to sum up: week (Monday to Sunday) or month, the last full one")
+					out := fs.String("out", "", "write the report to this `file` instead of stdout")
+					return func([]string) error {
+						if *format != formatHTML && *format != formatMarkdown {
+							return usagef("invalid format '%s'; use html or md", *format)
+						}
+						if *period != reportWeek && *period != reportMonth {
+							return usagef("invalid period '%s'; use week or month", *period)
+						}
+						err := writeOutput(*out, func(w io.Writer) error { return generateReport(w, *format, *period, clock()) })
+						if err == nil && *out != "" {
+							fmt.Fprintf(stdout, "Report written to %s\n", *out)
+						}
+						return err
+					}
+				},
+			},
+		},
+	}
+}
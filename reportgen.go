@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+// Formats 'task report generate' writes.
+const (
+	formatHTML     = "html"
+	formatMarkdown = "md"
+)
+
+const chartColor = "#4a7bd0"
+
+// generatedReport is what a generated report is made of, whichever the
+// format.
+type generatedReport struct {
+	Title    string
+	Tasks    []reportRow // Completion figures, one value each.
+	Daily    template.HTML
+	Spending template.HTML
+	Expenses []reportRow
+	Total    string
+	Budget   []reportRow // Income against spending, one value each.
+	Over     bool
+	Projects []reportRow
+}
+
+// chartBar is a labelled value in a chart.
+type chartBar struct {
+	Label string
+	Value float64
+}
+
+// buildReport gathers the last full week or month across projects: how
+// many tasks were completed and deadlines met, what was spent by category
+// and how spending compares to income. There are no budgets to hold
+// spending against, so income is the budget.
+func buildReport(period string, now time.Time) (generatedReport, error) {
+	start, end := reportPeriod(period, now)
+	a, err := collectActivity(start, end, now)
+	if err != nil {
+		return generatedReport{}, err
+	}
+
+	r := generatedReport{Title: "Week of " + start.Format("2 January 2006")}
+	if period == reportMonth {
+		r.Title = start.Format("January 2006")
+	}
+
+	overdue, focus := 0, 0
+	for _, t := range a.open {
+		if t.Due != nil && now.After(tracker.Deadline(*t.Due)) {
+			overdue++
+		}
+	}
+	for _, p := range a.projects {
+		focus += p.FocusMinutes
+	}
+	met := "-"
+	if len(a.due) > 0 {
+		onTime := len(a.due) - len(a.slipped)
+		met = fmt.Sprintf("%d of %d (%d%%)", onTime, len(a.due), onTime*100/len(a.due))
+	}
+	r.Tasks = []reportRow{
+		{"Completed", []string{strconv.Itoa(len(a.completed))}},
+		{"Deadlines met", []string{met}},
+		{"Focus time", []string{fmt.Sprintf("%dh%02dm", focus/60, focus%60)}},
+		{"Open now", []string{strconv.Itoa(len(a.open))}},
+		{"Overdue now", []string{strconv.Itoa(overdue)}},
+	}
+
+	var daily, spent []chartBar
+	perDay := map[string]int{}
+	for _, t := range a.completed {
+		perDay[t.CompletedAt.Format(dateLayout)]++
+	}
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		label := day.Format("2")
+		if period == reportWeek {
+			label = day.Format("Mon")
+		}
+		daily = append(daily, chartBar{label, float64(perDay[day.Format(dateLayout)])})
+	}
+	r.Daily = template.HTML(columnsSVG(daily))
+
+	total := 0.0
+	for _, category := range sortedKeys(a.byCategory) {
+		amount := a.byCategory[category]
+		r.Expenses = append(r.Expenses, reportRow{category, []string{formatAmount(amount, "")}})
+		spent = append(spent, chartBar{category, amount})
+		total += amount
+	}
+	r.Total = formatAmount(total, "")
+	if len(spent) > 0 {
+		r.Spending = template.HTML(barsSVG(spent, func(v float64) string { return formatAmount(v, "") }))
+	}
+
+	rate := "-"
+	if a.earned > 0 {
+		rate = fmt.Sprintf("%.0f%%", (a.earned-total)/a.earned*100)
+	}
+	r.Over = total > a.earned
+	r.Budget = []reportRow{
+		{"Income", []string{formatAmount(a.earned, "")}},
+		{"Spent", []string{formatAmount(total, "")}},
+		{"Left over", []string{formatAmount(a.earned-total, "")}},
+		{"Savings rate", []string{rate}},
+	}
+
+	for _, p := range a.projects {
+		r.Projects = append(r.Projects, reportRow{p.Name, []string{
+			strconv.Itoa(p.Completed),
+			fmt.Sprintf("%dh%02dm", p.FocusMinutes/60, p.FocusMinutes%60),
+			formatAmount(p.Spent, ""),
+		}})
+	}
+	return r, nil
+}
+
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Report: {{.Title}}</title>
+<style>
+body { font-family: -apple-system, Segoe UI, Helvetica, Arial, sans-serif; color: #222; max-width: 720px; margin: 2em auto; padding: 0 1em; }
+h1 { font-size: 22px; } h2 { font-size: 17px; margin-top: 1.6em; }
+table { border-collapse: collapse; } td, th { padding: 4px 10px; } th { text-align: left; }
+td.n { text-align: right; } .muted { color: #777; } .over { color: #c0392b; } .under { color: #27ae60; }
+</style></head>
+<body>
+<h1>Report: {{.Title}}</h1>
+<h2>Tasks</h2>
+<table>{{range .Tasks}}<tr><td>{{.Label}}</td>{{range .Values}}<td class="n">{{.}}</td>{{end}}</tr>{{end}}</table>
+<h3>Completed per day</h3>
+{{.Daily}}
+<h2>Expenses</h2>
+{{if .Expenses}}{{.Spending}}
+<table>{{range .Expenses}}<tr><td>{{.Label}}</td>{{range .Values}}<td class="n">{{.}}</td>{{end}}</tr>{{end}}
+<tr><td><strong>Total</strong></td><td class="n"><strong>{{.Total}}</strong></td></tr></table>
+{{else}}<p class="muted">No expenses were recorded.</p>{{end}}
+<h2>Budget</h2>
+<table>{{range .Budget}}<tr><td>{{.Label}}</td>{{range .Values}}<td class="n">{{.}}</td>{{end}}</tr>{{end}}</table>
+{{if .Over}}<p class="over">Spending was more than income.</p>{{else}}<p class="under">Spending was within income.</p>{{end}}
+<h2>Projects</h2>
+<table><tr><th>Project</th><th>Completed</th><th>Focus time</th><th>Spent</th></tr>
+{{range .Projects}}<tr><td>{{.Label}}</td>{{range .Values}}<td class="n">{{.}}</td>{{end}}</tr>{{end}}</table>
+</body></html>
+`))
+
+// generateReport writes the report for the last full week or month as an
+// HTML page or a Markdown document, with its charts as SVG: inline in HTML,
+// and as embedded images in Markdown.
+func generateReport(w io.Writer, format, period string, now time.Time) error {
+	r, err := buildReport(period, now)
+	if err != nil {
+		return err
+	}
+	if format == formatHTML {
+		return reportTemplate.Execute(w, r)
+	}
+
+	bw := bufio.NewWriter(w)
+	table := func(rows []reportRow, header ...string) {
+		fmt.Fprintf(bw, "| %s |\n|---%s|\n", strings.Join(header, " | "), strings.Repeat("|---:", len(header)-1))
+		for _, row := range rows {
+			fmt.Fprintf(bw, "| %s | %s |\n", row.Label, strings.Join(row.Values, " | "))
+		}
+	}
+	image := func(alt, svg string) {
+		fmt.Fprintf(bw, "![%s](data:image/svg+xml;base64,%s)\n\n", alt, base64.StdEncoding.EncodeToString([]byte(svg)))
+	}
+
+	fmt.Fprintf(bw, "# Report: %s\n\n", r.Title)
+	fmt.Fprintln(bw, "## Tasks")
+	fmt.Fprintln(bw)
+	table(r.Tasks, "Tasks", "")
+	fmt.Fprintln(bw)
+	image("Completed per day", string(r.Daily))
+
+	fmt.Fprintln(bw, "## Expenses")
+	fmt.Fprintln(bw)
+	if len(r.Expenses) == 0 {
+		fmt.Fprintln(bw, "No expenses were recorded.")
+	} else {
+		image("Spending by category", string(r.Spending))
+		table(r.Expenses, "Category", "Amount")
+		fmt.Fprintf(bw, "| **Total** | **%s** |\n", r.Total)
+	}
+	fmt.Fprintln(bw)
+
+	fmt.Fprintln(bw, "## Budget")
+	fmt.Fprintln(bw)
+	table(r.Budget, "Money", "")
+	fmt.Fprintln(bw)
+	if r.Over {
+		fmt.Fprintln(bw, "Spending was more than income.")
+	} else {
+		fmt.Fprintln(bw, "Spending was within income.")
+	}
+	fmt.Fprintln(bw)
+
+	fmt.Fprintln(bw, "## Projects")
+	fmt.Fprintln(bw)
+	table(r.Projects, "Project", "Completed", "Focus time", "Spent")
+	return bw.Flush()
+}
+
+// barsSVG draws a horizontal bar per value, labelled on the left and with
+// the value, as value formats it, at its end.
+func barsSVG(bars []chartBar, value func(float64) string) string {
+	const width, labelWidth, barWidth, row = 640, 160, 360, 24
+	peak := 0.0
+	for _, b := range bars {
+		peak = max(peak, b.Value)
+	}
+	var s strings.Builder
+	fmt.Fprintf(&s, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="sans-serif" font-size="12">`, width, row*len(bars))
+	for i, b := range bars {
+		y, w := i*row, 0.0
+		if peak > 0 {
+			w = max(0, b.Value) / peak * barWidth
+		}
+		fmt.Fprintf(&s, `<text x="%d" y="%d" text-anchor="end">%s</text>`, labelWidth-8, y+16, template.HTMLEscapeString(b.Label))
+		fmt.Fprintf(&s, `<rect x="%d" y="%d" width="%.1f" height="%d" fill="%s"/>`, labelWidth, y+4, w, row-8, chartColor)
+		fmt.Fprintf(&s, `<text x="%.1f" y="%d">%s</text>`, labelWidth+w+6, y+16, template.HTMLEscapeString(value(b.Value)))
+	}
+	s.WriteString("</svg>")
+	return s.String()
+}
+
+// columnsSVG draws an upright column per value, labelled underneath, with
+// the values above the columns that are not empty.
+func columnsSVG(bars []chartBar) string {
+	const top, plot, column = 14, 100, 20
+	peak := 0.0
+	for _, b := range bars {
+		peak = max(peak, b.Value)
+	}
+	var s strings.Builder
+	fmt.Fprintf(&s, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="sans-serif" font-size="10" text-anchor="middle">`, column*len(bars), top+plot+18)
+	fmt.Fprintf(&s, `<line x1="0" y1="%d" x2="%d" y2="%d" stroke="#ccc"/>`, top+plot, column*len(bars), top+plot)
+	for i, b := range bars {
+		x, h := i*column, 0.0
+		if peak > 0 {
+			h = b.Value / peak * plot
+		}
+		if b.Value > 0 {
+			fmt.Fprintf(&s, `<rect x="%d" y="%.1f" width="%d" height="%.1f" fill="%s"/>`, x+3, top+plot-h, column-6, h, chartColor)
+			fmt.Fprintf(&s, `<text x="%d" y="%.1f">%s</text>`, x+column/2, top+plot-h-3, strconv.FormatFloat(b.Value, 'f', -1, 64))
+		}
+		fmt.Fprintf(&s, `<text x="%d" y="%d">%s</text>`, x+column/2, top+plot+13, template.HTMLEscapeString(b.Label))
+	}
+	s.WriteString("</svg>")
+	return s.String()
+}
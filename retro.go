@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+const monthLayout = "2006-01" // Layout for --month values.
+
+// retroCommand returns the retro command.
+func retroCommand() *command {
+	return &command{
+		name: "retro", summary: "Write a Markdown monthly retrospective across projects", group: groupPlanning,
+		setup: func(fs *flag.FlagSet) runFunc {
+			monthStr := fs.String("month", "", "`month` to review (YYYY-MM, default last month)")
+			output := fs.String("output", "", "write the Markdown to this `file` instead of stdout")
+			return func([]string) error {
+				month := clock().AddDate(0, -1, 0)
+				if *monthStr != "" {
+					var err error
+					month, err = time.ParseInLocation(monthLayout, *monthStr, time.Local)
+					if err != nil {
+						return usagef("invalid month '%s'; use YYYY-MM", *monthStr)
+					}
+				}
+				err := writeOutput(*output, func(w io.Writer) error { return writeRetro(w, month) })
+				if err == nil && *output != "" {
+					fmt.Fprintf(stdout, "Retrospective written to %s\n", *output)
+				}
+				return err
+			}
+		},
+	}
+}
+
+// retroProject collects one project's activity for a retrospective.
+type retroProject struct {
+	Name         string
+	Completed    int
+	FocusMinutes int
+	Pomodoros    int
+	Spent        float64
+}
+
+// activity is what happened across projects in a period, as retrospectives
+// and reports tell it.
+type activity struct {
+	completed  []projectTask // Completed in the period.
+	due        []projectTask // Due in the period.
+	slipped    []projectTask // Due in the period, and late or still open.
+	open       []projectTask // Not done, whenever due.
+	projects   []retroProject
+	byCategory map[string]float64 // Spent in the period.
+	earned     float64            // Income received in the period.
+}
+
+// collectActivity gathers the activity of every project in [start, end).
+func collectActivity(start, end, now time.Time) (activity, error) {
+	inPeriod := func(t time.Time) bool { return !t.Before(start) && t.Before(end) }
+	a := activity{byCategory: map[string]float64{}}
+	err := forEachProject(func(project string) error {
+		tasks, err := loadTasks()
+		if err != nil {
+			return err
+		}
+		// Tasks completed in the period may have been archived since.
+		archived, err := tr().ArchivedTasks()
+		if err != nil {
+			return err
+		}
+		tasks = append(tasks, archived...)
+		expenses, err := loadExpenses()
+		if err != nil {
+			return err
+		}
+		income, err := tr().Income()
+		if err != nil {
+			return err
+		}
+
+		summary := retroProject{Name: project}
+		for _, task := range tasks {
+			if done := task.CompletedAt; done != nil && inPeriod(*done) {
+				a.completed = append(a.completed, projectTask{Task: task, Project: project})
+				summary.Completed++
+			}
+			if task.Due != nil && inPeriod(*task.Due) {
+				a.due = append(a.due, projectTask{Task: task, Project: project})
+			}
+			if isSlipped(task, start, end, now) {
+				a.slipped = append(a.slipped, projectTask{Task: task, Project: project})
+			}
+			if !config.Workflow.IsDone(task.Status) && task.ArchivedAt == nil {
+				a.open = append(a.open, projectTask{Task: task, Project: project})
+			}
+			for _, p := range task.Pomodoros {
+				if inPeriod(p.Start) {
+					summary.Pomodoros++
+					summary.FocusMinutes += p.Minutes
+				}
+			}
+		}
+		for _, expense := range expenses {
+			if inPeriod(expense.Date) {
+				summary.Spent += expense.Amount
+				category := expense.Category
+				if category == "" {
+					category = "uncategorized"
+				}
+				a.byCategory[category] += expense.Amount
+			}
+		}
+		for _, in := range income {
+			if inPeriod(in.Date) {
+				a.earned += in.Amount
+			}
+		}
+		a.projects = append(a.projects, summary)
+		return nil
+	})
+	return a, err
+}
+
+// writeRetro writes a Markdown retrospective for the month starting at
+// month, covering every project: completed highlights, slipped deadlines,
+// focus time by project and expense totals.
+func writeRetro(w io.Writer, month time.Time) error {
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.Local)
+	a, err := collectActivity(start, start.AddDate(0, 1, 0), clock())
+	if err != nil {
+		return err
+	}
+	completed, slipped, projects, byCategory := a.completed, a.slipped, a.projects, a.byCategory
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# Retrospective: %s\n\n", start.Format("January 2006"))
+
+	fmt.Fprintln(bw, "## Highlights")
+	fmt.Fprintln(bw)
+	if len(completed) == 0 {
+		fmt.Fprintln(bw, "No tasks were completed this month.")
+	} else {
+		sort.SliceStable(completed, func(i, j int) bool {
+			pi, pj := priorityRank(completed[i].Priority), priorityRank(completed[j].Priority)
+			if pi != pj {
+				return pi > pj
+			}
+			return completed[i].CompletedAt.Before(*completed[j].CompletedAt)
+		})
+		fmt.Fprintf(bw, "Completed %d task(s).\n\n", len(completed))
+		for _, t := range completed {
+			fmt.Fprintf(bw, "- %s (%s, %s)\n", retroTitle(t), t.CompletedAt.Format(dateLayout), t.Project)
+		}
+	}
+	fmt.Fprintln(bw)
+
+	fmt.Fprintln(bw, "## Slipped deadlines")
+	fmt.Fprintln(bw)
+	if len(slipped) == 0 {
+		fmt.Fprintln(bw, "Every deadline this month was met.")
+	} else {
+		sort.SliceStable(slipped, func(i, j int) bool { return slipped[i].Due.Before(*slipped[j].Due) })
+		for _, t := range slipped {
+			outcome := "still open"
+			if done := t.CompletedAt; done != nil {
+				outcome = "done " + formatDays(done.Sub(tracker.Deadline(*t.Due))) + " late"
+			}
+			if t.Postponed > 0 {
+				outcome += fmt.Sprintf(", postponed %dx", t.Postponed)
+			}
+			fmt.Fprintf(bw, "- %s (due %s, %s; %s)\n", t.Description, formatDue(*t.Due), outcome, t.Project)
+		}
+	}
+	fmt.Fprintln(bw)
+
+	totalMinutes, totalSpent := 0, 0.0
+	for _, p := range projects {
+		totalMinutes += p.FocusMinutes
+		totalSpent += p.Spent
+	}
+
+	fmt.Fprintln(bw, "## Time by project")
+	fmt.Fprintln(bw)
+	fmt.Fprintln(bw, "| Project | Focus time | Share | Pomodoros | Completed | Spent |")
+	fmt.Fprintln(bw, "|---|---:|---:|---:|---:|---:|")
+	for _, p := range projects {
+		share := 0
+		if totalMinutes > 0 {
+			share = p.FocusMinutes * 100 / totalMinutes
+		}
+		fmt.Fprintf(bw, "| %s | %dh%02dm | %d%% | %d | %d | %.2f |\n",
+			p.Name, p.FocusMinutes/60, p.FocusMinutes%60, share, p.Pomodoros, p.Completed, p.Spent)
+	}
+	fmt.Fprintln(bw)
+
+	fmt.Fprintln(bw, "## Expenses")
+	fmt.Fprintln(bw)
+	if len(byCategory) == 0 {
+		fmt.Fprintln(bw, "No expenses were recorded this month.")
+	} else {
+		fmt.Fprintln(bw, "| Category | Amount |")
+		fmt.Fprintln(bw, "|---|---:|")
+		for _, category := range sortedKeys(byCategory) {
+			fmt.Fprintf(bw, "| %s | %.2f |\n", category, byCategory[category])
+		}
+		fmt.Fprintf(bw, "| **Total** | **%.2f** |\n", totalSpent)
+	}
+
+	return bw.Flush()
+}
+
+// isSlipped reports whether a task due in [start, end) was completed after
+// its due date or is still open past it.
+func isSlipped(task Task, start, end, now time.Time) bool {
+	if task.Due == nil || task.Due.Before(start) || !task.Due.Before(end) {
+		return false
+	}
+	deadline := tracker.Deadline(*task.Due)
+	if done := task.CompletedAt; done != nil {
+		return done.After(deadline)
+	}
+	return now.After(deadline)
+}
+
+// priorityRank orders priorities from none (0) to high (3).
+func priorityRank(priority string) int {
+	switch priority {
+	case tracker.PriorityHigh:
+		return 3
+	case tracker.PriorityMedium:
+		return 2
+	case tracker.PriorityLow:
+		return 1
+	}
+	return 0
+}
+
+// retroTitle renders a completed task, emphasising high priority ones.
+func retroTitle(t projectTask) string {
+	title := t.Description
+	if len(t.Tags) > 0 {
+		title += " " + formatTags(t.Tags)
+	}
+	if t.Priority == tracker.PriorityHigh {
+		return "**" + title + "**"
+	}
+	return title
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+// reviewCommand returns the review command.
+func reviewCommand() *command {
+	return &command{
+		name: "review", summary: "Walk through overdue, stale and undated tasks one by one", group: groupPlanning,
+		setup: func(fs *flag.FlagSet) runFunc {
+			stale := fs.String("stale", "7d", "review tasks not updated for this long, e.g. 7d, 2w or 48h")
+			return func(args []string) error {
+				if len(args) > 0 {
+					return usagef("too many arguments")
+				}
+				age, err := parseAge(*stale)
+				if err != nil {
+					return usagef("%v", err)
+				}
+				return reviewTasks(age, clock())
+			}
+		},
+	}
+}
+
+// reviewReasons returns why an open task needs reviewing: it is overdue,
+// has not been updated for stale, or has no due date.
+func reviewReasons(task Task, stale time.Duration, now time.Time) []string {
+	var reasons []string
+	if task.Due != nil && now.After(tracker.Deadline(*task.Due)) {
+		reasons = append(reasons, "overdue since "+formatDue(*task.Due))
+	}
+	if now.Sub(task.UpdatedAt) >= stale {
+		reasons = append(reasons, "last updated "+relativeTime(task.UpdatedAt, now))
+	}
+	if task.Due == nil {
+		reasons = append(reasons, "no due date")
+	}
+	return reasons
+}
+
+// reviewTasks asks what to do with each open task that needs reviewing,
+// overdue tasks first, until all are reviewed or the user quits.
+func reviewTasks(stale time.Duration, now time.Time) error {
+	tasks, err := loadTasks()
+	if err != nil {
+		return err
+	}
+
+	var overdue, others []Task
+	for _, task := range tasks {
+		if config.Workflow.IsDone(task.Status) {
+			continue
+		}
+		switch {
+		case task.Due != nil && now.After(tracker.Deadline(*task.Due)):
+			overdue = append(overdue, task)
+		case len(reviewReasons(task, stale, now)) > 0:
+			others = append(others, task)
+		}
+	}
+	queue := append(overdue, others...)
+	if len(queue) == 0 {
+		fmt.Fprintln(stdout, "Nothing to review: no overdue, stale or undated tasks.")
+		return nil
+	}
+
+	fmt.Fprintf(stdout, "--- Weekly Review: %s ---\n", plural(len(queue), "task"))
+	counts := map[string]int{}
+	for i, task := range queue {
+		fmt.Fprintf(stdout, "\n[%d/%d] #%d %s\n", i+1, len(queue), task.ID, task.Description)
+		fmt.Fprintf(stdout, "  %s\n", strings.Join(reviewReasons(task, stale, now), ", "))
+
+		action, err := reviewTask(task, now)
+		if errors.Is(err, io.EOF) || action == "quit" {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		counts[action]++
+	}
+
+	fmt.Fprintf(stdout, "\nReviewed %s: %d kept, %d rescheduled, %d done, %d deleted.\n",
+		plural(counts["keep"]+counts["reschedule"]+counts["done"]+counts["delete"], "task"),
+		counts["keep"], counts["reschedule"], counts["done"], counts["delete"])
+	return nil
+}
+
+// reviewTask prompts for what to do with a task until it is done, and
+// returns the action taken: keep, reschedule, done, delete or quit.
+func reviewTask(task Task, now time.Time) (string, error) {
+	for {
+		answer, err := readLine("  [k]eep, [r]eschedule, [d]one, [x] delete or [q]uit? ")
+		if err != nil {
+			return "", err
+		}
+
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "k", "keep", "":
+			return "keep", nil
+		case "r", "reschedule":
+			input, err := readLine("  New due date: ")
+			if err != nil {
+				return "", err
+			}
+			due, err := parseDate(strings.TrimSpace(input), now)
+			if err != nil {
+				fmt.Fprintf(stdout, "  %v\n", err)
+				continue
+			}
+			if err := updateTaskDue(task.ID, due); err != nil {
+				return "", err
+			}
+			fmt.Fprintf(stdout, "  Due %s.\n", formatDue(due))
+			return "reschedule", nil
+		case "d", "done":
+			if err := updateTaskStatus(task.ID, config.Workflow.DoneStatus()); err != nil {
+				fmt.Fprintf(stdout, "  %v\n", err)
+				continue
+			}
+			fmt.Fprintln(stdout, "  Marked done.")
+			return "done", nil
+		case "x", "delete":
+			if err := tr().DeleteTask(task.ID); err != nil {
+				return "", err
+			}
+			fmt.Fprintln(stdout, "  Deleted.")
+			return "delete", nil
+		case "q", "quit":
+			return "quit", nil
+		default:
+			fmt.Fprintln(stdout, "  Please answer k, r, d, x or q.")
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+const (
+	rollupByTag     = "tag"
+	rollupByProject = "project"
+	rollupYear      = "year"
+	rollupUntagged  = "(untagged)" // Where tasks without tags are rolled up.
+)
+
+// rollupTotal is the time tracked on the tasks of a tag or project in a
+// period, and the expenses linked to them by currency, "" being the base
+// currency.
+type rollupTotal struct {
+	minutes int
+	spent   map[string]float64
+	tasks   map[string]bool // UUIDs of the tasks with time or expenses.
+}
+
+// rollupPeriod returns the label of the period t falls in: the Monday of
+// its week, its month or its year.
+func rollupPeriod(t time.Time, period string) string {
+	switch period {
+	case reportWeek:
+		return startOfWeek(t).Format(dateLayout)
+	case rollupYear:
+		return t.Format("2006")
+	}
+	return t.Format(monthLayout)
+}
+
+// reportRollup prints, per period and per tag or project, the focus time
+// of the pomodoros on the tasks across projects and the expenses linked to
+// them, in the base currency, with the totals of each tag or project over
+// all periods. Archived tasks count too. Time falls in the period it was
+// tracked in and an expense in the period it was made in; a task with
+// several tags counts under each of them.
+func reportRollup(by, period string, window DateRange) error {
+	inWindow := func(t time.Time) bool {
+		return (window.Since == nil || !t.Before(*window.Since)) && (window.Until == nil || !t.After(*window.Until))
+	}
+	totals := map[string]map[string]*rollupTotal{} // By period, then key.
+	total := func(period, key string) *rollupTotal {
+		if totals[period] == nil {
+			totals[period] = map[string]*rollupTotal{}
+		}
+		if totals[period][key] == nil {
+			totals[period][key] = &rollupTotal{spent: map[string]float64{}, tasks: map[string]bool{}}
+		}
+		return totals[period][key]
+	}
+	keys := func(task Task, project string) []string {
+		switch {
+		case by == rollupByProject:
+			return []string{project}
+		case len(task.Tags) == 0:
+			return []string{rollupUntagged}
+		}
+		return task.Tags
+	}
+
+	mixed := false
+	err := forEachProject(func(project string) error {
+		tasks, err := loadTasks()
+		if err != nil {
+			return err
+		}
+		archived, err := tr().ArchivedTasks()
+		if err != nil {
+			return err
+		}
+		tasks = append(tasks, archived...)
+		expenses, err := loadExpenses()
+		if err != nil {
+			return err
+		}
+
+		byUUID := map[string]Task{}
+		for _, task := range tasks {
+			if task.UUID != "" {
+				byUUID[task.UUID] = task
+			}
+			for _, p := range task.Pomodoros {
+				if !inWindow(p.Start) {
+					continue
+				}
+				for _, key := range keys(task, project) {
+					t := total(rollupPeriod(p.Start, period), key)
+					t.minutes += p.Minutes
+					t.tasks[task.UUID] = true
+				}
+			}
+		}
+		for _, e := range expenses {
+			task, ok := byUUID[e.Task]
+			if e.Task == "" || !ok || !inWindow(e.Date) {
+				continue
+			}
+			for _, key := range keys(task, project) {
+				t := total(rollupPeriod(e.Date, period), key)
+				t.spent[e.Currency] += e.Amount
+				t.tasks[task.UUID] = true
+			}
+			mixed = mixed || e.Currency != "" && e.Currency != config.Currency.Base
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(totals) == 0 {
+		fmt.Fprintln(stdout, "No tracked time or linked expenses found.")
+		return nil
+	}
+
+	base := config.Currency.Base
+	rates := tracker.Rates{Base: base}
+	if mixed {
+		if base == "" {
+			return fmt.Errorf("set currency.base in %s to total money in different currencies", configFile)
+		}
+		if rates, err = config.Currency.rateProvider().Rates(); err != nil {
+			return err
+		}
+	}
+
+	title := "Rollup by " + by + " per " + period
+	if base != "" {
+		title += " in " + base
+	}
+	column := "Tag"
+	if by == rollupByProject {
+		column = "Project"
+	}
+	fmt.Fprintf(stdout, "--- %s ---\n", title)
+	fmt.Fprintf(stdout, "  %-10s %-20s %5s %9s %12s\n", "Period", column, "Tasks", "Time", "Spent")
+	overall := map[string]*rollupTotal{}
+	for _, p := range sortedKeys(totals) {
+		label := p
+		for _, key := range sortedKeys(totals[p]) {
+			t := totals[p][key]
+			if err := printRollupRow(label, key, t, rates); err != nil {
+				return err
+			}
+			label = ""
+
+			sum := overall[key]
+			if sum == nil {
+				sum = &rollupTotal{spent: map[string]float64{}, tasks: map[string]bool{}}
+				overall[key] = sum
+			}
+			sum.minutes += t.minutes
+			for code, amount := range t.spent {
+				sum.spent[code] += amount
+			}
+			for uuid := range t.tasks {
+				sum.tasks[uuid] = true
+			}
+		}
+	}
+	if len(totals) > 1 {
+		// The totals come busiest first, by time and then by money.
+		names := sortedKeys(overall)
+		spent := map[string]float64{}
+		for _, key := range names {
+			spent[key], _ = rates.ConvertTotals(overall[key].spent, base, base)
+		}
+		slices.SortStableFunc(names, func(a, b string) int {
+			return cmp.Or(cmp.Compare(overall[b].minutes, overall[a].minutes), cmp.Compare(spent[b], spent[a]))
+		})
+		label := "Total"
+		for _, key := range names {
+			if err := printRollupRow(label, key, overall[key], rates); err != nil {
+				return err
+			}
+			label = ""
+		}
+	}
+	fmt.Fprintln(stdout, "----------------")
+	if !rates.AsOf.IsZero() {
+		fmt.Fprintf(stdout, "Rates as of %s.\n", rates.AsOf.Format(dateLayout))
+	}
+	return nil
+}
+
+// printRollupRow prints a line of the rollup report, with the expenses
+// converted to the base currency.
+func printRollupRow(period, key string, t *rollupTotal, rates tracker.Rates) error {
+	spent, err := rates.ConvertTotals(t.spent, config.Currency.Base, config.Currency.Base)
+	if err != nil {
+		return err
+	}
+	focus := fmt.Sprintf("%dh%02dm", t.minutes/60, t.minutes%60)
+	fmt.Fprintf(stdout, "  %-10s %-20s %5d %9s %12.2f\n", period, key, len(t.tasks), focus, spent)
+	return nil
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReportRollup(t *testing.T) {
+	out := setupCLI(t)
+	mustRunCLI(t, out, "add", "Paint the fence")
+	mustRunCLI(t, out, "tag", "1", "+home")
+	mustRunCLI(t, out, "expense", "add", "--task", "1", "30", "Paint")
+	mustRunCLI(t, out, "expense", "add", "12", "Lunch") // Not on a task, so not rolled up.
+
+	s := mustRunCLI(t, out, "report", "rollup")
+	if !strings.Contains(s, "2025-03") || !strings.Contains(s, "home") || !strings.Contains(s, "30.00") {
+		t.Errorf("report rollup:\n%s", s)
+	}
+	if strings.Contains(s, "42.00") {
+		t.Errorf("report rollup counted an expense not on a task:\n%s", s)
+	}
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Fields a category rule can match.
+const (
+	ruleFieldPayee       = "payee"
+	ruleFieldDescription = "description"
+)
+
+// CategoryRule assigns a category to expenses whose payee or description
+// contains some text, ignoring case.
+type CategoryRule struct {
+	Field    string `json:"field,omitempty"` // "payee", "description", or either if unset.
+	Contains string `json:"contains"`
+	Category string `json:"category"`
+}
+
+// validateCategoryRules checks that each rule matches something and sets a
+// category.
+func validateCategoryRules(rules []CategoryRule) error {
+	for i, r := range rules {
+		if r.Field != "" && r.Field != ruleFieldPayee && r.Field != ruleFieldDescription {
+			return fmt.Errorf("rule %d: invalid field '%s'; use payee or description", i+1, r.Field)
+		}
+		if strings.TrimSpace(r.Contains) == "" || strings.TrimSpace(r.Category) == "" {
+			return fmt.Errorf("rule %d: contains and category must be set", i+1)
+		}
+	}
+	return nil
+}
+
+// matches reports whether the rule applies to an expense's payee and
+// description.
+func (r CategoryRule) matches(payee, description string) bool {
+	text := strings.ToLower(r.Contains)
+	inPayee := strings.Contains(strings.ToLower(payee), text)
+	inDescription := strings.Contains(strings.ToLower(description), text)
+	switch r.Field {
+	case ruleFieldPayee:
+		return inPayee
+	case ruleFieldDescription:
+		return inDescription
+	}
+	return inPayee || inDescription
+}
+
+// String renders the rule as it is listed.
+func (r CategoryRule) String() string {
+	return fmt.Sprintf("%s contains '%s' → %s", ruleFieldName(r.Field), r.Contains, r.Category)
+}
+
+// ruleFieldName names the field a rule matches.
+func ruleFieldName(field string) string {
+	if field == "" {
+		return "payee or description"
+	}
+	return field
+}
+
+// matchCategoryRule returns the index of the first configured rule that
+// matches, or -1.
+func matchCategoryRule(payee, description string) int {
+	for i, r := range config.CategoryRules {
+		if r.matches(payee, description) {
+			return i
+		}
+	}
+	return -1
+}
+
+// applyCategoryRules sets the category of an uncategorized expense from the
+// first matching rule.
+func applyCategoryRules(e *Expense) {
+	if e.Category != "" {
+		return
+	}
+	if i := matchCategoryRule(e.Payee, e.Description); i >= 0 {
+		e.Category = config.CategoryRules[i].Category
+	}
+}
+
+// categoryRulesCommand returns the expense rules command group.
+func categoryRulesCommand() *command {
+	return &command{
+		name: "rules", summary: "Categorize expenses automatically by payee or description",
+		subcommands: []*command{
+			{
+				name: "list", summary: "List the category rules in the order they are tried",
+				setup: run(func([]string) error {
+					return listCategoryRules()
+				}),
+			},
+			{
+				name: "add", args: "<text> <category>", summary: "Categorize expenses whose payee or description contains text", minArgs: 2,
+				setup: func(fs *flag.FlagSet) runFunc {
+					field := fs.String("field", "", "only match the `field` payee or description")
+					return func(args []string) error {
+						if len(args) > 2 {
+							return usagef("expected the text and the category; quote text with spaces")
+						}
+						rule := CategoryRule{Field: *field, Contains: args[0], Category: args[1]}
+						if err := validateCategoryRules([]CategoryRule{rule}); err != nil {
+							return usagef("%s", strings.TrimPrefix(err.Error(), "rule 1: "))
+						}
+						return addCategoryRule(rule)
+					}
+				},
+			},
+			{
+				name: "delete", args: "<n>", summary: "Delete a rule by its number in the list", minArgs: 1,
+				setup: run(func(args []string) error {
+					n, err := parseID(args[0], "rule")
+					if err != nil {
+						return err
+					}
+					return deleteCategoryRule(n)
+				}),
+			},
+			{
+				name: "test", args: "[text]", summary: "Show the category text would get, or what the rules would change in existing expenses",
+				setup: func(fs *flag.FlagSet) runFunc {
+					recategorize := fs.Bool("recategorize", false, "apply the rules to existing expenses, replacing their categories")
+					return func(args []string) error {
+						if len(args) > 0 {
+							if *recategorize {
+								return usagef("--recategorize applies to existing expenses; give no text")
+							}
+							return testCategoryRules(strings.Join(args, " "))
+						}
+						return recategorizeExpenses(*recategorize)
+					}
+				},
+			},
+		},
+	}
+}
+
+// listCategoryRules prints the configured rules, numbered.
+func listCategoryRules() error {
+	if len(config.CategoryRules) == 0 {
+		fmt.Fprintln(stdout, "No category rules. Add one with 'task expense rules add <text> <category>'.")
+		return nil
+	}
+	fmt.Fprintln(stdout, "--- Category Rules ---")
+	for i, r := range config.CategoryRules {
+		fmt.Fprintf(stdout, "%d. %s\n", i+1, r)
+	}
+	fmt.Fprintln(stdout, "----------------------")
+	return nil
+}
+
+// addCategoryRule appends a rule to the config file.
+func addCategoryRule(rule CategoryRule) error {
+	rules := append(config.CategoryRules, rule)
+	if err := saveCategoryRules(rules); err != nil {
+		return err
+	}
+	printDone("Rule %d added: %s\n", len(rules), rule)
+	return nil
+}
+
+// deleteCategoryRule removes the nth rule from the config file.
+func deleteCategoryRule(n int) error {
+	rules := config.CategoryRules
+	if n < 1 || n > len(rules) {
+		return fmt.Errorf("there is no rule %d", n)
+	}
+	deleted := rules[n-1]
+	if err := saveCategoryRules(append(rules[:n-1:n-1], rules[n:]...)); err != nil {
+		return err
+	}
+	printDone("Rule %d deleted: %s\n", n, deleted)
+	return nil
+}
+
+// saveCategoryRules replaces the rules in the config file, keeping the rest
+// of it as it is.
+func saveCategoryRules(rules []CategoryRule) error {
+	settings := map[string]json.RawMessage{}
+	data, err := os.ReadFile(configFile)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error reading %s: %w", configFile, err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &settings); err != nil {
+			return fmt.Errorf("error unmarshalling %s: %w", configFile, err)
+		}
+	}
+	if settings["categoryRules"], err = json.Marshal(rules); err != nil {
+		return err
+	}
+	data, err = json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFile(configFile, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("error writing %s: %w", configFile, err)
+	}
+	config.CategoryRules = rules
+	return nil
+}
+
+// testCategoryRules prints the rule that text, as a payee or description,
+// would match.
+func testCategoryRules(text string) error {
+	i := matchCategoryRule(text, text)
+	if i < 0 {
+		fmt.Fprintln(stdout, "No rule matches.")
+		return nil
+	}
+	fmt.Fprintf(stdout, "%s (rule %d: %s)\n", config.CategoryRules[i].Category, i+1, config.CategoryRules[i])
+	return nil
+}
+
+// recategorizeExpenses lists the existing expenses whose category a rule
+// would change and, if apply is set, saves the change. Expenses that no rule
+// matches keep their categories.
+func recategorizeExpenses(apply bool) error {
+	expenses, err := loadExpenses()
+	if err != nil {
+		return err
+	}
+	changed := 0
+	for i := range expenses {
+		e := &expenses[i]
+		r := matchCategoryRule(e.Payee, e.Description)
+		if r < 0 || config.CategoryRules[r].Category == e.Category {
+			continue
+		}
+		from := e.Category
+		if from == "" {
+			from = "uncategorized"
+		}
+		e.Category = config.CategoryRules[r].Category
+		fmt.Fprintf(stdout, "[ID: %d] %s %s: %s → %s\n", e.ID, e.Date.Format(dateLayout), e.Description, from, e.Category)
+		changed++
+	}
+
+	switch {
+	case changed == 0:
+		fmt.Fprintln(stdout, "The rules change no expenses.")
+		return nil
+	case !apply:
+		fmt.Fprintf(stdout, "%s would change; apply with --recategorize.\n", plural(changed, "expense"))
+		return nil
+	}
+	if err := tr().SaveExpenses(expenses); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Recategorized %s.\n", plural(changed, "expense"))
+	return nil
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+const scheduleHorizon = 60 // Days ahead 'task schedule --auto' looks for a free slot.
+
+// Schedule configures the working hours tasks are scheduled in.
+type Schedule struct {
+	Start string   `json:"start,omitempty"` // Time of day work starts, such as "09:00".
+	End   string   `json:"end,omitempty"`   // Time of day work ends, such as "17:30".
+	Days  []string `json:"days,omitempty"`  // Working days, such as "mon"; Monday to Friday if unset.
+}
+
+// workHours returns the configured working hours, defaulting to 9:00 to
+// 17:00 on weekdays.
+func (s Schedule) workHours() (tracker.WorkHours, error) {
+	hours := tracker.DefaultWorkHours()
+	for _, c := range []struct {
+		value string
+		set   *time.Duration
+	}{{s.Start, &hours.Start}, {s.End, &hours.End}} {
+		if c.value == "" {
+			continue
+		}
+		h, m, ok := parseClock(strings.ToLower(c.value))
+		if !ok {
+			return hours, fmt.Errorf("invalid time '%s'; use e.g. 09:00", c.value)
+		}
+		*c.set = time.Duration(h)*time.Hour + time.Duration(m)*time.Minute
+	}
+	if hours.End <= hours.Start {
+		return hours, errors.New("work must end after it starts")
+	}
+	if len(s.Days) > 0 {
+		hours.Days = nil
+		for _, day := range s.Days {
+			weekday, ok := weekdays[strings.ToLower(day)]
+			if !ok {
+				return hours, fmt.Errorf("invalid day '%s'", day)
+			}
+			hours.Days = append(hours.Days, weekday)
+		}
+	}
+	return hours, nil
+}
+
+// scheduleCommand returns the schedule command.
+func scheduleCommand() *command {
+	return &command{
+		name: "schedule", args: "<id> [date|none]", summary: "Plan when to work on a task, or find a free slot with --auto", group: groupTasks, minArgs: 1,
+		complete: positional(taskIDs),
+		setup: func(fs *flag.FlagSet) runFunc {
+			auto := fs.Bool("auto", false, "propose the first free slot in working hours")
+			yes := fs.Bool("yes", false, "with --auto, accept the first proposal without asking")
+			return func(args []string) error {
+				id, err := parseID(args[0], "task")
+				if err != nil {
+					return err
+				}
+				switch {
+				case *auto && len(args) > 1:
+					return usagef("give a date or --auto, not both")
+				case *auto:
+					return autoSchedule(id, *yes, clock())
+				case len(args) == 1:
+					return usagef("expected a date, none or --auto")
+				case len(args) == 2 && strings.EqualFold(args[1], "none"):
+					_, err := tr().SetScheduled(id, nil)
+					return err
+				}
+				start, err := parseDate(strings.Join(args[1:], " "), clock())
+				if err != nil {
+					return usagef("%v", err)
+				}
+				if _, err := tr().SetScheduled(id, &start); err != nil {
+					return err
+				}
+				warnMeetingConflicts(start)
+				return nil
+			}
+		},
+	}
+}
+
+// autoSchedule proposes free slots for a task, one after another, until
+// one is accepted and saved as the task's scheduled start. Slots are in
+// working hours and clear of other scheduled tasks and of the meetings of
+// the calendar feed, if one is configured; they are long enough for the
+// task's estimate, or an hour.
+func autoSchedule(id int, yes bool, now time.Time) error {
+	hours, _ := config.Schedule.workHours() // Checked by loadConfig.
+	tasks, err := loadTasks()
+	if err != nil {
+		return err
+	}
+	task, err := tr().Task(id)
+	if err != nil {
+		return err
+	}
+
+	busy := tracker.BusyTasks(tasks, config.Workflow, id)
+	if config.Calendar.Feed != "" {
+		meetings, err := loadMeetings(config.Calendar.Feed)
+		if err != nil {
+			fmt.Fprintf(stdout, "Warning: could not check your calendar: %v\n", err)
+		}
+		for _, m := range meetings {
+			busy = append(busy, tracker.Interval{Start: m.Start, End: m.End})
+		}
+	}
+
+	length := tracker.TaskLength(task)
+	until := now.AddDate(0, 0, scheduleHorizon)
+	for from := now; ; {
+		start, ok := tracker.NextFreeSlot(from, length, busy, hours, until)
+		if !ok {
+			fmt.Fprintf(stdout, "No free slot of %s in the next %d days.\n", formatHours(length.Hours()), scheduleHorizon)
+			return nil
+		}
+		fmt.Fprintf(stdout, "Proposed start: %s %s for %s", start.Format("Mon"), formatDue(start), formatHours(length.Hours()))
+		if task.Estimate <= 0 {
+			fmt.Fprint(stdout, " (no estimate)")
+		}
+		if task.Due != nil && start.Add(length).After(*task.Due) {
+			fmt.Fprint(stdout, ", "+colorize("after the due date", "red"))
+		}
+		fmt.Fprintln(stdout)
+
+		answer := "y"
+		if !yes {
+			if answer, err = readLine("Accept? [y]es, [n]ext, [q]uit: "); err != nil {
+				return err
+			}
+		}
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+			if _, err := tr().SetScheduled(id, &start); err != nil {
+				return err
+			}
+			fmt.Fprintf(stdout, "Task ID %d scheduled for %s.\n", id, formatDue(start))
+			return nil
+		case "n", "next":
+			from = start.Add(length)
+		default:
+			return nil
+		}
+	}
+}
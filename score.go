@@ -0,0 +1,188 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+const scoreFile = "score.json" // The name of the saved points ledger.
+
+// Gamification configures the optional points layer.
+type Gamification struct {
+	Enabled        bool           `json:"enabled"`
+	Points         map[string]int `json:"points,omitempty"` // Points per priority; "none" for tasks without one.
+	PointsPerLevel int            `json:"pointsPerLevel,omitempty"`
+}
+
+// defaultGamification returns the points settings used unless configured.
+func defaultGamification() Gamification {
+	return Gamification{
+		Points: map[string]int{
+			"none":                 5,
+			tracker.PriorityLow:    5,
+			tracker.PriorityMedium: 10,
+			tracker.PriorityHigh:   20,
+		},
+		PointsPerLevel: 100,
+	}
+}
+
+// ScoreEntry records points earned by completing a task.
+type ScoreEntry struct {
+	TaskID      int       `json:"taskId"`
+	Description string    `json:"description"`
+	Points      int       `json:"points"`
+	At          time.Time `json:"at"`
+}
+
+// loadScores reads the points ledger from the saved JSON file.
+func loadScores() ([]ScoreEntry, error) {
+	raw, err := loadDocument(scoreFile)
+	if err != nil || raw == nil {
+		return []ScoreEntry{}, err
+	}
+
+	var entries []ScoreEntry
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, fmt.Errorf("error unmarshalling JSON: %w", err)
+	}
+
+	return entries, nil
+}
+
+// saveScores writes the points ledger to the JSON file.
+func saveScores(entries []ScoreEntry) error {
+	return saveDocument(scoreFile, entries)
+}
+
+// pointsFor returns the points earned by completing task.
+func (g Gamification) pointsFor(task Task) int {
+	key := task.Priority
+	if key == "" {
+		key = "none"
+	}
+	if points, ok := g.Points[key]; ok {
+		return points
+	}
+	return defaultGamification().Points[key]
+}
+
+// awardPoints records the points for completing task when gamification is
+// enabled.
+func awardPoints(task Task) error {
+	g := config.Gamification
+	if !g.Enabled {
+		return nil
+	}
+
+	entries, err := loadScores()
+	if err != nil {
+		return err
+	}
+
+	points := g.pointsFor(task)
+	entries = append(entries, ScoreEntry{
+		TaskID:      task.ID,
+		Description: task.Description,
+		Points:      points,
+		At:          clock(),
+	})
+	if err := saveScores(entries); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "+%d points!\n", points)
+	return nil
+}
+
+// printScore prints total points, level, streaks and recent completions.
+func printScore() error {
+	g := config.Gamification
+	if !g.Enabled {
+		fmt.Fprintln(stdout, `Points are disabled. Enable them with "gamification": {"enabled": true} in config.json.`)
+		return nil
+	}
+
+	entries, err := loadScores()
+	if err != nil {
+		return err
+	}
+
+	now := clock()
+	weekStart := startOfWeek(now)
+	total, week := 0, 0
+	days := map[time.Time]bool{}
+	for _, e := range entries {
+		total += e.Points
+		if !e.At.Before(weekStart) {
+			week += e.Points
+		}
+		days[startOfDay(e.At.Local())] = true
+	}
+
+	perLevel := g.PointsPerLevel
+	if perLevel <= 0 {
+		perLevel = defaultGamification().PointsPerLevel
+	}
+	level := total/perLevel + 1
+	into := total % perLevel
+
+	current, best := completionStreaks(days, now)
+
+	fmt.Fprintln(stdout, "--- Score ---")
+	fmt.Fprintf(stdout, "Points: %d (%d this week)\n", total, week)
+	fmt.Fprintf(stdout, "Level %d  %s %d/%d to level %d\n", level, progressBar(into, perLevel, 20), into, perLevel, level+1)
+	fmt.Fprintf(stdout, "Streak: %d day(s) (best: %d)\n", current, best)
+	if len(entries) > 0 {
+		fmt.Fprintln(stdout, "Recent:")
+		for i := len(entries) - 1; i >= 0 && i >= len(entries)-5; i-- {
+			e := entries[i]
+			fmt.Fprintf(stdout, "  +%-3d %s %s\n", e.Points, e.At.Format(dateLayout), e.Description)
+		}
+	}
+	fmt.Fprintln(stdout, "-------------")
+
+	return nil
+}
+
+// completionStreaks returns the current streak of consecutive days with a
+// completion (counting from today, or yesterday if nothing is done yet
+// today) and the best streak ever.
+func completionStreaks(days map[time.Time]bool, now time.Time) (int, int) {
+	current := 0
+	day := startOfDay(now)
+	if !days[day] {
+		day = day.AddDate(0, 0, -1)
+	}
+	for days[day] {
+		current++
+		day = day.AddDate(0, 0, -1)
+	}
+
+	best := 0
+	for d := range days {
+		if days[d.AddDate(0, 0, -1)] {
+			continue // Not the start of a run.
+		}
+		run := 0
+		for days[d] {
+			run++
+			d = d.AddDate(0, 0, 1)
+		}
+		best = max(best, run)
+	}
+	return current, best
+}
+
+// progressBar renders value out of total as a bar of width characters.
+func progressBar(value, total, width int) string {
+	filled := 0
+	if total > 0 {
+		filled = min(value*width/total, width)
+	}
+	return "[" + strings.Repeat("#", filled) + strings.Repeat("-", width-filled) + "]"
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+const (
+	secretPrefix   = "secret:"      // Starts a config value naming a secret rather than holding it.
+	secretsFile    = "secrets.json" // The encrypted fallback, in the user's configuration directory.
+	keyringService = "task-cli"     // What secrets are filed under in the OS keyring.
+	secretsDirEnv  = "TASK_SECRETS" // Overrides the directory of the fallback file.
+)
+
+// storedSecret is an entry of the fallback secrets file.
+type storedSecret struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// secretCommand returns the secret command group.
+func secretCommand() *command {
+	return &command{
+		name: "secret", summary: "Keep API tokens in the OS keyring for config.json to refer to", group: groupData,
+		subcommands: []*command{
+			{
+				name: "set", args: "<name>", summary: "Store a secret, read from standard input", minArgs: 1,
+				setup: run(func(args []string) error { return setSecret(args[0]) }),
+			},
+			{
+				name: "delete", args: "<name>", summary: "Delete a secret", minArgs: 1,
+				setup: run(func(args []string) error { return deleteSecret(args[0]) }),
+			},
+		},
+	}
+}
+
+// setSecret reads a secret's value and stores it in the OS keyring or,
+// where there is none, in the encrypted secrets file.
+func setSecret(name string) error {
+	if err := checkSecretName(name); err != nil {
+		return err
+	}
+	value, err := readLine("Value: ")
+	if err != nil {
+		return err
+	}
+	if value == "" {
+		return errors.New("secret must not be empty")
+	}
+
+	if tool := keyringTool(); tool != "" {
+		err := keyringSet(tool, name, value)
+		if err == nil {
+			removeFileSecret(name) // So that the keyring's value is not shadowed by a stale one.
+			fmt.Fprintf(stdout, "Secret '%s' saved to the keyring. Refer to it as \"%s%s\" in %s.\n", name, secretPrefix, name, configFile)
+			return nil
+		}
+		fmt.Fprintf(stdout, "Warning: could not use the keyring (%v); using the secrets file instead\n", err)
+	}
+	path, err := setFileSecret(name, value)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Secret '%s' saved to %s. Refer to it as \"%s%s\" in %s.\n", name, path, secretPrefix, name, configFile)
+	return nil
+}
+
+// deleteSecret removes a secret from wherever it is kept.
+func deleteSecret(name string) error {
+	found := false
+	if tool := keyringTool(); tool != "" && keyringDelete(tool, name) == nil {
+		found = true
+	}
+	removed, err := removeFileSecret(name)
+	if err != nil {
+		return err
+	}
+	if !found && !removed {
+		return fmt.Errorf("secret '%s' %w", name, tracker.ErrNotFound)
+	}
+	fmt.Fprintf(stdout, "Secret '%s' deleted.\n", name)
+	return nil
+}
+
+// checkSecretName rejects names that would not survive being passed to the
+// keyring tools or written after "secret:" in the config.
+func checkSecretName(name string) error {
+	if name == "" || strings.ContainsAny(name, " \t\n:") {
+		return usagef("secret names must not be empty or contain spaces or colons: '%s'", name)
+	}
+	return nil
+}
+
+// resolveSecret returns a config value, or the secret it names if it has
+// the form "secret:<name>", looking in the keyring and then the secrets
+// file.
+func resolveSecret(value string) (string, error) {
+	name, ok := strings.CutPrefix(value, secretPrefix)
+	if !ok {
+		return value, nil
+	}
+	if tool := keyringTool(); tool != "" {
+		if secret, err := keyringGet(tool, name); err == nil && secret != "" {
+			return secret, nil
+		}
+	}
+	secrets, err := loadFileSecrets(false)
+	if err != nil {
+		return "", err
+	}
+	i := slices.IndexFunc(secrets, func(s storedSecret) bool { return s.Name == name })
+	if i < 0 {
+		return "", fmt.Errorf("secret '%s' not found; store it with 'task secret set %s'", name, name)
+	}
+	return secrets[i].Value, nil
+}
+
+// keyringTool returns the command the platform's keyring is reached with,
+// or "" if it has none installed: the login keychain's security on macOS,
+// and secret-tool, for GNOME Keyring, KWallet and other Secret Service
+// keyrings, elsewhere.
+func keyringTool() string {
+	tool := "secret-tool"
+	switch runtime.GOOS {
+	case "darwin":
+		tool = "security"
+	case "windows", "android":
+		return ""
+	}
+	if _, err := exec.LookPath(tool); err != nil {
+		return ""
+	}
+	return tool
+}
+
+// keyringSet stores a secret in the keyring, replacing any with its name.
+// Both tools read the value from their standard input, never from their
+// arguments, which other processes can see: secret-tool once, and security,
+// given -w last without a value, twice, as it asks for it to be retyped.
+func keyringSet(tool, name, value string) error {
+	var cmd *exec.Cmd
+	if tool == "security" {
+		cmd = exec.Command(tool, "add-generic-password", "-U", "-s", keyringService, "-a", name, "-w")
+		cmd.Stdin = strings.NewReader(value + "\n" + value + "\n")
+	} else {
+		cmd = exec.Command(tool, "store", "--label", "task: "+name, "service", keyringService, "account", name)
+		cmd.Stdin = strings.NewReader(value)
+	}
+	return runKeyringTool(cmd)
+}
+
+// keyringGet returns a secret from the keyring.
+func keyringGet(tool, name string) (string, error) {
+	var cmd *exec.Cmd
+	if tool == "security" {
+		cmd = exec.Command(tool, "find-generic-password", "-s", keyringService, "-a", name, "-w")
+	} else {
+		cmd = exec.Command(tool, "lookup", "service", keyringService, "account", name)
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+// keyringDelete removes a secret from the keyring.
+func keyringDelete(tool, name string) error {
+	if tool == "security" {
+		return runKeyringTool(exec.Command(tool, "delete-generic-password", "-s", keyringService, "-a", name))
+	}
+	if _, err := keyringGet(tool, name); err != nil {
+		return err // secret-tool clear succeeds whether or not there was anything to clear.
+	}
+	return runKeyringTool(exec.Command(tool, "clear", "service", keyringService, "account", name))
+}
+
+// runKeyringTool runs a keyring command, turning a failure into an error
+// carrying what it printed.
+func runKeyringTool(cmd *exec.Cmd) error {
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%s: %s", filepath.Base(cmd.Path), msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// secretsTracker is the tracker of the secrets file, kept so that its
+// passphrase is asked for once.
+var secretsTracker *tracker.Tracker
+
+// openSecrets returns a tracker for the directory of the secrets file:
+// $TASK_SECRETS, or the user's configuration directory, away from the data
+// files so that sharing or syncing them does not give the secrets away.
+func openSecrets() (*tracker.Tracker, error) {
+	if secretsTracker != nil {
+		return secretsTracker, nil
+	}
+	dir := os.Getenv(secretsDirEnv)
+	if dir == "" {
+		base, err := os.UserConfigDir()
+		if err != nil {
+			return nil, fmt.Errorf("no place for the secrets file; set %s: %w", secretsDirEnv, err)
+		}
+		dir = filepath.Join(base, "task")
+	}
+	secretsTracker = tracker.New(tracker.Options{Dir: dir, Passphrase: promptPassphrase})
+	return secretsTracker, nil
+}
+
+// loadFileSecrets reads the secrets file. With create set, a missing file
+// is created, encrypted with a new passphrase.
+func loadFileSecrets(create bool) ([]storedSecret, error) {
+	t, err := openSecrets()
+	if err != nil {
+		return nil, err
+	}
+	if !t.DocumentEncrypted(secretsFile) {
+		if _, err := os.Stat(filepath.Join(t.Dir(), secretsFile)); err == nil {
+			return nil, fmt.Errorf("%s is not encrypted; delete it and set the secrets again", filepath.Join(t.Dir(), secretsFile))
+		}
+		if !create {
+			return nil, nil
+		}
+		fmt.Fprintln(stdout, "Secrets without a keyring are kept in a file encrypted with a passphrase.")
+		passphrase, err := promptNewPassphrase()
+		if err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(t.Dir(), 0700); err != nil {
+			return nil, fmt.Errorf("error creating directory: %w", err)
+		}
+		if err := t.EncryptDocument(secretsFile, passphrase); err != nil {
+			return nil, err
+		}
+	}
+	raw, err := t.ReadDocument(secretsFile)
+	if err != nil || raw == nil {
+		return nil, err
+	}
+	var secrets []storedSecret
+	if err := json.Unmarshal(raw, &secrets); err != nil {
+		return nil, fmt.Errorf("error unmarshalling JSON: %w", err)
+	}
+	return secrets, nil
+}
+
+// saveFileSecrets writes the secrets file, which is already encrypted.
+func saveFileSecrets(secrets []storedSecret) (string, error) {
+	t, err := openSecrets()
+	if err != nil {
+		return "", err
+	}
+	if err := t.WriteDocument(secretsFile, secrets); err != nil {
+		return "", err
+	}
+	return filepath.Join(t.Dir(), secretsFile), nil
+}
+
+// setFileSecret stores a secret in the secrets file and returns its path.
+func setFileSecret(name, value string) (string, error) {
+	secrets, err := loadFileSecrets(true)
+	if err != nil {
+		return "", err
+	}
+	secrets = slices.DeleteFunc(secrets, func(s storedSecret) bool { return s.Name == name })
+	return saveFileSecrets(append(secrets, storedSecret{Name: name, Value: value}))
+}
+
+// removeFileSecret deletes a secret from the secrets file, if it is there.
+func removeFileSecret(name string) (bool, error) {
+	secrets, err := loadFileSecrets(false)
+	if err != nil || len(secrets) == 0 {
+		return false, err
+	}
+	kept := slices.DeleteFunc(slices.Clone(secrets), func(s storedSecret) bool { return s.Name == name })
+	if len(kept) == len(secrets) {
+		return false, nil
+	}
+	_, err = saveFileSecrets(kept)
+	return true, err
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+func TestSecretFile(t *testing.T) {
+	out := setupCLI(t)
+	t.Setenv("PATH", "") // No keyring tool, so the secrets go to the file.
+	t.Setenv(secretsDirEnv, t.TempDir())
+	t.Setenv(passphraseEnvVar, "correct horse")
+
+	stdin = bufio.NewReader(strings.NewReader("s3cret\n"))
+	mustRunCLI(t, out, "secret", "set", "todoist")
+	secretsTracker = nil // As a later run would, read the file again.
+	got, err := resolveSecret(secretPrefix + "todoist")
+	if err != nil || got != "s3cret" {
+		t.Errorf("resolveSecret = %q, %v; want s3cret", got, err)
+	}
+
+	mustRunCLI(t, out, "secret", "delete", "todoist")
+	if _, err := runCLI(t, out, "secret", "delete", "todoist"); !errors.Is(err, tracker.ErrNotFound) {
+		t.Errorf("deleting a missing secret: %v, want ErrNotFound", err)
+	}
+	if _, err := resolveSecret(secretPrefix + "todoist"); err == nil {
+		t.Error("deleted secret still resolves")
+	}
+}
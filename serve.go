@@ -0,0 +1,243 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"mime"
+	"net/http"
+	"sync"
+)
+
+const defaultServeAddr = "localhost:8080"
+
+// serveMu serializes requests: handlers select projects in turn, and the
+// current project is shared state.
+var serveMu sync.Mutex
+
+// serveCommand returns the serve command.
+func serveCommand() *command {
+	return &command{
+		name: "serve", summary: "Serve a web UI, reports and imports over HTTP for a shared deployment", group: groupData,
+		setup: func(fs *flag.FlagSet) runFunc {
+			addr := fs.String("addr", defaultServeAddr, "`address` to listen on")
+			grpc := fs.Bool("grpc", false, "serve the gRPC services in tracker.proto too, over HTTP/2 without TLS")
+			return func([]string) error {
+				srv := &http.Server{Addr: *addr, Handler: newServer(*grpc)}
+				fmt.Fprintf(stdout, "Serving on http://%s\n", *addr)
+				if *grpc {
+					srv.Protocols = new(http.Protocols)
+					srv.Protocols.SetHTTP1(true)
+					srv.Protocols.SetUnencryptedHTTP2(true)
+					fmt.Fprintf(stdout, "Serving gRPC on %s\n", *addr)
+				}
+				return srv.ListenAndServe()
+			}
+		},
+	}
+}
+
+// newServer returns the HTTP handler of server mode, serving the gRPC
+// methods as well if grpc is set.
+func newServer(grpc bool) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /tasks", handleTasks)
+	mux.HandleFunc("GET /tasks/{uuid}", handleTask)
+	mux.HandleFunc("POST /tasks", sendingChanges(handleAddTask))
+	mux.HandleFunc("PATCH /tasks/{uuid}", sendingChanges(handleMarkTask))
+	mux.HandleFunc("DELETE /tasks/{uuid}", sendingChanges(handleDeleteTask))
+	mux.HandleFunc("GET /expenses", handleExpenses)
+	mux.HandleFunc("POST /expenses", sendingChanges(handleAddExpense))
+	mux.HandleFunc("GET /workflow", handleWorkflow)
+	mux.HandleFunc("GET /reports/workload", handleWorkload)
+	mux.HandleFunc("GET /feed/{project}", handleFeed)
+	mux.HandleFunc("POST /import", sendingChanges(handleImport))
+	mux.HandleFunc("GET /sync/{project}", handleSyncGet)
+	mux.HandleFunc("PUT /sync/{project}", sendingChanges(handleSyncPut))
+	mux.Handle("GET /", webUI())
+	if grpc {
+		for _, service := range grpcServices {
+			mux.HandleFunc("POST /"+service+"/", handleGRPC)
+		}
+	}
+	return lockPerRequest(mux)
+}
+
+// sendingChanges calls h, then snapshots the changes it saved, if the data
+// is kept in git, and sends their webhooks, as commands do once they have
+// run.
+func sendingChanges(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h(w, r)
+		serveMu.Lock()
+		defer serveMu.Unlock()
+		sendChanges(r)
+	}
+}
+
+// sendChanges snapshots the changes saved by a request and sends their
+// webhooks. It is called with serveMu held.
+func sendChanges(r *http.Request) {
+	noteChange("", []string{"serve", r.Method, r.URL.Path}, 0)
+	snapshotData()
+	sendWebhooks()
+}
+
+// lockPerRequest locks the data files while each request is handled, so
+// task commands run meanwhile wait rather than overwrite the changes.
+// Requests take turns, as the lock is held by the process.
+func lockPerRequest(h http.Handler) http.Handler {
+	var mu sync.Mutex
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		unlock, err := lockData()
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		defer unlock()
+		h.ServeHTTP(w, r)
+	})
+}
+
+// apiTask is a task in responses, with the project it belongs to.
+type apiTask struct {
+	Task
+	Project string `json:"project"`
+}
+
+// allTasks returns the tasks of every project.
+func allTasks() ([]apiTask, error) {
+	var all []apiTask
+	err := forEachProject(func(project string) error {
+		tasks, err := loadTasks()
+		for _, task := range tasks {
+			all = append(all, apiTask{task, project})
+		}
+		return err
+	})
+	return all, err
+}
+
+// handleTasks responds with the tasks of every project, or those matching
+// the query in the q parameter.
+func handleTasks(w http.ResponseWriter, r *http.Request) {
+	serveMu.Lock()
+	defer serveMu.Unlock()
+
+	q, err := parseQuery(r.URL.Query().Get("q"), config.Workflow, clock())
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid query: " + err.Error()})
+		return
+	}
+	tasks, err := allTasks()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	matched := []apiTask{}
+	for _, task := range tasks {
+		if q.matches(task.Task) {
+			matched = append(matched, task)
+		}
+	}
+	writeJSON(w, http.StatusOK, matched)
+}
+
+// handleTask responds with the task with a UUID, in whichever project it is.
+func handleTask(w http.ResponseWriter, r *http.Request) {
+	serveMu.Lock()
+	defer serveMu.Unlock()
+
+	tasks, err := allTasks()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	for _, task := range tasks {
+		if task.UUID == r.PathValue("uuid") {
+			writeJSON(w, http.StatusOK, task)
+			return
+		}
+	}
+	writeJSON(w, http.StatusNotFound, map[string]string{"error": "task not found"})
+}
+
+// handleWorkload responds with the open tasks, estimated hours and overdue
+// tasks of each assignee across projects, busiest first.
+func handleWorkload(w http.ResponseWriter, r *http.Request) {
+	serveMu.Lock()
+	defer serveMu.Unlock()
+
+	loads, err := projectWorkload(clock())
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, loads)
+}
+
+// maxImportSize limits the payload accepted by POST /import.
+const maxImportSize = 10 << 20
+
+// importReport is the response to POST /import.
+type importReport struct {
+	Added   int            `json:"added"`
+	Skipped int            `json:"skipped"`
+	Rows    []ImportResult `json:"rows"`
+}
+
+// handleImport adds the tasks in a CSV or JSON payload to the current
+// project and responds with the outcome of every row. The format is taken
+// from the format query parameter, or else the Content-Type, which must be
+// text/csv or application/json.
+func handleImport(w http.ResponseWriter, r *http.Request) {
+	// As for decodeForm, a body any web page could post is refused.
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "text/csv" && mediaType != "application/json" {
+		writeJSON(w, http.StatusUnsupportedMediaType, map[string]string{"error": "expected Content-Type: text/csv or application/json"})
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = importCSV
+		if mediaType == "application/json" {
+			format = importJSON
+		}
+	}
+
+	records, err := parseImport(http.MaxBytesReader(w, r.Body, maxImportSize), format)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	serveMu.Lock()
+	defer serveMu.Unlock()
+
+	results, err := importTasks(records)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	report := importReport{Rows: results}
+	for _, res := range results {
+		if res.Error != "" {
+			report.Skipped++
+		} else {
+			report.Added++
+		}
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// writeJSON responds with v encoded as JSON.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+// serveRequest sends a request to the server of 'task serve' and returns
+// the response.
+func serveRequest(t *testing.T, method, path, contentType, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	w := httptest.NewRecorder()
+	newServer(false).ServeHTTP(w, req)
+	return w
+}
+
+func TestServeRejectsCrossSiteAndBadProjects(t *testing.T) {
+	setupCLI(t)
+	for _, c := range []struct {
+		contentType, body string
+		want              int
+	}{
+		{"text/plain", `{"description": "Buy milk"}`, http.StatusUnsupportedMediaType},
+		{"application/json", `{"description": "Buy milk", "project": "../../victim"}`, http.StatusBadRequest},
+		{"application/json", `{"description": "Buy milk", "project": "nope"}`, http.StatusNotFound},
+		{"application/json; charset=utf-8", `{"description": "Buy milk"}`, http.StatusCreated},
+	} {
+		if w := serveRequest(t, "POST", "/tasks", c.contentType, c.body); w.Code != c.want {
+			t.Errorf("POST /tasks as %s with %s: %d %s, want %d", c.contentType, c.body, w.Code, w.Body, c.want)
+		}
+	}
+	if w := serveRequest(t, "POST", "/import", "text/plain", "description\nCall mom\n"); w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("POST /import as text/plain: %d %s", w.Code, w.Body)
+	}
+
+	tasks, err := loadTasks()
+	if err != nil || len(tasks) != 1 {
+		t.Errorf("tasks = %+v, %v; want the one added", tasks, err)
+	}
+}
+
+func TestServeSendsWebhooks(t *testing.T) {
+	setupCLI(t)
+	var events []string
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p webhookPayload
+		json.NewDecoder(r.Body).Decode(&p)
+		events = append(events, p.Event)
+	}))
+	defer hook.Close()
+	config.Webhooks = []Webhook{{URL: hook.URL, Events: []string{eventTaskAdded}}}
+	baseTracker = tracker.New(tracker.Options{FS: tracker.NewMemFS(), Now: clock, Location: time.UTC, Workflow: config.Workflow, ReviewTasks: reviewSavedTasks})
+
+	if w := serveRequest(t, "POST", "/tasks", "application/json", `{"description": "Buy milk"}`); w.Code != http.StatusCreated {
+		t.Fatalf("POST /tasks: %d %s", w.Code, w.Body)
+	}
+	if len(events) != 1 || events[0] != eventTaskAdded {
+		t.Errorf("webhook events = %q, want [%s]", events, eventTaskAdded)
+	}
+	if len(pendingWebhooks) != 0 {
+		t.Errorf("%d webhook payloads left queued", len(pendingWebhooks))
+	}
+}
@@ -0,0 +1,530 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+// Limits on shared packs, which may come from anyone.
+const (
+	maxPackSize     = 1 << 20 // Bytes in a pack file.
+	maxPackEntries  = 1000    // Templates, recipes and their lines together.
+	maxPackName     = 64      // Characters in a name.
+	maxPackText     = 200     // Characters in a description, checklist step or filter query.
+	maxPackQuantity = 1000    // Largest quantity, per-day count or cap.
+	maxPackTerms    = 32      // Words and parentheses in a filter query.
+	maxPackNesting  = 4       // Depth of the parentheses in a filter query.
+)
+
+// SharedPack is a bundle of packing templates, recipes, task templates,
+// saved filters and category rules shared between users. It is plain data:
+// nothing in it is run, and the quantities it sets are bounded, so
+// installing it can at worst add templates, filters and rules. Filter
+// queries, priorities, due dates and rules are checked as they would be
+// when used, so a pack cannot install something that fails later.
+type SharedPack struct {
+	Name        string                    `json:"name"`
+	Version     string                    `json:"version,omitempty"`
+	Description string                    `json:"description,omitempty"`
+	Packing     map[string][]PackingEntry `json:"packing,omitempty"`
+	Recipes     map[string]Recipe         `json:"recipes,omitempty"`
+	Templates   map[string]TaskTemplate   `json:"templates,omitempty"`
+	Filters     map[string]string         `json:"filters,omitempty"`
+	Rules       []CategoryRule            `json:"rules,omitempty"` // Tried after the rules already configured.
+}
+
+// packChange is a difference installing a pack would make.
+type packChange struct {
+	mark   string // "+" adds, "~" replaces, "-" removes, "=" leaves as it is.
+	kind   string
+	name   string
+	detail string
+}
+
+// parseSharedPack reads and validates a pack. Fields it does not know are
+// rejected rather than ignored, so a pack cannot smuggle in settings.
+func parseSharedPack(r io.Reader) (SharedPack, error) {
+	var pack SharedPack
+	data, err := io.ReadAll(io.LimitReader(r, maxPackSize+1))
+	if err != nil {
+		return pack, fmt.Errorf("error reading pack: %w", err)
+	}
+	if len(data) > maxPackSize {
+		return pack, fmt.Errorf("pack is larger than %d KiB", maxPackSize>>10)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&pack); err != nil {
+		return pack, fmt.Errorf("invalid pack: %w", err)
+	}
+	if dec.More() {
+		return pack, errors.New("invalid pack: unexpected data after the pack")
+	}
+	return pack, pack.validate()
+}
+
+// validate checks the names and quantities in a pack against the limits.
+func (p SharedPack) validate() error {
+	if err := checkPackText("pack name", p.Name, true); err != nil {
+		return err
+	}
+	if err := checkPackText("version", p.Version, false); err != nil {
+		return err
+	}
+
+	entries := len(p.Packing) + len(p.Recipes) + len(p.Templates) + len(p.Filters) + len(p.Rules)
+	for name, items := range p.Packing {
+		entries += len(items)
+		if err := checkPackText("packing template name", name, true); err != nil {
+			return err
+		}
+		for _, item := range items {
+			if err := checkPackText("item in packing template '"+name+"'", item.Item, true); err != nil {
+				return err
+			}
+			for _, n := range []int{item.Quantity, item.PerDay, item.Max} {
+				if n < 0 || n > maxPackQuantity {
+					return fmt.Errorf("quantity %d of '%s' in packing template '%s' is outside 0-%d", n, item.Item, name, maxPackQuantity)
+				}
+			}
+		}
+	}
+	for name, recipe := range p.Recipes {
+		entries += len(recipe.Ingredients)
+		if err := checkPackText("recipe name", name, true); err != nil {
+			return err
+		}
+		for _, ing := range recipe.Ingredients {
+			what := "ingredient of recipe '" + name + "'"
+			if err := checkPackText(what, ing.Name, true); err != nil {
+				return err
+			}
+			if err := checkPackText("store of "+what, ing.Store, false); err != nil {
+				return err
+			}
+			if err := checkPackText("aisle of "+what, ing.Aisle, false); err != nil {
+				return err
+			}
+			if ing.Quantity < 0 || ing.Quantity > maxPackQuantity {
+				return fmt.Errorf("quantity %d of '%s' in recipe '%s' is outside 0-%d", ing.Quantity, ing.Name, name, maxPackQuantity)
+			}
+		}
+	}
+	for name, tmpl := range p.Templates {
+		entries += len(tmpl.Tags) + len(tmpl.Checklist)
+		if err := checkTemplateInPack(name, tmpl); err != nil {
+			return err
+		}
+	}
+	for name, query := range p.Filters {
+		if err := checkFilterInPack(name, query); err != nil {
+			return err
+		}
+	}
+	if err := validateCategoryRules(p.Rules); err != nil {
+		return fmt.Errorf("category %w", err)
+	}
+	for i, r := range p.Rules {
+		if err := checkPackText(fmt.Sprintf("text of category rule %d", i+1), r.Contains, true); err != nil {
+			return err
+		}
+		if err := checkPackText(fmt.Sprintf("category of category rule %d", i+1), r.Category, true); err != nil {
+			return err
+		}
+	}
+	if entries == 0 {
+		return errors.New("pack is empty")
+	}
+	if entries > maxPackEntries {
+		return fmt.Errorf("pack has %d entries; at most %d are allowed", entries, maxPackEntries)
+	}
+	return nil
+}
+
+// checkTemplateInPack checks a task template of a pack: its texts against
+// the limits, its priority, and its due date unless it uses variables,
+// which are only known once it is used.
+func checkTemplateInPack(name string, tmpl TaskTemplate) error {
+	if err := checkPackText("task template name", name, true); err != nil {
+		return err
+	}
+	what := "task template '" + name + "'"
+	if err := checkPackLine("description of "+what, tmpl.Description, true); err != nil {
+		return err
+	}
+	for _, tag := range tmpl.Tags {
+		if err := checkPackText("tag of "+what, tag, true); err != nil {
+			return err
+		}
+	}
+	for _, step := range tmpl.Checklist {
+		if err := checkPackLine("checklist step of "+what, step, true); err != nil {
+			return err
+		}
+	}
+	if tmpl.Priority != "" && !tracker.IsValidPriority(tmpl.Priority) {
+		return fmt.Errorf("invalid priority '%s' in %s", tmpl.Priority, what)
+	}
+	if err := checkPackText("due date of "+what, tmpl.Due, false); err != nil {
+		return err
+	}
+	if tmpl.Due != "" && !templateVarPattern.MatchString(tmpl.Due) {
+		if _, err := parseDate(tmpl.Due, clock()); err != nil {
+			return fmt.Errorf("invalid due date in %s: %w", what, err)
+		}
+	}
+	return nil
+}
+
+// checkFilterInPack checks a saved filter of a pack: its name as 'task
+// filter save' would, and its query after bounding its size, so that a
+// query cannot make the parser recurse without end.
+func checkFilterInPack(name, query string) error {
+	if err := checkPackText("filter name", name, true); err != nil {
+		return err
+	}
+	if err := checkFilterName(name, config.Workflow); err != nil {
+		return err
+	}
+	what := "query of filter '" + name + "'"
+	if err := checkPackLine(what, query, true); err != nil {
+		return err
+	}
+	tokens, err := queryTokens(query)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", what, err)
+	}
+	if len(tokens) > maxPackTerms {
+		return fmt.Errorf("%s has %d terms; at most %d are allowed", what, len(tokens), maxPackTerms)
+	}
+	depth := 0
+	for _, tok := range tokens {
+		switch {
+		case tok.quoted:
+		case tok.text == "(":
+			if depth++; depth > maxPackNesting {
+				return fmt.Errorf("%s nests parentheses deeper than %d", what, maxPackNesting)
+			}
+		case tok.text == ")":
+			depth--
+		}
+	}
+	if _, err := parseQuery(query, config.Workflow, clock()); err != nil {
+		return fmt.Errorf("invalid %s: %w", what, err)
+	}
+	return nil
+}
+
+// checkPackText checks that a name in a pack is short, printable text.
+func checkPackText(what, s string, required bool) error {
+	return checkPackString(what, s, required, maxPackName)
+}
+
+// checkPackLine checks that a longer text in a pack, such as a
+// description, is printable and within maxPackText.
+func checkPackLine(what, s string, required bool) error {
+	return checkPackString(what, s, required, maxPackText)
+}
+
+// checkPackString checks that s is printable text of at most limit
+// characters.
+func checkPackString(what, s string, required bool, limit int) error {
+	switch {
+	case s == "" && required:
+		return fmt.Errorf("missing %s", what)
+	case utf8.RuneCountInString(s) > limit:
+		return fmt.Errorf("%s '%s…' is longer than %d characters", what, truncate(s, 20), limit)
+	case strings.IndexFunc(s, func(r rune) bool { return !unicode.IsPrint(r) }) >= 0:
+		return fmt.Errorf("%s %q contains control characters", what, s)
+	}
+	return nil
+}
+
+// diffSharedPack lists what installing a pack would change in the targets
+// given, replacing the previous version of the pack.
+func diffSharedPack(p, previous SharedPack, t packTargets) []packChange {
+	var changes []packChange
+	changes = append(changes, diffNamed("packing template", p.Packing, previous.Packing, t.packing, func(items []PackingEntry) string {
+		return plural(len(items), "item")
+	})...)
+	changes = append(changes, diffNamed("recipe", p.Recipes, previous.Recipes, t.plan.Recipes, func(r Recipe) string {
+		return plural(len(r.Ingredients), "ingredient")
+	})...)
+	changes = append(changes, diffNamed("task template", p.Templates, previous.Templates, t.templates, func(tmpl TaskTemplate) string {
+		return "'" + tmpl.Description + "'"
+	})...)
+	changes = append(changes, diffNamed("filter", p.Filters, previous.Filters, t.filters, func(query string) string {
+		return "'" + query + "'"
+	})...)
+	changes = append(changes, diffNamed("category rule", rulesByName(p.Rules), rulesByName(previous.Rules), rulesByName(t.rules), func(CategoryRule) string {
+		return "tried after the rules there are"
+	})...)
+	return changes
+}
+
+// diffNamed lists what installing items of a kind would change in current,
+// removing those of the previous version of the pack that it no longer has
+// and that were not changed since. describe sums up an item.
+func diffNamed[T any](kind string, items, previous, current map[string]T, describe func(T) string) []packChange {
+	var changes []packChange
+	for _, name := range sortedKeys(items) {
+		item := items[name]
+		change := packChange{mark: "+", kind: kind, name: name, detail: describe(item)}
+		if cur, ok := current[name]; ok {
+			change.mark, change.detail = "~", fmt.Sprintf("replaces %s with %s", describe(cur), describe(item))
+			if reflect.DeepEqual(cur, item) {
+				change.mark, change.detail = "=", "unchanged"
+			}
+		}
+		changes = append(changes, change)
+	}
+	for _, name := range sortedKeys(previous) {
+		cur, ok := current[name]
+		if _, kept := items[name]; !kept && ok && reflect.DeepEqual(cur, previous[name]) {
+			changes = append(changes, packChange{mark: "-", kind: kind, name: name, detail: "no longer in the pack"})
+		}
+	}
+	return changes
+}
+
+// rulesByName keys rules by how they are listed, which tells them apart.
+func rulesByName(rules []CategoryRule) map[string]CategoryRule {
+	byName := make(map[string]CategoryRule, len(rules))
+	for _, r := range rules {
+		byName[r.String()] = r
+	}
+	return byName
+}
+
+// readTemplateFile reads a JSON template file into v, leaving v as it is if
+// the file is missing. Unlike the loaders of the commands using the file,
+// it does not write an example.
+func readTemplateFile(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading file: %w", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("error unmarshalling %s: %w", path, err)
+	}
+	return nil
+}
+
+// writeTemplateFile writes v as indented JSON to a template file.
+func writeTemplateFile(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling JSON: %w", err)
+	}
+	if err := writeFile(path, data, 0644); err != nil {
+		return fmt.Errorf("error writing file: %w", err)
+	}
+	return nil
+}
+
+// packTargets are the templates, filters and rules a pack installs into.
+type packTargets struct {
+	packing   map[string][]PackingEntry
+	plan      MealPlan
+	templates map[string]TaskTemplate
+	filters   map[string]string // Those of filters.json; config.json's are left alone.
+	rules     []CategoryRule
+}
+
+// loadPackTargets reads what a pack installs into, empty where the files do
+// not exist yet.
+func loadPackTargets() (packTargets, error) {
+	t := packTargets{packing: map[string][]PackingEntry{}, templates: map[string]TaskTemplate{}, rules: config.CategoryRules}
+	if err := readTemplateFile(packingFile, &t.packing); err != nil {
+		return t, err
+	}
+	if err := readTemplateFile(mealsFile, &t.plan); err != nil {
+		return t, err
+	}
+	if err := readTemplateFile(templatesFile, &t.templates); err != nil {
+		return t, err
+	}
+	var err error
+	t.filters, err = fileFilters()
+	return t, err
+}
+
+// save writes the files given back, out of packingFile, mealsFile,
+// templatesFile, filtersFile and configFile.
+func (t packTargets) save(files []string) error {
+	for _, file := range files {
+		var err error
+		switch file {
+		case packingFile:
+			err = writeTemplateFile(packingFile, t.packing)
+		case mealsFile:
+			err = writeTemplateFile(mealsFile, t.plan)
+		case templatesFile:
+			err = writeTemplateFile(templatesFile, t.templates)
+		case filtersFile:
+			err = writeFilters(t.filters)
+		case configFile:
+			if err = saveCategoryRules(t.rules); err == nil {
+				config.CategoryRules = t.rules
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// installSharedPack adds a pack's templates, recipes and filters, replacing
+// those with the same names, and its category rules after those configured.
+func installSharedPack(p SharedPack) error {
+	t, err := loadPackTargets()
+	if err != nil {
+		return err
+	}
+
+	var changed []string
+	if installNamed(p.Packing, &t.packing) {
+		changed = append(changed, packingFile)
+	}
+	if installNamed(p.Recipes, &t.plan.Recipes) {
+		changed = append(changed, mealsFile)
+	}
+	if installNamed(p.Templates, &t.templates) {
+		changed = append(changed, templatesFile)
+	}
+	if installNamed(p.Filters, &t.filters) {
+		changed = append(changed, filtersFile)
+	}
+	rules := slices.Clone(t.rules)
+	for _, r := range p.Rules {
+		if !slices.Contains(rules, r) {
+			rules = append(rules, r)
+		}
+	}
+	if len(rules) > len(t.rules) {
+		t.rules = rules
+		changed = append(changed, configFile)
+	}
+	return t.save(changed)
+}
+
+// installNamed adds items to current, replacing those with the same names,
+// and reports whether there were any.
+func installNamed[T any](items map[string]T, current *map[string]T) bool {
+	if len(items) == 0 {
+		return false
+	}
+	if *current == nil {
+		*current = map[string]T{}
+	}
+	maps.Copy(*current, items)
+	return true
+}
+
+// uninstallNamed removes from current the items of a kind old added that
+// next does not, unless they were changed since, and reports whether it
+// removed any.
+func uninstallNamed[T any](kind string, old, next, current map[string]T) bool {
+	removed := false
+	for _, name := range sortedKeys(old) {
+		if _, kept := next[name]; kept {
+			continue
+		}
+		if cur, ok := current[name]; ok {
+			if !reflect.DeepEqual(cur, old[name]) {
+				fmt.Fprintf(stdout, "Kept %s '%s': it was changed after the pack installed it.\n", kind, name)
+				continue
+			}
+			delete(current, name)
+			removed = true
+		}
+	}
+	return removed
+}
+
+// previewSharedPack prints what installing a pack in place of its previous
+// version would change and reports whether it would change anything.
+func previewSharedPack(p, previous SharedPack) (bool, error) {
+	t, err := loadPackTargets()
+	if err != nil {
+		return false, err
+	}
+
+	title := p.Name
+	if p.Version != "" {
+		title += " " + p.Version
+	}
+	fmt.Fprintf(stdout, "--- Pack: %s ---\n", title)
+	if p.Description != "" {
+		fmt.Fprintln(stdout, p.Description)
+	}
+	changed := false
+	for _, c := range diffSharedPack(p, previous, t) {
+		fmt.Fprintf(stdout, "  %s %s '%s' (%s)\n", c.mark, c.kind, c.name, c.detail)
+		changed = changed || c.mark != "="
+	}
+	return changed, nil
+}
+
+// importSharedPack previews a pack file and installs it once confirmed.
+func importSharedPack(path string, yes bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("error opening file: %w", err)
+	}
+	defer f.Close()
+
+	pack, err := parseSharedPack(f)
+	if err != nil {
+		return err
+	}
+	_, err = offerSharedPack(pack, SharedPack{}, yes)
+	return err
+}
+
+// offerSharedPack previews a pack replacing its previous version and
+// installs it once confirmed, unless yes is set, and reports whether it was
+// installed.
+func offerSharedPack(pack, previous SharedPack, yes bool) (bool, error) {
+	changed, err := previewSharedPack(pack, previous)
+	if err != nil {
+		return false, err
+	}
+	if !changed {
+		fmt.Fprintln(stdout, "Nothing to install; everything in the pack is already there.")
+		return true, nil
+	}
+	if !yes {
+		ok, err := confirm("Install?")
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			fmt.Fprintln(stdout, "Pack not installed.")
+			return false, nil
+		}
+	}
+
+	if err := installSharedPack(pack); err != nil {
+		return false, err
+	}
+	printDone("Installed pack '%s'.\n", pack.Name)
+	return true, nil
+}
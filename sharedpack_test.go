@@ -0,0 +1,72 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+const testPack = `{
+  "name": "gtd",
+  "templates": {"review": {"description": "Weekly review", "checklist": ["Empty the inbox"]}},
+  "filters": {"urgent": "priority:high"},
+  "rules": [{"contains": "grocer", "category": "Food"}]
+}`
+
+func TestImportSharedPack(t *testing.T) {
+	out := setupCLI(t)
+	if err := os.WriteFile("gtd.json", []byte(testPack), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := mustRunCLI(t, out, "import", "pack", "--yes", "gtd.json")
+	for _, want := range []string{"+ task template 'review'", "+ filter 'urgent'", "+ category rule"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("preview lacks %q:\n%s", want, s)
+		}
+	}
+	templates, err := loadTaskTemplates()
+	if err != nil || templates["review"].Description != "Weekly review" {
+		t.Errorf("templates = %+v, %v", templates, err)
+	}
+	filters, err := fileFilters()
+	if err != nil || filters["urgent"] != "priority:high" {
+		t.Errorf("filters = %v, %v", filters, err)
+	}
+	if len(config.CategoryRules) != 1 || config.CategoryRules[0].Category != "Food" {
+		t.Errorf("rules = %+v", config.CategoryRules)
+	}
+
+	s = mustRunCLI(t, out, "import", "pack", "--yes", "gtd.json")
+	if !strings.Contains(s, "Nothing to install") {
+		t.Errorf("installing again:\n%s", s)
+	}
+	if len(config.CategoryRules) != 1 {
+		t.Errorf("rule added twice: %+v", config.CategoryRules)
+	}
+}
+
+func TestParseSharedPackRejectsBadExpressions(t *testing.T) {
+	setupCLI(t)
+	deep := strings.Repeat("(", maxPackNesting+1) + "invoice" + strings.Repeat(")", maxPackNesting+1)
+	for name, pack := range map[string]string{
+		"bad query":      `{"name": "p", "filters": {"x": "priority>=urgent"}}`,
+		"too many terms": `{"name": "p", "filters": {"x": "` + strings.Repeat("a ", maxPackTerms+1) + `"}}`,
+		"deep nesting":   `{"name": "p", "filters": {"x": "` + deep + `"}}`,
+		"status name":    `{"name": "p", "filters": {"todo": "tag:work"}}`,
+		"bad priority":   `{"name": "p", "templates": {"x": {"description": "X", "priority": "asap"}}}`,
+		"bad due":        `{"name": "p", "templates": {"x": {"description": "X", "due": "someday"}}}`,
+		"long step":      `{"name": "p", "templates": {"x": {"description": "X", "checklist": ["` + strings.Repeat("a", maxPackText+1) + `"]}}}`,
+		"bad rule field": `{"name": "p", "rules": [{"field": "amount", "contains": "a", "category": "B"}]}`,
+		"empty rule":     `{"name": "p", "rules": [{"contains": " ", "category": "B"}]}`,
+	} {
+		if _, err := parseSharedPack(strings.NewReader(pack)); err == nil {
+			t.Errorf("%s: pack accepted", name)
+		}
+	}
+
+	ok := `{"name": "p", "templates": {"x": {"description": "X {who}", "due": "in {days} days"}}, "filters": {"x": "(tag:a or tag:b) priority>=high"}}`
+	if _, err := parseSharedPack(strings.NewReader(ok)); err != nil {
+		t.Errorf("valid pack rejected: %v", err)
+	}
+}
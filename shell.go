@@ -0,0 +1,236 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// shellHelp describes the commands only the shell understands.
+const shellHelp = `Type any task command without the leading 'task', e.g. 'list todo'.
+End a line with '?' to list what can come next, or press Tab then Enter
+to list the ways to complete the last word.
+
+Shell commands:
+  save        Save the changes made so far
+  exit, quit  Save the changes and leave the shell (also Ctrl-D)
+  begin       Start a transaction: later changes are saved by 'commit'
+              or dropped by 'rollback', and dropped if you exit first
+`
+
+// session names what is running commands one after another, "shell" or
+// "batch", or is empty for a single command.
+var session string
+
+// shellCommand returns the shell command.
+func shellCommand(root *command) *command {
+	return &command{
+		name: "shell", summary: "Run commands at a prompt, saving changes together on 'save' or exit", group: groupData,
+		setup: run(func([]string) error {
+			if session != "" {
+				return fmt.Errorf("cannot start a shell from a %s", session)
+			}
+			return runShell(root)
+		}),
+	}
+}
+
+// runShell reads commands from standard input until exit or end of input.
+// Data files are read once and changes are held in memory, so successive
+// commands are fast and are saved together.
+func runShell(root *command) error {
+	// The --project the shell was started with applies to every line
+	_, project, _ := extractProjectFlag(os.Args[1:])
+	session = "shell"
+	defer func() { session = "" }()
+
+	tr()
+	baseTracker.Begin()
+	defer baseTracker.Rollback()
+
+	interactive := isTerminal(os.Stdin)
+	if interactive {
+		fmt.Fprintln(stdout, "Type 'help' for commands, '?' for completions, and 'exit' to save and leave.")
+	}
+	for {
+		prompt := ""
+		if interactive {
+			prompt = shellPrompt()
+		}
+		line, err := readLine(prompt)
+		if errors.Is(err, io.EOF) {
+			if interactive {
+				fmt.Fprintln(stdout)
+			}
+			return exitShell()
+		}
+		if err != nil {
+			return err
+		}
+
+		words, err := splitWords(line)
+		if err != nil {
+			fmt.Fprintf(stdout, "Error: %v.\n", err)
+			continue
+		}
+		if strings.HasSuffix(line, "\t") || len(words) > 0 && words[len(words)-1] == "?" {
+			printCompletions(root, line, words)
+			continue
+		}
+		if len(words) == 0 {
+			continue
+		}
+
+		switch words[0] {
+		case "exit", "quit":
+			return exitShell()
+		case "save":
+			if inTransaction {
+				fmt.Fprintln(stdout, "Error: a transaction is open; end it with 'commit' or 'rollback'.")
+			} else if err := saveShell(); err != nil {
+				fmt.Fprintf(stdout, "Operation Failed: %v\n", err)
+			}
+			continue
+		case "help":
+			if len(words) == 1 {
+				fmt.Fprint(stdout, shellHelp)
+				fmt.Fprintln(stdout)
+				printCommandHelp(stdout, root, []string{root.name})
+				continue
+			}
+		}
+
+		if err := runLine(root, project, words); err != nil {
+			reportError(err)
+		}
+	}
+}
+
+// runLine runs a line of the shell or a batch file: a command, optionally
+// preceded by --project, or the project the session was started with.
+func runLine(root *command, project string, words []string) error {
+	args, lineProject, err := extractProjectFlag(words)
+	if err != nil {
+		return err
+	}
+	if lineProject == "" {
+		lineProject = project
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	if err := selectProject(lineProject); err != nil && args[0] != "project" {
+		return err
+	}
+	return executeAndRecord(root, lineProject, args)
+}
+
+// shellPrompt returns the prompt, naming the current project, showing an
+// open transaction and marking unsaved changes with '*'.
+func shellPrompt() string {
+	prompt := "task"
+	if currentProject != "" {
+		prompt += ":" + currentProject
+	}
+	if inTransaction {
+		prompt += " (transaction)"
+	}
+	if len(baseTracker.Pending()) > 0 {
+		prompt += "*"
+	}
+	return prompt + "> "
+}
+
+// saveShell saves the changes held by the shell and carries on holding
+// new ones.
+func saveShell() error {
+	n := len(baseTracker.Pending())
+	if err := commitSession(); err != nil {
+		return err
+	}
+	baseTracker.Begin()
+	if n == 0 {
+		fmt.Fprintln(stdout, "Nothing to save.")
+	} else {
+		fmt.Fprintf(stdout, "Saved %s.\n", plural(n, "file"))
+	}
+	return nil
+}
+
+// exitShell saves the changes held by the shell, except those of a
+// transaction that was not committed.
+func exitShell() error {
+	if inTransaction {
+		rollbackTransaction()
+		fmt.Fprintln(stdout, "Transaction rolled back: it was not committed.")
+	}
+	return commitSession()
+}
+
+// printCompletions lists the candidates for the word being typed: the
+// last word of a line ending with a Tab, or the word after a final '?'.
+func printCompletions(root *command, line string, words []string) {
+	if len(words) > 0 && words[len(words)-1] == "?" {
+		words[len(words)-1] = ""
+	} else if len(words) == 0 || strings.TrimRight(line, "\t") != strings.TrimRight(line, " \t") {
+		// A space before the Tab starts a new word
+		words = append(words, "")
+	}
+
+	cs := completeWords(root, words)
+	if len(cs) == 0 {
+		fmt.Fprintln(stdout, "No completions.")
+		return
+	}
+	tw := tabwriter.NewWriter(stdout, 0, 0, 3, ' ', 0)
+	for _, c := range cs {
+		fmt.Fprintf(tw, "  %s\t%s\n", c.value, c.desc)
+	}
+	tw.Flush()
+}
+
+// splitWords splits a line into words the way a POSIX shell would, honouring
+// single and double quotes and backslash escapes.
+func splitWords(line string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inWord = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("unterminated quote")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+// ShopItem represents a single entry on the shopping list.
+type ShopItem struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Quantity  int       `json:"quantity"`
+	Store     string    `json:"store,omitempty"`
+	Aisle     string    `json:"aisle,omitempty"`
+	Bought    bool      `json:"bought"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+const (
+	shoppingFile     = "shopping.json" // The name of the saved shopping list file.
+	shoppingCategory = "groceries"     // Category given to expenses created from bought items.
+	anyStore         = "any store"
+	anyAisle         = "other"
+)
+
+// shopCommand returns the shop command group.
+func shopCommand() *command {
+	return &command{
+		name: "shop", summary: "Keep a shopping list", group: groupHousehold,
+		subcommands: []*command{
+			{
+				name: "add", args: "<item>", summary: "Add an item to the shopping list", minArgs: 1,
+				setup: func(fs *flag.FlagSet) runFunc {
+					qty := fs.Int("qty", 1, "quantity to buy")
+					store := fs.String("store", "", "store to buy it at")
+					aisle := fs.String("aisle", "", "aisle or section of the store")
+					return func(args []string) error {
+						if *qty < 1 {
+							return usagef("invalid quantity %d", *qty)
+						}
+						return addShopItem(strings.Join(args, " "), *qty, *store, *aisle)
+					}
+				},
+			},
+			{
+				name: "list", summary: "Show the shopping list grouped by store and aisle",
+				setup: func(fs *flag.FlagSet) runFunc {
+					store := fs.String("store", "", "only show items for this store")
+					return func([]string) error { return listShopping(*store) }
+				},
+			},
+			{
+				name: "buy", args: "<id>", summary: "Mark an item bought and record it as an expense", minArgs: 1,
+				setup: func(fs *flag.FlagSet) runFunc {
+					price := fs.Float64("price", -1, "price paid (prompted for when omitted)")
+					return func(args []string) error {
+						id, err := parseID(args[0], "shopping item")
+						if err != nil {
+							return err
+						}
+						return buyShopItem(id, *price)
+					}
+				},
+			},
+			{
+				name: "remove", args: "<id>", summary: "Remove an item", minArgs: 1,
+				setup: run(func(args []string) error {
+					id, err := parseID(args[0], "shopping item")
+					if err != nil {
+						return err
+					}
+					return removeShopItem(id)
+				}),
+			},
+			{
+				name: "clear", summary: "Remove all bought items",
+				setup: run(func([]string) error { return clearBoughtItems() }),
+			},
+		},
+	}
+}
+
+// loadShopping reads the shopping list from the saved JSON file.
+func loadShopping() ([]ShopItem, error) {
+	raw, err := loadDocument(shoppingFile)
+	if err != nil || raw == nil {
+		return []ShopItem{}, err
+	}
+
+	var items []ShopItem
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return nil, fmt.Errorf("error unmarshalling JSON: %w", err)
+	}
+
+	return items, nil
+}
+
+// saveShopping writes the shopping list to the JSON file.
+func saveShopping(items []ShopItem) error {
+	return saveDocument(shoppingFile, items)
+}
+
+// getNextShopID creates a new shopping item ID.
+func getNextShopID(items []ShopItem) int {
+	maxID := 0
+	for _, item := range items {
+		if item.ID > maxID {
+			maxID = item.ID
+		}
+	}
+	return maxID + 1
+}
+
+// addShopItem adds an item to the shopping list. Store and aisle names are
+// stored in lower case so grouping and filtering are case-insensitive.
+func addShopItem(name string, quantity int, store, aisle string) error {
+	items, err := loadShopping()
+	if err != nil {
+		return err
+	}
+
+	item := ShopItem{
+		ID:        getNextShopID(items),
+		Name:      name,
+		Quantity:  quantity,
+		Store:     strings.ToLower(store),
+		Aisle:     strings.ToLower(aisle),
+		CreatedAt: clock(),
+	}
+
+	items = append(items, item)
+	if err := saveShopping(items); err != nil {
+		return err
+	}
+
+	printDone("Shopping item added successfully (ID: %d)\n", item.ID)
+	return nil
+}
+
+// removeShopItem deletes an item from the shopping list by ID.
+func removeShopItem(id int) error {
+	items, err := loadShopping()
+	if err != nil {
+		return err
+	}
+
+	for i, item := range items {
+		if item.ID == id {
+			items = append(items[:i], items[i+1:]...)
+			if err := saveShopping(items); err != nil {
+				return err
+			}
+			printDone("Shopping item ID %d removed successfully\n", id)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("shopping item with ID %d %w", id, tracker.ErrNotFound)
+}
+
+// buyShopItem marks an item as bought and records its price as an expense.
+// If price is negative the user is prompted for it; an empty answer marks
+// the item bought without recording an expense.
+func buyShopItem(id int, price float64) error {
+	items, err := loadShopping()
+	if err != nil {
+		return err
+	}
+
+	index := -1
+	for i, item := range items {
+		if item.ID == id {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		return fmt.Errorf("shopping item with ID %d %w", id, tracker.ErrNotFound)
+	}
+	item := items[index]
+	if item.Bought {
+		return fmt.Errorf("shopping item with ID %d is already bought", id)
+	}
+
+	if price < 0 {
+		answer, err := readLine(fmt.Sprintf("Price paid for %d x %s (leave empty to skip): ", item.Quantity, item.Name))
+		if err != nil {
+			return err
+		}
+		answer = strings.TrimSpace(answer)
+		if answer != "" {
+			price, err = strconv.ParseFloat(answer, 64)
+			if err != nil || price < 0 {
+				return fmt.Errorf("invalid price '%s'", answer)
+			}
+		}
+	}
+
+	if price >= 0 {
+		description := item.Name
+		if item.Quantity > 1 {
+			description = fmt.Sprintf("%d x %s", item.Quantity, item.Name)
+		}
+		expense, err := tr().AddExpense(Expense{
+			Date:        clock(),
+			Amount:      price,
+			Description: description,
+			Category:    shoppingCategory,
+			Payee:       item.Store,
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Expense recorded (ID: %d)\n", expense.ID)
+	}
+
+	items[index].Bought = true
+	if err := saveShopping(items); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "Shopping item ID %d marked as bought.\n", id)
+	return nil
+}
+
+// clearBoughtItems removes every bought item from the shopping list.
+func clearBoughtItems() error {
+	items, err := loadShopping()
+	if err != nil {
+		return err
+	}
+
+	var remaining []ShopItem
+	for _, item := range items {
+		if !item.Bought {
+			remaining = append(remaining, item)
+		}
+	}
+
+	if err := saveShopping(remaining); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "Cleared %d bought item(s).\n", len(items)-len(remaining))
+	return nil
+}
+
+// listShopping prints the shopping list grouped by store and aisle,
+// optionally limited to a single store.
+func listShopping(store string) error {
+	items, err := loadShopping()
+	if err != nil {
+		return err
+	}
+
+	store = strings.ToLower(store)
+	groups := map[string]map[string][]ShopItem{}
+	for _, item := range items {
+		if store != "" && item.Store != store && item.Store != "" {
+			continue
+		}
+		itemStore := item.Store
+		if itemStore == "" {
+			itemStore = anyStore
+		}
+		aisle := item.Aisle
+		if aisle == "" {
+			aisle = anyAisle
+		}
+		if groups[itemStore] == nil {
+			groups[itemStore] = map[string][]ShopItem{}
+		}
+		groups[itemStore][aisle] = append(groups[itemStore][aisle], item)
+	}
+
+	if len(groups) == 0 {
+		fmt.Fprintln(stdout, "Shopping list is empty.")
+		return nil
+	}
+
+	fmt.Fprintln(stdout, "--- Shopping List ---")
+	for _, storeName := range sortedKeys(groups) {
+		fmt.Fprintf(stdout, "%s:\n", storeName)
+		aisles := groups[storeName]
+		for _, aisle := range sortedKeys(aisles) {
+			fmt.Fprintf(stdout, "  %s\n", aisle)
+			for _, item := range aisles[aisle] {
+				check := " "
+				if item.Bought {
+					check = "x"
+				}
+				fmt.Fprintf(stdout, "    [%s] [ID: %d] %d x %s\n", check, item.ID, item.Quantity, item.Name)
+			}
+		}
+	}
+	fmt.Fprintln(stdout, "---------------------")
+
+	return nil
+}
+
+// sortedKeys returns the keys of m in ascending order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+// Signatures use the minisign format, so files signed here can be checked
+// with minisign and the other way round. Only pure Ed25519 signatures (what
+// minisign calls legacy, made with -l) are supported: the prehashed kind
+// needs BLAKE2b, which the standard library does not have.
+const (
+	signatureExt   = ".minisig"
+	signAlgorithm  = "Ed"
+	prehashedAlgo  = "ED"
+	signKeyEnvVar  = "TASK_SIGNING_KEY" // Overrides where the secret key is kept.
+	signKeyFile    = "sign.key"
+	signPubKeyFile = "sign.pub"
+	untrustedLabel = "untrusted comment: "
+	trustedLabel   = "trusted comment: "
+)
+
+// signingKey is a secret key and the ID minisign identifies its public key by.
+type signingKey struct {
+	id  [8]byte
+	key ed25519.PrivateKey
+}
+
+// signCommand returns the sign command group.
+func signCommand() *command {
+	return &command{
+		name: "sign", summary: "Sign exports and check the signatures of files to import", group: groupData,
+		subcommands: []*command{
+			{
+				name: "keygen", summary: "Create the key pair exports are signed with",
+				setup: func(fs *flag.FlagSet) runFunc {
+					force := fs.Bool("force", false, "replace an existing key pair")
+					return func([]string) error { return generateSigningKey(*force) }
+				},
+			},
+			{
+				name: "file", args: "<file>...", summary: "Sign files, writing a .minisig signature next to each", minArgs: 1,
+				setup: run(func(args []string) error {
+					for _, path := range args {
+						if err := signFile(path); err != nil {
+							return err
+						}
+					}
+					return nil
+				}),
+			},
+			{
+				name: "verify", args: "<file>", summary: "Check a file's .minisig signature against a public key", minArgs: 1,
+				setup: func(fs *flag.FlagSet) runFunc {
+					key := fs.String("key", "", "public `key`, or the file holding it (default your own)")
+					return func(args []string) error {
+						comment, err := verifyFile(args[0], *key)
+						if err != nil {
+							return err
+						}
+						fmt.Fprintf(stdout, "Signature OK (%s)\n", comment)
+						return nil
+					}
+				},
+			},
+		},
+	}
+}
+
+// signKeyPath returns where the secret key is kept: $TASK_SIGNING_KEY, or
+// in the user's configuration directory, away from the data files so that
+// sharing or backing them up does not give the key away.
+func signKeyPath() (string, error) {
+	if path := os.Getenv(signKeyEnvVar); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("no place for the signing key; set %s: %w", signKeyEnvVar, err)
+	}
+	return filepath.Join(dir, "task", signKeyFile), nil
+}
+
+// generateSigningKey creates a key pair, saving the secret key readable by
+// the user only and the public key next to it.
+func generateSigningKey(force bool) error {
+	path, err := signKeyPath()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err == nil && !force {
+		return fmt.Errorf("a signing key already exists at %s; use --force to replace it", path)
+	}
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	var id [8]byte
+	rand.Read(id[:])
+
+	if err := makeDir(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("error creating directory: %w", err)
+	}
+	secret := untrustedLabel + "task secret key\n" + encodeKey(id, priv) + "\n"
+	if err := writeFile(path, []byte(secret), 0600); err != nil {
+		return fmt.Errorf("error writing file: %w", err)
+	}
+	public := formatPublicKey(id, pub)
+	pubPath := filepath.Join(filepath.Dir(path), signPubKeyFile)
+	if err := writeFile(pubPath, []byte(public), 0644); err != nil {
+		return fmt.Errorf("error writing file: %w", err)
+	}
+	printDone("Signing key saved to %s.\nShare the public key in %s:\n%s", path, pubPath, public)
+	return nil
+}
+
+// encodeKey encodes a key with the algorithm and key ID in front, as
+// minisign does.
+func encodeKey(id [8]byte, key []byte) string {
+	return base64.StdEncoding.EncodeToString(append(append([]byte(signAlgorithm), id[:]...), key...))
+}
+
+// formatPublicKey renders a public key file in minisign's format.
+func formatPublicKey(id [8]byte, pub ed25519.PublicKey) string {
+	return fmt.Sprintf("%sminisign public key %X\n%s\n", untrustedLabel, binary.LittleEndian.Uint64(id[:]), encodeKey(id, pub))
+}
+
+// loadSigningKey reads the secret key made by 'task sign keygen'.
+func loadSigningKey() (signingKey, error) {
+	var sk signingKey
+	path, err := signKeyPath()
+	if err != nil {
+		return sk, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return sk, errors.New("no signing key; create one with 'task sign keygen'")
+	}
+	if err != nil {
+		return sk, fmt.Errorf("error reading signing key: %w", err)
+	}
+	raw, err := decodeKeyLine(data, ed25519.PrivateKeySize)
+	if err != nil {
+		return sk, fmt.Errorf("invalid signing key %s: %w", path, err)
+	}
+	copy(sk.id[:], raw[2:10])
+	sk.key = ed25519.PrivateKey(raw[10:])
+	return sk, nil
+}
+
+// decodeKeyLine decodes the key in a key file or string: the last line
+// that is not a comment, holding the algorithm, the key ID and a key of
+// size bytes.
+func decodeKeyLine(data []byte, size int) ([]byte, error) {
+	var line string
+	for _, l := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		if l = strings.TrimSpace(l); l != "" && !strings.HasPrefix(l, untrustedLabel) {
+			line = l
+		}
+	}
+	raw, err := base64.StdEncoding.DecodeString(line)
+	if err != nil || len(raw) != 2+8+size {
+		return nil, errors.New("not a minisign key")
+	}
+	if string(raw[:2]) != signAlgorithm {
+		return nil, fmt.Errorf("unsupported key algorithm '%s'", raw[:2])
+	}
+	return raw, nil
+}
+
+// signFile writes the signature of a file to the file's name plus .minisig.
+func signFile(path string) error {
+	sk, err := loadSigningKey()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading file: %w", err)
+	}
+
+	sig := ed25519.Sign(sk.key, data)
+	trusted := fmt.Sprintf("timestamp:%d\tfile:%s", clock().Unix(), filepath.Base(path))
+	global := ed25519.Sign(sk.key, append(bytes.Clone(sig), trusted...))
+	doc := fmt.Sprintf("%ssignature from task secret key\n%s\n%s%s\n%s\n",
+		untrustedLabel, encodeKey(sk.id, sig), trustedLabel, trusted, base64.StdEncoding.EncodeToString(global))
+	if err := writeFile(path+signatureExt, []byte(doc), 0644); err != nil {
+		return fmt.Errorf("error writing file: %w", err)
+	}
+	printDone("Signed %s (%s)\n", path, path+signatureExt)
+	return nil
+}
+
+// verifyFile checks the signature in a file's .minisig against a public
+// key, given as the key itself or the file holding it, or else the user's
+// own. It returns the signature's trusted comment.
+func verifyFile(path, key string) (string, error) {
+	pub, err := loadPublicKey(key)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading file: %w", err)
+	}
+	doc, err := os.ReadFile(path + signatureExt)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("%s is not signed: %s %w", path, path+signatureExt, tracker.ErrNotFound)
+	}
+	if err != nil {
+		return "", fmt.Errorf("error reading signature: %w", err)
+	}
+	return verifySignature(data, doc, pub)
+}
+
+// checkSignature verifies a file to be imported against key, if one is
+// given, and reports who signed it and when.
+func checkSignature(path, key string) error {
+	if key == "" {
+		return nil
+	}
+	comment, err := verifyFile(path, key)
+	if err != nil {
+		return fmt.Errorf("not importing %s: %w", path, err)
+	}
+	fmt.Fprintf(stdout, "Signature OK (%s)\n", comment)
+	return nil
+}
+
+// loadPublicKey reads a public key given as the key itself or a file, or
+// the one 'task sign keygen' made if key is empty.
+func loadPublicKey(key string) ([]byte, error) {
+	data := []byte(key)
+	if key == "" {
+		path, err := signKeyPath()
+		if err != nil {
+			return nil, err
+		}
+		key = filepath.Join(filepath.Dir(path), signPubKeyFile)
+	}
+	if contents, err := os.ReadFile(key); err == nil {
+		data = contents
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error reading public key: %w", err)
+	} else if len(data) == 0 {
+		return nil, errors.New("no public key; give one with --key or create yours with 'task sign keygen'")
+	}
+	raw, err := decodeKeyLine(data, ed25519.PublicKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	return raw, nil
+}
+
+// verifySignature checks a minisign signature document over data against a
+// decoded public key, including the signature over its trusted comment.
+func verifySignature(data, doc, pub []byte) (string, error) {
+	lines := strings.Split(strings.ReplaceAll(string(doc), "\r\n", "\n"), "\n")
+	if len(lines) < 4 || !strings.HasPrefix(lines[0], untrustedLabel) || !strings.HasPrefix(lines[2], trustedLabel) {
+		return "", errors.New("invalid signature file")
+	}
+	sig, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil || len(sig) != 2+8+ed25519.SignatureSize {
+		return "", errors.New("invalid signature file")
+	}
+	switch string(sig[:2]) {
+	case signAlgorithm:
+	case prehashedAlgo:
+		return "", errors.New("prehashed signatures are not supported; sign with 'minisign -S -l'")
+	default:
+		return "", fmt.Errorf("unsupported signature algorithm '%s'", sig[:2])
+	}
+	if !bytes.Equal(sig[2:10], pub[2:10]) {
+		return "", fmt.Errorf("signed by another key (%s, not %s)", keyID(sig[2:10]), keyID(pub[2:10]))
+	}
+	key := ed25519.PublicKey(pub[10:])
+	if !ed25519.Verify(key, data, sig[10:]) {
+		return "", errors.New("signature does not match: the file has been changed")
+	}
+	trusted := strings.TrimPrefix(lines[2], trustedLabel)
+	global, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+	if err != nil || !ed25519.Verify(key, append(bytes.Clone(sig[10:]), trusted...), global) {
+		return "", errors.New("the signature's trusted comment has been changed")
+	}
+	return trusted, nil
+}
+
+// keyID renders a key ID as minisign prints it, a little-endian number in
+// hex.
+func keyID(id []byte) string {
+	return fmt.Sprintf("%X", binary.LittleEndian.Uint64(id))
+}
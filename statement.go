@@ -0,0 +1,425 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	statementCSV = "csv"
+	statementOFX = "ofx"
+)
+
+// ofxTagPattern matches an OFX tag and the value following it. OFX 1.x
+// files are SGML and leave most closing tags out; OFX 2.x files are XML.
+var ofxTagPattern = regexp.MustCompile(`<(/?[A-Za-z0-9.]+)>([^<]*)`)
+
+// Statement maps the columns of bank statement CSV files to expense fields.
+// Columns left unset are found by common names such as "Date", "Amount"
+// and "Payee".
+type Statement struct {
+	Date        string `json:"date,omitempty"`
+	Amount      string `json:"amount,omitempty"` // Negative for money out.
+	Debit       string `json:"debit,omitempty"`  // Money out, for statements with separate columns instead of Amount.
+	Credit      string `json:"credit,omitempty"` // Money in, likewise.
+	Payee       string `json:"payee,omitempty"`
+	Description string `json:"description,omitempty"`
+	Currency    string `json:"currency,omitempty"`
+
+	// DateFormat is how dates are written, such as "DD.MM.YYYY"; any date
+	// the tracker understands if unset.
+	DateFormat string `json:"dateFormat,omitempty"`
+
+	Decimal   string `json:"decimal,omitempty"`   // "," for amounts written 1.234,56.
+	Delimiter string `json:"delimiter,omitempty"` // Between columns; "," if unset.
+}
+
+// statementColumns are the column names looked for, in lower case, when
+// a column is not configured.
+var statementColumns = map[string][]string{
+	"date":        {"date", "booking date", "transaction date", "posted date", "posting date", "value date"},
+	"amount":      {"amount", "value"},
+	"debit":       {"debit", "withdrawal", "withdrawals", "money out", "paid out"},
+	"credit":      {"credit", "deposit", "deposits", "money in", "paid in"},
+	"payee":       {"payee", "name", "merchant", "counterparty", "beneficiary", "recipient"},
+	"description": {"description", "memo", "details", "reference", "narrative"},
+	"currency":    {"currency"},
+}
+
+// dateFormatTokens translate a statement's DateFormat into a Go layout.
+var dateFormatTokens = strings.NewReplacer("YYYY", "2006", "YY", "06", "MM", "01", "DD", "02")
+
+// validate checks the delimiter and decimal separator.
+func (s Statement) validate() error {
+	if len([]rune(s.Delimiter)) > 1 {
+		return fmt.Errorf("invalid delimiter '%s'; use a single character", s.Delimiter)
+	}
+	if s.Decimal != "" && s.Decimal != "." && s.Decimal != "," {
+		return fmt.Errorf("invalid decimal '%s'; use . or ,", s.Decimal)
+	}
+	return nil
+}
+
+// transaction is a line of a bank statement. Amount is negative for money
+// out.
+type transaction struct {
+	Row         int
+	Date        time.Time
+	Amount      float64
+	Currency    string
+	Payee       string
+	Description string
+}
+
+// statementFormat returns the format of a statement file from its
+// extension, defaulting to CSV.
+func statementFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ofx", ".qfx":
+		return statementOFX
+	}
+	return statementCSV
+}
+
+// parseStatementCSV reads transactions from a CSV statement with a header
+// row, using the column mapping s.
+func parseStatementCSV(r io.Reader, s Statement) ([]transaction, []ImportResult, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	if s.Delimiter != "" {
+		cr.Comma = []rune(s.Delimiter)[0]
+	}
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading CSV: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil, errors.New("CSV file is empty")
+	}
+
+	configured := map[string]string{
+		"date": s.Date, "amount": s.Amount, "debit": s.Debit, "credit": s.Credit,
+		"payee": s.Payee, "description": s.Description, "currency": s.Currency,
+	}
+	columns := map[string]int{}
+	for field, name := range configured {
+		names := statementColumns[field]
+		if name != "" {
+			names = []string{strings.ToLower(name)}
+		}
+		for i, header := range rows[0] {
+			if slices.Contains(names, strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header, "\ufeff")))) {
+				columns[field] = i
+				break
+			}
+		}
+		if _, ok := columns[field]; !ok && name != "" {
+			return nil, nil, fmt.Errorf("CSV file has no column '%s'", name)
+		}
+	}
+	if _, ok := columns["date"]; !ok {
+		return nil, nil, errors.New("CSV file has no date column; set statement.date in " + configFile)
+	}
+	_, hasAmount := columns["amount"]
+	_, hasDebit := columns["debit"]
+	_, hasCredit := columns["credit"]
+	if !hasAmount && !hasDebit && !hasCredit {
+		return nil, nil, errors.New("CSV file has no amount column; set statement.amount in " + configFile)
+	}
+
+	var txns []transaction
+	var skipped []ImportResult
+	for line, row := range rows[1:] {
+		value := func(field string) string {
+			if i, ok := columns[field]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+		t, err := transactionFromRow(value, s)
+		if err != nil {
+			skipped = append(skipped, ImportResult{Row: line + 2, Error: err.Error()})
+			continue
+		}
+		t.Row = line + 2
+		txns = append(txns, t)
+	}
+	return txns, skipped, nil
+}
+
+// transactionFromRow builds a transaction from the fields of a CSV row.
+func transactionFromRow(value func(field string) string, s Statement) (transaction, error) {
+	t := transaction{Payee: value("payee"), Description: value("description"), Currency: strings.ToUpper(value("currency"))}
+	var err error
+	if s.DateFormat != "" {
+		t.Date, err = time.ParseInLocation(dateFormatTokens.Replace(s.DateFormat), value("date"), time.Local)
+	} else {
+		t.Date, err = parseDate(value("date"), clock())
+	}
+	if err != nil {
+		return t, fmt.Errorf("invalid date '%s'", value("date"))
+	}
+
+	if v := value("amount"); v != "" {
+		if t.Amount, err = parseStatementAmount(v, s.Decimal); err != nil {
+			return t, err
+		}
+	} else if v := value("debit"); v != "" {
+		debit, err := parseStatementAmount(v, s.Decimal)
+		if err != nil {
+			return t, err
+		}
+		t.Amount = -max(debit, -debit)
+	} else if v := value("credit"); v != "" {
+		if t.Amount, err = parseStatementAmount(v, s.Decimal); err != nil {
+			return t, err
+		}
+	}
+	return t, nil
+}
+
+// parseStatementAmount parses an amount such as "-1,234.56", or with
+// decimal ",", "-1.234,56". Currency symbols and spaces are ignored.
+func parseStatementAmount(v, decimal string) (float64, error) {
+	cleaned := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' || r == '-' || r == '.' || r == ',' || r == '(' {
+			return r
+		}
+		return -1
+	}, v)
+	if strings.HasPrefix(cleaned, "(") { // Accounting notation for negatives.
+		cleaned = "-" + cleaned[1:]
+	}
+	if decimal == "," {
+		cleaned = strings.ReplaceAll(cleaned, ".", "")
+		cleaned = strings.ReplaceAll(cleaned, ",", ".")
+	} else {
+		cleaned = strings.ReplaceAll(cleaned, ",", "")
+	}
+	amount, err := strconv.ParseFloat(cleaned, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount '%s'", v)
+	}
+	return amount, nil
+}
+
+// parseStatementOFX reads the transactions of an OFX or QFX statement.
+func parseStatementOFX(r io.Reader) ([]transaction, []ImportResult, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading OFX: %w", err)
+	}
+
+	var txns []transaction
+	var skipped []ImportResult
+	var fields map[string]string
+	currency := ""
+	n := 0
+	for _, m := range ofxTagPattern.FindAllStringSubmatch(string(data), -1) {
+		tag, value := strings.ToUpper(m[1]), strings.TrimSpace(m[2])
+		switch {
+		case tag == "CURDEF":
+			currency = strings.ToUpper(value)
+		case tag == "STMTTRN":
+			fields = map[string]string{}
+			n++
+		case tag == "/STMTTRN" && fields != nil:
+			t, err := transactionFromOFX(fields)
+			if err != nil {
+				skipped = append(skipped, ImportResult{Row: n, Error: err.Error()})
+			} else {
+				t.Row, t.Currency = n, currency
+				txns = append(txns, t)
+			}
+			fields = nil
+		case fields != nil && !strings.HasPrefix(tag, "/"):
+			fields[tag] = ofxUnescape(value)
+		}
+	}
+	if n == 0 {
+		return nil, nil, errors.New("no transactions found in OFX file")
+	}
+	return txns, skipped, nil
+}
+
+// transactionFromOFX builds a transaction from the fields of an OFX
+// STMTTRN element.
+func transactionFromOFX(fields map[string]string) (transaction, error) {
+	t := transaction{Payee: fields["NAME"], Description: fields["MEMO"]}
+	posted := fields["DTPOSTED"]
+	if len(posted) < 8 {
+		return t, fmt.Errorf("invalid date '%s'", posted)
+	}
+	date, err := time.ParseInLocation("20060102", posted[:8], time.Local)
+	if err != nil {
+		return t, fmt.Errorf("invalid date '%s'", posted)
+	}
+	t.Date = date
+	amount, err := strconv.ParseFloat(strings.ReplaceAll(fields["TRNAMT"], ",", "."), 64)
+	if err != nil {
+		return t, fmt.Errorf("invalid amount '%s'", fields["TRNAMT"])
+	}
+	t.Amount = amount
+	return t, nil
+}
+
+// ofxUnescape decodes the character entities OFX values may contain.
+func ofxUnescape(s string) string {
+	return strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">", "&quot;", `"`, "&apos;", "'").Replace(s)
+}
+
+// duplicateKey identifies a transaction for duplicate detection by its
+// date, amount and payee.
+func duplicateKey(date time.Time, amount float64, payee string) string {
+	return fmt.Sprintf("%s|%.2f|%s", date.Format(dateLayout), amount, strings.ToLower(strings.TrimSpace(payee)))
+}
+
+// importStatement imports a bank statement: money out as expenses and money
+// in as income. Transactions already recorded, by date, amount and payee,
+// are skipped, so a statement overlapping an earlier one can be imported.
+// The new entries are of account, if given. With ask, the new expenses
+// without a category are categorized by payee interactively.
+func importStatement(path, format, account string, ask bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("error opening file: %w", err)
+	}
+	defer f.Close()
+
+	if format == "" {
+		format = statementFormat(path)
+	}
+	var txns []transaction
+	var skipped []ImportResult
+	if format == statementOFX {
+		txns, skipped, err = parseStatementOFX(f)
+	} else {
+		txns, skipped, err = parseStatementCSV(f, config.Statement)
+	}
+	if err != nil {
+		return err
+	}
+
+	expenses, err := loadExpenses()
+	if err != nil {
+		return err
+	}
+	income, err := tr().Income()
+	if err != nil {
+		return err
+	}
+	// Counted, so that two identical coffees on one day both import, but
+	// only once however often the statement is imported.
+	seen := map[string]int{}
+	for _, e := range expenses {
+		seen[duplicateKey(e.Date, -e.Amount, e.Payee)]++
+	}
+	for _, in := range income {
+		seen[duplicateKey(in.Date, in.Amount, in.Source)]++
+	}
+
+	var newExpenses []Expense
+	var newIncome []Income
+	duplicates := 0
+	for _, t := range txns {
+		if key := duplicateKey(t.Date, t.Amount, t.Payee); seen[key] > 0 {
+			seen[key]--
+			duplicates++
+			continue
+		}
+		currency := t.Currency
+		if currency == config.Currency.Base {
+			currency = ""
+		}
+		description := t.Description
+		if description == "" {
+			description = t.Payee
+		}
+		switch {
+		case t.Amount < 0:
+			newExpenses = append(newExpenses, Expense{Date: t.Date, Amount: -t.Amount, Currency: currency, Description: description, Payee: t.Payee, Account: account})
+		case t.Amount > 0:
+			newIncome = append(newIncome, Income{Date: t.Date, Amount: t.Amount, Currency: currency, Description: description, Source: t.Payee, Account: account})
+		}
+	}
+
+	for _, res := range skipped {
+		fmt.Fprintf(stdout, "Row %d: skipped: %s\n", res.Row, res.Error)
+	}
+	for i := range newExpenses {
+		applyCategoryRules(&newExpenses[i])
+	}
+	if ask && isTerminal(os.Stdin) {
+		if err := categorizeExpenses(newExpenses, expenses); err != nil {
+			return err
+		}
+	}
+	if len(newExpenses) > 0 {
+		if _, err := tr().AddExpenses(newExpenses); err != nil {
+			return err
+		}
+	}
+	if len(newIncome) > 0 {
+		if _, err := tr().AddIncomes(newIncome); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(stdout, "Imported statement: %s and %d income added; %s and %s skipped.\n",
+		plural(len(newExpenses), "expense"), len(newIncome), plural(duplicates, "duplicate"), plural(len(skipped), "invalid row"))
+	return nil
+}
+
+// categorizeExpenses asks for the category of each payee among the
+// uncategorized expenses, applying the answer to all of the payee's
+// expenses. An empty answer leaves them uncategorized; "q" stops asking.
+func categorizeExpenses(added, existing []Expense) error {
+	categories := map[string]bool{}
+	for _, e := range existing {
+		if e.Category != "" {
+			categories[e.Category] = true
+		}
+	}
+	asked := map[string]string{}
+	for i := range added {
+		e := &added[i]
+		if e.Category != "" {
+			continue
+		}
+		payee := strings.ToLower(e.Payee)
+		if category, ok := asked[payee]; ok {
+			e.Category = category
+			continue
+		}
+		hint := ""
+		if len(categories) > 0 {
+			hint = " [" + strings.Join(sortedKeys(categories), ", ") + "]"
+		}
+		answer, err := readLine(fmt.Sprintf("Category for %s %s %s%s (Enter to skip, q to stop): ",
+			e.Date.Format(dateLayout), formatAmount(e.Amount, e.Currency), e.Description, hint))
+		if err != nil {
+			return err
+		}
+		answer = strings.TrimSpace(answer)
+		if answer == "q" {
+			return nil
+		}
+		e.Category = answer
+		if answer != "" {
+			categories[answer] = true
+		}
+		if e.Payee != "" {
+			asked[payee] = answer
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// printStats prints task counts by status and pomodoro totals.
+func printStats() error {
+	tasks, err := loadTasks()
+	if err != nil {
+		return err
+	}
+
+	counts := map[string]int{}
+	today := startOfDay(clock())
+	totalPomodoros, todayPomodoros, focusMinutes := 0, 0, 0
+	for _, task := range tasks {
+		counts[task.Status]++
+		for _, p := range task.Pomodoros {
+			totalPomodoros++
+			focusMinutes += p.Minutes
+			if !p.Start.Before(today) {
+				todayPomodoros++
+			}
+		}
+	}
+
+	fmt.Fprintln(stdout, "--- Task Stats ---")
+	fmt.Fprintf(stdout, "Tasks: %d total", len(tasks))
+	for _, status := range config.Workflow.StatusNames() {
+		fmt.Fprintf(stdout, ", %d %s", counts[status], status)
+	}
+	fmt.Fprintln(stdout)
+	fmt.Fprintf(stdout, "Pomodoros: %d total, %d today (%dh%02dm focused)\n",
+		totalPomodoros, todayPomodoros, focusMinutes/60, focusMinutes%60)
+
+	ranked := make([]Task, 0, len(tasks))
+	for _, task := range tasks {
+		if len(task.Pomodoros) > 0 {
+			ranked = append(ranked, task)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return len(ranked[i].Pomodoros) > len(ranked[j].Pomodoros)
+	})
+	if len(ranked) > 5 {
+		ranked = ranked[:5]
+	}
+	if len(ranked) > 0 {
+		fmt.Fprintln(stdout, "Most pomodoros:")
+		for _, task := range ranked {
+			fmt.Fprintf(stdout, "  %3d  [ID: %d] %s\n", len(task.Pomodoros), task.ID, task.Description)
+		}
+	}
+	fmt.Fprintln(stdout, "------------------")
+
+	return nil
+}
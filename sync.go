@@ -0,0 +1,380 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+const (
+	syncFile        = "sync.json" // The state of the last sync, per project.
+	syncTimeout     = 30 * time.Second
+	syncAttempts    = 3 // Merges tried when the remote changes meanwhile.
+	maxSyncDocument = 32 << 20
+)
+
+// errSyncStale is returned when the remote changed since it was fetched.
+var errSyncStale = errors.New("the remote changed during the sync")
+
+// Sync configures where tasks are synced to.
+type Sync struct {
+	Remote string     `json:"remote,omitempty"` // URL of a 'task serve' server.
+	GitHub GitHubSync `json:"github"`
+	CalDAV CalDAVSync `json:"caldav"`
+}
+
+// syncState is what a project remembers of its last sync: the tasks both
+// copies had then, which later changes are merged against, and the
+// conflicts still to resolve.
+type syncState struct {
+	Remote    string             `json:"remote"`
+	Version   string             `json:"version"`
+	SyncedAt  time.Time          `json:"syncedAt"`
+	Base      []Task             `json:"base"`
+	Conflicts []tracker.Conflict `json:"conflicts,omitempty"`
+}
+
+// syncDocument is the tasks of a project as exchanged with the server, with
+// the version they are at.
+type syncDocument struct {
+	Version string `json:"version"`
+	Tasks   []Task `json:"tasks"`
+}
+
+// syncCommand returns the sync command.
+func syncCommand() *command {
+	return &command{
+		name: "sync", args: "[conflicts | resolve <id|uuid> <local|remote> | github | caldav]", summary: "Merge tasks with a 'task serve' server shared by several machines, GitHub issues or a CalDAV task list", group: groupData,
+		complete: positional(fixed("conflicts", "resolve", "github", "caldav"), conflictIDs, fixed("local", "remote")),
+		setup: func(fs *flag.FlagSet) runFunc {
+			remote := fs.String("remote", "", "`URL` of the server; sync.remote in config.json by default")
+			repo := fs.String("repo", "", "GitHub repository, as `owner/name`, for 'sync github'; sync.github.repo in config.json by default")
+			list := fs.String("list", "", "`URL` of the CalDAV task list for 'sync caldav'; sync.caldav.url in config.json by default")
+			return func(args []string) error {
+				if len(args) == 0 {
+					return syncTasks(*remote)
+				}
+				switch {
+				case args[0] == "github" && len(args) == 1:
+					return syncGitHub(*repo)
+				case args[0] == "caldav" && len(args) == 1:
+					return syncCalDAV(*list)
+				case args[0] == "conflicts" && len(args) == 1:
+					return listConflicts()
+				case args[0] == "resolve" && len(args) == 3:
+					if args[2] != "local" && args[2] != "remote" {
+						return usagef("invalid side '%s'; use local or remote", args[2])
+					}
+					return resolveConflict(args[1], args[2] == "remote")
+				}
+				return usagef("expected conflicts, resolve <id|uuid> <local|remote>, github or caldav")
+			}
+		},
+	}
+}
+
+// loadSyncState reads the current project's sync state.
+func loadSyncState() (syncState, error) {
+	var state syncState
+	err := loadStateDocument(syncFile, &state)
+	return state, err
+}
+
+// syncTasks merges the current project's tasks with the same project on
+// the remote: changes made on either side since the last sync are applied
+// to both, and tasks changed differently on both are reported as conflicts.
+func syncTasks(remote string) error {
+	if remote == "" {
+		remote = config.Sync.Remote
+	}
+	if remote == "" {
+		return usagef("no remote; pass --remote or set sync.remote in %s", configFile)
+	}
+	u, err := url.Parse(remote)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return usagef("invalid remote '%s'; use the http(s) URL of a 'task serve' server", remote)
+	}
+	project := currentProject
+	if project == "" {
+		project = defaultProject
+	}
+	endpoint := strings.TrimSuffix(remote, "/") + "/sync/" + url.PathEscape(project)
+
+	state, err := loadSyncState()
+	if err != nil {
+		return err
+	}
+	if state.Remote != remote {
+		// Never synced with this remote: merge everything, as if both
+		// started empty.
+		state = syncState{Remote: remote}
+	}
+
+	for range syncAttempts {
+		var doc syncDocument
+		if err := syncRequest(http.MethodGet, endpoint, nil, &doc); err != nil {
+			return err
+		}
+		local, err := loadTasks()
+		if err != nil {
+			return err
+		}
+
+		merge := tracker.MergeTasks(state.Base, local, doc.Tasks)
+		var saved syncDocument
+		err = syncRequest(http.MethodPut, endpoint, syncDocument{Version: doc.Version, Tasks: merge.Tasks}, &saved)
+		if errors.Is(err, errSyncStale) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := saveTasks(saved.Tasks); err != nil {
+			return err
+		}
+		state.Version, state.SyncedAt, state.Base = saved.Version, clock(), saved.Tasks
+		for _, c := range merge.Conflicts {
+			state.Conflicts = slices.DeleteFunc(state.Conflicts, func(old tracker.Conflict) bool { return old.UUID == c.UUID })
+			state.Conflicts = append(state.Conflicts, c)
+		}
+		if err := saveDocument(syncFile, state); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(stdout, "Synced with %s: %d received, %d sent.\n", remote, merge.Pulled, merge.Pushed)
+		if len(merge.Conflicts) > 0 {
+			fmt.Fprintf(stdout, "%s changed on both sides; kept this machine's version of each.\n", plural(len(merge.Conflicts), "task"))
+			fmt.Fprintln(stdout, "See them with 'task sync conflicts' and pick a version with 'task sync resolve'.")
+		}
+		return nil
+	}
+	return fmt.Errorf("%w %d times in a row; try again", errSyncStale, syncAttempts)
+}
+
+// syncRequest sends body, if any, as JSON to the server and decodes the
+// response into out.
+func syncRequest(method, endpoint string, body, out any) error {
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("error marshalling JSON: %w", err)
+		}
+		r = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, endpoint, r)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := &http.Client{Timeout: syncTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error reaching the remote: %w", err)
+	}
+	defer resp.Body.Close()
+
+	dec := json.NewDecoder(io.LimitReader(resp.Body, maxSyncDocument))
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if err := dec.Decode(out); err != nil {
+			return fmt.Errorf("invalid response from the remote: %w", err)
+		}
+		return nil
+	case http.StatusConflict:
+		return errSyncStale
+	}
+	var e struct{ Error string }
+	if dec.Decode(&e) == nil && e.Error != "" {
+		return fmt.Errorf("the remote refused the sync: %s", e.Error)
+	}
+	return fmt.Errorf("the remote refused the sync: %s", resp.Status)
+}
+
+// listConflicts prints the unresolved conflicts of the current project.
+func listConflicts() error {
+	state, err := loadSyncState()
+	if err != nil {
+		return err
+	}
+	if len(state.Conflicts) == 0 {
+		fmt.Fprintln(stdout, "No sync conflicts.")
+		return nil
+	}
+
+	tasks, err := loadTasks()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, "--- Sync Conflicts ---")
+	for _, c := range state.Conflicts {
+		id := "deleted here"
+		if i := slices.IndexFunc(tasks, func(t Task) bool { return t.UUID == c.UUID }); i >= 0 {
+			id = fmt.Sprintf("ID: %d", tasks[i].ID)
+		}
+		fmt.Fprintf(stdout, "[%s] %s\n", id, c.UUID)
+		fmt.Fprintf(stdout, "  local:  %s\n", describeConflictSide(c.Local))
+		fmt.Fprintf(stdout, "  remote: %s\n", describeConflictSide(c.Remote))
+	}
+	fmt.Fprintln(stdout, "----------------------")
+	return nil
+}
+
+// describeConflictSide summarizes one side's version of a conflicting task.
+func describeConflictSide(t *Task) string {
+	if t == nil {
+		return "deleted"
+	}
+	return fmt.Sprintf("%s [%s], updated %s", t.Description, t.Status, t.UpdatedAt.Format("2006-01-02 15:04"))
+}
+
+// resolveConflict settles a conflict, naming the task by ID or UUID, by
+// keeping the local version or taking the remote one. The choice is sent
+// with the next sync.
+func resolveConflict(ref string, remote bool) error {
+	state, err := loadSyncState()
+	if err != nil {
+		return err
+	}
+	tasks, err := loadTasks()
+	if err != nil {
+		return err
+	}
+
+	uuid := ref
+	if id, err := parseID(ref, "task"); err == nil {
+		if i := slices.IndexFunc(tasks, func(t Task) bool { return t.ID == id }); i >= 0 {
+			uuid = tasks[i].UUID
+		}
+	}
+	ci := slices.IndexFunc(state.Conflicts, func(c tracker.Conflict) bool { return c.UUID == uuid })
+	if ci < 0 {
+		return fmt.Errorf("no sync conflict for task '%s'", ref)
+	}
+	c := state.Conflicts[ci]
+
+	chosen := c.Local
+	if remote {
+		chosen = c.Remote
+	}
+	i := slices.IndexFunc(tasks, func(t Task) bool { return t.UUID == uuid })
+	switch {
+	case chosen == nil && i >= 0:
+		tasks = slices.Delete(tasks, i, i+1)
+	case chosen != nil && i >= 0:
+		chosen.ID = tasks[i].ID
+		tasks[i] = *chosen
+	case chosen != nil:
+		chosen.ID = getNextID(tasks)
+		tasks = append(tasks, *chosen)
+	}
+	if err := saveTasks(tasks); err != nil {
+		return err
+	}
+
+	state.Conflicts = slices.Delete(state.Conflicts, ci, ci+1)
+	if err := saveDocument(syncFile, state); err != nil {
+		return err
+	}
+	side := "local"
+	if remote {
+		side = "remote"
+	}
+	fmt.Fprintf(stdout, "Kept the %s version; run 'task sync' to send it.\n", side)
+	return nil
+}
+
+// conflictIDs offers the UUIDs of the current project's sync conflicts.
+func conflictIDs([]string) []candidate {
+	state, _ := loadSyncState()
+	var cs []candidate
+	for _, c := range state.Conflicts {
+		cs = append(cs, candidate{c.UUID, describeConflictSide(c.Local)})
+	}
+	return cs
+}
+
+// syncVersion identifies the state of a project's tasks.
+func syncVersion(tasks []Task) string {
+	data, _ := json.Marshal(tasks)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// handleSyncGet responds with a project's tasks and their version.
+func handleSyncGet(w http.ResponseWriter, r *http.Request) {
+	serveMu.Lock()
+	defer serveMu.Unlock()
+
+	tasks, ok := syncProjectTasks(w, r.PathValue("project"))
+	if ok {
+		writeJSON(w, http.StatusOK, syncDocument{syncVersion(tasks), tasks})
+	}
+}
+
+// handleSyncPut replaces a project's tasks with those merged by a client,
+// provided they are still at the version the client merged with; otherwise
+// it responds with 409 Conflict and the client merges again.
+func handleSyncPut(w http.ResponseWriter, r *http.Request) {
+	var doc syncDocument
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSyncDocument)).Decode(&doc); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON: " + err.Error()})
+		return
+	}
+
+	serveMu.Lock()
+	defer serveMu.Unlock()
+
+	name := r.PathValue("project")
+	tasks, ok := syncProjectTasks(w, name)
+	if !ok {
+		return
+	}
+	if syncVersion(tasks) != doc.Version {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": errSyncStale.Error()})
+		return
+	}
+
+	saved := currentProject
+	defer func() { currentProject = saved }()
+	selectProject(name)
+	if err := saveTasks(doc.Tasks); err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	tasks, err := loadTasks()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, syncDocument{syncVersion(tasks), tasks})
+}
+
+// syncProjectTasks returns the tasks of a project on the server, or
+// responds with an error and reports false.
+func syncProjectTasks(w http.ResponseWriter, name string) ([]Task, bool) {
+	saved := currentProject
+	defer func() { currentProject = saved }()
+	if !selectRequestProject(w, name) {
+		return nil, false
+	}
+	tasks, err := loadTasks()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return nil, false
+	}
+	return tasks, true
+}
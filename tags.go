@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+// updateTaskTags adds and removes tags on a task by ID. Each change is a tag
+// name prefixed with '+' to add it (the default) or '-' to remove it.
+func updateTaskTags(id int, changes []string) error {
+	tasks, err := loadTasks()
+	if err != nil {
+		return err
+	}
+
+	for i, task := range tasks {
+		if task.ID == id {
+			tags := slices.Clone(task.Tags)
+			for _, change := range changes {
+				remove := strings.HasPrefix(change, "-")
+				tag := normalizeTag(strings.TrimLeft(change, "+-"))
+				if tag == "" {
+					return fmt.Errorf("invalid tag '%s'", change)
+				}
+				if remove {
+					tags = slices.DeleteFunc(tags, func(t string) bool { return t == tag })
+				} else if !slices.Contains(tags, tag) {
+					tags = append(tags, tag)
+				}
+			}
+			slices.Sort(tags)
+			tasks[i].Tags = tags
+			tasks[i].UpdatedAt = clock()
+			if err := saveTasks(tasks); err != nil {
+				return err
+			}
+			fmt.Fprintf(stdout, "Task ID %d tags: %s\n", id, formatTags(tags))
+			return nil
+		}
+	}
+
+	return fmt.Errorf("task with ID %d %w", id, tracker.ErrNotFound)
+}
+
+// normalizeTag returns the canonical form of a tag name.
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// formatTags renders tags for display.
+func formatTags(tags []string) string {
+	if len(tags) == 0 {
+		return "(none)"
+	}
+	return "#" + strings.Join(tags, " #")
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+const templatesFile = "templates.json" // Task templates for 'task add --template'.
+
+// TaskTemplate describes a task added over and over, such as the steps of a
+// repeatable process. Its text may use variables, written {name}, that are
+// filled in from --var name=value when it is used.
+type TaskTemplate struct {
+	Description string   `json:"description"`
+	Tags        []string `json:"tags,omitempty"`
+	Priority    string   `json:"priority,omitempty"`
+	Checklist   []string `json:"checklist,omitempty"` // Steps added to the task's checklist.
+	Due         string   `json:"due,omitempty"`       // Date the task is due, from the day it is added, such as "in 3 days".
+}
+
+// exampleTaskTemplates is written when no template file exists yet.
+var exampleTaskTemplates = map[string]TaskTemplate{
+	"onboarding": {
+		Description: "Onboard {name}",
+		Tags:        []string{"hr"},
+		Priority:    tracker.PriorityHigh,
+		Checklist:   []string{"Create accounts for {name}", "Order a laptop", "Book a welcome lunch", "Pair {name} with a buddy"},
+		Due:         "in 7 days",
+	},
+}
+
+// templateVarPattern matches a variable in a template.
+var templateVarPattern = regexp.MustCompile(`\{([A-Za-z0-9_-]+)\}`)
+
+// templateVars holds the values of the variables given with --var.
+type templateVars map[string]string
+
+func (v templateVars) String() string {
+	return formatFields(v)
+}
+
+func (v templateVars) Set(s string) error {
+	name, value, ok := strings.Cut(s, "=")
+	if !ok || !templateVarPattern.MatchString("{"+name+"}") {
+		return fmt.Errorf("invalid variable '%s'; use name=value", s)
+	}
+	v[name] = value
+	return nil
+}
+
+// loadTaskTemplates reads the task templates, writing an example file and
+// returning an error if none exists yet.
+func loadTaskTemplates() (map[string]TaskTemplate, error) {
+	data, err := os.ReadFile(templatesFile)
+	if os.IsNotExist(err) {
+		example, err := json.MarshalIndent(exampleTaskTemplates, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("error marshalling JSON: %w", err)
+		}
+		if err := writeFile(templatesFile, example, 0644); err != nil {
+			return nil, fmt.Errorf("error writing file: %w", err)
+		}
+		return nil, fmt.Errorf("no task templates found; an example was written to %s, edit it and run again", templatesFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+
+	var templates map[string]TaskTemplate
+	if err := json.Unmarshal(data, &templates); err != nil {
+		return nil, fmt.Errorf("error unmarshalling %s: %w", templatesFile, err)
+	}
+	return templates, nil
+}
+
+// templateCandidates completes the names of the task templates.
+func templateCandidates() []candidate {
+	templates, err := loadTaskTemplates()
+	if err != nil {
+		return nil
+	}
+	var candidates []candidate
+	for _, name := range sortedKeys(templates) {
+		candidates = append(candidates, candidate{name, templates[name].Description})
+	}
+	return candidates
+}
+
+// expandTemplate returns the task the named template makes with vars
+// filled in. Every variable the template uses must be given, and every one
+// given must be used, so that a misspelt name is caught.
+func expandTemplate(name string, vars templateVars) (Task, error) {
+	templates, err := loadTaskTemplates()
+	if err != nil {
+		return Task{}, err
+	}
+	tmpl, ok := templates[name]
+	if !ok {
+		return Task{}, usagef("task template '%s' not found in %s; use one of: %s", name, templatesFile, strings.Join(sortedKeys(templates), ", "))
+	}
+
+	var missing []string
+	used := map[string]bool{}
+	expand := func(text string) string {
+		return templateVarPattern.ReplaceAllStringFunc(text, func(v string) string {
+			name := v[1 : len(v)-1]
+			used[name] = true
+			value, ok := vars[name]
+			if !ok && !slices.Contains(missing, name) {
+				missing = append(missing, name)
+			}
+			return value
+		})
+	}
+
+	task := Task{Description: expand(tmpl.Description)}
+	for _, tag := range tmpl.Tags {
+		if tag := normalizeTag(expand(tag)); tag != "" && !slices.Contains(task.Tags, tag) {
+			task.Tags = append(task.Tags, tag)
+		}
+	}
+	slices.Sort(task.Tags)
+	for _, item := range tmpl.Checklist {
+		task.Checklist = append(task.Checklist, ChecklistItem{Text: expand(item)})
+	}
+	due := expand(tmpl.Due)
+
+	if len(missing) > 0 {
+		return Task{}, usagef("template '%s' needs a value for %s; give it with --var", name, strings.Join(missing, ", "))
+	}
+	for _, v := range sortedKeys(vars) {
+		if !used[v] {
+			return Task{}, usagef("template '%s' has no variable {%s}", name, v)
+		}
+	}
+	if tmpl.Priority != "" {
+		if !tracker.IsValidPriority(tmpl.Priority) {
+			return Task{}, fmt.Errorf("invalid priority '%s' in template '%s' in %s", tmpl.Priority, name, templatesFile)
+		}
+		task.Priority = tmpl.Priority
+	}
+	if due != "" {
+		at, err := parseDate(due, clock())
+		if err != nil {
+			return Task{}, fmt.Errorf("invalid due date in template '%s' in %s: %w", name, templatesFile, err)
+		}
+		task.Due = &at
+	}
+	return task, nil
+}
+
+// addTaskFromTemplate adds the task the named template makes, with fields.
+func addTaskFromTemplate(name string, vars templateVars, fields map[string]string) error {
+	task, err := expandTemplate(name, vars)
+	if err != nil {
+		return err
+	}
+	if len(fields) > 0 {
+		task.Fields = fields
+	}
+	added, err := tr().AddTaskFrom(task)
+	if err != nil {
+		return err
+	}
+	printDone(msg("Task added successfully (ID: %d)\n"), added.ID)
+	return nil
+}
@@ -0,0 +1,25 @@
+//go:build !linux && !darwin && !windows
+
+package main
+
+import "os"
+
+// terminalWidth returns 0: the terminal size is not known on this platform.
+func terminalWidth(*os.File) int {
+	return 0
+}
+
+// terminalHeight returns 0: the terminal size is not known on this platform.
+func terminalHeight(*os.File) int {
+	return 0
+}
+
+// enableANSI reports that ANSI escape codes can be used.
+func enableANSI(*os.File) bool {
+	return true
+}
+
+// disableEcho returns nil: echoing cannot be turned off on this platform.
+func disableEcho(*os.File) func() {
+	return nil
+}
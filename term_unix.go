@@ -0,0 +1,58 @@
+//go:build linux || darwin
+
+package main
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// terminalWidth returns the number of columns of the terminal f is
+// connected to, or 0 if it is not a terminal.
+func terminalWidth(f *os.File) int {
+	cols, _ := terminalSize(f)
+	return cols
+}
+
+// terminalHeight returns the number of rows of the terminal f is connected
+// to, or 0 if it is not a terminal.
+func terminalHeight(f *os.File) int {
+	_, rows := terminalSize(f)
+	return rows
+}
+
+// terminalSize returns the columns and rows of the terminal f is connected
+// to, or zeros if it is not a terminal.
+func terminalSize(f *os.File) (int, int) {
+	var size struct{ rows, cols, xpixels, ypixels uint16 }
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&size)))
+	if errno != 0 {
+		return 0, 0
+	}
+	return int(size.cols), int(size.rows)
+}
+
+// enableANSI reports that ANSI escape codes can be used: terminals here
+// support them.
+func enableANSI(*os.File) bool {
+	return true
+}
+
+// disableEcho stops the terminal f is connected to from echoing what is
+// typed, returning a function that turns echoing back on, or nil if f is
+// not a terminal.
+func disableEcho(f *os.File) func() {
+	var state syscall.Termios
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), ioctlGetTermios, uintptr(unsafe.Pointer(&state))); errno != 0 {
+		return nil
+	}
+	quiet := state
+	quiet.Lflag &^= syscall.ECHO
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), ioctlSetTermios, uintptr(unsafe.Pointer(&quiet))); errno != 0 {
+		return nil
+	}
+	return func() {
+		syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), ioctlSetTermios, uintptr(unsafe.Pointer(&state)))
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	enableEchoInput                 = 0x4
+	enableVirtualTerminalProcessing = 0x4
+)
+
+var (
+	kernel32                       = syscall.NewLazyDLL("kernel32.dll")
+	procSetConsoleMode             = kernel32.NewProc("SetConsoleMode")
+	procGetConsoleScreenBufferInfo = kernel32.NewProc("GetConsoleScreenBufferInfo")
+)
+
+// enableANSI turns on ANSI escape codes in the console f writes to and
+// reports whether it supports them (Windows 10 and later).
+func enableANSI(f *os.File) bool {
+	var mode uint32
+	h := syscall.Handle(f.Fd())
+	if err := syscall.GetConsoleMode(h, &mode); err != nil {
+		return false
+	}
+	if mode&enableVirtualTerminalProcessing != 0 {
+		return true
+	}
+	r, _, _ := procSetConsoleMode.Call(uintptr(h), uintptr(mode|enableVirtualTerminalProcessing))
+	return r != 0
+}
+
+// terminalWidth returns the number of columns of the console window f is
+// connected to, or 0 if it is not a console.
+func terminalWidth(f *os.File) int {
+	cols, _ := consoleWindow(f)
+	return cols
+}
+
+// terminalHeight returns the number of rows of the console window f is
+// connected to, or 0 if it is not a console.
+func terminalHeight(f *os.File) int {
+	_, rows := consoleWindow(f)
+	return rows
+}
+
+// consoleWindow returns the columns and rows of the console window f is
+// connected to, or zeros if it is not a console.
+func consoleWindow(f *os.File) (int, int) {
+	var info struct {
+		size, cursor             struct{ x, y int16 }
+		attributes               uint16
+		left, top, right, bottom int16
+		maxSize                  struct{ x, y int16 }
+	}
+	r, _, _ := procGetConsoleScreenBufferInfo.Call(f.Fd(), uintptr(unsafe.Pointer(&info)))
+	if r == 0 {
+		return 0, 0
+	}
+	return int(info.right-info.left) + 1, int(info.bottom-info.top) + 1
+}
+
+// disableEcho stops the console f reads from from echoing what is typed,
+// returning a function that turns echoing back on, or nil if f is not a
+// console.
+func disableEcho(f *os.File) func() {
+	var mode uint32
+	h := syscall.Handle(f.Fd())
+	if err := syscall.GetConsoleMode(h, &mode); err != nil {
+		return nil
+	}
+	if r, _, _ := procSetConsoleMode.Call(uintptr(h), uintptr(mode&^enableEchoInput)); r == 0 {
+		return nil
+	}
+	return func() {
+		procSetConsoleMode.Call(uintptr(h), uintptr(mode))
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Timer configures how a running pomodoro reports its progress besides the
+// countdown it prints.
+type Timer struct {
+	// Title shows the countdown in the terminal's title, so it can be seen
+	// from other windows and tabs.
+	Title bool `json:"title,omitempty"`
+
+	// Milestones are the points at which to send a notification: a share
+	// of the pomodoro done, such as "50%", or the time left, such as "5m".
+	// The end of the pomodoro is always notified.
+	Milestones []string `json:"milestones,omitempty"`
+}
+
+// validate checks the milestones.
+func (t Timer) validate() error {
+	for _, m := range t.Milestones {
+		if _, err := parseMilestone(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// milestone is a point in a pomodoro to notify at.
+type milestone struct {
+	percent int           // Of the pomodoro done; 0 for a time left.
+	left    time.Duration // Of the pomodoro remaining.
+}
+
+// parseMilestone parses "50%" or "5m".
+func parseMilestone(s string) (milestone, error) {
+	if n, ok := strings.CutSuffix(s, "%"); ok {
+		percent, err := strconv.Atoi(n)
+		if err != nil || percent <= 0 || percent >= 100 {
+			return milestone{}, fmt.Errorf("invalid milestone '%s'; use a percentage between 0 and 100", s)
+		}
+		return milestone{percent: percent}, nil
+	}
+	left, err := time.ParseDuration(s)
+	if err != nil || left <= 0 {
+		return milestone{}, fmt.Errorf("invalid milestone '%s'; use a percentage such as 50%% or the time left such as 5m", s)
+	}
+	return milestone{left: left}, nil
+}
+
+// timerProgress reports a running pomodoro's progress as configured.
+type timerProgress struct {
+	title   bool
+	task    string
+	length  time.Duration
+	pending []milestone // Not yet reached, soonest first.
+}
+
+// newTimerProgress returns the progress reporting of a pomodoro of length
+// for a task, saving the terminal title if it is to be replaced.
+func newTimerProgress(t Timer, task string, length time.Duration) *timerProgress {
+	p := &timerProgress{title: t.Title && isTerminal(os.Stdout) && enableANSI(os.Stdout), task: task, length: length}
+	for _, s := range t.Milestones {
+		m, _ := parseMilestone(s)
+		if m.left < length {
+			p.pending = append(p.pending, m)
+		}
+	}
+	slices.SortFunc(p.pending, func(a, b milestone) int { return cmp.Compare(p.remainingAt(b), p.remainingAt(a)) })
+	if p.title {
+		fmt.Fprint(stdout, "\033[22;0t") // Save the title to restore it in done.
+	}
+	return p
+}
+
+// remainingAt returns the time left when m is reached.
+func (p *timerProgress) remainingAt(m milestone) time.Duration {
+	if m.percent > 0 {
+		return p.length * time.Duration(100-m.percent) / 100
+	}
+	return m.left
+}
+
+// update reports the time remaining, notifying about the milestones it
+// has passed.
+func (p *timerProgress) update(remaining time.Duration) {
+	if p.title {
+		fmt.Fprintf(stdout, "\033]0;%s %s\007", formatCountdown(remaining), p.task)
+	}
+	for len(p.pending) > 0 && remaining <= p.remainingAt(p.pending[0]) {
+		m := p.pending[0]
+		p.pending = p.pending[1:]
+		if m.percent > 0 {
+			notify(fmt.Sprintf("Pomodoro %d%% done", m.percent), fmt.Sprintf("%s left on %s", formatCountdown(remaining), p.task))
+		} else {
+			notify(fmt.Sprintf("%s left", formatCountdown(m.left)), fmt.Sprintf("Wrap up %s", p.task))
+		}
+	}
+}
+
+// done restores the terminal title.
+func (p *timerProgress) done() {
+	if p.title {
+		fmt.Fprint(stdout, "\033[23;0t")
+	}
+}
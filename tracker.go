@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+// Types shared with the tracker library.
+type (
+	Task          = tracker.Task
+	ChecklistItem = tracker.ChecklistItem
+	Pomodoro      = tracker.Pomodoro
+	Workflow      = tracker.Workflow
+	StatusDef     = tracker.StatusDef
+	Expense       = tracker.Expense
+	Income        = tracker.Income
+)
+
+const (
+	tasksFile    = tracker.TasksFile
+	expensesFile = tracker.ExpensesFile
+)
+
+// baseTracker holds the settings shared by the trackers of every project.
+var baseTracker *tracker.Tracker
+
+// tr returns the tracker for the current project's data files.
+func tr() *tracker.Tracker {
+	if baseTracker == nil {
+		opts := tracker.Options{
+			Workflow:       config.Workflow,
+			Passphrase:     promptPassphrase,
+			Journal:        config.Storage.Journal,
+			Now:            clock,
+			Location:       time.Local,
+			MaxDescription: config.Validation.MaxDescription,
+			MaxAmount:      config.Validation.MaxAmount,
+			Currencies:     config.Currency.codes(),
+			OnUpgrade: func(path string, from, to int, backup string) {
+				fmt.Fprintf(stdout, "Upgraded %s from schema version %d to %d (backup: %s)\n", path, from, to, backup)
+			},
+		}
+		if !dryRun {
+			opts.ReviewTasks = reviewSavedTasks
+			opts.ReviewExpenses = reviewSavedExpenses
+		}
+		baseTracker = tracker.New(opts)
+	}
+	return baseTracker.At(projectDir(currentProject))
+}
+
+// reviewSavedTasks runs the hooks on tasks about to be saved, if any are set,
+// notes the changes webhooks are sent and records them in the audit log.
+func reviewSavedTasks(saved, tasks []Task) ([]Task, error) {
+	if config.Hooks.configured() {
+		var err error
+		if tasks, err = runTaskHooks(saved, tasks); err != nil {
+			return nil, err
+		}
+	}
+	if len(config.Webhooks) > 0 {
+		noteTaskEvents(saved, tasks)
+	}
+	if err := auditTasks(saved, tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// reviewSavedExpenses notes the expenses webhooks are sent for and records
+// the changes in the audit log.
+func reviewSavedExpenses(saved, expenses []Expense) ([]Expense, error) {
+	if len(config.Webhooks) > 0 {
+		noteExpenseEvents(saved, expenses)
+	}
+	if err := auditExpenses(saved, expenses); err != nil {
+		return nil, err
+	}
+	return expenses, nil
+}
+
+// loadDocument reads the records of a data file in the current project.
+func loadDocument(name string) (json.RawMessage, error) {
+	return tr().ReadDocument(name)
+}
+
+// loadStateDocument reads a data file holding a single record, such as the
+// state of the last sync, into v. A missing file, or the empty one that
+// encryption creates, leaves v as it is.
+func loadStateDocument(name string, v any) error {
+	data, err := loadDocument(name)
+	if err != nil || data == nil || string(bytes.TrimSpace(data)) == "[]" {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("error unmarshalling %s: %w", name, err)
+	}
+	return nil
+}
+
+// saveDocument writes the records of a data file in the current project.
+func saveDocument(name string, items any) error {
+	return tr().WriteDocument(name, items)
+}
+
+// loadTasks reads tasks from the saved JSON file.
+func loadTasks() ([]Task, error) {
+	return tr().Tasks()
+}
+
+// saveTasks writes the tasks slice to the JSON file.
+func saveTasks(tasks []Task) error {
+	return tr().SaveTasks(tasks)
+}
+
+// getNextID creates a new ID.
+func getNextID(tasks []Task) int {
+	return tracker.NextTaskID(tasks)
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+// inTransaction is set between begin and commit or rollback.
+var inTransaction bool
+
+// atomicBatch is set while an atomic batch, the default, runs: its
+// transaction spans the whole batch.
+var atomicBatch bool
+
+// transactionCommands returns the begin, commit and rollback commands,
+// which group the changes of several commands in the shell or a batch file.
+func transactionCommands() []*command {
+	return []*command{
+		{
+			name: "begin", summary: "Start a transaction in the shell or a batch file", group: groupData,
+			setup: run(func([]string) error { return beginTransaction() }),
+		},
+		{
+			name: "commit", summary: "Save the changes made since 'begin'", group: groupData,
+			setup: run(func([]string) error { return commitTransaction() }),
+		},
+		{
+			name: "rollback", summary: "Drop the changes made since 'begin'", group: groupData,
+			setup: run(func([]string) error {
+				if atomicBatch {
+					return errors.New("an atomic batch is rolled back by failing; run it with --atomic=false to use rollback")
+				}
+				if !inTransaction {
+					return errors.New("no transaction is open")
+				}
+				rollbackTransaction()
+				fmt.Fprintln(stdout, "Transaction rolled back.")
+				return nil
+			}),
+		},
+	}
+}
+
+// beginTransaction saves the changes made so far and holds the ones after
+// it until commit or rollback.
+func beginTransaction() error {
+	if session == "" {
+		return errors.New("transactions can only be used in 'task shell' or a file run with 'task batch'")
+	}
+	if atomicBatch {
+		return errors.New("an atomic batch is already one transaction; run it with --atomic=false to use begin")
+	}
+	if inTransaction {
+		return errors.New("a transaction is already open; commit or roll it back first")
+	}
+	if err := commitSession(); err != nil {
+		return err
+	}
+	baseTracker.Begin()
+	inTransaction = true
+	fmt.Fprintln(stdout, "Transaction started.")
+	return nil
+}
+
+// commitTransaction saves the changes made since begin.
+func commitTransaction() error {
+	if atomicBatch {
+		return errors.New("an atomic batch is committed when it ends; run it with --atomic=false to use commit")
+	}
+	if !inTransaction {
+		return errors.New("no transaction is open")
+	}
+	n := len(baseTracker.Pending())
+	if err := commitSession(); err != nil {
+		return err
+	}
+	baseTracker.Begin()
+	inTransaction = false
+	fmt.Fprintf(stdout, "Transaction committed (%s changed).\n", plural(n, "file"))
+	return nil
+}
+
+// rollbackTransaction drops the changes made since begin.
+func rollbackTransaction() {
+	baseTracker.Rollback()
+	baseTracker.Begin()
+	dropSnapshot()
+	dropWebhooks()
+	inTransaction = false
+}
+
+// batchCommand returns the batch command.
+func batchCommand(root *command) *command {
+	return &command{
+		name: "batch", args: "[file]", summary: "Run the commands in a file or standard input, one per line or as a JSON array", group: groupData,
+		setup: func(fs *flag.FlagSet) runFunc {
+			atomic := fs.Bool("atomic", true, "run every command in one transaction, saved once at the end; with false, save each command as it runs")
+			return func(args []string) error {
+				if session != "" {
+					return fmt.Errorf("cannot run a batch file from a %s", session)
+				}
+				if len(args) == 0 || args[0] == "-" {
+					return runBatch(root, os.Stdin, *atomic)
+				}
+				f, err := os.Open(args[0])
+				if err != nil {
+					return fmt.Errorf("error opening file: %w", err)
+				}
+				defer f.Close()
+				return runBatch(root, f, *atomic)
+			}
+		},
+	}
+}
+
+// batchOp is one command of a batch file, and where it is.
+type batchOp struct {
+	where string // "line 3: add milk" or "operation 2".
+	words []string
+	err   error // Set if the command could not be parsed.
+}
+
+// parseBatch splits a batch file into its commands: one per line, or a JSON
+// array whose operations are each a command line or an array of arguments.
+func parseBatch(data []byte) ([]batchOp, error) {
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		var ops []batchOp
+		for n, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			words, err := splitWords(line)
+			ops = append(ops, batchOp{fmt.Sprintf("line %d: %s", n+1, line), words, err})
+		}
+		return ops, nil
+	}
+
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, usagef("invalid JSON array of operations: %v", err)
+	}
+	ops := make([]batchOp, len(raw))
+	for i, item := range raw {
+		op := batchOp{where: fmt.Sprintf("operation %d", i+1)}
+		var line string
+		if err := json.Unmarshal(item, &line); err == nil {
+			op.words, op.err = splitWords(line)
+			op.where += ": " + line
+		} else if err := json.Unmarshal(item, &op.words); err != nil {
+			op.err = tracker.Invalid(fmt.Errorf("an operation must be a command line or an array of arguments, not %s", item))
+		} else {
+			op.where += ": " + strings.Join(op.words, " ")
+		}
+		ops[i] = op
+	}
+	return ops, nil
+}
+
+// runBatch runs the commands in r, stopping at the first that fails. If
+// atomic is set, the whole batch is one transaction: the data files are
+// read once and saved once, when every command has succeeded. Otherwise
+// each command is saved as it runs, except between begin and commit, whose
+// changes are saved together; a failure or the end of the file drops them
+// instead.
+func runBatch(root *command, r io.Reader, atomic bool) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("error reading file: %w", err)
+	}
+	ops, err := parseBatch(data)
+	if err != nil {
+		return err
+	}
+	_, project, _ := extractProjectFlag(os.Args[1:])
+	session = "batch"
+	tr()
+	baseTracker.Begin()
+	atomicBatch, inTransaction = atomic, atomic
+	defer func() {
+		baseTracker.Rollback()
+		dropSnapshot()
+		dropWebhooks()
+		atomicBatch, inTransaction = false, false
+		session = ""
+	}()
+
+	for _, op := range ops {
+		err := op.err
+		if err == nil {
+			err = runLine(root, project, op.words)
+		}
+		if err == nil && !inTransaction {
+			err = commitSession()
+			baseTracker.Begin()
+		}
+		if err != nil {
+			fmt.Fprintf(stdout, "Stopped at %s\n", op.where)
+			if inTransaction {
+				fmt.Fprintln(stdout, "Transaction rolled back.")
+			}
+			return err
+		}
+	}
+	if atomic {
+		n := len(baseTracker.Pending())
+		if err := commitSession(); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "Batch committed (%s, %s changed).\n", plural(len(ops), "command"), plural(n, "file"))
+		return nil
+	}
+	if inTransaction {
+		fmt.Fprintln(stdout, "Transaction rolled back: it was not committed.")
+	}
+	return nil
+}
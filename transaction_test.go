@@ -0,0 +1,27 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestBatchIsAtomic(t *testing.T) {
+	out := setupCLI(t)
+	if err := os.WriteFile("batch.txt", []byte("add \"Buy milk\"\nmark done 7\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := runCLI(t, out, "batch", "batch.txt"); err == nil {
+		t.Fatal("batch with a failing command succeeded")
+	}
+	if tasks, err := loadTasks(); err != nil || len(tasks) != 0 {
+		t.Errorf("failed batch saved %+v, %v", tasks, err)
+	}
+
+	if _, err := runCLI(t, out, "batch", "--atomic=false", "batch.txt"); err == nil {
+		t.Fatal("batch with a failing command succeeded")
+	}
+	if tasks, err := loadTasks(); err != nil || len(tasks) != 1 {
+		t.Errorf("non-atomic batch saved %+v, %v; want the task added before the failure", tasks, err)
+	}
+}
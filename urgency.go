@@ -0,0 +1,216 @@
+package main
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+// Urgency factors, as named in the urgency weights of config.json.
+const (
+	urgencyHigh     = tracker.PriorityHigh
+	urgencyMedium   = tracker.PriorityMedium
+	urgencyLow      = tracker.PriorityLow
+	urgencyDue      = "due"      // Due now or overdue; a fifth of it two weeks out.
+	urgencyAge      = "age"      // A year old or more; in proportion for younger tasks.
+	urgencyBlocking = "blocking" // Other open tasks wait on the task.
+	urgencyBlocked  = "blocked"  // The task waits on an open task.
+)
+
+const defaultNext = 3 // Tasks 'task next' shows without a count.
+
+// Urgency weighs the factors of a task's urgency by name, over their
+// defaults.
+type Urgency map[string]float64
+
+// defaultUrgency returns the weight of each urgency factor unless configured.
+func defaultUrgency() Urgency {
+	return Urgency{
+		urgencyHigh:     6,
+		urgencyMedium:   3.9,
+		urgencyLow:      1.8,
+		urgencyDue:      12,
+		urgencyAge:      2,
+		urgencyBlocking: 8,
+		urgencyBlocked:  -5,
+	}
+}
+
+// validate checks that u only names urgency factors.
+func (u Urgency) validate() error {
+	factors := sortedKeys(defaultUrgency())
+	for name := range u {
+		if !slices.Contains(factors, name) {
+			return fmt.Errorf("unknown factor '%s'; use %s", name, strings.Join(factors, ", "))
+		}
+	}
+	return nil
+}
+
+// weight returns the weight of factor, or its default.
+func (u Urgency) weight(factor string) float64 {
+	if w, ok := u[factor]; ok {
+		return w
+	}
+	return defaultUrgency()[factor]
+}
+
+// urgencyScore is how urgent a task is, with what made it so.
+type urgencyScore struct {
+	score   float64
+	reasons []string
+}
+
+// urgencies scores the open tasks of tasks, which must be the whole task
+// list for tasks waiting on each other to count. Done tasks score nothing.
+func urgencies(tasks []Task, now time.Time) map[int]urgencyScore {
+	open := map[string]bool{} // By UUID.
+	for _, t := range tasks {
+		open[t.UUID] = !config.Workflow.IsDone(t.Status)
+	}
+	waiting := map[string]int{}
+	for _, t := range tasks {
+		if open[t.UUID] {
+			for _, uuid := range t.WaitsOn {
+				waiting[uuid]++
+			}
+		}
+	}
+
+	scores := map[int]urgencyScore{}
+	for _, t := range tasks {
+		if !open[t.UUID] {
+			continue
+		}
+		var u urgencyScore
+		add := func(factor string, scale float64, reason string) {
+			u.score += config.Urgency.weight(factor) * scale
+			u.reasons = append(u.reasons, reason)
+		}
+		if t.Priority != "" {
+			add(t.Priority, 1, t.Priority+" priority")
+		}
+		if t.Due != nil {
+			if days := tracker.Deadline(*t.Due).Sub(now).Hours() / 24; days < 14 {
+				reason := relativeDue(*t.Due, now)
+				if !tracker.Deadline(*t.Due).Before(now) {
+					reason = fmt.Sprintf(msg("due %s"), reason)
+				}
+				add(urgencyDue, min(1, 0.2+0.8*(14-days)/21), reason)
+			}
+		}
+		if age := now.Sub(t.CreatedAt).Hours() / 24; age >= 7 {
+			u.score += config.Urgency.weight(urgencyAge) * min(1, age/365)
+		}
+		if n := waiting[t.UUID]; n > 0 {
+			add(urgencyBlocking, 1, fmt.Sprintf("%s waiting on it", plural(n, "task")))
+		}
+		if slices.ContainsFunc(t.WaitsOn, func(uuid string) bool { return open[uuid] }) {
+			add(urgencyBlocked, 1, "waits on other tasks")
+		}
+		u.score = math.Round(u.score*10) / 10
+		scores[t.ID] = u
+	}
+	return scores
+}
+
+// sortByUrgency orders tasks most urgent first, then by ID, with done
+// tasks last.
+func sortByUrgency(tasks []Task) error {
+	all, err := tr().Tasks()
+	if err != nil {
+		return err
+	}
+	scores := urgencies(all, clock())
+	slices.SortStableFunc(tasks, func(a, b Task) int {
+		if doneA, doneB := config.Workflow.IsDone(a.Status), config.Workflow.IsDone(b.Status); doneA != doneB {
+			if doneA {
+				return 1
+			}
+			return -1
+		}
+		return cmp.Or(cmp.Compare(scores[b.ID].score, scores[a.ID].score), cmp.Compare(a.ID, b.ID))
+	})
+	return nil
+}
+
+// nextCommand returns the next command.
+func nextCommand() *command {
+	return &command{
+		name: "next", args: "[n]", summary: "Show the n most urgent tasks to work on now", group: groupPlanning,
+		completeFlags: map[string]func() []candidate{"tag": tagCandidates},
+		setup: func(fs *flag.FlagSet) runFunc {
+			tag := fs.String("tag", "", "only consider tasks with this tag")
+			return func(args []string) error {
+				n := defaultNext
+				if len(args) > 1 {
+					return usagef("too many arguments")
+				}
+				if len(args) == 1 {
+					var err error
+					if n, err = strconv.Atoi(args[0]); err != nil || n < 1 {
+						return usagef("invalid count '%s'", args[0])
+					}
+				}
+				return printNext(n, normalizeTag(strings.TrimPrefix(*tag, "#")))
+			}
+		},
+	}
+}
+
+// printNext prints the n most urgent tasks that can be worked on now: open,
+// not snoozed, not scheduled for later and not waiting on open tasks.
+func printNext(n int, tag string) error {
+	all, err := tr().Tasks()
+	if err != nil {
+		return err
+	}
+	now := clock()
+	scores := urgencies(all, now)
+	tomorrow := startOfDay(now).AddDate(0, 0, 1)
+	var ready []Task
+	for _, t := range all {
+		if config.Workflow.IsDone(t.Status) || t.Snoozed(now) || tag != "" && !slices.Contains(t.Tags, tag) {
+			continue
+		}
+		if t.Scheduled != nil && !t.Scheduled.Before(tomorrow) {
+			continue
+		}
+		if slices.ContainsFunc(t.WaitsOn, func(uuid string) bool {
+			i := slices.IndexFunc(all, func(o Task) bool { return o.UUID == uuid })
+			return i >= 0 && !config.Workflow.IsDone(all[i].Status)
+		}) {
+			continue
+		}
+		ready = append(ready, t)
+	}
+	if len(ready) == 0 {
+		fmt.Fprintln(stdout, "Nothing to do right now.")
+		return nil
+	}
+	slices.SortStableFunc(ready, func(a, b Task) int {
+		return cmp.Or(cmp.Compare(scores[b.ID].score, scores[a.ID].score), cmp.Compare(a.ID, b.ID))
+	})
+
+	fmt.Fprintln(stdout, "--- Next ---")
+	for i, t := range ready[:min(n, len(ready))] {
+		u := scores[t.ID]
+		fmt.Fprintf(stdout, "%d. [ID: %d] [%s] %s  %s\n", i+1, t.ID, colorStatus(t.Status), t.Description,
+			colorize(fmt.Sprintf("(urgency %.1f)", u.score), "gray"))
+		if len(u.reasons) > 0 {
+			fmt.Fprintf(stdout, "   %s\n", strings.Join(u.reasons, ", "))
+		}
+	}
+	fmt.Fprintln(stdout, "-----------------")
+	if len(ready) > n {
+		fmt.Fprintf(stdout, "%d more ready after these.\n", len(ready)-n)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+const defaultWatchInterval = time.Second // How often 'task watch' looks for changes.
+
+// watchCommand returns the watch command.
+func watchCommand() *command {
+	return &command{
+		name: "watch", args: "[command]", summary: "Run a task command again, or a command of your own, whenever the data changes", group: groupData,
+		setup: func(fs *flag.FlagSet) runFunc {
+			run := fs.String("exec", "", "run this `command` instead of a task command")
+			interval := fs.Duration("interval", defaultWatchInterval, "how often to look for changes")
+			return func(args []string) error {
+				if *run != "" && len(args) > 0 {
+					return usagef("give either a task command or --exec")
+				}
+				if *interval <= 0 {
+					return usagef("--interval must be positive")
+				}
+				if len(args) == 0 {
+					args = []string{"list"}
+				}
+				return watchData(args, strings.Fields(*run), *interval)
+			}
+		},
+	}
+}
+
+// watchData runs a task command, or the user's command if run is set, and
+// again whenever the current project's data files change, until
+// interrupted. The files are compared every interval rather than watched
+// through the OS, so the same works on every platform. A task command gets
+// a cleared screen each time, so the output stays in place as in a tmux
+// pane.
+func watchData(args, run []string, interval time.Duration) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	project := cmp.Or(currentProject, defaultProject)
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var seen map[string]time.Time
+	for {
+		if state := watchedState(); !maps.Equal(state, seen) {
+			seen = state
+			var cmd *exec.Cmd
+			if len(run) > 0 {
+				cmd = exec.Command(run[0], run[1:]...)
+			} else {
+				if isTerminal(os.Stdout) {
+					fmt.Fprint(stdout, "\x1b[H\x1b[2J")
+				}
+				cmd = exec.Command(executable, append([]string{"--project", project}, args...)...)
+			}
+			cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+			if err := cmd.Run(); err != nil {
+				fmt.Fprintf(stdout, "Warning: %s: %v\n", strings.Join(cmd.Args, " "), err)
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-interrupt:
+			fmt.Fprintln(stdout)
+			return nil
+		}
+	}
+}
+
+// watchedState returns the modification times of the current project's
+// data files that exist. The command history is left out, as the task
+// commands run record themselves in it.
+func watchedState() map[string]time.Time {
+	names := append([]string{tasksFile, tracker.JournalFile, tracker.ArchiveFile, expensesFile, tracker.IncomeFile}, extraDataFiles...)
+	state := map[string]time.Time{}
+	for _, name := range names {
+		if info, err := os.Stat(filepath.Join(projectDir(currentProject), name)); err == nil {
+			state[name] = info.ModTime()
+		}
+	}
+	return state
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	webhookTimeout  = 10 * time.Second // How long a webhook delivery may take.
+	webhookAttempts = 3                // Deliveries tried before giving up.
+	webhookBackoff  = time.Second      // Wait before the first retry, doubled after each.
+)
+
+// Events webhooks can be sent.
+const (
+	eventTaskAdded     = "task.added"
+	eventTaskCompleted = "task.completed"
+	eventTaskDeleted   = "task.deleted"
+	eventExpenseOver   = "expense.over" // An expense of at least the webhook's threshold.
+)
+
+var webhookEvents = []string{eventTaskAdded, eventTaskCompleted, eventTaskDeleted, eventExpenseOver}
+
+// Webhook is a URL that is sent a JSON payload when tasks are added,
+// completed or deleted, or a large expense is recorded. Payloads are sent
+// once the change is saved, and are signed with an HMAC of the secret, if
+// set, in the X-Task-Signature header.
+type Webhook struct {
+	URL    string   `json:"url"`              // May name a secret ("secret:<name>").
+	Events []string `json:"events,omitempty"` // Every event if empty.
+	Secret string   `json:"secret,omitempty"` // Signs the payloads; may name a secret.
+
+	// Threshold is the amount, in the expense's own currency, from which
+	// expense.over is sent.
+	Threshold float64 `json:"threshold,omitempty"`
+}
+
+// wants reports whether w is sent event.
+func (w Webhook) wants(event string) bool {
+	return len(w.Events) == 0 || slices.Contains(w.Events, event)
+}
+
+// validateWebhooks checks that each webhook has a URL and known events.
+func validateWebhooks(hooks []Webhook) error {
+	for _, w := range hooks {
+		if !strings.HasPrefix(w.URL, secretPrefix) {
+			u, err := url.Parse(w.URL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("'%s' is not an http or https URL", w.URL)
+			}
+		}
+		for _, event := range w.Events {
+			if !slices.Contains(webhookEvents, event) {
+				return fmt.Errorf("unknown event '%s'; use one of: %s", event, strings.Join(webhookEvents, ", "))
+			}
+		}
+		if w.Threshold < 0 {
+			return fmt.Errorf("threshold %g must not be negative", w.Threshold)
+		}
+	}
+	return nil
+}
+
+// webhookPayload is what a webhook is sent. Text summarizes the event, so
+// that chat services such as Slack show it as it is.
+type webhookPayload struct {
+	Event   string    `json:"event"`
+	Text    string    `json:"text"`
+	Project string    `json:"project"`
+	At      time.Time `json:"at"`
+	Task    *Task     `json:"task,omitempty"`
+	Expense *Expense  `json:"expense,omitempty"`
+}
+
+// pendingWebhooks holds the payloads of changes not saved yet; like
+// pendingSnapshot, they are sent when the shell or a batch file saves.
+var pendingWebhooks []webhookPayload
+
+// noteTaskEvents queues the webhook payloads for the tasks added, completed
+// or deleted between saved and tasks. Tasks moved to or from the archive
+// are neither added nor deleted.
+func noteTaskEvents(saved, tasks []Task) {
+	previous := map[string]Task{}
+	for _, task := range saved {
+		previous[task.UUID] = task
+	}
+	inArchive := archiveChecker()
+
+	for _, task := range tasks {
+		prev, existed := previous[task.UUID]
+		delete(previous, task.UUID)
+		switch {
+		case !existed && !inArchive(task.UUID):
+			queueWebhook(eventTaskAdded, fmt.Sprintf("Task %d added: %s", task.ID, task.Description), &task, nil)
+		case existed && !config.Workflow.IsDone(prev.Status) && config.Workflow.IsDone(task.Status):
+			queueWebhook(eventTaskCompleted, fmt.Sprintf("Task %d completed: %s", task.ID, task.Description), &task, nil)
+		}
+	}
+	for _, task := range saved {
+		if _, removed := previous[task.UUID]; removed && !inArchive(task.UUID) {
+			queueWebhook(eventTaskDeleted, fmt.Sprintf("Task %d deleted: %s", task.ID, task.Description), &task, nil)
+		}
+	}
+}
+
+// noteExpenseEvents queues the webhook payloads for the expenses added
+// between saved and expenses that reach a webhook's threshold.
+func noteExpenseEvents(saved, expenses []Expense) {
+	known := map[int]bool{}
+	for _, e := range saved {
+		known[e.ID] = true
+	}
+	for _, e := range expenses {
+		if !known[e.ID] {
+			text := fmt.Sprintf("Expense %d of %s: %s", e.ID, formatAmount(e.Amount, cmp.Or(e.Currency, config.Currency.Base)), e.Description)
+			queueWebhook(eventExpenseOver, text, nil, &e)
+		}
+	}
+}
+
+// queueWebhook queues a payload if any webhook wants the event.
+func queueWebhook(event, text string, task *Task, expense *Expense) {
+	if !slices.ContainsFunc(config.Webhooks, func(w Webhook) bool { return w.wants(event) }) {
+		return
+	}
+	pendingWebhooks = append(pendingWebhooks, webhookPayload{
+		Event:   event,
+		Text:    text,
+		Project: cmp.Or(currentProject, defaultProject),
+		At:      clock(),
+		Task:    task,
+		Expense: expense,
+	})
+}
+
+// dropWebhooks forgets the payloads of changes that were rolled back.
+func dropWebhooks() {
+	pendingWebhooks = nil
+}
+
+// sendWebhooks delivers the queued payloads to the webhooks that want them.
+// A delivery that fails is retried with a growing wait; one that still
+// fails is a warning, since the change itself was saved.
+func sendWebhooks() {
+	payloads := pendingWebhooks
+	dropWebhooks()
+	for _, p := range payloads {
+		for _, w := range config.Webhooks {
+			if !w.wants(p.Event) {
+				continue
+			}
+			if p.Event == eventExpenseOver && p.Expense.Amount < w.Threshold {
+				continue
+			}
+			if err := deliverWebhook(w, p); err != nil {
+				fmt.Fprintf(stdout, "Warning: could not send %s webhook: %v\n", p.Event, err)
+			}
+		}
+	}
+}
+
+// deliverWebhook posts a payload to w, signed if it has a secret.
+func deliverWebhook(w Webhook, p webhookPayload) error {
+	target, err := resolveSecret(w.URL)
+	if err != nil {
+		return err
+	}
+	secret, err := resolveSecret(w.Secret)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("error marshalling JSON: %w", err)
+	}
+
+	header := http.Header{"X-Task-Event": {p.Event}}
+	if secret != "" {
+		header.Set("X-Task-Signature", "sha256="+signPayload(secret, body))
+	}
+	return postJSON(target, body, header)
+}
+
+// postJSON posts a JSON body to target, trying up to webhookAttempts times
+// while the request fails or the server answers with a 5xx or 429 status.
+func postJSON(target string, body []byte, header http.Header) error {
+	client := &http.Client{Timeout: webhookTimeout}
+	wait := webhookBackoff
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "task-cli")
+
+		resp, err := client.Do(req)
+		retry := err != nil
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode >= 300 {
+				err = fmt.Errorf("unexpected status %s", resp.Status)
+				retry = resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+			}
+		}
+		if err == nil || !retry || attempt == webhookAttempts {
+			return err
+		}
+		time.Sleep(wait)
+		wait *= 2
+	}
+}
+
+// signPayload returns the hex HMAC-SHA256 of body keyed with secret, which
+// receivers compute in turn to check a payload came from here.
+func signPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
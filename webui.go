@@ -0,0 +1,267 @@
+package main
+
+import (
+	"cmp"
+	"embed"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+// maxFormSize limits the payload of the requests the web UI makes.
+const maxFormSize = 64 << 10
+
+// webFiles holds the single-page web UI served at /.
+//
+//go:embed web
+var webFiles embed.FS
+
+// webUI returns the handler serving the web UI.
+func webUI() http.Handler {
+	files, _ := fs.Sub(webFiles, "web")
+	return http.FileServerFS(files)
+}
+
+// writeError responds with err and the status for its kind.
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch code, _ := classifyError(err); code {
+	case "validation":
+		status = http.StatusBadRequest
+	case "not_found":
+		status = http.StatusNotFound
+	case "conflict":
+		status = http.StatusConflict
+	}
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// decodeForm decodes the JSON body of a request into v, or responds with
+// 400 Bad Request and reports false. The body must be sent as JSON: any web
+// page can post a form or plain text to the server without the browser
+// asking it first, but not JSON.
+func decodeForm(w http.ResponseWriter, r *http.Request, v any) bool {
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+		writeJSON(w, http.StatusUnsupportedMediaType, map[string]string{"error": "expected Content-Type: application/json"})
+		return false
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxFormSize)).Decode(v); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON: " + err.Error()})
+		return false
+	}
+	return true
+}
+
+// handleWorkflow responds with the statuses of the workflow, in order, and
+// those that count as done.
+func handleWorkflow(w http.ResponseWriter, r *http.Request) {
+	statuses := config.Workflow.StatusNames()
+	done := []string{}
+	for _, status := range statuses {
+		if config.Workflow.IsDone(status) {
+			done = append(done, status)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string][]string{"statuses": statuses, "done": done})
+}
+
+// handleAddTask adds a task to the project in the request, or the default
+// project, and responds with it.
+func handleAddTask(w http.ResponseWriter, r *http.Request) {
+	var form struct {
+		Description string `json:"description"`
+		Project     string `json:"project"`
+	}
+	if !decodeForm(w, r, &form) {
+		return
+	}
+	description := strings.TrimSpace(form.Description)
+	if description == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "expected a description"})
+		return
+	}
+
+	serveMu.Lock()
+	defer serveMu.Unlock()
+
+	project := cmp.Or(form.Project, defaultProject)
+	saved := currentProject
+	defer func() { currentProject = saved }()
+	if !selectRequestProject(w, project) {
+		return
+	}
+	task, err := tr().AddTask(description)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, apiTask{task, project})
+}
+
+// selectRequestProject selects the project named in a request, or responds
+// with 400 Bad Request for a name that is not one, such as "../x", or 404
+// Not Found for a project that does not exist, and reports false.
+func selectRequestProject(w http.ResponseWriter, project string) bool {
+	if err := selectProject(project); err != nil {
+		status := http.StatusNotFound
+		if errors.Is(err, tracker.ErrInvalid) {
+			status = http.StatusBadRequest
+		}
+		writeJSON(w, status, map[string]string{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
+// inTaskProject calls fn with the project of the task with the UUID in the
+// request selected, or responds with 404 Not Found if there is none.
+func inTaskProject(w http.ResponseWriter, r *http.Request, fn func(task apiTask)) {
+	tasks, err := allTasks()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	for _, task := range tasks {
+		if task.UUID == r.PathValue("uuid") {
+			saved := currentProject
+			defer func() { currentProject = saved }()
+			selectProject(task.Project)
+			fn(task)
+			return
+		}
+	}
+	writeJSON(w, http.StatusNotFound, map[string]string{"error": "task not found"})
+}
+
+// handleMarkTask moves a task to the status in the request, awarding points
+// when it is completed, and responds with the task.
+func handleMarkTask(w http.ResponseWriter, r *http.Request) {
+	var form struct {
+		Status string `json:"status"`
+	}
+	if !decodeForm(w, r, &form) {
+		return
+	}
+	if err := checkStatus(form.Status); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	serveMu.Lock()
+	defer serveMu.Unlock()
+
+	inTaskProject(w, r, func(task apiTask) {
+		change, err := tr().SetStatus(task.ID, form.Status)
+		if err == nil && change.Completed {
+			err = awardPoints(change.Task)
+		}
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, apiTask{change.Task, task.Project})
+	})
+}
+
+// handleDeleteTask deletes a task.
+func handleDeleteTask(w http.ResponseWriter, r *http.Request) {
+	serveMu.Lock()
+	defer serveMu.Unlock()
+
+	inTaskProject(w, r, func(task apiTask) {
+		if err := tr().DeleteTask(task.ID); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// apiExpense is an expense in responses, with the project it belongs to.
+type apiExpense struct {
+	Expense
+	Project string `json:"project"`
+}
+
+// handleExpenses responds with the expenses of every project.
+func handleExpenses(w http.ResponseWriter, r *http.Request) {
+	serveMu.Lock()
+	defer serveMu.Unlock()
+
+	all := []apiExpense{}
+	err := forEachProject(func(project string) error {
+		expenses, err := loadExpenses()
+		for _, e := range expenses {
+			all = append(all, apiExpense{e, project})
+		}
+		return err
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, all)
+}
+
+// requestedExpense returns the expense a request to add one describes,
+// dated today unless it gives a date.
+func requestedExpense(amount float64, description, category, currency, date string) (Expense, error) {
+	e := Expense{Amount: amount, Description: strings.TrimSpace(description), Category: strings.TrimSpace(category), Date: clock()}
+	var err error
+	switch {
+	case e.Amount < 0:
+		err = usagef("invalid amount %g", e.Amount)
+	case e.Description == "":
+		err = usagef("expected a description")
+	case date != "":
+		e.Date, err = parseDate(date, clock())
+	}
+	if err == nil && currency != "" {
+		e.Currency, err = parseCurrency(currency)
+	}
+	return e, err
+}
+
+// handleAddExpense records an expense in the project in the request, or the
+// default project, categorized by the rules if it has no category, and
+// responds with it.
+func handleAddExpense(w http.ResponseWriter, r *http.Request) {
+	var form struct {
+		Amount      float64 `json:"amount"`
+		Description string  `json:"description"`
+		Category    string  `json:"category"`
+		Currency    string  `json:"currency"`
+		Date        string  `json:"date"` // Today if empty.
+		Project     string  `json:"project"`
+	}
+	if !decodeForm(w, r, &form) {
+		return
+	}
+	e, err := requestedExpense(form.Amount, form.Description, form.Category, form.Currency, form.Date)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	serveMu.Lock()
+	defer serveMu.Unlock()
+
+	project := cmp.Or(form.Project, defaultProject)
+	saved := currentProject
+	defer func() { currentProject = saved }()
+	if !selectRequestProject(w, project) {
+		return
+	}
+	applyCategoryRules(&e)
+	added, err := tr().AddExpense(e)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, apiExpense{added, project})
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/arijit-gogoi/expense-tracker-go/pkg/tracker"
+)
+
+// projectWorkload totals the open tasks per assignee across all projects.
+func projectWorkload(now time.Time) ([]tracker.Workload, error) {
+	var all []Task
+	err := forEachProject(func(string) error {
+		tasks, err := loadTasks()
+		all = append(all, tasks...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tracker.SummarizeWorkload(all, config.Workflow, now), nil
+}
+
+// reportWorkload prints the open tasks, estimated hours and overdue tasks
+// of each assignee across projects, busiest first.
+func reportWorkload() error {
+	loads, err := projectWorkload(clock())
+	if err != nil {
+		return err
+	}
+	if len(loads) == 0 {
+		fmt.Fprintln(stdout, "No open tasks found.")
+		return nil
+	}
+
+	fmt.Fprintln(stdout, "--- Workload ---")
+	fmt.Fprintf(stdout, "  %-16s %5s %10s %8s\n", "Assignee", "Open", "Estimated", "Overdue")
+	unestimated := false
+	for _, load := range loads {
+		estimate := formatHours(load.EstimatedHours)
+		if load.Unestimated > 0 {
+			estimate += "+"
+			unestimated = true
+		}
+		overdue := fmt.Sprintf("%8d", load.Overdue)
+		if load.Overdue > 0 {
+			overdue = colorize(overdue, "red")
+		}
+		fmt.Fprintf(stdout, "  %-16s %5d %10s %s\n", load.Assignee, load.Open, estimate, overdue)
+	}
+	if unestimated {
+		fmt.Fprintln(stdout, "  + open tasks without an estimate (task estimate <id> <hours>)")
+	}
+	fmt.Fprintln(stdout, "----------------")
+
+	return nil
+}
+
+// formatHours renders a number of hours, e.g. "1.5h".
+func formatHours(hours float64) string {
+	return strconv.FormatFloat(hours, 'f', -1, 64) + "h"
+}